@@ -127,6 +127,10 @@ func (a *Agent) Run() error {
 		case pbagent.MongoDBConnectionWrite:
 			a.processMongoDBProtocol(pkt)
 
+		// Redis Protocol
+		case pbagent.RedisConnectionWrite:
+			a.processRedisProtocol(pkt)
+
 		// raw tcp
 		case pbagent.TCPConnectionWrite:
 			a.processTCPWriteServer(pkt)
@@ -204,6 +208,11 @@ func (a *Agent) processSessionOpen(pkt *pb.Packet) {
 				},
 			})
 		}
+		if err := a.provisionDatabaseCredentials(sessionIDKey, connParams); err != nil {
+			log.With("sid", sessionIDKey).Warnf("failed provisioning database credentials, err=%v", err)
+			a.sendClientSessionClose(sessionIDKey, err.Error(), pb.SpecClientExitCodeKey+"=1")
+			return
+		}
 		a.connStore.Set(string(sessionID), connParams)
 		_ = a.client.Send(&pb.Packet{
 			Type: pbclient.SessionOpenOK,
@@ -339,6 +348,8 @@ func (a *Agent) buildConnectionParams(pkt *pb.Packet) (*pb.AgentConnectionParams
 			connParams.EnvVars["envvar:MYSQL_PWD"] = b64EncPaswd
 		case pb.ConnectionTypeMSSQL:
 			connParams.EnvVars["envvar:SQLCMDPASSWORD"] = b64EncPaswd
+		case pb.ConnectionTypeRedis:
+			connParams.EnvVars["envvar:REDISCLI_AUTH"] = b64EncPaswd
 		}
 	}
 	return connParams, nil
@@ -351,7 +362,8 @@ func (a *Agent) checkTCPLiveness(pkt *pb.Packet, envVars map[string]any) error {
 		connType == pb.ConnectionTypeTCP ||
 		connType == pb.ConnectionTypeMySQL ||
 		connType == pb.ConnectionTypeMSSQL ||
-		connType == pb.ConnectionTypeMongoDB {
+		connType == pb.ConnectionTypeMongoDB ||
+		connType == pb.ConnectionTypeRedis {
 		connEnvVars, err := parseConnectionEnvVars(envVars, connType)
 		if err != nil {
 			return err
@@ -529,6 +541,13 @@ func parseConnectionEnvVars(envVars map[string]any, connType pb.ConnectionType)
 			return nil, fmt.Errorf("missing required secrets for mongodb connection [HOST, USER, PASS]")
 		}
 
+	case pb.ConnectionTypeRedis:
+		if env.port == "" {
+			env.port = "6379"
+		}
+		if env.host == "" {
+			return nil, fmt.Errorf("missing required secrets for redis connection [HOST]")
+		}
 	case pb.ConnectionTypeTCP:
 		if env.host == "" || env.port == "" {
 			return nil, fmt.Errorf("missing required environment for connection [HOST, PORT]")
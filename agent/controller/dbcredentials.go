@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+)
+
+const (
+	dbCredentialsStoreKeyPrefix = "dbcredentials"
+	// the maximum lifetime of a temporary user in case the agent fails to remove it
+	dbCredentialsMaxTTL  = time.Hour * 24
+	dbCredentialsTimeout = time.Second * 30
+)
+
+// dbCredentials is a temporary database user created for a session.
+// It implements the io.Closer interface allowing the session cleanup
+// to remove the user from the database.
+type dbCredentials struct {
+	sid      string
+	connType pb.ConnectionType
+	admin    *connEnv
+	user     string
+	password string
+}
+
+type dbCredentialsTemplateData struct {
+	User     string
+	Database string
+}
+
+// provisionDatabaseCredentials creates a temporary user applying the grants of the
+// connection. The credentials of the connection are replaced by the temporary ones.
+func (a *Agent) provisionDatabaseCredentials(sid string, connParams *pb.AgentConnectionParams) error {
+	if len(connParams.DatabaseCredentialsGrants) == 0 {
+		return nil
+	}
+	connType := pb.ConnectionType(connParams.ConnectionType)
+	if connType != pb.ConnectionTypePostgres && connType != pb.ConnectionTypeMySQL {
+		return fmt.Errorf("database credentials manager is not supported for %v connections", connType)
+	}
+	admin, err := parseConnectionEnvVars(connParams.EnvVars, connType)
+	if err != nil {
+		return err
+	}
+	creds, err := newDBCredentials(sid, connType, admin)
+	if err != nil {
+		return err
+	}
+	statements, err := creds.createStatements(connParams.DatabaseCredentialsGrants)
+	if err != nil {
+		return err
+	}
+	if err := creds.create(statements); err != nil {
+		return fmt.Errorf("failed creating temporary database user: %v", err)
+	}
+	log.With("sid", sid).Infof("temporary %v user %v created, grants=%v", connType, creds.user, len(statements)-1)
+
+	b64EncPasswd := b64Enc([]byte(creds.password))
+	connParams.EnvVars["envvar:USER"] = b64Enc([]byte(creds.user))
+	connParams.EnvVars["envvar:PASS"] = b64EncPasswd
+	switch connType {
+	case pb.ConnectionTypePostgres:
+		connParams.EnvVars["envvar:PGPASSWORD"] = b64EncPasswd
+	case pb.ConnectionTypeMySQL:
+		connParams.EnvVars["envvar:MYSQL_PWD"] = b64EncPasswd
+	}
+	a.connStore.Set(fmt.Sprintf("%s:%s", dbCredentialsStoreKeyPrefix, sid), creds)
+	return nil
+}
+
+func newDBCredentials(sid string, connType pb.ConnectionType, admin *connEnv) (*dbCredentials, error) {
+	userSuffix := make([]byte, 6)
+	password := make([]byte, 24)
+	if _, err := rand.Read(userSuffix); err != nil {
+		return nil, fmt.Errorf("failed generating user: %v", err)
+	}
+	if _, err := rand.Read(password); err != nil {
+		return nil, fmt.Errorf("failed generating password: %v", err)
+	}
+	return &dbCredentials{
+		sid:      sid,
+		connType: connType,
+		admin:    admin,
+		// mysql has a limit of 32 characters for user names
+		user:     fmt.Sprintf("hoop_%s", hex.EncodeToString(userSuffix)),
+		password: hex.EncodeToString(password),
+	}, nil
+}
+
+func (c *dbCredentials) createStatements(grants []string) ([]string, error) {
+	var statements []string
+	switch c.connType {
+	case pb.ConnectionTypePostgres:
+		validUntil := time.Now().UTC().Add(dbCredentialsMaxTTL).Format(time.RFC3339)
+		statements = append(statements, fmt.Sprintf(`CREATE ROLE "%s" WITH LOGIN PASSWORD '%s' VALID UNTIL '%s'`,
+			c.user, c.password, validUntil))
+	case pb.ConnectionTypeMySQL:
+		statements = append(statements, fmt.Sprintf(`CREATE USER '%s'@'%%' IDENTIFIED BY '%s' PASSWORD EXPIRE INTERVAL 1 DAY`,
+			c.user, c.password))
+	}
+	data := dbCredentialsTemplateData{User: c.user, Database: c.admin.dbname}
+	for _, grant := range grants {
+		tmpl, err := template.New("").Option("missingkey=error").Parse(grant)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing grant template: %v", err)
+		}
+		var stmt bytes.Buffer
+		if err := tmpl.Execute(&stmt, data); err != nil {
+			return nil, fmt.Errorf("failed executing grant template: %v", err)
+		}
+		statements = append(statements, stmt.String())
+	}
+	return statements, nil
+}
+
+// Close removes the temporary user from the database
+func (c *dbCredentials) Close() error {
+	var statements []string
+	switch c.connType {
+	case pb.ConnectionTypePostgres:
+		statements = []string{
+			// terminate any remaining session of the user, otherwise the role can't be dropped
+			fmt.Sprintf(`SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = '%s'`, c.user),
+			fmt.Sprintf(`REASSIGN OWNED BY "%s" TO CURRENT_USER`, c.user),
+			fmt.Sprintf(`DROP OWNED BY "%s"`, c.user),
+			fmt.Sprintf(`DROP ROLE IF EXISTS "%s"`, c.user),
+		}
+	case pb.ConnectionTypeMySQL:
+		statements = []string{fmt.Sprintf(`DROP USER IF EXISTS '%s'@'%%'`, c.user)}
+	}
+	if err := c.exec(statements); err != nil {
+		log.With("sid", c.sid).Errorf("failed removing temporary %v user %v, err=%v", c.connType, c.user, err)
+		return err
+	}
+	log.With("sid", c.sid).Infof("temporary %v user %v removed", c.connType, c.user)
+	return nil
+}
+
+// create runs the statements creating the user and applying its grants.
+// Postgres rolls back all of them when one fails, mysql commits each statement
+// implicitly, in this case the user is removed when any of them fails.
+func (c *dbCredentials) create(statements []string) error {
+	err := c.exec(statements)
+	if err != nil && c.connType == pb.ConnectionTypeMySQL {
+		_ = c.Close()
+	}
+	return err
+}
+
+// exec runs the statements using the client of the database with the credentials of the connection.
+// Postgres runs them in a single transaction, mysql stops at the first statement that fails.
+func (c *dbCredentials) exec(statements []string) error {
+	ctx, cancelFn := context.WithTimeout(context.Background(), dbCredentialsTimeout)
+	defer cancelFn()
+	var cmd *exec.Cmd
+	switch c.connType {
+	case pb.ConnectionTypePostgres:
+		dbname := c.admin.dbname
+		if dbname == "" {
+			dbname = "postgres"
+		}
+		cmd = exec.CommandContext(ctx, "psql", "-v", "ON_ERROR_STOP=1", "--single-transaction", "-q",
+			"-h", c.admin.host, "-p", c.admin.port, "-U", c.admin.user, "-d", dbname, "-f", "-")
+		cmd.Env = append(os.Environ(), "PGPASSWORD="+c.admin.pass)
+		if c.admin.postgresSSLMode != "" {
+			cmd.Env = append(cmd.Env, "PGSSLMODE="+c.admin.postgresSSLMode)
+		}
+	case pb.ConnectionTypeMySQL:
+		cmd = exec.CommandContext(ctx, "mysql", "-h", c.admin.host, "-P", c.admin.port, "-u", c.admin.user)
+		cmd.Env = append(os.Environ(), "MYSQL_PWD="+c.admin.pass)
+	default:
+		return fmt.Errorf("connection type %v not supported", c.connType)
+	}
+	cmd.Stdin = strings.NewReader(strings.Join(statements, ";\n") + ";\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	pb "github.com/hoophq/hoop/common/proto"
+)
+
+// fakeDatabaseClient records the input of each execution in a log file
+// and fails when the input contains a grant statement
+const fakeDatabaseClient = `#!/bin/sh
+input=$(cat)
+printf '%s\n--\n' "$input" >> "$FAKE_CLIENT_LOG"
+case "$input" in
+	*GRANT*) echo "ERROR 1044 (42000): Access denied" >&2; exit 1 ;;
+esac
+`
+
+func TestProvisionDatabaseCredentialsFailedGrant(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake database client requires a shell")
+	}
+	for _, tt := range []struct {
+		msg       string
+		connType  pb.ConnectionType
+		client    string
+		wantCalls []string
+	}{
+		{
+			msg:       "it must remove the mysql user when a grant fails",
+			connType:  pb.ConnectionTypeMySQL,
+			client:    "mysql",
+			wantCalls: []string{"CREATE USER", "DROP USER IF EXISTS"},
+		},
+		{
+			msg:       "it must rely on the transaction rollback of postgres when a grant fails",
+			connType:  pb.ConnectionTypePostgres,
+			client:    "psql",
+			wantCalls: []string{"CREATE ROLE"},
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			binDir := t.TempDir()
+			logFile := filepath.Join(binDir, "client.log")
+			if err := os.WriteFile(filepath.Join(binDir, tt.client), []byte(fakeDatabaseClient), 0700); err != nil {
+				t.Fatalf("failed writing fake client: %v", err)
+			}
+			t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+			t.Setenv("FAKE_CLIENT_LOG", logFile)
+
+			connParams := &pb.AgentConnectionParams{
+				ConnectionType:            tt.connType.String(),
+				DatabaseCredentialsGrants: []string{`GRANT SELECT ON {{ .Database }}.* TO '{{ .User }}'@'%'`},
+				EnvVars: map[string]any{
+					"envvar:HOST": b64Enc([]byte("127.0.0.1")),
+					"envvar:USER": b64Enc([]byte("root")),
+					"envvar:PASS": b64Enc([]byte("secret")),
+					"envvar:DB":   b64Enc([]byte("shop")),
+				},
+			}
+			err := (&Agent{}).provisionDatabaseCredentials("sid", connParams)
+			if err == nil || !strings.Contains(err.Error(), "Access denied") {
+				t.Fatalf("expected access denied error, got=%v", err)
+			}
+			if got := connParams.EnvVars["envvar:USER"]; got != b64Enc([]byte("root")) {
+				t.Errorf("expected the credentials of the connection to be kept, got user=%v", got)
+			}
+
+			data, err := os.ReadFile(logFile)
+			if err != nil {
+				t.Fatalf("failed reading client log: %v", err)
+			}
+			calls := strings.Split(strings.TrimSuffix(string(data), "--\n"), "--\n")
+			if len(calls) != len(tt.wantCalls) {
+				t.Fatalf("expected %v executions, got=%v: %q", len(tt.wantCalls), len(calls), calls)
+			}
+			for i, want := range tt.wantCalls {
+				if !strings.HasPrefix(calls[i], want) {
+					t.Errorf("execution %v: expected to start with %q, got=%q", i, want, calls[i])
+				}
+			}
+		})
+	}
+}
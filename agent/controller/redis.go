@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+	pbclient "github.com/hoophq/hoop/common/proto/client"
+	"github.com/hoophq/hoop/common/redistypes"
+)
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *redisConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func (a *Agent) processRedisProtocol(pkt *pb.Packet) {
+	sid := string(pkt.Spec[pb.SpecGatewaySessionID])
+	streamClient := pb.NewStreamWriter(a.client, pbclient.RedisConnectionWrite, pkt.Spec)
+	connParams := a.connectionParams(sid)
+	if connParams == nil {
+		log.With("sid", sid).Errorf("connection params not found")
+		a.sendClientSessionClose(sid, "connection params not found, contact the administrator")
+		return
+	}
+
+	clientConnectionID := string(pkt.Spec[pb.SpecClientConnectionID])
+	if clientConnectionID == "" && pkt.Payload != nil {
+		log.Errorf("connection id not found in memory")
+		a.sendClientSessionClose(sid, "connection id not found, contact the administrator")
+		return
+	}
+
+	// the credentials are managed by the agent, authentication commands
+	// sent by the client must not reach the server
+	payload, reply := rewriteRedisAuthCommand(pkt.Payload)
+	if reply != nil {
+		_, _ = streamClient.Write(reply)
+		return
+	}
+
+	clientConnectionIDKey := fmt.Sprintf("%s:%s", sid, string(clientConnectionID))
+	clientObj := a.connStore.Get(clientConnectionIDKey)
+	if serverWriter, ok := clientObj.(io.WriteCloser); ok {
+		if _, err := serverWriter.Write(payload); err != nil {
+			log.With("sid", sid).Errorf("failed sending packet, err=%v", err)
+			a.sendClientSessionClose(sid, "fail to write packet")
+			_ = serverWriter.Close()
+		}
+		return
+	}
+
+	connenv, err := parseConnectionEnvVars(connParams.EnvVars, pb.ConnectionTypeRedis)
+	if err != nil {
+		log.With("sid", sid).Errorf("redis credentials not found in memory, err=%v", err)
+		a.sendClientSessionClose(sid, "credentials are empty, contact the administrator")
+		return
+	}
+
+	log.With("sid", sid, "conn", clientConnectionID).Infof("starting redis connection at %v", connenv.Address())
+	serverConn, err := newRedisConn(connenv)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed connecting with redis server, err=%v", err)
+		log.With("sid", sid).Errorf(errMsg)
+		a.sendClientSessionClose(sid, errMsg)
+		return
+	}
+	a.connStore.Set(clientConnectionIDKey, serverConn)
+	go func() {
+		defer a.connStore.Del(clientConnectionIDKey)
+		if _, err := io.Copy(streamClient, serverConn); err != nil {
+			log.With("sid", sid).Infof("done copying redis connection, reason=%v", err)
+		}
+		a.sendClientTCPConnectionClose(sid, clientConnectionID)
+	}()
+	if _, err := serverConn.Write(payload); err != nil {
+		log.With("sid", sid).Errorf("failed writing first packet, err=%v", err)
+		_ = serverConn.Close()
+		a.sendClientSessionClose(sid, "fail to write packet")
+	}
+}
+
+// newRedisConn dials the redis server authenticating and selecting
+// the logical database based on the connection credentials
+func newRedisConn(env *connEnv) (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: time.Second * 10}
+	var conn net.Conn
+	var err error
+	if env.Get("tls") == "true" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", env.Address(), &tls.Config{
+			ServerName:         env.host,
+			InsecureSkipVerify: env.insecure,
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", env.Address())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed dialing server: %v", err)
+	}
+	rc := &redisConn{Conn: conn, r: bufio.NewReader(conn)}
+	var handshake [][]string
+	switch {
+	case env.user != "" && env.pass != "":
+		handshake = append(handshake, []string{"AUTH", env.user, env.pass})
+	case env.pass != "":
+		handshake = append(handshake, []string{"AUTH", env.pass})
+	}
+	if env.dbname != "" {
+		handshake = append(handshake, []string{"SELECT", env.dbname})
+	}
+	for _, args := range handshake {
+		if err := rc.roundTrip(args...); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed executing %s: %v", args[0], err)
+		}
+	}
+	return rc, nil
+}
+
+// roundTrip writes a command to the server expecting a simple string reply
+func (c *redisConn) roundTrip(args ...string) error {
+	if _, err := c.Conn.Write(redistypes.NewCommand(args...)); err != nil {
+		return err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) > 0 && redistypes.DataType(line[0]) == redistypes.TypeSimpleError {
+		return fmt.Errorf("%s", line[1:])
+	}
+	return nil
+}
+
+// rewriteRedisAuthCommand acknowledges AUTH commands and strips the
+// credentials from HELLO commands. It returns a reply when the
+// command must not be forwarded to the server.
+func rewriteRedisAuthCommand(payload []byte) ([]byte, []byte) {
+	pkt, err := redistypes.DecodeCommand(payload)
+	if err != nil || !pkt.IsAuth() {
+		return payload, nil
+	}
+	switch pkt.Command() {
+	case "AUTH":
+		return nil, redistypes.NewSimpleString("OK")
+	case "HELLO":
+		if len(pkt.Args) > 1 {
+			return redistypes.NewCommand("HELLO", string(pkt.Args[1])), nil
+		}
+		return redistypes.NewCommand("HELLO"), nil
+	}
+	return payload, nil
+}
@@ -17,6 +17,10 @@ const (
 	secretProviderAWSSecretsManager secretProviderType = "_aws"
 	// fetches secrets from environment variables mapped as json in unix environments
 	secretProviderEnvJSON secretProviderType = "_envjson"
+	// fetch secrets from hashicorp vault key value secrets engine (version 1)
+	secretProviderVaultKV1 secretProviderType = "_vaultkv1"
+	// fetch secrets from hashicorp vault key value secrets engine (version 2)
+	secretProviderVaultKV2 secretProviderType = "_vaultkv2"
 )
 
 // Decode environment variables based on the provider of a certain env.
@@ -27,6 +31,8 @@ func Decode(envVars map[string]any) (map[string]any, error) {
 	providerSingleton := map[secretProviderType]secretsGetter{
 		secretProviderAWSSecretsManager: nil,
 		secretProviderEnvJSON:           nil,
+		secretProviderVaultKV1:          nil,
+		secretProviderVaultKV2:          nil,
 	}
 	decodedEnvVars := map[string]any{}
 	var errors []string
@@ -60,6 +66,20 @@ func Decode(envVars map[string]any) (map[string]any, error) {
 				providerSingleton[secretProviderEnvJSON] = envJsonProv
 				provider = envJsonProv
 			}
+		case secretProviderVaultKV1, secretProviderVaultKV2:
+			provider = providerSingleton[attr.provider]
+			if provider == nil {
+				kvVersion := 1
+				if attr.provider == secretProviderVaultKV2 {
+					kvVersion = 2
+				}
+				vaultProv, err := newVaultProvider(kvVersion)
+				if err != nil {
+					return nil, fmt.Errorf("failed initializing vault provider, err=%v", err)
+				}
+				providerSingleton[attr.provider] = vaultProv
+				provider = vaultProv
+			}
 		default:
 			// it's not an secrets manager env definition
 			decodedEnvVars[envKey] = encEnvVal
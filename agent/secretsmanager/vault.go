@@ -0,0 +1,319 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/common/memory"
+)
+
+const (
+	defaultVaultAppRoleMountPath = "approle"
+	defaultVaultK8sMountPath     = "kubernetes"
+	defaultVaultK8sTokenPath     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// renew the token before it expires to avoid failures in flight
+	vaultTokenExpireThreshold = time.Second * 30
+)
+
+type vaultAuthMethod string
+
+const (
+	vaultAuthToken      vaultAuthMethod = "token"
+	vaultAuthAppRole    vaultAuthMethod = "approle"
+	vaultAuthKubernetes vaultAuthMethod = "kubernetes"
+)
+
+var (
+	vaultClientInstance *vaultClient
+	vaultClientMu       sync.Mutex
+)
+
+type vaultClient struct {
+	addr       string
+	namespace  string
+	httpClient *http.Client
+	authMethod vaultAuthMethod
+	authPath   string
+	authData   map[string]string
+	// the service account token is read on every login, kubernetes rotates it
+	k8sTokenPath string
+
+	mu            sync.Mutex
+	token         string
+	tokenExpireAt time.Time
+}
+
+type vaultResponse struct {
+	LeaseDuration int            `json:"lease_duration"`
+	Data          map[string]any `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+type vaultCachedSecret struct {
+	data     map[string]any
+	expireAt time.Time
+}
+
+// vaultProvider fetches secrets from the key value secrets engine (version 1 or 2)
+// of Hashicorp Vault. Version 1 could also be used to obtain dynamic secrets,
+// e.g.: _vaultkv1:database/creds/readonly:username
+type vaultProvider struct {
+	client    *vaultClient
+	kvVersion int
+	// secrets are cached by the lifetime of the provider,
+	// it guarantees that dynamic secrets are consistent in a session
+	cache memory.Store
+}
+
+func newVaultProvider(kvVersion int) (*vaultProvider, error) {
+	client, err := getVaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return &vaultProvider{client: client, kvVersion: kvVersion, cache: memory.New()}, nil
+}
+
+func (p *vaultProvider) GetKey(secretID, secretKey string) (string, error) {
+	var data map[string]any
+	if obj, ok := p.cache.Get(secretID).(*vaultCachedSecret); ok {
+		if obj.expireAt.IsZero() || time.Now().UTC().Before(obj.expireAt) {
+			data = obj.data
+		}
+	}
+	if data == nil {
+		resp, err := p.client.read(p.apiPath(secretID))
+		if err != nil {
+			return "", fmt.Errorf("(%v) %v", secretID, err)
+		}
+		data = resp.Data
+		if p.kvVersion == 2 {
+			data, _ = resp.Data["data"].(map[string]any)
+		}
+		if data == nil {
+			return "", fmt.Errorf("secret id %s has no data", secretID)
+		}
+		obj := &vaultCachedSecret{data: data}
+		if resp.LeaseDuration > 0 {
+			obj.expireAt = time.Now().UTC().Add(time.Duration(resp.LeaseDuration) * time.Second)
+		}
+		p.cache.Set(secretID, obj)
+	}
+	if v, ok := data[secretKey]; ok {
+		return fmt.Sprintf("%v", v), nil
+	}
+	return "", fmt.Errorf("secret id %s found, but key %s was not", secretID, secretKey)
+}
+
+// apiPath returns the path to read a secret. The version 2 of the kv engine
+// requires the data prefix after the mount path: <mount>/data/<path>
+func (p *vaultProvider) apiPath(secretID string) string {
+	secretID = strings.Trim(secretID, "/")
+	if p.kvVersion != 2 {
+		return secretID
+	}
+	mount, path, found := strings.Cut(secretID, "/")
+	if !found || strings.HasPrefix(path, "data/") {
+		return secretID
+	}
+	return fmt.Sprintf("%s/data/%s", mount, path)
+}
+
+// getVaultClient returns a client shared by all sessions,
+// it allows reusing the authentication token until it expires
+func getVaultClient() (*vaultClient, error) {
+	vaultClientMu.Lock()
+	defer vaultClientMu.Unlock()
+	if vaultClientInstance != nil {
+		return vaultClientInstance, nil
+	}
+	client, err := newVaultClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	vaultClientInstance = client
+	return client, nil
+}
+
+// newVaultClientFromEnv configures a client based on the environment variables
+// of the agent. The authentication method is chosen by the ones that are set:
+//
+// - VAULT_TOKEN: token authentication
+// - VAULT_APP_ROLE_ID, VAULT_APP_SECRET_ID: AppRole authentication
+// - VAULT_K8S_ROLE: Kubernetes authentication using the service account token
+func newVaultClientFromEnv() (*vaultClient, error) {
+	addr := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return nil, fmt.Errorf("missing VAULT_ADDR environment variable")
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: os.Getenv("VAULT_SKIP_VERIFY") == "true"}
+	if caFile := os.Getenv("VAULT_CACERT"); caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading VAULT_CACERT: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed loading certificates from VAULT_CACERT")
+		}
+	}
+	c := &vaultClient{
+		addr:      addr,
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		httpClient: &http.Client{
+			Timeout:   time.Second * 15,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+	}
+	switch {
+	case os.Getenv("VAULT_TOKEN") != "":
+		c.authMethod = vaultAuthToken
+		c.token = os.Getenv("VAULT_TOKEN")
+	case os.Getenv("VAULT_APP_ROLE_ID") != "":
+		c.authMethod = vaultAuthAppRole
+		c.authPath = envOrDefault("VAULT_APPROLE_MOUNT_PATH", defaultVaultAppRoleMountPath)
+		c.authData = map[string]string{
+			"role_id":   os.Getenv("VAULT_APP_ROLE_ID"),
+			"secret_id": os.Getenv("VAULT_APP_SECRET_ID"),
+		}
+	case os.Getenv("VAULT_K8S_ROLE") != "":
+		c.authMethod = vaultAuthKubernetes
+		c.authPath = envOrDefault("VAULT_K8S_MOUNT_PATH", defaultVaultK8sMountPath)
+		c.authData = map[string]string{"role": os.Getenv("VAULT_K8S_ROLE")}
+		c.k8sTokenPath = envOrDefault("VAULT_K8S_TOKEN_PATH", defaultVaultK8sTokenPath)
+		if _, err := c.loginData(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("missing vault authentication, set VAULT_TOKEN, VAULT_APP_ROLE_ID or VAULT_K8S_ROLE")
+	}
+	log.Infof("vault client configured, addr=%v, auth-method=%v", c.addr, c.authMethod)
+	return c, nil
+}
+
+// getToken returns the authentication token, it logs in again
+// when the token is close to expiring
+func (c *vaultClient) getToken(forceLogin bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authMethod == vaultAuthToken {
+		return c.token, nil
+	}
+	hasExpired := !c.tokenExpireAt.IsZero() &&
+		time.Now().UTC().Add(vaultTokenExpireThreshold).After(c.tokenExpireAt)
+	if c.token != "" && !hasExpired && !forceLogin {
+		return c.token, nil
+	}
+	authData, err := c.loginData()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(http.MethodPost, fmt.Sprintf("auth/%s/login", c.authPath), "", authData)
+	if err != nil {
+		return "", fmt.Errorf("failed authenticating with %v method: %v", c.authMethod, err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("failed authenticating with %v method: empty token", c.authMethod)
+	}
+	c.token = resp.Auth.ClientToken
+	c.tokenExpireAt = time.Time{}
+	if resp.Auth.LeaseDuration > 0 {
+		c.tokenExpireAt = time.Now().UTC().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)
+	}
+	log.Infof("vault authenticated with %v method, token-expire-at=%v", c.authMethod, c.tokenExpireAt.Format(time.RFC3339))
+	return c.token, nil
+}
+
+// loginData returns the payload of the login request, the kubernetes
+// service account token is read from its file on each login
+func (c *vaultClient) loginData() (map[string]string, error) {
+	if c.authMethod != vaultAuthKubernetes {
+		return c.authData, nil
+	}
+	jwt, err := os.ReadFile(c.k8sTokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading kubernetes service account token: %v", err)
+	}
+	return map[string]string{
+		"role": c.authData["role"],
+		"jwt":  strings.TrimSpace(string(jwt)),
+	}, nil
+}
+
+// read a secret, it authenticates once again in case the token is revoked
+func (c *vaultClient) read(path string) (*vaultResponse, error) {
+	token, err := c.getToken(false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(http.MethodGet, path, token, nil)
+	if err == errVaultForbidden && c.authMethod != vaultAuthToken {
+		if token, err = c.getToken(true); err != nil {
+			return nil, err
+		}
+		resp, err = c.do(http.MethodGet, path, token, nil)
+	}
+	return resp, err
+}
+
+var errVaultForbidden = fmt.Errorf("permission denied")
+
+func (c *vaultClient) do(method, path, token string, body any) (*vaultResponse, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/v1/%s", c.addr, path), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	var resp vaultResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed decoding response (status=%v): %v", httpResp.StatusCode, err)
+	}
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+		return &resp, nil
+	case http.StatusForbidden:
+		return nil, errVaultForbidden
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("secret not found")
+	}
+	return nil, fmt.Errorf("status=%v, errors=%v", httpResp.StatusCode, resp.Errors)
+}
+
+func envOrDefault(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
@@ -0,0 +1,217 @@
+package secretsmanager
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newVaultDevServer emulates the api of a vault server running in dev mode
+func newVaultDevServer(t *testing.T, tokenTTL int) (*httptest.Server, *int32, *int32) {
+	var logins, dynamicCreds int32
+	mux := http.NewServeMux()
+	write := func(w http.ResponseWriter, status int, obj any) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(obj)
+	}
+	authenticated := func(w http.ResponseWriter, r *http.Request) bool {
+		token := r.Header.Get("X-Vault-Token")
+		if token == "root" || token == fmt.Sprintf("approle-token-%v", atomic.LoadInt32(&logins)) {
+			return true
+		}
+		write(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return false
+	}
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role-id" || body["secret_id"] != "secret-id" {
+			write(w, http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret id"}})
+			return
+		}
+		n := atomic.AddInt32(&logins, 1)
+		write(w, http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token":   fmt.Sprintf("approle-token-%v", n),
+			"lease_duration": tokenTTL,
+		}})
+	})
+	// the service account token is valid while it's the current one of the pod
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		currentJWT, _ := os.ReadFile(os.Getenv("VAULT_K8S_TOKEN_PATH"))
+		if body["role"] != "agent" || body["jwt"] != strings.TrimSpace(string(currentJWT)) {
+			write(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		n := atomic.AddInt32(&logins, 1)
+		write(w, http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token":   fmt.Sprintf("approle-token-%v", n),
+			"lease_duration": tokenTTL,
+		}})
+	})
+	mux.HandleFunc("/v1/secret/data/myapp", func(w http.ResponseWriter, r *http.Request) {
+		if authenticated(w, r) {
+			write(w, http.StatusOK, map[string]any{"data": map[string]any{
+				"data":     map[string]any{"HOST": "127.0.0.1", "PORT": 5432},
+				"metadata": map[string]any{"version": 1},
+			}})
+		}
+	})
+	mux.HandleFunc("/v1/kv/myapp", func(w http.ResponseWriter, r *http.Request) {
+		if authenticated(w, r) {
+			write(w, http.StatusOK, map[string]any{"data": map[string]any{"USER": "bob"}})
+		}
+	})
+	mux.HandleFunc("/v1/database/creds/readonly", func(w http.ResponseWriter, r *http.Request) {
+		if authenticated(w, r) {
+			n := atomic.AddInt32(&dynamicCreds, 1)
+			write(w, http.StatusOK, map[string]any{
+				"lease_duration": 3600,
+				"data":           map[string]any{"username": fmt.Sprintf("v-user-%v", n), "password": fmt.Sprintf("v-pwd-%v", n)},
+			})
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &logins, &dynamicCreds
+}
+
+func resetVaultClient() {
+	vaultClientMu.Lock()
+	defer vaultClientMu.Unlock()
+	vaultClientInstance = nil
+}
+
+func encodeEnvVars(envVars map[string]string) map[string]any {
+	encEnvVars := map[string]any{}
+	for key, val := range envVars {
+		encEnvVars[key] = base64.StdEncoding.EncodeToString([]byte(val))
+	}
+	return encEnvVars
+}
+
+func decodeEnvVars(t *testing.T, envVars map[string]any) map[string]string {
+	decEnvVars := map[string]string{}
+	for key, val := range envVars {
+		data, err := base64.StdEncoding.DecodeString(fmt.Sprintf("%v", val))
+		if err != nil {
+			t.Fatalf("failed decoding env %v, err=%v", key, err)
+		}
+		decEnvVars[key] = string(data)
+	}
+	return decEnvVars
+}
+
+func TestVaultTokenAuth(t *testing.T) {
+	srv, _, dynamicCreds := newVaultDevServer(t, 0)
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	resetVaultClient()
+	defer resetVaultClient()
+
+	envVars, err := Decode(encodeEnvVars(map[string]string{
+		"envvar:HOST": "_vaultkv2:secret/myapp:HOST",
+		"envvar:PORT": "_vaultkv2:secret/myapp:PORT",
+		"envvar:USER": "_vaultkv1:kv/myapp:USER",
+		"envvar:DB":   "mydb",
+	}))
+	if err != nil {
+		t.Fatalf("did not expect error, got=%v", err)
+	}
+	got := decodeEnvVars(t, envVars)
+	want := map[string]string{"envvar:HOST": "127.0.0.1", "envvar:PORT": "5432", "envvar:USER": "bob", "envvar:DB": "mydb"}
+	for key, val := range want {
+		if got[key] != val {
+			t.Errorf("expected %v to match, want=%v, got=%v", key, val, got[key])
+		}
+	}
+
+	// dynamic secrets must be the same in a session
+	envVars, err = Decode(encodeEnvVars(map[string]string{
+		"envvar:USER": "_vaultkv1:database/creds/readonly:username",
+		"envvar:PASS": "_vaultkv1:database/creds/readonly:password",
+	}))
+	if err != nil {
+		t.Fatalf("did not expect error, got=%v", err)
+	}
+	got = decodeEnvVars(t, envVars)
+	if got["envvar:USER"] != "v-user-1" || got["envvar:PASS"] != "v-pwd-1" {
+		t.Errorf("expected credentials from the same lease, got=%v", got)
+	}
+	if *dynamicCreds != 1 {
+		t.Errorf("expected to generate dynamic credentials once, got=%v", *dynamicCreds)
+	}
+}
+
+func TestVaultAppRoleAuth(t *testing.T) {
+	// the token expires before the refresh threshold, it must login on every session
+	srv, logins, _ := newVaultDevServer(t, 10)
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_APP_ROLE_ID", "role-id")
+	t.Setenv("VAULT_APP_SECRET_ID", "secret-id")
+	resetVaultClient()
+	defer resetVaultClient()
+
+	for i := 1; i <= 2; i++ {
+		envVars, err := Decode(encodeEnvVars(map[string]string{"envvar:HOST": "_vaultkv2:secret/data/myapp:HOST"}))
+		if err != nil {
+			t.Fatalf("did not expect error, got=%v", err)
+		}
+		if got := decodeEnvVars(t, envVars)["envvar:HOST"]; got != "127.0.0.1" {
+			t.Errorf("expected host to match, got=%v", got)
+		}
+		if *logins != int32(i) {
+			t.Errorf("expected to login %v time(s), got=%v", i, *logins)
+		}
+	}
+}
+
+func TestVaultKubernetesAuth(t *testing.T) {
+	// the token expires before the refresh threshold, it must login on every session
+	srv, logins, _ := newVaultDevServer(t, 10)
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_K8S_ROLE", "agent")
+	t.Setenv("VAULT_K8S_TOKEN_PATH", tokenPath)
+	resetVaultClient()
+	defer resetVaultClient()
+
+	for i := 1; i <= 2; i++ {
+		// kubernetes rotates the projected service account token
+		if err := os.WriteFile(tokenPath, []byte(fmt.Sprintf("sa-token-%v\n", i)), 0600); err != nil {
+			t.Fatalf("failed writing service account token: %v", err)
+		}
+		envVars, err := Decode(encodeEnvVars(map[string]string{"envvar:HOST": "_vaultkv2:secret/data/myapp:HOST"}))
+		if err != nil {
+			t.Fatalf("did not expect error, got=%v", err)
+		}
+		if got := decodeEnvVars(t, envVars)["envvar:HOST"]; got != "127.0.0.1" {
+			t.Errorf("expected host to match, got=%v", got)
+		}
+		if *logins != int32(i) {
+			t.Errorf("expected to login %v time(s), got=%v", i, *logins)
+		}
+	}
+}
+
+func TestVaultErrors(t *testing.T) {
+	srv, _, _ := newVaultDevServer(t, 0)
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "invalid-token")
+	resetVaultClient()
+	defer resetVaultClient()
+
+	for _, env := range []string{"_vaultkv2:secret/myapp:HOST", "_vaultkv2:secret/myapp:UNKNOWN", "_vaultkv1:kv/notfound:KEY"} {
+		if _, err := Decode(encodeEnvVars(map[string]string{"envvar:KEY": env})); err == nil {
+			t.Errorf("expected error decoding %v", env)
+		}
+	}
+}
@@ -17,6 +17,7 @@ var hoopVersionStr = version.Get().Version
 func init() {
 	MainCmd.AddCommand(deleteCmd)
 	MainCmd.AddCommand(getCmd)
+	MainCmd.AddCommand(killCmd)
 	MainCmd.AddCommand(createCmd)
 	MainCmd.AddCommand(serverInfoCmd)
 	MainCmd.AddCommand(openWebhooksDashboardCmd)
@@ -97,6 +97,11 @@ func parseResourceOrDie(args []string, method, outputFlag string) *apiResource {
 	case "sessions":
 		apir.resourceList = false
 		apir.suffixEndpoint = path.Join("/api/sessions", apir.name)
+		if liveFlag {
+			apir.resourceGet = false
+			apir.resourceList = true
+			apir.suffixEndpoint = "/api/sessions/live"
+		}
 	case "users":
 		apir.resourceUpdate = true
 		apir.resourceCreate = true
@@ -122,6 +127,14 @@ func parseResourceOrDie(args []string, method, outputFlag string) *apiResource {
 		if method == "POST" {
 			apir.suffixEndpoint = "/api/serviceaccounts"
 		}
+	case "token", "tokens":
+		apir.resourceGet = false
+		apir.resourceCreate = true
+		apir.resourceDelete = true
+		apir.suffixEndpoint = path.Join("/api/tokens", apir.name)
+		if method == "POST" {
+			apir.suffixEndpoint = "/api/tokens"
+		}
 	case "review", "reviews":
 		apir.suffixEndpoint = path.Join("/api/reviews", apir.name)
 	case "plugin", "plugins":
@@ -3,8 +3,10 @@ package admin
 import (
 	"encoding/base64"
 	"fmt"
+	"math"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/hoophq/hoop/client/cmd/styles"
 	clientconfig "github.com/hoophq/hoop/client/config"
@@ -23,6 +25,12 @@ var (
 	connSecretFlag       []string
 	connAccessModesFlag  []string
 	connSchemaFlag       string
+	connReviewTTLFlag    time.Duration
+	connRequireJustFlag  bool
+	connTicketPattern    string
+	connRecentAuthFlag   time.Duration
+	connDenyRulesFlag    []string
+	connBreakGlassFlag   []string
 	skipStrictValidation bool
 	connOverwriteFlag    bool
 
@@ -31,7 +39,7 @@ var (
 
 func init() {
 	createConnectionCmd.Flags().StringVarP(&connAgentFlag, "agent", "a", "", "Name of the agent")
-	createConnectionCmd.Flags().StringVarP(&connTypeFlag, "type", "t", "custom", "Type of the connection. One off: (application,custom,database,application/tcp,database/mssql,database/mysql,database/postgres,database/mongodb)")
+	createConnectionCmd.Flags().StringVarP(&connTypeFlag, "type", "t", "custom", "Type of the connection. One off: (application,custom,database,application/tcp,database/mssql,database/mysql,database/postgres,database/mongodb,database/redis)")
 	createConnectionCmd.Flags().StringSliceVarP(&connPuginFlag, "plugin", "p", nil, "Plugins that will be enabled for this connection in the form of: <plugin>:<config01>;<config02>,...")
 	createConnectionCmd.Flags().StringSliceVar(&reviewersFlag, "reviewers", nil, "The approval groups for this connection")
 	createConnectionCmd.Flags().StringSliceVar(&connRedactTypesFlag, "redact-types", nil, "The redact types for this connection")
@@ -40,6 +48,12 @@ func init() {
 	createConnectionCmd.Flags().StringSliceVarP(&connSecretFlag, "env", "e", nil, "The environment variables of the connection")
 	createConnectionCmd.Flags().StringSliceVar(&connTagsFlag, "tags", nil, "Tags to identify connections in a key=value format")
 	createConnectionCmd.Flags().StringSliceVar(&connAccessModesFlag, "access-modes", defaultAccessModes, "Access modes enabled for this connection. Accepted values: [runbooks, exec, connect]")
+	createConnectionCmd.Flags().DurationVar(&connReviewTTLFlag, "review-ttl", 0, "The amount of time a review could stay pending before expiring, e.g.: 30m, 24h")
+	createConnectionCmd.Flags().BoolVar(&connRequireJustFlag, "require-justification", false, "Require users to provide a justification and a ticket id when opening sessions")
+	createConnectionCmd.Flags().StringVar(&connTicketPattern, "ticket-pattern", "", "A regular expression that the ticket id must match, e.g.: JIRA-[0-9]+")
+	createConnectionCmd.Flags().DurationVar(&connRecentAuthFlag, "require-recent-auth", 0, "Require users to have authenticated within this amount of time to open sessions, e.g.: 15m")
+	createConnectionCmd.Flags().StringArrayVar(&connDenyRulesFlag, "deny-rule", nil, "Regular expressions of statements rejected when the access_control plugin is enabled, it could be repeated")
+	createConnectionCmd.Flags().StringSliceVar(&connBreakGlassFlag, "break-glass-groups", nil, "The groups allowed to bypass the review of this connection (break-glass)")
 	createConnectionCmd.Flags().StringVar(&connSchemaFlag, "schema", "", "Enable or disable the schema for this connection on the WebClient. Accepted values: [disabled, enabled]")
 	createConnectionCmd.MarkFlagRequired("agent")
 }
@@ -105,6 +119,10 @@ var createConnectionCmd = &cobra.Command{
 				if err := validateNativeDbEnvs(envVar); err != nil {
 					styles.PrintErrorAndExit(err.Error())
 				}
+			case pb.ConnectionTypeRedis:
+				if envVar["envvar:HOST"] == "" {
+					styles.PrintErrorAndExit("missing required HOST env for %v", pb.ConnectionTypeRedis)
+				}
 			case pb.ConnectionTypeMongoDB:
 				if envVar["envvar:CONNECTION_STRING"] == "" {
 					styles.PrintErrorAndExit("missing required CONNECTION_STRING env for %v", pb.ConnectionTypeMongoDB)
@@ -127,20 +145,26 @@ var createConnectionCmd = &cobra.Command{
 		}
 
 		connectionBody := map[string]any{
-			"name":                 apir.name,
-			"type":                 connType,
-			"subtype":              subType,
-			"command":              cmdList,
-			"secret":               envVar,
-			"agent_id":             agentID,
-			"reviewers":            reviewersFlag,
-			"redact_enabled":       redactEnabled,
-			"redact_types":         connRedactTypesFlag,
-			"tags":                 connTagsFlag,
-			"access_mode_runbooks": verifyAccessModeStatus("runbooks"),
-			"access_mode_exec":     verifyAccessModeStatus("exec"),
-			"access_mode_connect":  verifyAccessModeStatus("connect"),
-			"access_schema":        verifySchemaStatus(connSchemaFlag, connType),
+			"name":                  apir.name,
+			"type":                  connType,
+			"subtype":               subType,
+			"command":               cmdList,
+			"secret":                envVar,
+			"agent_id":              agentID,
+			"reviewers":             reviewersFlag,
+			"redact_enabled":        redactEnabled,
+			"redact_types":          connRedactTypesFlag,
+			"tags":                  connTagsFlag,
+			"access_mode_runbooks":  verifyAccessModeStatus("runbooks"),
+			"access_mode_exec":      verifyAccessModeStatus("exec"),
+			"access_mode_connect":   verifyAccessModeStatus("connect"),
+			"access_schema":         verifySchemaStatus(connSchemaFlag, connType),
+			"review_ttl_sec":        int(connReviewTTLFlag.Seconds()),
+			"require_justification": connRequireJustFlag,
+			"ticket_id_pattern":     connTicketPattern,
+			"require_recent_auth":   int(math.Ceil(connRecentAuthFlag.Minutes())),
+			"deny_rules":            connDenyRulesFlag,
+			"break_glass_groups":    connBreakGlassFlag,
 		}
 
 		resp, err := httpBodyRequest(apir, method, connectionBody)
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/hoophq/hoop/client/cmd/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenScopesFlag        []string
+	tokenExpiresInDaysFlag int
+)
+
+func init() {
+	createTokenCmd.Flags().StringSliceVar(&tokenScopesFlag, "scopes", []string{}, "The scopes of the token, e.g.: sessions:read,exec:<connection>,exec:*,admin")
+	createTokenCmd.Flags().IntVar(&tokenExpiresInDaysFlag, "expires-in-days", 90, "The number of days the token is valid, between 1 and 365")
+}
+
+var createTokenExamplesDesc = `
+hoop admin create token ci-pipeline --scopes exec:pgdemo
+hoop admin create token reports --scopes sessions:read --expires-in-days 30
+`
+
+var createTokenCmd = &cobra.Command{
+	Use:     "token NAME",
+	Aliases: []string{"tokens"},
+	Short:   "Create a personal access token owned by the logged user.",
+	Example: createTokenExamplesDesc,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Usage()
+			styles.PrintErrorAndExit("missing resource name")
+		}
+		if len(tokenScopesFlag) == 0 {
+			cmd.Usage()
+			styles.PrintErrorAndExit("missing --scopes flag")
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		apir := parseResourceOrDie([]string{"tokens"}, "POST", outputFlag)
+		resp, err := httpBodyRequest(apir, "POST", map[string]any{
+			"name":            args[0],
+			"scopes":          tokenScopesFlag,
+			"expires_in_days": tokenExpiresInDaysFlag,
+		})
+		if err != nil {
+			styles.PrintErrorAndExit(err.Error())
+		}
+		if apir.decodeTo == "raw" {
+			jsonData, _ := resp.([]byte)
+			fmt.Println(string(jsonData))
+			return
+		}
+		respMap, ok := resp.(map[string]any)
+		if !ok {
+			styles.PrintErrorAndExit("failed decoding response map")
+		}
+		// the token is only returned once, print it to stdout to allow piping it
+		fmt.Printf("%v\n", respMap["token"])
+	},
+}
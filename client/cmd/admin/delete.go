@@ -12,6 +12,7 @@ var deleteLongDesc = `Delete resource by its name. Available ones:
 * agent
 * connection
 * users
+* tokens (revokes the token by its id)
 `
 
 var deleteCmd = &cobra.Command{
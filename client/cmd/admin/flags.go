@@ -1,3 +1,6 @@
 package admin
 
-var outputFlag string
+var (
+	outputFlag string
+	liveFlag   bool
+)
@@ -17,6 +17,7 @@ import (
 
 func init() {
 	getCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Output format. One off: (json)")
+	getCmd.Flags().BoolVar(&liveFlag, "live", false, "List the sessions that are currently active (sessions only)")
 }
 
 var getLongDesc = `Display one or many resources. Available ones:
@@ -28,13 +29,15 @@ var getLongDesc = `Display one or many resources. Available ones:
 * reviews
 * runbooks
 * serviceaccounts (tabview)
-* sessions
+* sessions (tabview with --live)
+* tokens (tabview)
 * users (tabview)
 `
 
 var getExamplesDesc = `
 hoop admin get agents
 hoop admin get connections -o json
+hoop admin get sessions --live
 hoop admin get plugins`
 
 var getCmd = &cobra.Command{
@@ -189,6 +192,27 @@ var getCmd = &cobra.Command{
 					fmt.Fprintln(w)
 				}
 			}
+		case "token", "tokens":
+			contents, _ := obj.([]map[string]any)
+			fmt.Fprintln(w, "ID\tNAME\tSCOPES\tSTATUS\tEXPIRES\tLAST USED\t")
+			for _, m := range contents {
+				scopes, _ := m["scopes"].([]any)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t",
+					m["id"], m["name"], joinItems(scopes), tokenStatus(m), optionalStr(m["expires_at"]), optionalAbsTime(m["last_used_at"]))
+				fmt.Fprintln(w)
+			}
+		case "sessions":
+			contents, ok := obj.([]map[string]any)
+			if !liveFlag || !ok {
+				styles.PrintErrorAndExit("tab view not implemented for resource type %q, try repeating the command with the -o json option.", apir.resourceType)
+			}
+			fmt.Fprintln(w, "SID\tUSER\tCONNECTION\tTYPE\tVERB\tSTARTED\tSENT\tRECEIVED\t")
+			for _, m := range contents {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t",
+					m["id"], m["user"], m["connection"], toStr(m["connection_type"]), m["verb"],
+					absTime(m["started_at"]), humanBytes(m["bytes_sent"]), humanBytes(m["bytes_received"]))
+				fmt.Fprintln(w)
+			}
 		case "runbooks":
 			switch contents := obj.(type) {
 			case map[string]any:
@@ -358,6 +382,33 @@ func joinCmd(cmdList []any, trunc bool) string {
 	return fmt.Sprintf("[ %s ]", cmd)
 }
 
+func tokenStatus(m map[string]any) string {
+	if m["revoked_at"] != nil {
+		return "revoked"
+	}
+	if expiresAt, ok := m["expires_at"].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, expiresAt)
+		if err == nil && time.Now().UTC().After(t) {
+			return "expired"
+		}
+	}
+	return "active"
+}
+
+func optionalStr(v any) string {
+	if v == nil {
+		return "-"
+	}
+	return toStr(v)
+}
+
+func optionalAbsTime(v any) string {
+	if v == nil {
+		return "-"
+	}
+	return absTime(v)
+}
+
 func joinItems(items []any) string {
 	var list []string
 	for _, c := range items {
@@ -382,6 +433,20 @@ func joinList(v any, trunc bool) string {
 	return fmt.Sprintf("[ %s ]", cmd)
 }
 
+// humanBytes given v as a number of bytes, format it to a human readable size
+func humanBytes(v any) string {
+	size, _ := v.(float64)
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%.0fB", size)
+	}
+	exp := 0
+	for n := size / unit; n >= unit && exp < 4; n /= unit {
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", size/math.Pow(unit, float64(exp+1)), "KMGTP"[exp])
+}
+
 // absTime given v as a time string, parse to absolute time
 func absTime(v any) string {
 	t1, err := time.Parse(time.RFC3339Nano, v.(string))
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hoophq/hoop/client/cmd/styles"
+	clientconfig "github.com/hoophq/hoop/client/config"
+	"github.com/spf13/cobra"
+)
+
+var killLongDesc = `Terminate live resources. Available ones:
+
+* session
+`
+
+var killExamplesDesc = `
+hoop admin kill session 1CBC8DB5-FBF8-4293-8E35-59A6EEA40207
+hoop admin kill session/1CBC8DB5-FBF8-4293-8E35-59A6EEA40207`
+
+var killCmd = &cobra.Command{
+	Use:     "kill TYPE/NAME",
+	Short:   "Terminate live resources",
+	Long:    killLongDesc,
+	Example: killExamplesDesc,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Usage()
+			styles.PrintErrorAndExit("missing resource: type/name")
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var resourceType, resourceName string
+		switch len(args) {
+		case 1:
+			resourceType, resourceName, _ = strings.Cut(args[0], "/")
+		default:
+			resourceType, resourceName = args[0], args[1]
+		}
+		switch resourceType {
+		case "session", "sessions":
+		default:
+			styles.PrintErrorAndExit("resource type %q not supported", resourceType)
+		}
+		if resourceName == "" {
+			styles.PrintErrorAndExit("missing resource name")
+		}
+		apir := &apiResource{
+			resourceType:   resourceType,
+			name:           resourceName,
+			conf:           clientconfig.GetClientConfigOrDie(),
+			suffixEndpoint: path.Join("/api/sessions", resourceName, "kill"),
+		}
+		if _, err := httpBodyRequest(apir, "POST", map[string]any{}); err != nil {
+			styles.PrintErrorAndExit(err.Error())
+		}
+		fmt.Printf("session %q killed\n", resourceName)
+	},
+}
@@ -31,6 +31,18 @@ var inputStdin string
 var autoExec bool
 var inputEnvVars []string
 var verboseMode bool
+var breakGlassJustification string
+var sessionJustification string
+var sessionTicketID string
+
+const breakGlassUsage = "Access the connection without waiting for the review approval in emergencies. " +
+	"The value is the justification of the access, the admins are alerted and the review is performed retrospectively. " +
+	"It requires being a member of the break-glass groups of the connection"
+
+const (
+	justificationUsage = "The reason to access the connection, it's required when the connection requires a justification"
+	ticketUsage        = "The reference of the ticket to access the connection (e.g.: JIRA-123), it's required when the connection requires a justification"
+)
 
 // execCmd represents the exec command
 var execCmd = &cobra.Command{
@@ -41,6 +53,10 @@ var execCmd = &cobra.Command{
 			cmd.Usage()
 			os.Exit(1)
 		}
+		if err := validateBreakGlassFlag(cmd); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		clientEnvVars, err := parseClientEnvVars()
@@ -58,9 +74,19 @@ func init() {
 	execCmd.Flags().StringSliceVarP(&inputEnvVars, "env", "e", nil, "Input environment variables to send")
 	execCmd.Flags().BoolVar(&autoExec, "auto-approve", false, "Automatically run after a command is approved")
 	execCmd.Flags().BoolVarP(&verboseMode, "verbose", "v", false, "Verbose mode")
+	execCmd.Flags().StringVar(&breakGlassJustification, "break-glass", "", breakGlassUsage)
+	execCmd.Flags().StringVar(&sessionJustification, "justification", "", justificationUsage)
+	execCmd.Flags().StringVar(&sessionTicketID, "ticket", "", ticketUsage)
 	rootCmd.AddCommand(execCmd)
 }
 
+func validateBreakGlassFlag(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("break-glass") && strings.TrimSpace(breakGlassJustification) == "" {
+		return fmt.Errorf("the break-glass access requires a justification")
+	}
+	return nil
+}
+
 func parseFlagInputs(c *connect) []byte {
 	if inputFilepath != "" && inputStdin != "" {
 		sentry.CaptureMessage("exec - client used --file and --input together")
@@ -134,6 +160,15 @@ func runExec(args []string, clientEnvVars map[string]string) {
 	c := newClientConnect(config, loader, args, pb.ClientVerbExec)
 	c.client.StartKeepAlive()
 	execSpec := newClientArgsSpec(c.clientArgs, clientEnvVars)
+	if breakGlassJustification != "" {
+		execSpec[pb.SpecBreakGlassJustification] = []byte(breakGlassJustification)
+	}
+	if sessionJustification != "" {
+		execSpec[pb.SpecSessionJustification] = []byte(sessionJustification)
+	}
+	if sessionTicketID != "" {
+		execSpec[pb.SpecSessionTicketID] = []byte(sessionTicketID)
+	}
 	isStdinInput, execInputPayload := parseExecInput(c)
 	sendOpenSessionPktFn := func() {
 		if err := c.client.Send(&pb.Packet{
@@ -23,11 +23,17 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var noBrowser bool
+var (
+	noBrowser     bool
+	loginProvider string
+	loginEmail    string
+	loginReauth   bool
+)
 
 type login struct {
-	Url     string `json:"login_url"`
-	Message string `json:"message"`
+	Url        string `json:"login_url"`
+	AuthMethod string `json:"auth_method"`
+	Message    string `json:"message"`
 }
 
 var loginCmd = &cobra.Command{
@@ -81,6 +87,9 @@ var loginCmd = &cobra.Command{
 
 func init() {
 	loginCmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Print the login url to stdout instead of opening the browser")
+	loginCmd.Flags().StringVar(&loginProvider, "provider", "", "The name of the identity provider to authenticate")
+	loginCmd.Flags().StringVar(&loginEmail, "email", "", "The email of the user, it selects the identity provider by the domain of the email")
+	loginCmd.Flags().BoolVar(&loginReauth, "reauth", false, "Force the identity provider to authenticate again instead of reusing an existing session")
 	rootCmd.AddCommand(loginCmd)
 }
 
@@ -101,10 +110,14 @@ func configureHostsPrompt(conf *proxyconfig.Config) {
 }
 
 func doLogin(apiURL, tlsCA string) (string, error) {
-	loginUrl, err := requestForUrl(apiURL, tlsCA)
+	l, err := requestForUrl(apiURL, tlsCA)
 	if err != nil {
 		return "", err
 	}
+	if l.AuthMethod == authMethodLocal {
+		return doLocalLogin(apiURL, tlsCA)
+	}
+	loginUrl := l.Url
 
 	if !isValidURL(loginUrl) {
 		return "", fmt.Errorf("login url in wrong format or it's missing, url='%v'", loginUrl)
@@ -156,29 +169,45 @@ func doLogin(apiURL, tlsCA string) (string, error) {
 	}
 }
 
-func requestForUrl(apiUrl, tlsCA string) (string, error) {
+func requestForUrl(apiUrl, tlsCA string) (*login, error) {
 	c := httpclient.NewHttpClient(tlsCA)
-	url := fmt.Sprintf("%s/api/login", apiUrl)
+	qs := url.Values{}
+	if loginProvider != "" {
+		qs.Set("provider", loginProvider)
+	}
+	if loginEmail != "" {
+		qs.Set("email", loginEmail)
+	}
+	// connections requiring a recent authentication (step-up)
+	// are only accessible after a new authentication
+	if loginReauth {
+		qs.Set("prompt", "login")
+		qs.Set("max_age", "0")
+	}
+	loginURL := fmt.Sprintf("%s/api/login", apiUrl)
+	if len(qs) > 0 {
+		loginURL += "?" + qs.Encode()
+	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequest(http.MethodGet, loginURL, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	log.Debugf("GET %s/api/login status=%v", apiUrl, resp.StatusCode)
 	defer resp.Body.Close()
 	var l login
 	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
-		return "", fmt.Errorf("failed decoding response body, err=%v", err)
+		return nil, fmt.Errorf("failed decoding response body, err=%v", err)
 	}
 	if resp.StatusCode == http.StatusOK {
-		return l.Url, nil
+		return &l, nil
 	}
-	return "", fmt.Errorf("failed authenticating, status=%v, response=%v", resp.StatusCode, l.Message)
+	return nil, fmt.Errorf("failed authenticating, status=%v, response=%v", resp.StatusCode, l.Message)
 }
 
 func fetchGrpcURL(apiURL, bearerToken, tlsCA string) (string, error) {
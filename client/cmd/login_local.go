@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hoophq/hoop/common/httpclient"
+	"github.com/hoophq/hoop/common/log"
+	"golang.org/x/term"
+)
+
+const authMethodLocal = "local"
+
+type localLoginResponse struct {
+	MFAToken   string `json:"mfa_token"`
+	Enrollment *struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	} `json:"enrollment"`
+}
+
+type localMFAResponse struct {
+	Token         string   `json:"token"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// doLocalLogin authenticates with the credentials managed by the gateway.
+// It prompts the email, the password and the code of the authenticator app,
+// users without an authenticator app are guided to enroll one.
+func doLocalLogin(apiURL, tlsCA string) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	email := loginEmail
+	if email == "" {
+		fmt.Print("Email: ")
+		email, _ = reader.ReadString('\n')
+		email = strings.TrimSpace(email)
+	}
+	fmt.Print("Password: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed reading password: %v", err)
+	}
+
+	var loginResp localLoginResponse
+	err = postLocalAuth(apiURL, tlsCA, "/api/localauth/login", map[string]string{
+		"email":    email,
+		"password": string(password),
+	}, &loginResp)
+	if err != nil {
+		return "", err
+	}
+
+	prompt := "Enter the code of your authenticator app (or a recovery code): "
+	if loginResp.Enrollment != nil {
+		fmt.Printf("\nMulti-factor authentication is required, add the key below to your authenticator app\n")
+		fmt.Printf("---------------------------------------------------------------------------------\n")
+		fmt.Printf("• secret: %s\n", loginResp.Enrollment.Secret)
+		fmt.Printf("• uri:    %s\n\n", loginResp.Enrollment.URI)
+		prompt = "Enter the code of your authenticator app to confirm the enrollment: "
+	}
+	fmt.Print(prompt)
+	code, _ := reader.ReadString('\n')
+	code = strings.TrimSpace(code)
+	mfaRequest := map[string]string{"mfa_token": loginResp.MFAToken, "code": code}
+	if strings.Contains(code, "-") {
+		mfaRequest = map[string]string{"mfa_token": loginResp.MFAToken, "recovery_code": code}
+	}
+	var mfaResp localMFAResponse
+	if err := postLocalAuth(apiURL, tlsCA, "/api/localauth/mfa", mfaRequest, &mfaResp); err != nil {
+		return "", err
+	}
+	if len(mfaResp.RecoveryCodes) > 0 {
+		fmt.Printf("\nSave the recovery codes below in a safe place, each one could be used once\n")
+		fmt.Printf("when the authenticator app isn't available. They won't be displayed again.\n")
+		fmt.Printf("---------------------------------------------------------------------------\n")
+		for _, recoveryCode := range mfaResp.RecoveryCodes {
+			fmt.Printf("• %s\n", recoveryCode)
+		}
+		fmt.Println()
+	}
+	if mfaResp.Token == "" {
+		return "", fmt.Errorf("empty token")
+	}
+	return mfaResp.Token, nil
+}
+
+func postLocalAuth(apiURL, tlsCA, path string, body map[string]string, into any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, apiURL+path, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpclient.NewHttpClient(tlsCA).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	log.Debugf("POST %s%s status=%v", apiURL, path, resp.StatusCode)
+	var errResp struct {
+		Message string `json:"message"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		return fmt.Errorf("failed authenticating, status=%v, response=%v", resp.StatusCode, errResp.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return fmt.Errorf("failed decoding response body, err=%v", err)
+	}
+	return nil
+}
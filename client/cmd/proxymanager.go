@@ -145,6 +145,12 @@ func runAutoConnect(client pb.ClientTransport) (err error) {
 					return err
 				}
 				connStore.Set(sid, srv)
+			case pb.ConnectionTypeRedis:
+				srv := proxy.NewRedisServer(proxyPort, client)
+				if err := srv.Serve(sid); err != nil {
+					return err
+				}
+				connStore.Set(sid, srv)
 			case pb.ConnectionTypeTCP:
 				srv := proxy.NewTCPServer(proxyPort, client, pbagent.TCPConnectionWrite)
 				if err := srv.Serve(sid); err != nil {
@@ -201,6 +207,16 @@ func runAutoConnect(client pb.ClientTransport) (err error) {
 			if _, err := srv.PacketWriteClient(connectionID, pkt); err != nil {
 				return fmt.Errorf("failed writing to client, err=%v", err)
 			}
+		case pbclient.RedisConnectionWrite:
+			srvObj := connStore.Get(sid)
+			srv, ok := srvObj.(*proxy.RedisServer)
+			if !ok {
+				return fmt.Errorf("redis proxy server instance not found")
+			}
+			connectionID := string(pkt.Spec[pb.SpecClientConnectionID])
+			if _, err := srv.PacketWriteClient(connectionID, pkt); err != nil {
+				return fmt.Errorf("failed writing to client, err=%v", err)
+			}
 		case pbclient.TCPConnectionWrite:
 			connectionID := string(pkt.Spec[pb.SpecClientConnectionID])
 			if tcp, ok := connStore.Get(sid).(*proxy.TCPServer); ok {
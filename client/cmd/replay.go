@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	clientconfig "github.com/hoophq/hoop/client/config"
+	"github.com/hoophq/hoop/common/asciicast"
+	"github.com/hoophq/hoop/common/httpclient"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const replaySeekStep = time.Second * 5
+
+var (
+	replaySpeedFlag     float64
+	replaySeekFlag      time.Duration
+	replayIdleLimitFlag time.Duration
+	replayOutputFlag    string
+	replayInputFlag     bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay SESSION_ID",
+	Short: "Replay a session with its original timing",
+	Long: `Replay a session with its original timing.
+
+While replaying, use the following keys to control the player:
+
+  space        pause / resume
+  left, right  seek backward / forward (5s)
+  -, +         decrease / increase the speed
+  q            quit`,
+	Example: `  hoop replay 5701046A-7B7A-4A78-ABB0-A24C95E6FE54
+  hoop replay 5701046A-7B7A-4A78-ABB0-A24C95E6FE54 --speed 2 --seek 1m
+  hoop replay 5701046A-7B7A-4A78-ABB0-A24C95E6FE54 --output session.cast`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runReplay(args[0])
+	},
+}
+
+func init() {
+	replayCmd.Flags().Float64VarP(&replaySpeedFlag, "speed", "s", 1, "The speed multiplier of the replay")
+	replayCmd.Flags().DurationVar(&replaySeekFlag, "seek", 0, "Start the replay at this position, e.g.: 30s, 2m")
+	replayCmd.Flags().DurationVarP(&replayIdleLimitFlag, "idle-limit", "i", 0, "Limit the idle time between events, e.g.: 2s")
+	replayCmd.Flags().StringVarP(&replayOutputFlag, "output", "o", "", "Export the session to a file in the asciicast (v2) format instead of replaying it")
+	replayCmd.Flags().BoolVar(&replayInputFlag, "input", false, "Include input events when exporting the session")
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(sessionID string) {
+	config := clientconfig.GetClientConfigOrDie()
+	if replaySpeedFlag <= 0 {
+		printErrorAndExit("speed must be greater than zero")
+	}
+	events := "o,e"
+	if replayInputFlag && replayOutputFlag != "" {
+		events = "i,o,e"
+	}
+	body, err := replayHTTPRequest(config, sessionID, events)
+	if err != nil {
+		printErrorAndExit(err.Error())
+	}
+	defer body.Close()
+
+	if replayOutputFlag != "" {
+		f, err := os.Create(replayOutputFlag)
+		if err != nil {
+			printErrorAndExit("failed creating file: %v", err)
+		}
+		defer f.Close()
+		if _, err := io.Copy(f, body); err != nil {
+			printErrorAndExit("failed writing file: %v", err)
+		}
+		fmt.Printf("session exported to %v\n", replayOutputFlag)
+		return
+	}
+
+	header, eventList, err := asciicast.Decode(body)
+	if err != nil {
+		printErrorAndExit("failed decoding session: %v", err)
+	}
+	p := &player{
+		events:    eventList,
+		speed:     replaySpeedFlag,
+		idleLimit: replayIdleLimitFlag.Seconds(),
+		out:       os.Stdout,
+	}
+	if len(eventList) > 0 {
+		p.duration = eventList[len(eventList)-1].Time
+	}
+	if header.Duration > p.duration {
+		p.duration = header.Duration
+	}
+	p.play(replaySeekFlag.Seconds())
+}
+
+func replayHTTPRequest(c *clientconfig.Config, sessionID, events string) (io.ReadCloser, error) {
+	apiURL := fmt.Sprintf("%s/api/sessions/%s/replay?format=asciicast&events=%s",
+		c.ApiURL, url.PathEscape(sessionID), events)
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		apiURL += fmt.Sprintf("&width=%v&height=%v", width, height)
+	}
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	resp, err := httpclient.NewHttpClient(c.TlsCA()).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed performing replay request, err=%v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed fetching session, status-code=%v, payload=%v", resp.StatusCode, string(data))
+	}
+	return resp.Body, nil
+}
+
+type player struct {
+	events    []asciicast.Event
+	duration  float64
+	speed     float64
+	idleLimit float64
+	out       io.Writer
+
+	// the current position in seconds and the index of the next event
+	pos    float64
+	idx    int
+	paused bool
+}
+
+// play the events starting at the position (seconds), the player could
+// be controlled by the keyboard when the stdin is a terminal
+func (p *player) play(startAt float64) {
+	keyCh := make(chan string)
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err == nil {
+			defer func() { _ = term.Restore(int(os.Stdin.Fd()), oldState) }()
+			go readKeys(os.Stdin, keyCh)
+		}
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	if startAt > 0 {
+		p.seek(startAt)
+	}
+	for {
+		if p.idx >= len(p.events) && !p.paused {
+			return
+		}
+		var nextEventCh <-chan time.Time
+		if !p.paused {
+			nextEventCh = time.After(p.nextDelay())
+		}
+		select {
+		case <-sigCh:
+			return
+		case key := <-keyCh:
+			if quit := p.handleKey(key); quit {
+				return
+			}
+		case <-nextEventCh:
+			ev := p.events[p.idx]
+			p.write(ev)
+			p.pos = ev.Time
+			p.idx++
+		}
+	}
+}
+
+func (p *player) nextDelay() time.Duration {
+	if p.idx >= len(p.events) {
+		return 0
+	}
+	delay := p.events[p.idx].Time - p.pos
+	if p.idleLimit > 0 && delay > p.idleLimit {
+		delay = p.idleLimit
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return time.Duration(delay / p.speed * float64(time.Second))
+}
+
+func (p *player) handleKey(key string) (quit bool) {
+	switch key {
+	case "q", "\x03":
+		return true
+	case " ":
+		p.paused = !p.paused
+	case "+", "=":
+		p.speed *= 2
+	case "-", "_":
+		p.speed /= 2
+	case "\x1b[C", "l":
+		p.seek(p.pos + replaySeekStep.Seconds())
+	case "\x1b[D", "h":
+		p.seek(p.pos - replaySeekStep.Seconds())
+	}
+	return false
+}
+
+// seek renders all the events until the position clearing the screen first.
+func (p *player) seek(pos float64) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > p.duration {
+		pos = p.duration
+	}
+	_, _ = p.out.Write([]byte("\x1b[2J\x1b[H"))
+	p.idx = 0
+	for p.idx < len(p.events) && p.events[p.idx].Time <= pos {
+		p.write(p.events[p.idx])
+		p.idx++
+	}
+	p.pos = pos
+}
+
+func (p *player) write(ev asciicast.Event) {
+	if ev.Type == asciicast.OutputEvent {
+		_, _ = p.out.Write([]byte(ev.Data))
+	}
+}
+
+func readKeys(r io.Reader, keyCh chan<- string) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		keyCh <- string(buf[:n])
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hoophq/hoop/common/asciicast"
+)
+
+func newTestPlayer(out *bytes.Buffer) *player {
+	return &player{
+		events: []asciicast.Event{
+			{Time: 1, Type: asciicast.OutputEvent, Data: "a"},
+			{Time: 2, Type: asciicast.InputEvent, Data: "x"},
+			{Time: 10, Type: asciicast.OutputEvent, Data: "b"},
+			{Time: 12, Type: asciicast.OutputEvent, Data: "c"},
+		},
+		duration: 12,
+		speed:    1,
+		out:      out,
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	p := newTestPlayer(out)
+	p.seek(10)
+	if got := out.String(); got != "\x1b[2J\x1b[Hab" {
+		t.Errorf("expected output events until position, got=%q", got)
+	}
+	if p.idx != 3 || p.pos != 10 {
+		t.Errorf("expected player at idx=3 pos=10, got idx=%v pos=%v", p.idx, p.pos)
+	}
+
+	out.Reset()
+	p.handleKey("\x1b[D")
+	if got := out.String(); got != "\x1b[2J\x1b[Ha" || p.pos != 5 {
+		t.Errorf("expected to seek backward, pos=%v, got=%q", p.pos, got)
+	}
+	p.seek(100)
+	if p.pos != 12 || p.idx != len(p.events) {
+		t.Errorf("expected seek to stop at the end of the session, pos=%v, idx=%v", p.pos, p.idx)
+	}
+}
+
+func TestPlayerNextDelay(t *testing.T) {
+	p := newTestPlayer(bytes.NewBuffer(nil))
+	p.idx, p.pos = 2, 2
+	if got := p.nextDelay(); got != time.Second*8 {
+		t.Errorf("expected original timing, got=%v", got)
+	}
+	p.handleKey("+")
+	if got := p.nextDelay(); got != time.Second*4 {
+		t.Errorf("expected delay with speed multiplier, got=%v", got)
+	}
+	p.idleLimit = 1
+	if got := p.nextDelay(); got != time.Millisecond*500 {
+		t.Errorf("expected delay limited by idle time, got=%v", got)
+	}
+	if quit := p.handleKey("q"); !quit {
+		t.Errorf("expected to quit the player")
+	}
+}
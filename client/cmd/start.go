@@ -8,6 +8,7 @@ import (
 	"github.com/hoophq/hoop/agent"
 	"github.com/hoophq/hoop/gateway"
 	"github.com/hoophq/hoop/gateway/jobs"
+	jobretention "github.com/hoophq/hoop/gateway/jobs/retention"
 	jobsessions "github.com/hoophq/hoop/gateway/jobs/sessions"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 	"github.com/spf13/cobra"
@@ -42,6 +43,7 @@ When no argument is provided, it will run as a cronjob.
 Available jobs are:
 
 * walsessions
+* retention
 `
 
 var startGatewayJobsCmd = &cobra.Command{
@@ -54,9 +56,15 @@ var startGatewayJobsCmd = &cobra.Command{
 			jobs.Run()
 			return
 		}
+		if err := jobs.Load(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 		switch args[0] {
 		case "walsessions":
 			jobsessions.ProcessWalSessions(plugintypes.AuditPath, gocron.Job{})
+		case "retention":
+			jobretention.PurgeExpiredSessions(gocron.Job{})
 		default:
 			fmt.Printf("ad-hoc job %v not found\n", args[0])
 			os.Exit(1)
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/gorilla/websocket"
+	clientconfig "github.com/hoophq/hoop/client/config"
+	"github.com/hoophq/hoop/common/asciicast"
+	"github.com/spf13/cobra"
+)
+
+var watchInputFlag bool
+
+var watchCmd = &cobra.Command{
+	Use:   "watch SESSION_ID",
+	Short: "Follow a live session in real time (read-only)",
+	Long: `Follow a live session in real time (read-only).
+
+The output of the session is rendered in the terminal as it happens,
+the command exits when the session ends or when pressing Ctrl+C.
+Only admins are allowed to watch sessions.`,
+	Example: `  hoop watch 5701046A-7B7A-4A78-ABB0-A24C95E6FE54
+  hoop watch 5701046A-7B7A-4A78-ABB0-A24C95E6FE54 --input`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runWatch(args[0])
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchInputFlag, "input", false, "Display the input events of the session (e.g.: queries of database connections)")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(sessionID string) {
+	config := clientconfig.GetClientConfigOrDie()
+	events := "o,e"
+	if watchInputFlag {
+		events = "i,o,e"
+	}
+	conn, err := watchDial(config, sessionID, events)
+	if err != nil {
+		printErrorAndExit(err.Error())
+	}
+	defer conn.Close()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	doneCh := make(chan error, 1)
+	go func() { doneCh <- renderWatchEvents(conn, os.Stdout) }()
+	select {
+	case <-sigCh:
+		_ = conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	case err := <-doneCh:
+		fmt.Println()
+		if closeErr, ok := err.(*websocket.CloseError); ok && closeErr.Code == websocket.CloseNormalClosure {
+			fmt.Printf("stopped watching: %v\n", closeErr.Text)
+			return
+		}
+		printErrorAndExit("failed watching session: %v", err)
+	}
+}
+
+// renderWatchEvents writes the events of the session to w until the connection is closed
+func renderWatchEvents(conn *websocket.Conn, w io.Writer) error {
+	var header *asciicast.Header
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if header == nil {
+			header = &asciicast.Header{}
+			if err := json.Unmarshal(data, header); err != nil {
+				return fmt.Errorf("failed decoding header: %v", err)
+			}
+			fmt.Fprintf(w, "watching %s, press Ctrl+C to stop\r\n", header.Title)
+			continue
+		}
+		var ev asciicast.Event
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return fmt.Errorf("failed decoding event: %v", err)
+		}
+		if ev.Type == asciicast.InputEvent {
+			fmt.Fprintf(w, "%s\r\n", strings.TrimRight(ev.Data, "\r\n"))
+			continue
+		}
+		_, _ = io.WriteString(w, ev.Data)
+	}
+}
+
+func watchDial(c *clientconfig.Config, sessionID, events string) (*websocket.Conn, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/api/sessions/%s/watch?events=%s",
+		c.ApiURL, url.PathEscape(sessionID), events))
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = "ws"
+	if strings.HasPrefix(c.ApiURL, "https") {
+		u.Scheme = "wss"
+	}
+	dialer := *websocket.DefaultDialer
+	if tlsCA := c.TlsCA(); tlsCA != "" {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(tlsCA)) {
+			return nil, fmt.Errorf("failed to append root CA into cert pool")
+		}
+		dialer.TLSClientConfig = &tls.Config{RootCAs: certPool}
+	}
+	header := http.Header{}
+	header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	conn, resp, err := dialer.Dial(u.String(), header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			return nil, fmt.Errorf("failed watching session, status-code=%v, payload=%v", resp.StatusCode, string(data))
+		}
+		return nil, fmt.Errorf("failed connecting to the gateway, err=%v", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRenderWatchEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, msg := range []string{
+			`{"version":2,"width":80,"height":24,"title":"bash (connect) - john@domain.tld"}`,
+			`[0.5,"o","$ "]`,
+			`[1.2,"i","ls\n"]`,
+			`[1.3,"o","file.txt\r\n"]`,
+		} {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+		_ = conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session ended"))
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	out := bytes.NewBuffer(nil)
+	err = renderWatchEvents(conn, out)
+	if closeErr, ok := err.(*websocket.CloseError); !ok || closeErr.Text != "session ended" {
+		t.Errorf("expected close error with reason, got=%v", err)
+	}
+	expected := "watching bash (connect) - john@domain.tld, press Ctrl+C to stop\r\n$ ls\r\nfile.txt\r\n"
+	if got := out.String(); got != expected {
+		t.Errorf("expected rendered events %q, got=%q", expected, got)
+	}
+}
@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/common/memory"
+	pb "github.com/hoophq/hoop/common/proto"
+	pbagent "github.com/hoophq/hoop/common/proto/agent"
+	"github.com/hoophq/hoop/common/redistypes"
+)
+
+const defaultRedisPort = "6380"
+
+type RedisServer struct {
+	listenPort      string
+	client          pb.ClientTransport
+	connectionStore memory.Store
+	listener        net.Listener
+}
+
+func NewRedisServer(listenPort string, client pb.ClientTransport) *RedisServer {
+	if listenPort == "" {
+		listenPort = defaultRedisPort
+	}
+	return &RedisServer{
+		listenPort:      listenPort,
+		client:          client,
+		connectionStore: memory.New(),
+	}
+}
+
+func (s *RedisServer) Serve(sessionID string) error {
+	listenAddr := fmt.Sprintf("127.0.0.1:%s", s.listenPort)
+	lis, err := net.Listen("tcp4", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed listening to address %v, err=%v", listenAddr, err)
+	}
+	s.listener = lis
+	go func() {
+		connectionID := 0
+		for {
+			connectionID++
+			redisClient, err := lis.Accept()
+			if err != nil {
+				log.Infof("failed obtain listening connection, err=%v", err)
+				lis.Close()
+				break
+			}
+			go s.serveConn(sessionID, strconv.Itoa(connectionID), redisClient)
+		}
+	}()
+	return nil
+}
+
+func (s *RedisServer) serveConn(sessionID, connectionID string, redisClient net.Conn) {
+	defer func() {
+		log.Infof("session=%v | conn=%s | remote=%s - closing tcp connection",
+			sessionID, connectionID, redisClient.RemoteAddr())
+		s.connectionStore.Del(connectionID)
+		if err := redisClient.Close(); err != nil {
+			log.Warnf("failed closing client connection, err=%v", err)
+		}
+		_ = s.client.Send(&pb.Packet{
+			Type: pbagent.TCPConnectionClose,
+			Spec: map[string][]byte{
+				pb.SpecClientConnectionID: []byte(connectionID),
+				pb.SpecGatewaySessionID:   []byte(sessionID),
+			}})
+	}()
+	s.connectionStore.Set(connectionID, redisClient)
+	log.Infof("session=%v | conn=%s | client=%s - connected", sessionID, connectionID, redisClient.RemoteAddr())
+	redisServerWriter := pb.NewStreamWriter(s.client, pbagent.RedisConnectionWrite, map[string][]byte{
+		string(pb.SpecClientConnectionID): []byte(connectionID),
+		string(pb.SpecGatewaySessionID):   []byte(sessionID),
+	})
+	if written, err := copyRedisBuffer(redisServerWriter, redisClient, connectionID); err != nil {
+		log.Warnf("failed copying buffer, written=%v, err=%v", written, err)
+	}
+}
+
+func (s *RedisServer) PacketWriteClient(connectionID string, pkt *pb.Packet) (int, error) {
+	conn, err := s.getConnection(connectionID)
+	if err != nil {
+		return 0, err
+	}
+	return conn.Write(pkt.Payload)
+}
+
+func (s *RedisServer) CloseTCPConnection(connectionID string) {
+	if conn, err := s.getConnection(connectionID); err == nil {
+		_ = conn.Close()
+	}
+}
+
+func (s *RedisServer) Close() error { return s.listener.Close() }
+
+func (s *RedisServer) getConnection(connectionID string) (io.WriteCloser, error) {
+	connectionObj := s.connectionStore.Get(connectionID)
+	conn, ok := connectionObj.(io.WriteCloser)
+	if !ok {
+		return nil, fmt.Errorf("local connection %q not found", connectionID)
+	}
+	return conn, nil
+}
+
+func (s *RedisServer) ListenPort() string { return s.listenPort }
+
+// copyRedisBuffer sends each command in a distinct packet,
+// it allows the gateway to audit each command sent by the client.
+func copyRedisBuffer(dst io.Writer, src io.Reader, connectionID string) (written int64, err error) {
+	r := bufio.NewReader(src)
+	for {
+		pkt, err := redistypes.Decode(r)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return written, fmt.Errorf("fail to decode redis command, err=%v", err)
+		}
+		writtenSize, err := dst.Write(pkt.Encode())
+		if err != nil {
+			return written, fmt.Errorf("fail to write redis command, err=%v", err)
+		}
+		log.Debugf("%s, copied %v byte(s) from connection %v", pkt.Command(), writtenSize, connectionID)
+		written += int64(writtenSize)
+	}
+	return written, nil
+}
@@ -0,0 +1,129 @@
+// Package asciicast implements the asciinema file format (version 2)
+// https://docs.asciinema.org/manual/asciicast/v2/
+package asciicast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	Version = 2
+
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+type EventType string
+
+const (
+	OutputEvent EventType = "o"
+	InputEvent  EventType = "i"
+)
+
+// Header is the first line of an asciicast file
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Duration  float64           `json:"duration,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event represents a line in the format: [time, code, data].
+// The time is the amount of seconds since the beginning of the recording.
+type Event struct {
+	Time float64
+	Type EventType
+	Data string
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode([]any{e.Time, e.Type, e.Data}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var ev []any
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return err
+	}
+	if len(ev) != 3 {
+		return fmt.Errorf("invalid event, expected 3 elements, got=%v", len(ev))
+	}
+	eventTime, ok := ev[0].(float64)
+	if !ok {
+		return fmt.Errorf("invalid event time %v", ev[0])
+	}
+	eventType, _ := ev[1].(string)
+	eventData, _ := ev[2].(string)
+	*e = Event{Time: eventTime, Type: EventType(eventType), Data: eventData}
+	return nil
+}
+
+type Encoder struct {
+	w *json.Encoder
+}
+
+// NewEncoder writes the header to w returning an encoder to write the events
+func NewEncoder(w io.Writer, h Header) (*Encoder, error) {
+	if h.Version == 0 {
+		h.Version = Version
+	}
+	if h.Width == 0 || h.Height == 0 {
+		h.Width, h.Height = DefaultWidth, DefaultHeight
+	}
+	enc := &Encoder{w: json.NewEncoder(w)}
+	enc.w.SetEscapeHTML(false)
+	return enc, enc.w.Encode(h)
+}
+
+// Encode writes the event as a new line
+func (e *Encoder) Encode(ev Event) error { return e.w.Encode(ev) }
+
+// Decode parses an asciicast (version 2) content
+func Decode(r io.Reader) (*Header, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	// events could contain large outputs
+	scanner.Buffer(make([]byte, 0, 64*1024), 32<<20)
+	var header *Header
+	var events []Event
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if header == nil {
+			header = &Header{}
+			if err := json.Unmarshal(line, header); err != nil {
+				return nil, nil, fmt.Errorf("failed decoding header: %v", err)
+			}
+			if header.Version != Version {
+				return nil, nil, fmt.Errorf("unsupported asciicast version %v", header.Version)
+			}
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return nil, nil, fmt.Errorf("failed decoding event: %v", err)
+		}
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if header == nil {
+		return nil, nil, fmt.Errorf("missing asciicast header")
+	}
+	return header, events, nil
+}
@@ -0,0 +1,60 @@
+package asciicast
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, Header{Timestamp: 1700000000, Title: "sid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []Event{
+		{Time: 0.1, Type: OutputEvent, Data: "$ "},
+		{Time: 1.5, Type: InputEvent, Data: "ls\r"},
+		{Time: 1.75, Type: OutputEvent, Data: "a.txt\r\n<b>\r\n"},
+	}
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if want := `{"version":2,"width":80,"height":24,"timestamp":1700000000,"title":"sid"}`; lines[0] != want {
+		t.Errorf("expected header to match, want=%v, got=%v", want, lines[0])
+	}
+	if want := `[1.75,"o","a.txt\r\n<b>\r\n"]`; lines[3] != want {
+		t.Errorf("expected event to match, want=%v, got=%v", want, lines[3])
+	}
+
+	header, got, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != Version || header.Title != "sid" {
+		t.Errorf("expected header to match, got=%#v", header)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("expected %v events, got=%v", len(events), len(got))
+	}
+	for i := range events {
+		if got[i] != events[i] {
+			t.Errorf("expected event to match, want=%#v, got=%#v", events[i], got[i])
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, content := range []string{
+		"",
+		`{"version":1,"width":80,"height":24}`,
+		"{\"version\":2,\"width\":80,\"height\":24}\n[1.0,\"o\"]",
+	} {
+		if _, _, err := Decode(strings.NewReader(content)); err == nil {
+			t.Errorf("expected error decoding %q", content)
+		}
+	}
+}
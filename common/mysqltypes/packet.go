@@ -0,0 +1,40 @@
+package mysqltypes
+
+const (
+	// ComQuery is the command type of a text based query
+	// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_query.html
+	ComQuery byte = 0x03
+	// ComStmtPrepare is the command type that creates a prepared statement
+	// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_stmt_prepare.html
+	ComStmtPrepare byte = 0x16
+)
+
+// DecodeCommandQuery try to decode a packet to see if it's a COM_QUERY type,
+// it returns the query statement or nil if it's not a query packet
+func DecodeCommandQuery(payload []byte) []byte {
+	// packet header (4) + command (1)
+	if len(payload) < 5 {
+		return nil
+	}
+	if payload[4] != ComQuery {
+		return nil
+	}
+	pos := 5
+	// when the client has CLIENT_QUERY_ATTRIBUTES capability enabled,
+	// it sends the parameter count (0x00) and parameter set count (0x01)
+	if len(payload) > 6 && payload[5] == 0x00 && payload[6] == 0x01 {
+		pos += 2
+	}
+	// TODO: must check when parameters is set
+	return payload[pos:]
+}
+
+// DecodeStmtPrepare try to decode a packet to see if it's a COM_STMT_PREPARE type,
+// it returns the statement being prepared or nil if it's not a prepare packet
+func DecodeStmtPrepare(payload []byte) []byte {
+	// packet header (4) + command (1)
+	if len(payload) < 5 || payload[4] != ComStmtPrepare {
+		return nil
+	}
+	return payload[5:]
+}
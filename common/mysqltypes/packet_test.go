@@ -0,0 +1,54 @@
+package mysqltypes
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDecodeCommandQuery(t *testing.T) {
+	for _, tt := range []struct {
+		msg       string
+		want      string
+		pktStream string
+	}{
+		{
+			msg:       "it should decode a query statement",
+			want:      "select @@version_comment limit 1",
+			pktStream: "2100000003" + hex.EncodeToString([]byte("select @@version_comment limit 1")),
+		},
+		{
+			msg:       "it should skip parameter count and parameter set count",
+			want:      "DROP TABLE customers",
+			pktStream: "1700000003" + "0001" + hex.EncodeToString([]byte("DROP TABLE customers")),
+		},
+		{
+			msg:       "it should return nil when it is not a query packet",
+			want:      "",
+			pktStream: "0100000001",
+		},
+		{
+			msg:       "it should return nil with small packets",
+			want:      "",
+			pktStream: "010000",
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.pktStream)
+			got := DecodeCommandQuery(data)
+			if tt.want != string(got) {
+				t.Errorf("expect to decode query, want=%q, got=%q", tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestDecodeStmtPrepare(t *testing.T) {
+	data, _ := hex.DecodeString("1500000016" + hex.EncodeToString([]byte("DELETE FROM customers")))
+	if got := DecodeStmtPrepare(data); string(got) != "DELETE FROM customers" {
+		t.Errorf("expect to decode prepared statement, got=%q", string(got))
+	}
+	data, _ = hex.DecodeString("2100000003" + hex.EncodeToString([]byte("select 1")))
+	if got := DecodeStmtPrepare(data); got != nil {
+		t.Errorf("expect nil with query packets, got=%q", string(got))
+	}
+}
@@ -115,6 +115,34 @@ func SimpleQueryContent(payload []byte) (bool, []byte, error) {
 	return true, queryFrame, nil
 }
 
+// QueriesContent returns the statements of the simple query and parse (extended protocol)
+// messages of a payload. A payload may contain multiple messages, e.g.: Parse, Bind, Execute and Sync.
+// The statements decoded before an invalid message are returned along with the error.
+func QueriesContent(payload []byte) ([][]byte, error) {
+	var queries [][]byte
+	r := bytes.NewReader(payload)
+	for r.Len() > 0 {
+		pkt, err := Decode(r)
+		if err != nil {
+			return queries, err
+		}
+		switch pkt.Type() {
+		case ClientSimpleQuery:
+			queries = append(queries, bytes.TrimRight(pkt.Frame(), "\x00"))
+		case ClientParse:
+			// the frame starts with the name of the prepared statement followed by the query,
+			// both are null terminated strings
+			_, frame, found := bytes.Cut(pkt.Frame(), []byte{0x00})
+			if !found {
+				return queries, fmt.Errorf("failed decoding parse message, missing statement name")
+			}
+			query, _, _ := bytes.Cut(frame, []byte{0x00})
+			queries = append(queries, query)
+		}
+	}
+	return queries, nil
+}
+
 type BackendKeyData struct {
 	Pid       uint32
 	SecretKey uint32
@@ -27,6 +27,9 @@ const (
 	SpecGatewayJitID              string = "jit.id"
 	SpecJitStatus                 string = "jit.status"
 	SpecJitTimeout                string = "jit.timeout"
+	SpecBreakGlassJustification   string = "breakglass.justification"
+	SpecSessionJustification      string = "session.justification"
+	SpecSessionTicketID           string = "session.ticket_id"
 
 	DefaultKeepAlive time.Duration = 10 * time.Second
 
@@ -35,6 +38,7 @@ const (
 	ConnectionTypeMySQL       ConnectionType = "mysql"
 	ConnectionTypeMSSQL       ConnectionType = "mssql"
 	ConnectionTypeMongoDB     ConnectionType = "mongodb"
+	ConnectionTypeRedis       ConnectionType = "redis"
 	ConnectionTypeTCP         ConnectionType = "tcp"
 
 	ConnectionOriginAgent              = "agent"
@@ -1,8 +1,27 @@
 package proto
 
 import (
+	"strings"
+
 	"google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
 )
 
+// stepUpAuthMessage prefixes the errors of connections that require a recent authentication
+const stepUpAuthMessage = "step-up authentication required"
+
 var ErrAgentOffline = status.Errorf(codes.FailedPrecondition, "agent is offline")
+
+// NewStepUpAuthError returns the error of connections that require the user
+// to have authenticated within the last minutes (step-up authentication)
+func NewStepUpAuthError(connectionName string, maxAgeMinutes int) error {
+	return status.Errorf(codes.Unauthenticated,
+		"%s, the connection %v requires an authentication within the last %v minute(s), login again to continue",
+		stepUpAuthMessage, connectionName, maxAgeMinutes)
+}
+
+// IsStepUpAuthError reports if the user must authenticate again to access the connection
+func IsStepUpAuthError(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.Unauthenticated && strings.HasPrefix(st.Message(), stepUpAuthMessage)
+}
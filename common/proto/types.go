@@ -41,6 +41,10 @@ type (
 		ClientVerb     string
 		ClientOrigin   string
 		DLPInfoTypes   []string
+		// The grant statements to apply to a temporary user created
+		// for the session. It's only set when the database credentials
+		// manager plugin is enabled for the connection
+		DatabaseCredentialsGrants []string
 	}
 
 	// TODO: remove it later, kept for compatibility issues
@@ -173,6 +177,8 @@ func ToConnectionType(connectionType, subtype string) ConnectionType {
 			return ConnectionType(ConnectionTypeMongoDB)
 		case "mssql":
 			return ConnectionType(ConnectionTypeMSSQL)
+		case "redis":
+			return ConnectionType(ConnectionTypeRedis)
 		}
 	}
 	return ConnectionType(connectionType)
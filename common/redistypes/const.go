@@ -0,0 +1,28 @@
+package redistypes
+
+// DefaultMaxBulkSize is the maximum size of a bulk string accepted by the decoder.
+// It matches the proto-max-bulk-len default of a redis server (512MB)
+const DefaultMaxBulkSize = 512 << 20
+
+// DefaultMaxArgs is the maximum number of elements accepted in a command array
+const DefaultMaxArgs = 1 << 20
+
+type DataType byte
+
+func (t DataType) Byte() byte { return byte(t) }
+
+// RESP data types
+// https://redis.io/docs/latest/develop/reference/protocol-spec/#resp-protocol-description
+const (
+	TypeSimpleString DataType = '+'
+	TypeSimpleError  DataType = '-'
+	TypeInteger      DataType = ':'
+	TypeBulkString   DataType = '$'
+	TypeArray        DataType = '*'
+)
+
+// commands that carry credentials in their arguments
+var authCommands = map[string]bool{
+	"AUTH":  true,
+	"HELLO": true,
+}
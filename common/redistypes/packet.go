@@ -0,0 +1,151 @@
+package redistypes
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Packet represents a command sent by a client using the RESP protocol.
+// A client could send a command as an array of bulk strings or as an inline command.
+type Packet struct {
+	raw  []byte
+	Args [][]byte
+}
+
+// Encode returns the packet as it was received from the wire
+func (p *Packet) Encode() []byte { return p.raw }
+func (p *Packet) Dump()          { fmt.Print(hex.Dump(p.Encode())) }
+
+// Command returns the name of the command in upper case
+func (p *Packet) Command() string {
+	if len(p.Args) == 0 {
+		return ""
+	}
+	return strings.ToUpper(string(p.Args[0]))
+}
+
+// IsAuth reports if the command carries credentials (AUTH, HELLO)
+func (p *Packet) IsAuth() bool { return authCommands[p.Command()] }
+
+// String returns a human readable representation of the command.
+// Arguments of commands containing credentials are redacted.
+func (p *Packet) String() string {
+	if len(p.Args) == 0 {
+		return ""
+	}
+	cmd := []string{p.Command()}
+	for _, arg := range p.Args[1:] {
+		if p.IsAuth() {
+			cmd = append(cmd, "******")
+			continue
+		}
+		cmd = append(cmd, quoteArg(arg))
+	}
+	return strings.Join(cmd, " ")
+}
+
+// Decode reads a single client command from the reader
+func Decode(r *bufio.Reader) (*Packet, error) {
+	pkt := &Packet{}
+	line, err := pkt.readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return pkt, nil
+	}
+	if DataType(line[0]) != TypeArray {
+		for _, arg := range strings.Fields(string(line)) {
+			pkt.Args = append(pkt.Args, []byte(arg))
+		}
+		return pkt, nil
+	}
+	argsLen, err := strconv.Atoi(string(line[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing array length, err=%v", err)
+	}
+	if argsLen > DefaultMaxArgs {
+		return nil, fmt.Errorf("max number of arguments (%v) reached", DefaultMaxArgs)
+	}
+	for i := 0; i < argsLen; i++ {
+		line, err := pkt.readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || DataType(line[0]) != TypeBulkString {
+			return nil, fmt.Errorf("expected bulk string, found=%q", line)
+		}
+		bulkLen, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing bulk string length, err=%v", err)
+		}
+		if bulkLen > DefaultMaxBulkSize || bulkLen < 0 {
+			return nil, fmt.Errorf("max bulk size (%v) reached", DefaultMaxBulkSize)
+		}
+		// bulk data + CRLF
+		data := make([]byte, bulkLen+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("failed reading bulk string, err=%v", err)
+		}
+		pkt.raw = append(pkt.raw, data...)
+		pkt.Args = append(pkt.Args, data[:bulkLen])
+	}
+	return pkt, nil
+}
+
+// DecodeCommand decodes a command from a packet payload
+func DecodeCommand(payload []byte) (*Packet, error) {
+	return Decode(bufio.NewReader(bytes.NewReader(payload)))
+}
+
+// NewCommand encodes the arguments as an array of bulk strings
+func NewCommand(args ...string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return buf.Bytes()
+}
+
+// NewSimpleString encodes a simple string reply
+func NewSimpleString(s string) []byte { return []byte(fmt.Sprintf("+%s\r\n", s)) }
+
+// NewError encodes a simple error reply
+func NewError(format string, a ...any) []byte {
+	msg := strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf(format, a...))
+	return []byte(fmt.Sprintf("-ERR %s\r\n", msg))
+}
+
+// readLine reads a line terminated by CRLF returning it without the terminator
+func (p *Packet) readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		if err == io.EOF && len(line) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	p.raw = append(p.raw, line...)
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+func quoteArg(arg []byte) string {
+	needQuote := len(arg) == 0
+	for _, r := range string(arg) {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) || r == '"' {
+			needQuote = true
+			break
+		}
+	}
+	if needQuote {
+		return strconv.Quote(string(arg))
+	}
+	return string(arg)
+}
@@ -0,0 +1,101 @@
+package redistypes
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDecodeCommand(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		payload string
+		wantCmd string
+		want    string
+	}{
+		{
+			msg:     "it should decode an array of bulk strings",
+			payload: "*3\r\n$3\r\nset\r\n$5\r\nmykey\r\n$7\r\nmyvalue\r\n",
+			wantCmd: "SET",
+			want:    "SET mykey myvalue",
+		},
+		{
+			msg:     "it should quote arguments containing spaces",
+			payload: "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$11\r\nhello world\r\n",
+			wantCmd: "SET",
+			want:    `SET key "hello world"`,
+		},
+		{
+			msg:     "it should decode inline commands",
+			payload: "FLUSHALL ASYNC\r\n",
+			wantCmd: "FLUSHALL",
+			want:    "FLUSHALL ASYNC",
+		},
+		{
+			msg:     "it should redact credentials from auth commands",
+			payload: "*3\r\n$4\r\nAUTH\r\n$7\r\ndefault\r\n$6\r\nsecret\r\n",
+			wantCmd: "AUTH",
+			want:    "AUTH ****** ******",
+		},
+		{
+			msg:     "it should decode bulk strings containing crlf",
+			payload: "*2\r\n$3\r\nGET\r\n$4\r\na\r\nb\r\n",
+			wantCmd: "GET",
+			want:    `GET "a\r\nb"`,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			pkt, err := DecodeCommand([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("did not expect error, got=%v", err)
+			}
+			if pkt.Command() != tt.wantCmd {
+				t.Errorf("expect command to match, want=%v, got=%v", tt.wantCmd, pkt.Command())
+			}
+			if pkt.String() != tt.want {
+				t.Errorf("expect command string to match, want=%v, got=%v", tt.want, pkt.String())
+			}
+			if !bytes.Equal(pkt.Encode(), []byte(tt.payload)) {
+				t.Errorf("expect encoded packet to match, want=%q, got=%q", tt.payload, pkt.Encode())
+			}
+		})
+	}
+}
+
+func TestDecodePipelinedCommands(t *testing.T) {
+	stream := append(NewCommand("GET", "foo"), NewCommand("DEL", "foo", "bar")...)
+	r := bufio.NewReader(bytes.NewReader(stream))
+	var got []string
+	for {
+		pkt, err := Decode(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, pkt.String())
+	}
+	if len(got) != 2 || got[0] != "GET foo" || got[1] != "DEL foo bar" {
+		t.Errorf("expect to decode pipelined commands, got=%v", got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		payload string
+	}{
+		{msg: "it should fail with invalid array length", payload: "*a\r\n"},
+		{msg: "it should fail when element is not a bulk string", payload: "*1\r\n:1\r\n"},
+		{msg: "it should fail with truncated bulk string", payload: "*1\r\n$10\r\nGET\r\n"},
+		{msg: "it should fail with truncated line", payload: "*1\r\n$3"},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			if _, err := DecodeCommand([]byte(tt.payload)); err == nil {
+				t.Errorf("expect error decoding payload %q", tt.payload)
+			}
+		})
+	}
+}
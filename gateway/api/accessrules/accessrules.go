@@ -0,0 +1,218 @@
+package apiaccessrules
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgaccessrules "github.com/hoophq/hoop/gateway/pgrest/accessrules"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	pluginsaccessrules "github.com/hoophq/hoop/gateway/transport/plugins/accessrules"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ListAccessRules
+//
+//	@Summary		List Access Rules
+//	@Description	List the attribute based rules evaluated when users connect to connections
+//	@Tags			Core
+//	@Produce		json
+//	@Success		200	{array}		openapi.AccessRule
+//	@Failure		500	{object}	openapi.HTTPError
+//	@Router			/access-rules [get]
+func List(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	items, err := pgaccessrules.New().FetchAll(ctx)
+	if err != nil {
+		log.Errorf("failed listing access rules, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing access rules"})
+		return
+	}
+	rules := []openapi.AccessRule{}
+	for _, r := range items {
+		rules = append(rules, toOpenApi(&r))
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateAccessRule
+//
+//	@Summary		Create Access Rule
+//	@Description	Create an attribute based rule evaluated when users connect to any connection of the organization.
+//	@Description	A matching deny rule refuses the access; when there are allow rules applied to a connection, at least one of them must match.
+//	@Description	The expression is a subset of the Common Expression Language (CEL) with the attributes:
+//	@Description	`user.id`, `user.email`, `user.email_domain`, `user.groups`, `connection.name`, `connection.type`, `connection.subtype`, `connection.tags`,
+//	@Description	`agent.name`, `client.verb`, `client.origin`, `client.source_ip`, `now.weekday` (0 is sunday), `now.hour`, `now.minute` and `now.time` (HH:MM).
+//	@Description	The methods `contains`, `startsWith`, `endsWith`, `matches` and `inCIDR` are available.
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.AccessRule	true	"The request body resource"
+//	@Success		201				{object}	openapi.AccessRule
+//	@Failure		400,409,422,500	{object}	openapi.HTTPError
+//	@Router			/access-rules [post]
+func Create(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.AccessRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validateRule(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	existing, err := pgaccessrules.New().FetchOne(ctx, req.Name)
+	if err != nil {
+		log.Errorf("failed fetching access rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching access rule"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"message": "access rule already exists"})
+		return
+	}
+	obj, err := pgaccessrules.New().Create(ctx, toPgrest(&req))
+	if err != nil {
+		log.Errorf("failed creating access rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed creating access rule"})
+		return
+	}
+	c.JSON(http.StatusCreated, toOpenApi(obj))
+}
+
+// UpdateAccessRule
+//
+//	@Summary		Update Access Rule
+//	@Description	Update an attribute based rule evaluated when users connect to connections
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			name			path		string				true	"The name of the rule"
+//	@Param			request			body		openapi.AccessRule	true	"The request body resource"
+//	@Success		200				{object}	openapi.AccessRule
+//	@Failure		400,404,422,500	{object}	openapi.HTTPError
+//	@Router			/access-rules/{name} [put]
+func Update(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.AccessRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	// the name is immutable
+	req.Name = c.Param("name")
+	if err := validateRule(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	existing, err := pgaccessrules.New().FetchOne(ctx, req.Name)
+	if err != nil {
+		log.Errorf("failed fetching access rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching access rule"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "access rule not found"})
+		return
+	}
+	if err := pgaccessrules.New().Update(ctx, toPgrest(&req)); err != nil {
+		log.Errorf("failed updating access rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating access rule"})
+		return
+	}
+	obj, err := pgaccessrules.New().FetchOne(ctx, req.Name)
+	if err != nil || obj == nil {
+		log.Errorf("failed fetching access rule, found=%v, err=%v", obj != nil, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching access rule"})
+		return
+	}
+	c.JSON(http.StatusOK, toOpenApi(obj))
+}
+
+// DeleteAccessRule
+//
+//	@Summary		Delete Access Rule
+//	@Description	Delete an attribute based rule evaluated when users connect to connections
+//	@Tags			Core
+//	@Param			name	path	string	true	"The name of the rule"
+//	@Success		204
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/access-rules/{name} [delete]
+func Delete(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	existing, err := pgaccessrules.New().FetchOne(ctx, c.Param("name"))
+	if err != nil {
+		log.Errorf("failed fetching access rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching access rule"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "access rule not found"})
+		return
+	}
+	if err := pgaccessrules.New().Delete(ctx, existing.Name); err != nil {
+		log.Errorf("failed removing access rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed removing access rule"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func validateRule(req *openapi.AccessRule) error {
+	if !namePattern.MatchString(req.Name) {
+		return fmt.Errorf("name must contain only alphanumeric characters, dashes or underscores")
+	}
+	if req.Effect != pluginsaccessrules.EffectAllow && req.Effect != pluginsaccessrules.EffectDeny {
+		return fmt.Errorf("effect must be %q or %q", pluginsaccessrules.EffectAllow, pluginsaccessrules.EffectDeny)
+	}
+	if _, err := pluginsaccessrules.Compile(req.Expression); err != nil {
+		return fmt.Errorf("expression is invalid: %v", err)
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return fmt.Errorf("timezone is invalid: %v", err)
+	}
+	return nil
+}
+
+func toPgrest(r *openapi.AccessRule) pgrest.AccessRule {
+	return pgrest.AccessRule{
+		Name:        r.Name,
+		Description: r.Description,
+		Effect:      r.Effect,
+		Expression:  r.Expression,
+		Connections: r.Connections,
+		Timezone:    r.Timezone,
+	}
+}
+
+func toOpenApi(r *pgrest.AccessRule) openapi.AccessRule {
+	return openapi.AccessRule{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Effect:      r.Effect,
+		Expression:  r.Expression,
+		Connections: r.Connections,
+		Timezone:    r.Timezone,
+		CreatedAt:   r.GetCreatedAt(),
+		UpdatedAt:   r.GetUpdatedAt(),
+	}
+}
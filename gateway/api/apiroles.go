@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/security/usertoken"
 )
 
 var roleContextKey = "ginrole"
@@ -34,3 +35,31 @@ func RoleFromContext(c *gin.Context) openapi.RoleType {
 	role, _ := obj.(openapi.RoleType)
 	return role
 }
+
+var tokenScopeContextKey = "gintokenscope"
+
+// TokenScope grants access to personal access tokens containing the scope.
+// Routes without a scope are only accessible by tokens with the admin scope.
+func TokenScope(scope string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.Set(tokenScopeContextKey, scope)
+		c.Next()
+	}
+}
+
+// DenyTokenAccess denies the access of personal access tokens regardless of their scopes
+func DenyTokenAccess(c *gin.Context) {
+	c.Set(tokenScopeContextKey, "")
+	c.Next()
+}
+
+// tokenScopeFromContext returns the scope required by the route and
+// if the route allows the access of personal access tokens
+func tokenScopeFromContext(c *gin.Context) (scope string, allowed bool) {
+	obj, ok := c.Get(tokenScopeContextKey)
+	if !ok {
+		return usertoken.ScopeAdmin, true
+	}
+	scope, _ = obj.(string)
+	return scope, scope != ""
+}
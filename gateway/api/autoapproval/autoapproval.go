@@ -0,0 +1,249 @@
+package apiautoapproval
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgautoapproval "github.com/hoophq/hoop/gateway/pgrest/autoapproval"
+	"github.com/hoophq/hoop/gateway/storagev2"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ListReviewAutoApprovalRules
+//
+//	@Summary		List Review Auto Approval Rules
+//	@Description	List the rules that approve reviews automatically
+//	@Tags			Core
+//	@Produce		json
+//	@Success		200	{array}		openapi.ReviewAutoApprovalRule
+//	@Failure		500	{object}	openapi.HTTPError
+//	@Router			/review-auto-approval-rules [get]
+func List(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	items, err := pgautoapproval.New().FetchAll(ctx)
+	if err != nil {
+		log.Errorf("failed listing auto approval rules, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing auto approval rules"})
+		return
+	}
+	rules := []openapi.ReviewAutoApprovalRule{}
+	for _, r := range items {
+		rules = append(rules, toOpenApi(&r))
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateReviewAutoApprovalRule
+//
+//	@Summary		Create Review Auto Approval Rule
+//	@Description	Create a rule that approves reviews automatically. A review is approved when it matches all the non empty attributes of a rule,
+//	@Description	the rule is recorded as the reviewer of the approval groups.
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.ReviewAutoApprovalRule	true	"The request body resource"
+//	@Success		201				{object}	openapi.ReviewAutoApprovalRule
+//	@Failure		400,409,422,500	{object}	openapi.HTTPError
+//	@Router			/review-auto-approval-rules [post]
+func Create(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.ReviewAutoApprovalRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validateRule(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	existing, err := pgautoapproval.New().FetchOne(ctx, req.Name)
+	if err != nil {
+		log.Errorf("failed fetching auto approval rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching auto approval rule"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"message": "auto approval rule already exists"})
+		return
+	}
+	obj, err := pgautoapproval.New().Create(ctx, toPgrest(&req))
+	if err != nil {
+		log.Errorf("failed creating auto approval rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed creating auto approval rule"})
+		return
+	}
+	c.JSON(http.StatusCreated, toOpenApi(obj))
+}
+
+// UpdateReviewAutoApprovalRule
+//
+//	@Summary		Update Review Auto Approval Rule
+//	@Description	Update a rule that approves reviews automatically
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			name			path		string							true	"The name of the rule"
+//	@Param			request			body		openapi.ReviewAutoApprovalRule	true	"The request body resource"
+//	@Success		200				{object}	openapi.ReviewAutoApprovalRule
+//	@Failure		400,404,422,500	{object}	openapi.HTTPError
+//	@Router			/review-auto-approval-rules/{name} [put]
+func Update(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.ReviewAutoApprovalRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	// the name is immutable
+	req.Name = c.Param("name")
+	if err := validateRule(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	existing, err := pgautoapproval.New().FetchOne(ctx, req.Name)
+	if err != nil {
+		log.Errorf("failed fetching auto approval rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching auto approval rule"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "auto approval rule not found"})
+		return
+	}
+	if err := pgautoapproval.New().Update(ctx, toPgrest(&req)); err != nil {
+		log.Errorf("failed updating auto approval rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating auto approval rule"})
+		return
+	}
+	obj, err := pgautoapproval.New().FetchOne(ctx, req.Name)
+	if err != nil || obj == nil {
+		log.Errorf("failed fetching auto approval rule, found=%v, err=%v", obj != nil, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching auto approval rule"})
+		return
+	}
+	c.JSON(http.StatusOK, toOpenApi(obj))
+}
+
+// DeleteReviewAutoApprovalRule
+//
+//	@Summary		Delete Review Auto Approval Rule
+//	@Description	Delete a rule that approves reviews automatically
+//	@Tags			Core
+//	@Param			name	path	string	true	"The name of the rule"
+//	@Success		204
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/review-auto-approval-rules/{name} [delete]
+func Delete(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	existing, err := pgautoapproval.New().FetchOne(ctx, c.Param("name"))
+	if err != nil {
+		log.Errorf("failed fetching auto approval rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching auto approval rule"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "auto approval rule not found"})
+		return
+	}
+	if err := pgautoapproval.New().Delete(ctx, existing.Name); err != nil {
+		log.Errorf("failed removing auto approval rule, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed removing auto approval rule"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func validateRule(req *openapi.ReviewAutoApprovalRule) error {
+	if !namePattern.MatchString(req.Name) {
+		return fmt.Errorf("name must contain only alphanumeric characters, dashes or underscores")
+	}
+	if !req.ReadOnly && req.QueryPattern == "" && len(req.Groups) == 0 && req.BusinessHours == nil {
+		return fmt.Errorf("at least one of the attributes read_only, query_pattern, groups or business_hours must be set")
+	}
+	if req.QueryPattern != "" {
+		if _, err := regexp.Compile(req.QueryPattern); err != nil {
+			return fmt.Errorf("query_pattern is not a valid regular expression: %v", err)
+		}
+	}
+	if bh := req.BusinessHours; bh != nil {
+		if bh.Timezone == "" {
+			bh.Timezone = "UTC"
+		}
+		if _, err := time.LoadLocation(bh.Timezone); err != nil {
+			return fmt.Errorf("business_hours.timezone is invalid: %v", err)
+		}
+		for _, d := range bh.Weekdays {
+			if d < 0 || d > 6 {
+				return fmt.Errorf("business_hours.weekdays must be between 0 (sunday) and 6 (saturday)")
+			}
+		}
+		start, err := time.Parse("15:04", bh.Start)
+		if err != nil {
+			return fmt.Errorf("business_hours.start must be in the format HH:MM")
+		}
+		end, err := time.Parse("15:04", bh.End)
+		if err != nil {
+			return fmt.Errorf("business_hours.end must be in the format HH:MM")
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("business_hours.start and business_hours.end must be different")
+		}
+	}
+	return nil
+}
+
+func toPgrest(r *openapi.ReviewAutoApprovalRule) pgrest.ReviewAutoApprovalRule {
+	rule := pgrest.ReviewAutoApprovalRule{
+		Name:         r.Name,
+		Connections:  r.Connections,
+		ReadOnly:     r.ReadOnly,
+		QueryPattern: r.QueryPattern,
+		Groups:       r.Groups,
+	}
+	if bh := r.BusinessHours; bh != nil {
+		rule.BusinessHours = &pgrest.BusinessHours{
+			Timezone: bh.Timezone,
+			Weekdays: bh.Weekdays,
+			Start:    bh.Start,
+			End:      bh.End,
+		}
+	}
+	return rule
+}
+
+func toOpenApi(r *pgrest.ReviewAutoApprovalRule) openapi.ReviewAutoApprovalRule {
+	rule := openapi.ReviewAutoApprovalRule{
+		ID:           r.ID,
+		Name:         r.Name,
+		Connections:  r.Connections,
+		ReadOnly:     r.ReadOnly,
+		QueryPattern: r.QueryPattern,
+		Groups:       r.Groups,
+		CreatedAt:    r.GetCreatedAt(),
+		UpdatedAt:    r.GetUpdatedAt(),
+	}
+	if bh := r.BusinessHours; bh != nil {
+		rule.BusinessHours = &openapi.BusinessHours{
+			Timezone: bh.Timezone,
+			Weekdays: bh.Weekdays,
+			Start:    bh.Start,
+			End:      bh.End,
+		}
+	}
+	return rule
+}
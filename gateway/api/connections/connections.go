@@ -66,21 +66,27 @@ func Post(c *gin.Context) {
 	}
 
 	err = pgconnections.New().Upsert(ctx, pgrest.Connection{
-		ID:                 req.ID,
-		OrgID:              ctx.OrgID,
-		AgentID:            req.AgentId,
-		Name:               req.Name,
-		Command:            req.Command,
-		Type:               string(req.Type),
-		SubType:            req.SubType,
-		Envs:               coerceToMapString(req.Secrets),
-		Status:             req.Status,
-		ManagedBy:          nil,
-		Tags:               req.Tags,
-		AccessModeRunbooks: req.AccessModeRunbooks,
-		AccessModeExec:     req.AccessModeExec,
-		AccessModeConnect:  req.AccessModeConnect,
-		AccessSchema:       req.AccessSchema,
+		ID:                   req.ID,
+		OrgID:                ctx.OrgID,
+		AgentID:              req.AgentId,
+		Name:                 req.Name,
+		Command:              req.Command,
+		Type:                 string(req.Type),
+		SubType:              req.SubType,
+		Envs:                 coerceToMapString(req.Secrets),
+		Status:               req.Status,
+		ManagedBy:            nil,
+		Tags:                 req.Tags,
+		AccessModeRunbooks:   req.AccessModeRunbooks,
+		AccessModeExec:       req.AccessModeExec,
+		AccessModeConnect:    req.AccessModeConnect,
+		AccessSchema:         req.AccessSchema,
+		ReviewTTLSec:         req.ReviewTTLSec,
+		RequireJustification: req.RequireJustification,
+		TicketIDPattern:      req.TicketIDPattern,
+		RequireRecentAuth:    req.RequireRecentAuth,
+		DenyRules:            req.DenyRules,
+		BreakGlassGroups:     req.BreakGlassGroups,
 	})
 	if err != nil {
 		log.Errorf("failed creating connection, err=%v", err)
@@ -158,21 +164,27 @@ func Put(c *gin.Context) {
 	req.Name = conn.Name
 	req.Status = conn.Status
 	err = pgconnections.New().Upsert(ctx, pgrest.Connection{
-		ID:                 conn.ID,
-		OrgID:              conn.OrgID,
-		AgentID:            req.AgentId,
-		Name:               conn.Name,
-		Command:            req.Command,
-		Type:               req.Type,
-		SubType:            req.SubType,
-		Envs:               coerceToMapString(req.Secrets),
-		Status:             conn.Status,
-		ManagedBy:          nil,
-		Tags:               req.Tags,
-		AccessModeRunbooks: req.AccessModeRunbooks,
-		AccessModeExec:     req.AccessModeExec,
-		AccessModeConnect:  req.AccessModeConnect,
-		AccessSchema:       req.AccessSchema,
+		ID:                   conn.ID,
+		OrgID:                conn.OrgID,
+		AgentID:              req.AgentId,
+		Name:                 conn.Name,
+		Command:              req.Command,
+		Type:                 req.Type,
+		SubType:              req.SubType,
+		Envs:                 coerceToMapString(req.Secrets),
+		Status:               conn.Status,
+		ManagedBy:            nil,
+		Tags:                 req.Tags,
+		AccessModeRunbooks:   req.AccessModeRunbooks,
+		AccessModeExec:       req.AccessModeExec,
+		AccessModeConnect:    req.AccessModeConnect,
+		AccessSchema:         req.AccessSchema,
+		ReviewTTLSec:         req.ReviewTTLSec,
+		RequireJustification: req.RequireJustification,
+		TicketIDPattern:      req.TicketIDPattern,
+		RequireRecentAuth:    req.RequireRecentAuth,
+		DenyRules:            req.DenyRules,
+		BreakGlassGroups:     req.BreakGlassGroups,
 	})
 	if err != nil {
 		log.Errorf("failed updating connection, err=%v", err)
@@ -208,26 +220,32 @@ func Put(c *gin.Context) {
 //	@Description	Delete a connection resource.
 //	@Tags			Core
 //	@Produce		json
-//	@Param			name	path	string	true	"The name of the resource"
+//	@Param			nameOrID	path	string	true	"The name or ID of the resource"
 //	@Success		204
 //	@Failure		404,500	{object}	openapi.HTTPError
-//	@Router			/connections/{name} [delete]
+//	@Router			/connections/{nameOrID} [delete]
 func Delete(c *gin.Context) {
 	ctx := storagev2.ParseContext(c)
-	connName := c.Param("name")
-	if connName == "" {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "missing connection name"})
+	conn, err := pgconnections.New().FetchOneByNameOrID(ctx, c.Param("nameOrID"))
+	if err != nil {
+		log.Errorf("failed fetching connection, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching connection"})
+		return
+	}
+	if conn == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "not found"})
 		return
 	}
-	err := pgconnections.New().Delete(ctx, connName)
+	err = pgconnections.New().Delete(ctx, conn.Name)
 	switch err {
 	case pgrest.ErrNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"message": "not found"})
 	case nil:
-		connectionrequests.InvalidateSyncCache(ctx.OrgID, connName)
+		connectionrequests.InvalidateSyncCache(ctx.OrgID, conn.Name)
 		c.Writer.WriteHeader(http.StatusNoContent)
 	default:
-		log.Errorf("failed removing connection %v, err=%v", connName, err)
+		log.Errorf("failed removing connection %v, err=%v", conn.Name, err)
 		sentry.CaptureException(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed removing connection"})
 	}
@@ -284,23 +302,29 @@ func List(c *gin.Context) {
 				}
 			}
 			responseConnList = append(responseConnList, openapi.Connection{
-				ID:                 conn.ID,
-				Name:               conn.Name,
-				Command:            conn.Command,
-				Type:               conn.Type,
-				SubType:            conn.SubType,
-				Secrets:            coerceToAnyMap(conn.Envs),
-				AgentId:            conn.AgentID,
-				Status:             conn.Status,
-				Reviewers:          reviewers,
-				RedactEnabled:      len(redactTypes) > 0,
-				RedactTypes:        redactTypes,
-				ManagedBy:          conn.ManagedBy,
-				Tags:               conn.Tags,
-				AccessModeRunbooks: conn.AccessModeRunbooks,
-				AccessModeExec:     conn.AccessModeExec,
-				AccessModeConnect:  conn.AccessModeConnect,
-				AccessSchema:       conn.AccessSchema,
+				ID:                   conn.ID,
+				Name:                 conn.Name,
+				Command:              conn.Command,
+				Type:                 conn.Type,
+				SubType:              conn.SubType,
+				Secrets:              coerceToAnyMap(conn.Envs),
+				AgentId:              conn.AgentID,
+				Status:               conn.Status,
+				Reviewers:            reviewers,
+				RedactEnabled:        len(redactTypes) > 0,
+				RedactTypes:          redactTypes,
+				ManagedBy:            conn.ManagedBy,
+				Tags:                 conn.Tags,
+				AccessModeRunbooks:   conn.AccessModeRunbooks,
+				AccessModeExec:       conn.AccessModeExec,
+				AccessModeConnect:    conn.AccessModeConnect,
+				AccessSchema:         conn.AccessSchema,
+				ReviewTTLSec:         conn.ReviewTTLSec,
+				RequireJustification: conn.RequireJustification,
+				TicketIDPattern:      conn.TicketIDPattern,
+				RequireRecentAuth:    conn.RequireRecentAuth,
+				DenyRules:            conn.DenyRules,
+				BreakGlassGroups:     conn.BreakGlassGroups,
 			})
 		}
 
@@ -349,23 +373,29 @@ func Get(c *gin.Context) {
 		}
 	}
 	c.JSON(http.StatusOK, openapi.Connection{
-		ID:                 conn.ID,
-		Name:               conn.Name,
-		Command:            conn.Command,
-		Type:               conn.Type,
-		SubType:            conn.SubType,
-		Secrets:            coerceToAnyMap(conn.Envs),
-		AgentId:            conn.AgentID,
-		Status:             conn.Status,
-		Reviewers:          reviewers,
-		RedactEnabled:      len(redactTypes) > 0,
-		RedactTypes:        redactTypes,
-		ManagedBy:          conn.ManagedBy,
-		Tags:               conn.Tags,
-		AccessModeRunbooks: conn.AccessModeRunbooks,
-		AccessModeExec:     conn.AccessModeExec,
-		AccessModeConnect:  conn.AccessModeConnect,
-		AccessSchema:       conn.AccessSchema,
+		ID:                   conn.ID,
+		Name:                 conn.Name,
+		Command:              conn.Command,
+		Type:                 conn.Type,
+		SubType:              conn.SubType,
+		Secrets:              coerceToAnyMap(conn.Envs),
+		AgentId:              conn.AgentID,
+		Status:               conn.Status,
+		Reviewers:            reviewers,
+		RedactEnabled:        len(redactTypes) > 0,
+		RedactTypes:          redactTypes,
+		ManagedBy:            conn.ManagedBy,
+		Tags:                 conn.Tags,
+		AccessModeRunbooks:   conn.AccessModeRunbooks,
+		AccessModeExec:       conn.AccessModeExec,
+		AccessModeConnect:    conn.AccessModeConnect,
+		AccessSchema:         conn.AccessSchema,
+		ReviewTTLSec:         conn.ReviewTTLSec,
+		RequireJustification: conn.RequireJustification,
+		TicketIDPattern:      conn.TicketIDPattern,
+		RequireRecentAuth:    conn.RequireRecentAuth,
+		DenyRules:            conn.DenyRules,
+		BreakGlassGroups:     conn.BreakGlassGroups,
 	})
 }
 
@@ -12,6 +12,7 @@ import (
 	apivalidation "github.com/hoophq/hoop/gateway/api/validation"
 	"github.com/hoophq/hoop/gateway/pgrest"
 	pgplugins "github.com/hoophq/hoop/gateway/pgrest/plugins"
+	pluginsaccesscontrol "github.com/hoophq/hoop/gateway/transport/plugins/accesscontrol"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 )
 
@@ -57,6 +58,9 @@ func setConnectionDefaults(req *openapi.Connection) {
 		defaultCommand = []string{
 			"sqlcmd", "--exit-on-error", "--trim-spaces", "-r",
 			"-S$HOST:$PORT", "-U$USER", "-d$DB", "-i/dev/stdin"}
+	case pb.ConnectionTypeRedis:
+		defaultEnvVars["envvar:PORT"] = base64.StdEncoding.EncodeToString([]byte(`6379`))
+		defaultCommand = []string{"redis-cli", "-h", "$HOST", "-p", "$PORT"}
 	case pb.ConnectionTypeMongoDB:
 		defaultEnvVars["envvar:OPTIONS"] = base64.StdEncoding.EncodeToString([]byte(`tls=true`))
 		defaultEnvVars["envvar:PORT"] = base64.StdEncoding.EncodeToString([]byte(`27017`))
@@ -104,6 +108,20 @@ func validateConnectionRequest(req openapi.Connection) error {
 			errors = append(errors, "tags: values must contain between 1 and 128 alphanumeric characters, it may include (-), (_) or (.) characters")
 		}
 	}
+	if req.ReviewTTLSec < 0 {
+		errors = append(errors, "review_ttl_sec: must be a positive number")
+	}
+	if req.RequireRecentAuth < 0 {
+		errors = append(errors, "require_recent_auth: must be a positive number")
+	}
+	if req.TicketIDPattern != "" {
+		if _, err := regexp.Compile(req.TicketIDPattern); err != nil {
+			errors = append(errors, fmt.Sprintf("ticket_id_pattern: it's not a valid regular expression, %v", err))
+		}
+	}
+	if _, err := pluginsaccesscontrol.ParseRules(req.DenyRules); err != nil {
+		errors = append(errors, fmt.Sprintf("deny_rules: %v", err))
+	}
 	if len(errors) > 0 {
 		return fmt.Errorf(strings.Join(errors, "; "))
 	}
@@ -0,0 +1,179 @@
+package apiconnections
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	pgconnections "github.com/hoophq/hoop/gateway/pgrest/connections"
+	pgreview "github.com/hoophq/hoop/gateway/pgrest/review"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+const maxReviewWorkflowStages = 10
+
+// GetReviewWorkflow
+//
+//	@Summary		Get Review Workflow
+//	@Description	Get the approval workflow of a connection
+//	@Tags			Core
+//	@Param			nameOrID	path	string	true	"Name or UUID of the connection"
+//	@Produce		json
+//	@Success		200		{object}	openapi.ReviewWorkflow
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/connections/{nameOrID}/review-workflow [get]
+func GetReviewWorkflow(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	conn, err := pgconnections.New().FetchOneByNameOrID(ctx, c.Param("nameOrID"))
+	if err != nil {
+		log.Errorf("failed fetching connection, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching connection"})
+		return
+	}
+	if conn == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "connection not found"})
+		return
+	}
+	wf, err := pgreview.New().FetchWorkflow(ctx, conn.ID)
+	if err != nil {
+		log.Errorf("failed fetching review workflow, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching review workflow"})
+		return
+	}
+	if wf == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "review workflow not found"})
+		return
+	}
+	c.JSON(http.StatusOK, toOpenApiReviewWorkflow(wf))
+}
+
+// UpdateReviewWorkflow
+//
+//	@Summary		Update Review Workflow
+//	@Description	Create or replace the approval workflow of a connection. The stages are reviewed in order
+//	@Description	and each stage requires a minimum number of approvals from any of its groups.
+//	@Description	Pending reviews keep the workflow they were created with.
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			nameOrID		path		string					true	"Name or UUID of the connection"
+//	@Param			request			body		openapi.ReviewWorkflow	true	"The request body resource"
+//	@Success		200				{object}	openapi.ReviewWorkflow
+//	@Failure		400,404,422,500	{object}	openapi.HTTPError
+//	@Router			/connections/{nameOrID}/review-workflow [put]
+func UpdateReviewWorkflow(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.ReviewWorkflow
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validateReviewWorkflow(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	conn, err := pgconnections.New().FetchOneByNameOrID(ctx, c.Param("nameOrID"))
+	if err != nil {
+		log.Errorf("failed fetching connection, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching connection"})
+		return
+	}
+	if conn == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "connection not found"})
+		return
+	}
+	wf := pgreview.Workflow{
+		ConnectionID:       conn.ID,
+		AllowSelfApproval:  req.AllowSelfApproval,
+		SeparationOfDuties: req.SeparationOfDuties,
+	}
+	for _, stage := range req.Stages {
+		wf.Stages = append(wf.Stages, types.ReviewStage{
+			Name:         stage.Name,
+			Groups:       stage.Groups,
+			MinApprovals: stage.MinApprovals,
+		})
+	}
+	if err := pgreview.New().UpsertWorkflow(ctx, wf); err != nil {
+		log.Errorf("failed saving review workflow, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed saving review workflow"})
+		return
+	}
+	c.JSON(http.StatusOK, toOpenApiReviewWorkflow(&wf))
+}
+
+// DeleteReviewWorkflow
+//
+//	@Summary		Delete Review Workflow
+//	@Description	Remove the approval workflow of a connection, new reviews require the approval of all groups of the connection.
+//	@Tags			Core
+//	@Param			nameOrID	path	string	true	"Name or UUID of the connection"
+//	@Success		204
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/connections/{nameOrID}/review-workflow [delete]
+func DeleteReviewWorkflow(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	conn, err := pgconnections.New().FetchOneByNameOrID(ctx, c.Param("nameOrID"))
+	if err != nil {
+		log.Errorf("failed fetching connection, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching connection"})
+		return
+	}
+	if conn == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "connection not found"})
+		return
+	}
+	if err := pgreview.New().DeleteWorkflow(ctx, conn.ID); err != nil {
+		log.Errorf("failed removing review workflow, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed removing review workflow"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func validateReviewWorkflow(req *openapi.ReviewWorkflow) error {
+	if len(req.Stages) == 0 || len(req.Stages) > maxReviewWorkflowStages {
+		return fmt.Errorf("the workflow must have between 1 and %v stages", maxReviewWorkflowStages)
+	}
+	for i, stage := range req.Stages {
+		if stage.Name == "" {
+			req.Stages[i].Name = fmt.Sprintf("stage-%v", i+1)
+		}
+		if len(stage.Groups) == 0 {
+			return fmt.Errorf("stage %q must have at least one group", req.Stages[i].Name)
+		}
+		if stage.MinApprovals < 0 {
+			return fmt.Errorf("stage %q must have a positive number of approvals", req.Stages[i].Name)
+		}
+		if stage.MinApprovals == 0 {
+			req.Stages[i].MinApprovals = 1
+		}
+	}
+	return nil
+}
+
+func toOpenApiReviewWorkflow(wf *pgreview.Workflow) openapi.ReviewWorkflow {
+	resp := openapi.ReviewWorkflow{
+		Stages:             []openapi.ReviewWorkflowStage{},
+		AllowSelfApproval:  wf.AllowSelfApproval,
+		SeparationOfDuties: wf.SeparationOfDuties,
+	}
+	for _, stage := range wf.Stages {
+		resp.Stages = append(resp.Stages, openapi.ReviewWorkflowStage{
+			Name:         stage.Name,
+			Groups:       stage.Groups,
+			MinApprovals: stage.MinApprovals,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,183 @@
+package apigroupgrants
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pggroupgrants "github.com/hoophq/hoop/gateway/pgrest/groupgrants"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/storagev2"
+)
+
+const (
+	maxGrantDuration = time.Hour * 24 * 30
+	maxGroupNameSize = 100
+)
+
+// CreateUserGroupGrant
+//
+//	@Summary		Create User Group Grant
+//	@Description	Grant a membership to a group for a period of time. The user stops belonging to the group after it expires,
+//	@Description	an active grant of the same group is replaced by the new one. Permanent memberships are managed by the update user endpoint.
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string							true	"The subject identifier of the user"
+//	@Param			request			body		openapi.UserGroupGrantRequest	true	"The request body resource"
+//	@Success		201				{object}	openapi.UserGroupGrant
+//	@Failure		400,404,409,422,500	{object}	openapi.HTTPError
+//	@Router			/users/{id}/group-grants [post]
+func Create(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.UserGroupGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	userID := c.Param("id")
+	user, err := pgusers.New().FetchOneBySubject(ctx, userID)
+	if err != nil {
+		log.Errorf("failed getting user %s, err=%v", userID, err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed getting user"})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "user not found"})
+		return
+	}
+	isMember, err := pggroupgrants.New().HasPermanentMembership(ctx, user.ID, req.Group)
+	if err != nil {
+		log.Errorf("failed fetching memberships of user %s, err=%v", userID, err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching memberships of user"})
+		return
+	}
+	if isMember {
+		c.JSON(http.StatusConflict, gin.H{"message": fmt.Sprintf("the user already belongs to the group %v", req.Group)})
+		return
+	}
+	expiresAt := time.Now().UTC().Add(time.Duration(req.DurationSec) * time.Second)
+	grant, err := pggroupgrants.New().Create(ctx, pgrest.UserGroupGrant{
+		UserID:    user.ID,
+		UserEmail: user.Email,
+		GroupName: req.Group,
+		Reason:    req.Reason,
+		GrantedBy: ctx.UserEmail,
+		ExpiresAt: expiresAt.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		log.Errorf("failed granting group %v to user %s, err=%v", req.Group, userID, err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed granting group to user"})
+		return
+	}
+	log.With("org", ctx.OrgID, "user", user.Email).Infof("granted group %v until %v by %v",
+		req.Group, expiresAt.Format(time.RFC3339), ctx.UserEmail)
+	c.JSON(http.StatusCreated, toOpenApi(grant))
+}
+
+// ListUserGroupGrants
+//
+//	@Summary		List User Group Grants
+//	@Description	List the history of the time-bound memberships granted to users
+//	@Tags			User Management
+//	@Produce		json
+//	@Param			email	query		string	false	"Filter by the email of the user"
+//	@Param			group	query		string	false	"Filter by the name of the group"
+//	@Param			status	query		string	false	"Filter by the status of the grant"	Enums(active,expired,revoked,replaced)
+//	@Success		200		{array}		openapi.UserGroupGrant
+//	@Failure		500		{object}	openapi.HTTPError
+//	@Router			/group-grants [get]
+func List(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	items, err := pggroupgrants.New().FetchAll(ctx, c.Query("email"), c.Query("group"), c.Query("status"))
+	if err != nil {
+		log.Errorf("failed listing group grants, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing group grants"})
+		return
+	}
+	grants := []openapi.UserGroupGrant{}
+	for _, g := range items {
+		grants = append(grants, toOpenApi(&g))
+	}
+	c.JSON(http.StatusOK, grants)
+}
+
+// RevokeUserGroupGrant
+//
+//	@Summary		Revoke User Group Grant
+//	@Description	Remove a time-bound membership before it expires
+//	@Tags			User Management
+//	@Param			id	path	string	true	"The identifier of the grant"
+//	@Success		204
+//	@Failure		404,409,500	{object}	openapi.HTTPError
+//	@Router			/group-grants/{id} [delete]
+func Revoke(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	grant, err := pggroupgrants.New().FetchOne(ctx, c.Param("id"))
+	if err != nil {
+		log.Errorf("failed fetching group grant, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching group grant"})
+		return
+	}
+	if grant == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "group grant not found"})
+		return
+	}
+	if grant.Status != pggroupgrants.StatusActive {
+		c.JSON(http.StatusConflict, gin.H{"message": fmt.Sprintf("the group grant is %v", grant.Status)})
+		return
+	}
+	switch err := pggroupgrants.New().Revoke(ctx, *grant, pggroupgrants.StatusRevoked, ctx.UserEmail); err {
+	case nil:
+	case pgrest.ErrNotFound:
+		c.JSON(http.StatusConflict, gin.H{"message": "the group grant is not active"})
+		return
+	default:
+		log.Errorf("failed revoking group grant %v, err=%v", grant.ID, err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed revoking group grant"})
+		return
+	}
+	log.With("org", ctx.OrgID, "user", grant.UserEmail).Infof("revoked group %v by %v", grant.GroupName, ctx.UserEmail)
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func validateRequest(req *openapi.UserGroupGrantRequest) error {
+	if len(req.Group) > maxGroupNameSize {
+		return fmt.Errorf("group must not contain more than %v characters", maxGroupNameSize)
+	}
+	duration := time.Duration(req.DurationSec) * time.Second
+	if duration <= 0 || duration > maxGrantDuration {
+		return fmt.Errorf("duration_sec must be between 1 and %v", int(maxGrantDuration.Seconds()))
+	}
+	return nil
+}
+
+func toOpenApi(g *pgrest.UserGroupGrant) openapi.UserGroupGrant {
+	return openapi.UserGroupGrant{
+		ID:        g.ID,
+		UserEmail: g.UserEmail,
+		Group:     g.GroupName,
+		Reason:    g.Reason,
+		Status:    g.Status,
+		GrantedBy: g.GrantedBy,
+		RevokedBy: g.RevokedBy,
+		ExpiresAt: g.GetExpiresAt(),
+		RevokedAt: g.GetRevokedAt(),
+		CreatedAt: g.GetCreatedAt(),
+	}
+}
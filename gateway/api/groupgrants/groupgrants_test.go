@@ -0,0 +1,29 @@
+package apigroupgrants
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hoophq/hoop/gateway/api/openapi"
+)
+
+func TestValidateRequest(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		req     openapi.UserGroupGrantRequest
+		wantErr bool
+	}{
+		{msg: "it must accept grants of 8 hours", req: openapi.UserGroupGrantRequest{Group: "dba", DurationSec: 28800}},
+		{msg: "it must accept grants of 30 days", req: openapi.UserGroupGrantRequest{Group: "dba", DurationSec: 2592000}},
+		{msg: "it must reject grants longer than 30 days", req: openapi.UserGroupGrantRequest{Group: "dba", DurationSec: 2592001}, wantErr: true},
+		{msg: "it must reject negative durations", req: openapi.UserGroupGrantRequest{Group: "dba", DurationSec: -1}, wantErr: true},
+		{msg: "it must reject long group names", req: openapi.UserGroupGrantRequest{Group: strings.Repeat("a", 101), DurationSec: 60}, wantErr: true},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := validateRequest(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error=%v, got=%v", tt.wantErr, err)
+			}
+		})
+	}
+}
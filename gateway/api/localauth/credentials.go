@@ -0,0 +1,92 @@
+package localauthapi
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	bcryptCost        = 12
+	minPasswordSize   = 12
+	maxPasswordSize   = 72 // bcrypt ignores bytes after this size
+	recoveryCodesSize = 10
+
+	// the account is locked after the failed attempts of passwords or mfa codes
+	maxFailedAttempts = 5
+	lockoutDuration   = 15 * time.Minute
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// dummyPasswordHash is compared with passwords of unknown users,
+// it keeps a similar response time of existing users
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("hoop-dummy-password"), bcryptCost)
+	return hash
+})
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordSize || len(password) > maxPasswordSize {
+		return fmt.Errorf("the password must contain between %v and %v characters", minPasswordSize, maxPasswordSize)
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	return string(hash), err
+}
+
+func comparePassword(creds *pgrest.UserCredentials, password string) bool {
+	if creds == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(creds.PasswordHash), []byte(password)) == nil
+}
+
+// newRecoveryCodes returns the recovery codes presented to the user and their hashes
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodesSize; i++ {
+		data := make([]byte, 7)
+		if _, err := rand.Read(data); err != nil {
+			return nil, nil, err
+		}
+		encoded := recoveryCodeEncoding.EncodeToString(data)
+		code := encoded[:5] + "-" + encoded[5:10]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func isLocked(creds *pgrest.UserCredentials, now time.Time) bool {
+	lockedUntil := creds.GetLockedUntil()
+	return lockedUntil != nil && now.Before(*lockedUntil)
+}
+
+// isLockedByFailure reports if the account was locked by the last failed attempt,
+// the attempts are restarted when the account is locked
+func isLockedByFailure(creds *pgrest.UserCredentials, now time.Time) bool {
+	return creds.FailedAttempts == 0 && isLocked(creds, now)
+}
+
+func resetFailures(creds *pgrest.UserCredentials) {
+	creds.FailedAttempts = 0
+	creds.LockedUntil = nil
+}
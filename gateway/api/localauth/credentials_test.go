@@ -0,0 +1,55 @@
+package localauthapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePassword(t *testing.T) {
+	assert.NotNil(t, validatePassword("short"))
+	assert.NotNil(t, validatePassword(strings.Repeat("a", maxPasswordSize+1)))
+	assert.Nil(t, validatePassword("correct-horse-battery-staple"))
+}
+
+func TestComparePassword(t *testing.T) {
+	hash, err := hashPassword("correct-horse-battery-staple")
+	assert.Nil(t, err)
+	creds := &pgrest.UserCredentials{PasswordHash: hash}
+	assert.True(t, comparePassword(creds, "correct-horse-battery-staple"))
+	assert.False(t, comparePassword(creds, "staple-battery-horse-correct"))
+	assert.False(t, comparePassword(nil, "correct-horse-battery-staple"), "it must reject users without credentials")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	assert.Nil(t, err)
+	assert.Len(t, codes, recoveryCodesSize)
+	assert.Len(t, hashes, recoveryCodesSize)
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, code)
+	}
+
+	assert.Equal(t, hashes[3], hashRecoveryCode(" "+strings.ToUpper(codes[3])+" "), "it must normalize the recovery codes")
+	assert.NotEqual(t, hashes[3], hashRecoveryCode("aaaaa-bbbbb"))
+}
+
+func TestLockout(t *testing.T) {
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lockoutDuration).Format("2006-01-02T15:04:05.999999")
+	creds := &pgrest.UserCredentials{LockedUntil: &lockedUntil}
+	assert.True(t, isLocked(creds, now))
+	assert.True(t, isLocked(creds, now.Add(lockoutDuration-time.Second)))
+	assert.False(t, isLocked(creds, now.Add(lockoutDuration+time.Second)), "it must unlock after the lockout duration")
+	assert.True(t, isLockedByFailure(creds, now))
+
+	creds.FailedAttempts = maxFailedAttempts - 1
+	assert.False(t, isLockedByFailure(creds, now), "it must report only the failure that locked the account")
+
+	resetFailures(creds)
+	assert.False(t, isLocked(creds, now))
+	assert.False(t, isLocked(&pgrest.UserCredentials{}, now))
+}
@@ -0,0 +1,508 @@
+package localauthapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgorgs "github.com/hoophq/hoop/gateway/pgrest/orgs"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/security/idp"
+	"github.com/hoophq/hoop/gateway/security/totp"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+const totpIssuer = "hoop"
+
+type handler struct {
+	idpProv *idp.Provider
+}
+
+func New(provider *idp.Provider) *handler { return &handler{idpProv: provider} }
+
+// Register
+//
+//	@Summary		Local Authentication | Register
+//	@Description	Creates the first user of the gateway with administrator privileges. It's only available with local authentication and when the gateway doesn't have any users, the other users are created by administrators.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.LocalAuthRegisterRequest	true	"The request body resource"
+//	@Success		201				{object}	openapi.User
+//	@Failure		400,403,404,500	{object}	openapi.HTTPError
+//	@Router			/localauth/register [post]
+func (h *handler) Register(c *gin.Context) {
+	if !h.idpProv.Local {
+		c.JSON(http.StatusNotFound, gin.H{"message": "local authentication is not enabled"})
+		return
+	}
+	var req openapi.LocalAuthRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid email"})
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	org, totalUsers, err := pgorgs.New().FetchOrgByName(proto.DefaultOrgName)
+	if err != nil || org == nil || totalUsers == -1 {
+		log.Errorf("failed fetching default organization, users=%v, err=%v", totalUsers, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching default organization"})
+		return
+	}
+	if totalUsers > 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "the gateway already has users, contact an administrator to create your account"})
+		return
+	}
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		log.Errorf("failed hashing password, err=%v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed hashing password"})
+		return
+	}
+	user := pgrest.User{
+		ID:       uuid.NewString(),
+		OrgID:    org.ID,
+		Subject:  req.Email,
+		Name:     req.Name,
+		Email:    req.Email,
+		Verified: true,
+		Status:   string(types.UserStatusActive),
+		Groups:   []string{types.GroupAdmin},
+	}
+	switch err := pgusers.New().RegisterFirstUser(user, passwordHash); err {
+	case nil:
+	case pgrest.ErrNotFound:
+		c.JSON(http.StatusForbidden, gin.H{"message": "the gateway already has users, contact an administrator to create your account"})
+		return
+	default:
+		log.Errorf("failed creating user, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed creating user"})
+		return
+	}
+	log.With("user", user.Email).Infof("registered the first user of the gateway with local authentication")
+	c.JSON(http.StatusCreated, openapi.User{
+		ID:       user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Status:   openapi.StatusActive,
+		Verified: user.Verified,
+		Role:     string(openapi.RoleAdminType),
+		Groups:   user.Groups,
+	})
+}
+
+// Login
+//
+//	@Summary		Local Authentication | Login
+//	@Description	Verifies the password of the user and returns a short lived token to complete the login with the second factor.
+//	@Description	Users without an authenticator app must enroll the returned secret, the enrollment is mandatory.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.LocalAuthLoginRequest	true	"The request body resource"
+//	@Success		200				{object}	openapi.LocalAuthLoginResponse
+//	@Failure		400,401,404,500	{object}	openapi.HTTPError
+//	@Router			/localauth/login [post]
+func (h *handler) Login(c *gin.Context) {
+	if !h.idpProv.Local {
+		c.JSON(http.StatusNotFound, gin.H{"message": "local authentication is not enabled"})
+		return
+	}
+	var req openapi.LocalAuthLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	ctx, err := defaultOrgContext()
+	if err != nil {
+		log.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching default organization"})
+		return
+	}
+	user, creds, err := fetchUserCredentials(ctx, func() (*pgrest.User, error) {
+		return pgusers.New().FetchOneByEmail(ctx, req.Email)
+	})
+	if err != nil {
+		log.Errorf("failed fetching user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user credentials"})
+		return
+	}
+	now := time.Now().UTC()
+	if creds != nil && isLocked(creds, now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "the account is locked due to failed login attempts, try again later"})
+		return
+	}
+	if !comparePassword(creds, req.Password) {
+		if creds != nil {
+			h.registerFailure(ctx, user, now)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid email or password"})
+		return
+	}
+	if user.Status != string(types.UserStatusActive) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "user is not active"})
+		return
+	}
+
+	var resp openapi.LocalAuthLoginResponse
+	if !creds.TOTPEnabled {
+		// a new secret is generated until the user completes the enrollment
+		creds.TOTPSecret, err = totp.GenerateSecret()
+		if err == nil {
+			err = pgusers.New().UpsertCredentials(creds)
+		}
+		if err != nil {
+			log.Errorf("failed generating totp secret, err=%v", err)
+			sentry.CaptureException(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating totp secret"})
+			return
+		}
+		resp.Enrollment = &openapi.LocalAuthTOTPEnrollment{
+			Secret: creds.TOTPSecret,
+			URI:    totp.URI(totpIssuer, user.Email, creds.TOTPSecret),
+		}
+	}
+	resp.MFAToken, err = h.idpProv.NewMFAToken(user.ID)
+	if err != nil {
+		log.Errorf("failed generating mfa token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating mfa token"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyMFA
+//
+//	@Summary		Local Authentication | Verify MFA
+//	@Description	Completes the login with the code of the authenticator app or a recovery code and returns the access token of the user.
+//	@Description	The recovery codes are returned once, when the user enrolls the authenticator app.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.LocalAuthMFARequest	true	"The request body resource"
+//	@Success		200				{object}	openapi.LocalAuthTokenResponse
+//	@Failure		400,401,404,500	{object}	openapi.HTTPError
+//	@Router			/localauth/mfa [post]
+func (h *handler) VerifyMFA(c *gin.Context) {
+	if !h.idpProv.Local {
+		c.JSON(http.StatusNotFound, gin.H{"message": "local authentication is not enabled"})
+		return
+	}
+	var req openapi.LocalAuthMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	userID, err := h.idpProv.VerifyMFAToken(req.MFAToken)
+	if err != nil {
+		log.Infof("failed verifying mfa token, reason=%v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired mfa token"})
+		return
+	}
+	ctx, err := defaultOrgContext()
+	if err != nil {
+		log.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching default organization"})
+		return
+	}
+	user, creds, err := fetchUserCredentials(ctx, func() (*pgrest.User, error) {
+		return pgusers.New().FetchOneByID(ctx, userID)
+	})
+	if err != nil {
+		log.Errorf("failed fetching user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user credentials"})
+		return
+	}
+	if creds == nil || user.Status != string(types.UserStatusActive) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired mfa token"})
+		return
+	}
+	now := time.Now().UTC()
+	if isLocked(creds, now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "the account is locked due to failed login attempts, try again later"})
+		return
+	}
+	// the codes are consumed atomically, concurrent requests can't use the same code twice
+	valid := false
+	switch {
+	case req.RecoveryCode != "" && creds.TOTPEnabled:
+		err = pgusers.New().UseRecoveryCode(ctx, creds.UserID, hashRecoveryCode(req.RecoveryCode))
+		valid = err == nil
+	case req.Code != "" && creds.TOTPSecret != "":
+		var step int64
+		if step, valid = totp.Validate(creds.TOTPSecret, req.Code, now, creds.TOTPLastStep); valid {
+			err = pgusers.New().UpdateTOTPLastStepIf(ctx, creds.UserID, step)
+			valid = err == nil
+			creds.TOTPLastStep = step
+		}
+	}
+	if err != nil && err != pgrest.ErrNotFound {
+		log.Errorf("failed updating user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating user credentials"})
+		return
+	}
+	if !valid {
+		h.registerFailure(ctx, user, now)
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid mfa code"})
+		return
+	}
+
+	var resp openapi.LocalAuthTokenResponse
+	if !creds.TOTPEnabled {
+		creds.TOTPEnabled = true
+		resp.RecoveryCodes, creds.RecoveryCodes, err = newRecoveryCodes()
+		if err != nil {
+			log.Errorf("failed generating recovery codes, err=%v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating recovery codes"})
+			return
+		}
+		resetFailures(creds)
+		err = pgusers.New().UpsertCredentials(creds)
+	} else {
+		err = pgusers.New().ResetLoginFailures(ctx, creds.UserID)
+	}
+	if err != nil {
+		log.Errorf("failed updating user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating user credentials"})
+		return
+	}
+	if !user.Verified {
+		user.Verified = true
+		if err := pgusers.New().Upsert(*user); err != nil {
+			log.Errorf("failed updating user %v, err=%v", user.Email, err)
+			sentry.CaptureException(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating user"})
+			return
+		}
+	}
+	resp.Token, err = h.idpProv.NewSessionToken(idp.ProviderUserInfo{
+		Subject:  user.Subject,
+		Email:    user.Email,
+		Profile:  user.Name,
+		AuthTime: now,
+	})
+	if err != nil {
+		log.Errorf("failed generating session token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating session token"})
+		return
+	}
+	log.With("user", user.Email, "enrolled", len(resp.RecoveryCodes) > 0).Infof("success login with local authentication")
+	c.JSON(http.StatusOK, resp)
+}
+
+// ChangePassword
+//
+//	@Summary		Local Authentication | Change Password
+//	@Description	Changes the password of the authenticated user
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body	openapi.LocalAuthPasswordRequest	true	"The request body resource"
+//	@Success		204
+//	@Failure		400,401,404,500	{object}	openapi.HTTPError
+//	@Router			/localauth/password [put]
+func (h *handler) ChangePassword(c *gin.Context) {
+	if !h.idpProv.Local {
+		c.JSON(http.StatusNotFound, gin.H{"message": "local authentication is not enabled"})
+		return
+	}
+	ctx := storagev2.ParseContext(c)
+	var req openapi.LocalAuthPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	user, creds, err := fetchUserCredentials(ctx, func() (*pgrest.User, error) {
+		return pgusers.New().FetchOneBySubject(ctx, ctx.UserID)
+	})
+	if err != nil {
+		log.Errorf("failed fetching user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user credentials"})
+		return
+	}
+	if creds == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "the user doesn't have a password"})
+		return
+	}
+	now := time.Now().UTC()
+	if isLocked(creds, now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "the account is locked due to failed login attempts, try again later"})
+		return
+	}
+	if !comparePassword(creds, req.CurrentPassword) {
+		h.registerFailure(ctx, user, now)
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid password"})
+		return
+	}
+	resetFailures(creds)
+	h.setPassword(c, creds, req.NewPassword)
+}
+
+// SetUserPassword
+//
+//	@Summary		Local Authentication | Set User Password
+//	@Description	Sets the password of a user and unlocks the account. The user must enroll the second factor in the next login if it's not enrolled yet.
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string						true	"The subject identifier of the user"
+//	@Param			request			body	openapi.UserPasswordRequest	true	"The request body resource"
+//	@Success		204
+//	@Failure		400,404,500	{object}	openapi.HTTPError
+//	@Router			/users/{id}/password [put]
+func (h *handler) SetUserPassword(c *gin.Context) {
+	if !h.idpProv.Local {
+		c.JSON(http.StatusNotFound, gin.H{"message": "local authentication is not enabled"})
+		return
+	}
+	ctx := storagev2.ParseContext(c)
+	var req openapi.UserPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	user, creds, err := fetchUserCredentials(ctx, func() (*pgrest.User, error) {
+		return pgusers.New().FetchOneBySubject(ctx, c.Param("id"))
+	})
+	if err != nil {
+		log.Errorf("failed fetching user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user credentials"})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "user not found"})
+		return
+	}
+	if creds == nil {
+		creds = &pgrest.UserCredentials{UserID: user.ID, OrgID: user.OrgID}
+	}
+	resetFailures(creds)
+	h.setPassword(c, creds, req.Password)
+}
+
+// ResetUserMFA
+//
+//	@Summary		Local Authentication | Reset User MFA
+//	@Description	Removes the authenticator app and the recovery codes of a user, the user must enroll a new authenticator app in the next login.
+//	@Tags			User Management
+//	@Produce		json
+//	@Param			id	path	string	true	"The subject identifier of the user"
+//	@Success		204
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/users/{id}/mfa [delete]
+func (h *handler) ResetUserMFA(c *gin.Context) {
+	if !h.idpProv.Local {
+		c.JSON(http.StatusNotFound, gin.H{"message": "local authentication is not enabled"})
+		return
+	}
+	ctx := storagev2.ParseContext(c)
+	_, creds, err := fetchUserCredentials(ctx, func() (*pgrest.User, error) {
+		return pgusers.New().FetchOneBySubject(ctx, c.Param("id"))
+	})
+	if err != nil {
+		log.Errorf("failed fetching user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user credentials"})
+		return
+	}
+	if creds == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "user not found or it doesn't have local credentials"})
+		return
+	}
+	creds.TOTPSecret = ""
+	creds.TOTPEnabled = false
+	creds.TOTPLastStep = 0
+	creds.RecoveryCodes = nil
+	resetFailures(creds)
+	if err := pgusers.New().UpsertCredentials(creds); err != nil {
+		log.Errorf("failed updating user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating user credentials"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) setPassword(c *gin.Context, creds *pgrest.UserCredentials, password string) {
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		log.Errorf("failed hashing password, err=%v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed hashing password"})
+		return
+	}
+	creds.PasswordHash = passwordHash
+	if err := pgusers.New().UpsertCredentials(creds); err != nil {
+		log.Errorf("failed updating user credentials, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating user credentials"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// registerFailure persists a failed attempt of the user, it's a best-effort operation.
+// The attempts are incremented atomically to count concurrent requests.
+func (h *handler) registerFailure(ctx pgrest.OrgContext, user *pgrest.User, now time.Time) {
+	creds, err := pgusers.New().RegisterLoginFailure(ctx, user.ID, maxFailedAttempts, lockoutDuration)
+	if err != nil {
+		log.With("user", user.Email).Errorf("failed registering failed login attempt, err=%v", err)
+		sentry.CaptureException(err)
+		return
+	}
+	if isLockedByFailure(creds, now) {
+		log.With("user", user.Email).Warnf("account locked until %v due to failed login attempts", *creds.LockedUntil)
+	}
+}
+
+// fetchUserCredentials returns the user and its credentials,
+// the credentials are nil if the user doesn't exist or doesn't have a password
+func fetchUserCredentials(ctx pgrest.OrgContext, fetchUser func() (*pgrest.User, error)) (*pgrest.User, *pgrest.UserCredentials, error) {
+	user, err := fetchUser()
+	if err != nil || user == nil {
+		return nil, nil, err
+	}
+	creds, err := pgusers.New().FetchCredentials(ctx, user.ID)
+	return user, creds, err
+}
+
+// defaultOrgContext returns the context of the organization, local authentication is only
+// available in single tenant mode
+func defaultOrgContext() (pgrest.OrgContext, error) {
+	org, _, err := pgorgs.New().FetchOrgByName(proto.DefaultOrgName)
+	if err != nil || org == nil {
+		return nil, fmt.Errorf("failed fetching default organization, err=%v", err)
+	}
+	return pgrest.NewOrgContext(org.ID), nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,6 +43,9 @@ func New(provider *idp.Provider) *handler { return &handler{idpProv: provider} }
 //	@Param			redirect		query		string	false	"The URL to redirect after the signin"	Format(string)
 //	@Param			screen_hint		query		string	false	"Auth0 specific parameter"				Format(string)
 //	@Param			prompt			query		string	false	"The prompt value (OIDC spec)"			Format(string)
+//	@Param			max_age			query		int		false	"The maximum age in seconds of the authentication (OIDC spec), zero forces a new authentication"	Format(int)
+//	@Param			provider		query		string	false	"The name of the identity provider to signin"	Format(string)
+//	@Param			email			query		string	false	"The email of the user, it selects the identity provider by the domain"	Format(string)
 //	@Success		200				{object}	openapi.Login
 //	@Failure		400,409,422,500	{object}	openapi.HTTPError
 //	@Router			/login [get]
@@ -50,12 +54,32 @@ func (h *handler) Login(c *gin.Context) {
 	if redirectURL == "" {
 		redirectURL = fmt.Sprintf("http://%s/callback", proto.ClientLoginCallbackAddress)
 	}
+	prov, err := h.idpProv.Lookup(c.Query("provider"), c.Query("email"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	// step-up authentication, the identity provider must authenticate the user again
+	// instead of relying on a previous session when the authentication is older than max_age
+	maxAge := c.Query("max_age")
+	if maxAge != "" {
+		if val, err := strconv.Atoi(maxAge); err != nil || val < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "max_age must be a positive number of seconds"})
+			return
+		}
+	}
+	// users sign in with their credentials at the local authentication endpoints
+	if prov.Local {
+		c.JSON(http.StatusOK, openapi.Login{AuthMethod: prov.AuthMethod()})
+		return
+	}
 	stateUID := uuid.NewString()
-	err := pglogin.New().Upsert(&types.Login{
+	err = pglogin.New().Upsert(&types.Login{
 		ID:       stateUID,
 		Redirect: redirectURL,
 		Outcome:  "",
 		SlackID:  "",
+		Provider: prov.Name,
 	})
 	if err != nil {
 		sentry.CaptureException(err)
@@ -63,15 +87,33 @@ func (h *handler) Login(c *gin.Context) {
 		return
 	}
 
+	if prov.SAML != nil {
+		forceAuthn := maxAge != "" || c.Query("prompt") == "login"
+		url, err := prov.SAML.AuthnRequestURL(samlRequestID(stateUID), stateUID, forceAuthn)
+		if err != nil {
+			log.Errorf("failed generating saml authentication request, err=%v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating login url"})
+			return
+		}
+		c.JSON(http.StatusOK, openapi.Login{URL: url, AuthMethod: prov.AuthMethod()})
+		return
+	}
+
 	var params = []oauth2.AuthCodeOption{}
-	if h.idpProv.Audience != "" {
-		params = append(params, oauth2.SetAuthURLParam("audience", h.idpProv.Audience))
+	if prov.Audience != "" {
+		params = append(params, oauth2.SetAuthURLParam("audience", prov.Audience))
+	}
+	if email := c.Query("email"); email != "" {
+		params = append(params, oauth2.SetAuthURLParam("login_hint", email))
+	}
+	if maxAge != "" {
+		params = append(params, oauth2.SetAuthURLParam("max_age", maxAge))
 	}
 	if auth0Params := h.parseAuth0QueryParams(c); len(auth0Params) > 0 {
 		params = append(params, auth0Params...)
 	}
-	url := h.idpProv.AuthCodeURL(stateUID, params...)
-	c.JSON(http.StatusOK, openapi.Login{URL: url})
+	url := prov.AuthCodeURL(stateUID, params...)
+	c.JSON(http.StatusOK, openapi.Login{URL: url, AuthMethod: prov.AuthMethod()})
 }
 
 // LoginCallback
@@ -115,36 +157,68 @@ func (h *handler) LoginCallback(c *gin.Context) {
 	// update the login state when this method returns
 	defer updateLoginState(login)
 	log.With("state", stateUUID).Debugf("login record found")
-	token, uinfo, err := h.verifyIDToken(code)
+	prov, err := h.idpProv.Lookup(login.Provider, "")
+	if err != nil || prov.AuthMethod() != idp.AuthMethodOIDC {
+		login.Outcome = fmt.Sprintf("failed obtaining the identity provider %q of the login", login.Provider)
+		log.Error(login.Outcome)
+		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
+		return
+	}
+	token, uinfo, err := verifyIDToken(prov, code)
 	if err != nil {
 		login.Outcome = fmt.Sprintf("failed verifying id token, reason=%v", err)
 		log.Error(login.Outcome)
 		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
 		return
 	}
-	subject, err := h.idpProv.VerifyAccessToken(token.AccessToken)
+	subject, err := prov.VerifyAccessToken(token.AccessToken)
 	if err != nil {
 		login.Outcome = fmt.Sprintf("failed verifiying access token, reason=%v", err)
 		log.Warn(login.Outcome)
 		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
 		return
 	}
+	// the domain of the user must be routed to the provider that authenticated it
+	if err := h.idpProv.VerifyEmailDomain(prov, uinfo.Email); err != nil {
+		login.Outcome = fmt.Sprintf("failed validating the email domain of %v, reason=%v", uinfo.Email, err)
+		log.Warn(login.Outcome)
+		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
+		return
+	}
 	uinfo.Subject = subject
-	ctx, err := pguserauth.New().FetchUserContext(subject)
+	accessToken := token.AccessToken
+	// the time of the authentication is only informed by the id token, the gateway issues
+	// a session token carrying it to allow opening sessions that require a recent authentication
+	if !uinfo.AuthTime.IsZero() && h.idpProv.HasSessionTokens() {
+		accessToken, err = h.idpProv.NewSessionToken(uinfo)
+		if err != nil {
+			login.Outcome = fmt.Sprintf("failed generating session token, reason=%v", err)
+			log.Error(login.Outcome)
+			sentry.CaptureException(err)
+			c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
+			return
+		}
+	}
+	c.Redirect(http.StatusTemporaryRedirect, h.signin(c, login, uinfo, accessToken))
+}
+
+// signin registers the user authenticated by the identity provider and returns
+// the url to redirect the user with the access token or with the error
+func (h *handler) signin(c *gin.Context, login *pgrest.Login, uinfo idp.ProviderUserInfo, accessToken string) string {
+	redirectErrorURL := login.Redirect + "?error=unexpected_error"
+	ctx, err := pguserauth.New().FetchUserContext(uinfo.Subject)
 	if err != nil {
 		login.Outcome = fmt.Sprintf("failed fetching user subject=%s, email=%s, reason=%v", uinfo.Subject, uinfo.Email, err)
 		log.Error(login.Outcome)
 		sentry.CaptureException(err)
-		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
-		return
+		return redirectErrorURL
 	}
-	redirectSuccessURL := login.Redirect + "?token=" + token.AccessToken
+	redirectSuccessURL := login.Redirect + "?token=" + accessToken
 
 	if !ctx.IsEmpty() && ctx.UserStatus != string(types.UserStatusActive) {
 		login.Outcome = fmt.Sprintf("user is not active subject=%s, email=%s", uinfo.Subject, uinfo.Email)
 		log.With("org", ctx.OrgID).Warn(login.Outcome)
-		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
-		return
+		return redirectErrorURL
 	}
 
 	userAgent := apiutils.NormalizeUserAgent(c.Request.Header.Values)
@@ -160,8 +234,7 @@ func (h *handler) LoginCallback(c *gin.Context) {
 				uinfo.Subject, uinfo.Email, err)
 			log.With("multitenant", true).Error(login.Outcome)
 			sentry.CaptureException(err)
-			c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
-			return
+			return redirectErrorURL
 		}
 		// the signup process is performed by /api/signup when the gateway is running multi tenant mode
 		// track as a login event
@@ -169,8 +242,7 @@ func (h *handler) LoginCallback(c *gin.Context) {
 			h.analyticsTrack(false, userAgent, ctx)
 		}
 		login.Outcome = "success"
-		c.Redirect(http.StatusTemporaryRedirect, redirectSuccessURL)
-		return
+		return redirectSuccessURL
 	}
 
 	if len(login.SlackID) > 0 {
@@ -183,15 +255,14 @@ func (h *handler) LoginCallback(c *gin.Context) {
 		if err != errUserInactive {
 			sentry.CaptureException(err)
 		}
-		c.Redirect(http.StatusTemporaryRedirect, redirectErrorURL)
-		return
+		return redirectErrorURL
 	}
 
 	h.analyticsTrack(isNewUser, userAgent, ctx)
 
 	// TODO: add analytics (identify / track)
 	login.Outcome = "success"
-	c.Redirect(http.StatusTemporaryRedirect, redirectSuccessURL)
+	return redirectSuccessURL
 }
 
 func registerMultiTenantUser(uinfo idp.ProviderUserInfo, slackID string) (isNewUser bool, err error) {
@@ -300,14 +371,14 @@ func syncSingleTenantUser(ctx *pguserauth.Context, uinfo idp.ProviderUserInfo) (
 	})
 }
 
-func (h *handler) verifyIDToken(code string) (token *oauth2.Token, uinfo idp.ProviderUserInfo, err error) {
-	log.Debugf("verifying access token")
-	token, err = h.idpProv.Exchange(h.idpProv.Context, code)
+func verifyIDToken(prov *idp.Provider, code string) (token *oauth2.Token, uinfo idp.ProviderUserInfo, err error) {
+	log.With("provider", prov.Name).Debugf("verifying access token")
+	token, err = prov.Exchange(prov.Context, code)
 	if err != nil {
 		return nil, uinfo, fmt.Errorf("failed exchange authorization code, reason=%v", err)
 	}
 
-	idToken, err := h.idpProv.VerifyIDToken(token)
+	idToken, err := prov.VerifyIDToken(token)
 	if err != nil {
 		return nil, uinfo, fmt.Errorf("failed veryfing oidc ID Token, reason=%v", err)
 	}
@@ -319,12 +390,12 @@ func (h *handler) verifyIDToken(code string) (token *oauth2.Token, uinfo idp.Pro
 		return nil, uinfo, fmt.Errorf("failed extracting id token claims, reason=%v", err)
 	}
 	debugClaims(idToken.Subject, idTokenClaims, token)
-	uinfo = idp.ParseIDTokenClaims(idTokenClaims, h.idpProv.GroupsClaim)
+	uinfo = idp.ParseIDTokenClaims(idTokenClaims, prov.GroupsClaim)
 	return
 }
 
 func updateLoginState(l *pgrest.Login) {
-	loginState := &types.Login{ID: l.ID, Redirect: l.Redirect, Outcome: l.Outcome, SlackID: l.SlackID}
+	loginState := &types.Login{ID: l.ID, Redirect: l.Redirect, Outcome: l.Outcome, SlackID: l.SlackID, Provider: l.Provider}
 	if err := pglogin.New().Upsert(loginState); err != nil {
 		log.Warnf("failed updating login state, reason=%v", err)
 	}
@@ -0,0 +1,112 @@
+package loginapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	pglogin "github.com/hoophq/hoop/gateway/pgrest/login"
+	"github.com/hoophq/hoop/gateway/security/idp"
+)
+
+// samlRequestID derives the id of the authentication request from the login state.
+// The id must start with a letter or an underscore (xsd:ID)
+func samlRequestID(stateUID string) string { return "_" + stateUID }
+
+// SAMLMetadata
+//
+//	@Summary		SAML Service Provider Metadata
+//	@Description	Returns the metadata of the gateway as a SAML 2.0 service provider. It must be registered in the identity provider.
+//	@Tags			Authentication
+//	@Produce		xml
+//	@Success		200
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/saml/metadata [get]
+func (h *handler) SAMLMetadata(c *gin.Context) {
+	if h.idpProv.SAML == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "saml is not configured"})
+		return
+	}
+	metadata, err := h.idpProv.SAML.Metadata()
+	if err != nil {
+		log.Errorf("failed generating saml metadata, reason=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating saml metadata"})
+		return
+	}
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// SAMLCallback
+//
+//	@Summary		SAML Assertion Consumer Service
+//	@Description	It receives the response of the identity provider (HTTP-POST binding), validates the signed assertion and redirects the user with a gateway session token.
+//	@Tags			Authentication
+//	@Accept			x-www-form-urlencoded
+//	@Param			SAMLResponse	formData	string	true	"The base64 encoded SAML response"
+//	@Param			RelayState		formData	string	true	"The state of the login"
+//	@Success		302
+//	@Failure		400,404,500	{object}	openapi.HTTPError
+//	@Router			/saml/acs [post]
+func (h *handler) SAMLCallback(c *gin.Context) {
+	if h.idpProv.SAML == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "saml is not configured"})
+		return
+	}
+	stateUUID := c.PostForm("RelayState")
+	log.With("state", stateUUID).Infof("starting saml callback")
+	login, err := pglogin.New().FetchOne(stateUUID)
+	if err != nil || login == nil {
+		log.With("state", stateUUID).
+			Warnf("login record is empty or returned with error, err=%v, isempty=%v", err, login == nil)
+		statusCode := http.StatusBadRequest
+		if err != nil {
+			sentry.CaptureException(err)
+			statusCode = http.StatusInternalServerError
+		}
+		c.JSON(statusCode, gin.H{"message": "failed to retrieve login state internally"})
+		return
+	}
+	// a login state could be used only once, it prevents replaying responses
+	if login.Outcome != "" {
+		log.With("state", stateUUID).Warnf("login state was already used, outcome=%v", login.Outcome)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "login state was already used"})
+		return
+	}
+	redirectErrorURL := login.Redirect + "?error=unexpected_error"
+
+	// update the login state when this method returns
+	defer updateLoginState(login)
+	if login.Provider != "" && login.Provider != h.idpProv.Name {
+		login.Outcome = fmt.Sprintf("login was started with the identity provider %q", login.Provider)
+		log.Warn(login.Outcome)
+		c.Redirect(http.StatusFound, redirectErrorURL)
+		return
+	}
+	assertion, err := h.idpProv.SAML.ParseResponse(c.PostForm("SAMLResponse"), samlRequestID(login.ID))
+	if err != nil {
+		login.Outcome = fmt.Sprintf("failed validating saml response, reason=%v", err)
+		log.Warn(login.Outcome)
+		c.Redirect(http.StatusFound, redirectErrorURL)
+		return
+	}
+	uinfo := idp.ParseSAMLAssertion(assertion, h.idpProv.GroupsClaim)
+	if uinfo.Subject == "" || uinfo.Email == "" {
+		login.Outcome = fmt.Sprintf("saml assertion is missing the subject or the email, subject=%v, email=%v",
+			uinfo.Subject, uinfo.Email)
+		log.Warn(login.Outcome)
+		c.Redirect(http.StatusFound, redirectErrorURL)
+		return
+	}
+	token, err := h.idpProv.NewSessionToken(uinfo)
+	if err != nil {
+		login.Outcome = fmt.Sprintf("failed generating session token, reason=%v", err)
+		log.Error(login.Outcome)
+		sentry.CaptureException(err)
+		c.Redirect(http.StatusFound, redirectErrorURL)
+		return
+	}
+	c.Redirect(http.StatusFound, h.signin(c, login, uinfo, token))
+}
@@ -20,6 +20,7 @@ import (
 	pguserauth "github.com/hoophq/hoop/gateway/pgrest/userauth"
 	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
 	"github.com/hoophq/hoop/gateway/security/idp"
+	"github.com/hoophq/hoop/gateway/security/usertoken"
 	"github.com/hoophq/hoop/gateway/storagev2"
 	"go.uber.org/zap"
 )
@@ -28,7 +29,7 @@ var errInvalidAuthHeaderErr = errors.New("invalid authorization header")
 
 func (a *Api) Authenticate(c *gin.Context) {
 	roleName := RoleFromContext(c)
-	subject, err := a.validateAccessToken(c)
+	subject, tokenScopes, err := a.validateAccessToken(c)
 	if err != nil {
 		tokenHeader := c.GetHeader("authorization")
 		log.Infof("failed authenticating, %v, length=%v, reason=%v",
@@ -104,6 +105,12 @@ func (a *Api) Authenticate(c *gin.Context) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
+	if tokenScopes != nil && !allowTokenScopes(c, tokenScopes) {
+		log.Infof("personal access token denied, scopes=%v, method=%v, path=%v",
+			tokenScopes, c.Request.Method, c.Request.URL.Path)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "the scopes of the token don't allow this operation"})
+		return
+	}
 	log.Debugf("user authenticated, role=%s, org=%s, subject=%s, isadmin=%v", roleName, ctx.OrgName, subject, ctx.IsAdmin())
 	c.Set(storagev2.ContextKey,
 		storagev2.NewContext(ctx.UserSubject, ctx.OrgID).
@@ -127,13 +134,34 @@ func parseToken(c *gin.Context) (string, error) {
 }
 
 // validateAccessToken validates the access token by the user info if it's an opaque token
-// or by parsing and validating the token if it's a JWT token
-func (a *Api) validateAccessToken(c *gin.Context) (string, error) {
+// or by parsing and validating the token if it's a JWT token.
+// The scopes are only returned when it's a personal access token.
+func (a *Api) validateAccessToken(c *gin.Context) (string, []string, error) {
 	accessToken, err := parseToken(c)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+	if usertoken.IsToken(accessToken) {
+		return usertoken.Verify(accessToken)
+	}
+	subject, err := a.IDProvider.VerifyAccessToken(accessToken)
+	return subject, nil, err
+}
+
+// allowTokenScopes reports if the scopes of a personal access token grant access to the route.
+// The connection of exec routes is validated when the session is opened by the gRPC gateway.
+func allowTokenScopes(c *gin.Context, scopes []string) bool {
+	scope, allowed := tokenScopeFromContext(c)
+	if !allowed {
+		return false
+	}
+	if scope == usertoken.ScopeExecPrefix {
+		if connectionName := c.Param("name"); connectionName != "" {
+			return usertoken.AllowExec(scopes, connectionName)
+		}
+		return usertoken.HasExecScope(scopes)
 	}
-	return a.IDProvider.VerifyAccessToken(accessToken)
+	return usertoken.HasScope(scopes, scope)
 }
 
 // validateTokenWithUserInfo validates the access token by the user info endpoint
@@ -11,6 +11,66 @@ type HTTPError struct {
 type Login struct {
 	// The URL to redirect the user to the identity provider
 	URL string `json:"login_url"`
+	// How the users are authenticated, the login url is empty with local authentication
+	AuthMethod string `json:"auth_method" enums:"oidc,saml,local" example:"oidc"`
+}
+
+type LocalAuthRegisterRequest struct {
+	// The email of the first user (administrator) of the gateway
+	Email string `json:"email" format:"email" binding:"required" example:"john.wick@bad.org"`
+	// The display name of the user
+	Name string `json:"name" example:"John Wick"`
+	// The password of the user, it must contain between 12 and 72 characters
+	Password string `json:"password" binding:"required" example:"correct-horse-battery-staple"`
+}
+
+type LocalAuthLoginRequest struct {
+	// The email of the user
+	Email string `json:"email" format:"email" binding:"required" example:"john.wick@bad.org"`
+	// The password of the user
+	Password string `json:"password" binding:"required" example:"correct-horse-battery-staple"`
+}
+
+type LocalAuthLoginResponse struct {
+	// A short lived token (5 minutes) to complete the login with the second factor
+	MFAToken string `json:"mfa_token" example:"x-mfa-eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// The secret to enroll in an authenticator app, it's only present when the user must enroll the second factor
+	Enrollment *LocalAuthTOTPEnrollment `json:"enrollment,omitempty"`
+}
+
+type LocalAuthTOTPEnrollment struct {
+	// The base32 encoded secret of the time-based one-time password
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// The key uri of the secret, it could be rendered as a QR code
+	URI string `json:"uri" example:"otpauth://totp/hoop:john.wick@bad.org?algorithm=SHA1&digits=6&issuer=hoop&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+type LocalAuthMFARequest struct {
+	// The token returned by the login endpoint
+	MFAToken string `json:"mfa_token" binding:"required" example:"x-mfa-eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// The code of the authenticator app
+	Code string `json:"code" example:"123456"`
+	// A recovery code, it could be used instead of the code of the authenticator app after the enrollment
+	RecoveryCode string `json:"recovery_code" example:"f3k2p-q8xnd"`
+}
+
+type LocalAuthTokenResponse struct {
+	// The access token of the user
+	Token string `json:"token" example:"x-session-eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// The recovery codes of the user, they are only returned once when the user enrolls the second factor
+	RecoveryCodes []string `json:"recovery_codes,omitempty" example:"f3k2p-q8xnd,7bhm4-vcw2e"`
+}
+
+type LocalAuthPasswordRequest struct {
+	// The current password of the user
+	CurrentPassword string `json:"current_password" binding:"required" example:"correct-horse-battery-staple"`
+	// The new password of the user, it must contain between 12 and 72 characters
+	NewPassword string `json:"new_password" binding:"required" example:"staple-battery-horse-correct"`
+}
+
+type UserPasswordRequest struct {
+	// The new password of the user, it must contain between 12 and 72 characters
+	Password string `json:"password" binding:"required" example:"correct-horse-battery-staple"`
 }
 
 type SignupRequest struct {
@@ -188,6 +248,7 @@ type Connection struct {
 	// * mysql - Implements MySQL protocol
 	// * mongodb - Implements MongoDB Wire Protocol
 	// * mssql - Implements Microsoft SQL Server Protocol
+	// * redis - Implements Redis Serialization Protocol (RESP)
 	// * tcp - Forwards a TCP connection
 	SubType string `json:"subtype" example:"postgres"`
 	// Secrets are environment variables that are going to be exposed
@@ -198,6 +259,8 @@ type Connection struct {
 	// The value could also represent an integration with a external provider:
 	// * { envvar:[env-key]: _aws:[secret-name]:[secret-key] } - Obtain the value dynamically in the AWS secrets manager and expose as environment variable
 	// * { envvar:[env-key]: _envjson:[json-env-name]:[json-env-key] } - Obtain the value dynamically from a JSON env in the agent runtime. Example: MYENV={"KEY": "val"}
+	// * { envvar:[env-key]: _vaultkv1:[secret-path]:[secret-key] } - Obtain the value dynamically from Hashicorp Vault (kv version 1 or dynamic secrets)
+	// * { envvar:[env-key]: _vaultkv2:[secret-path]:[secret-key] } - Obtain the value dynamically from Hashicorp Vault (kv version 2)
 	Secrets map[string]any `json:"secret"`
 	// The agent associated with this connection
 	AgentId string `json:"agent_id" binding:"required" format:"uuid" example:"1837453e-01fc-46f3-9e4c-dcf22d395393"`
@@ -233,6 +296,22 @@ type Connection struct {
 	// * enabled - Enable the instrospection schema in the webapp
 	// * disabled - Disable the instrospection schema in the webapp
 	AccessSchema string `json:"access_schema" binding:"required" enums:"enabled,disabled"`
+	// The amount of time (in seconds) that a review could stay pending before expiring.
+	// A zero value means that reviews of this connection never expire.
+	ReviewTTLSec int `json:"review_ttl_sec" example:"3600"`
+	// Require users to provide a justification and a ticket id when opening sessions
+	RequireJustification bool `json:"require_justification" example:"true"`
+	// A regular expression to validate the ticket id provided when opening sessions, it must match the whole ticket id
+	TicketIDPattern string `json:"ticket_id_pattern" example:"JIRA-[0-9]+"`
+	// Require users to have authenticated in the identity provider within the last minutes
+	// to open sessions (step-up authentication). A zero value disables the requirement.
+	RequireRecentAuth int `json:"require_recent_auth" example:"15"`
+	// Regular expressions (case insensitive) of statements denied when the access_control plugin is enabled.
+	// Only database connections are supported, the rules are evaluated in the queries and prepared statements.
+	DenyRules []string `json:"deny_rules" example:"drop\\s+table"`
+	// The groups allowed to bypass the review (break-glass) and have the access reviewed retrospectively.
+	// Break-glass access is disabled when it's empty.
+	BreakGlassGroups []string `json:"break_glass_groups" example:"sre"`
 }
 
 type ExecRequest struct {
@@ -246,6 +325,10 @@ type ExecRequest struct {
 	Metadata map[string]any `json:"metadata"`
 	// Additional arguments that will be joined when construction the command to be executed
 	ClientArgs []string `json:"client_args" example:"hello world"`
+	// The reason to access the connection, it's required when the connection has `require_justification` enabled
+	Justification string `json:"justification" example:"investigate the failed payments of the incident INC-123"`
+	// The reference of the ticket to access the connection, it's required when the connection has `require_justification` enabled
+	TicketID string `json:"ticket_id" example:"JIRA-123"`
 }
 
 type ExecResponse struct {
@@ -269,6 +352,9 @@ type ExecResponse struct {
 	// * -2 - internal gateway code that means it was unable to obtain a valid exit code number from the agent outcome packet
 	// * 254 - internal agent code that means it was unable to obtain a valid exit code number from the process
 	ExitCode int `json:"exit_code" example:"1"`
+	// The connection requires the user to authenticate again to open sessions (step-up authentication).
+	// The client must perform a new login forcing the authentication in the identity provider.
+	StepUpAuthRequired bool `json:"step_up_auth_required" example:"false"`
 }
 
 type RunbookRequest struct {
@@ -282,6 +368,10 @@ type RunbookRequest struct {
 	ClientArgs []string `json:"client_args" example:"--verbose"`
 	// Metadata attributes to add in the session
 	Metadata map[string]any `json:"metadata"`
+	// The reason to access the connection, it's required when the connection has `require_justification` enabled
+	Justification string `json:"justification" example:"investigate the failed payments of the incident INC-123"`
+	// The reference of the ticket to access the connection, it's required when the connection has `require_justification` enabled
+	TicketID string `json:"ticket_id" example:"JIRA-123"`
 }
 
 type RunbookList struct {
@@ -346,6 +436,11 @@ type SessionGetByIDParams struct {
 	// * `json` - it will parse the content as a json stream.
 	// * `<any-format>` - No special parsing is applied
 	Extension string `json:"extension" example:"csv"`
+	// Export the session in a structured format, the extension defaults to the format when it's empty.
+	// * `jsonl` - one json object per event with its time, type, payload and metadata of the session
+	// * `csv` - the output of exec sessions parsed as csv rows (tab separated columns)
+	// * `asciicast` - the asciinema file format (v2)
+	Format string `json:"format" enums:"jsonl,csv,asciicast" example:"jsonl"`
 	// Choose the type of events to include
 	// * `i` - Input (stdin)
 	// * `o` - Output (stdout)
@@ -359,6 +454,74 @@ type SessionGetByIDParams struct {
 	EventStream string `json:"event_stream" enums:"utf8" default:""`
 }
 
+type SessionReplayParams struct {
+	// The format of the replay
+	// * `json` - the header and the events of the session as a json object
+	// * `asciicast` - the asciinema file format (version 2), one json per line
+	Format string `json:"format" enums:"json,asciicast" default:"json" example:"asciicast"`
+	// Choose the type of events to include, output (o) and error (e) events are replayed as output
+	// * `i` - Input (stdin)
+	// * `o` - Output (stdout)
+	// * `e` - Error (stderr)
+	Events []string `json:"events" example:"i,o,e"`
+	// The width of the terminal
+	Width int `json:"width" default:"80" example:"120"`
+	// The height of the terminal
+	Height int `json:"height" default:"24" example:"40"`
+}
+
+type SessionReplay struct {
+	// The resource unique identifier of the session
+	ID string `json:"id" format:"uuid" example:"1CBC8DB5-FBF8-4293-8E35-59A6EEA40207"`
+	// The version of the asciicast format
+	Version int `json:"version" example:"2"`
+	// The width of the terminal
+	Width int `json:"width" example:"80"`
+	// The height of the terminal
+	Height int `json:"height" example:"24"`
+	// The duration in seconds of the session
+	Duration float64 `json:"duration" example:"12.5"`
+	// When the session started
+	StartSession time.Time `json:"start_date" example:"2024-07-25T15:56:35.317601Z"`
+	// The events of the session in the following format
+	//
+	// `[[0.268589438, "o", "total 0\r\n"], ...]`
+	//
+	// * `<event-time>` - relative time in seconds to start_date
+	// * `<event-type>` - the event type as string (i: input, o: output)
+	// * `<content>` - the content of the event as utf-8 string
+	Events []SessionEventStream `json:"events"`
+}
+
+type LiveSession struct {
+	// The resource unique identifier of the session
+	ID string `json:"id" format:"uuid" example:"1CBC8DB5-FBF8-4293-8E35-59A6EEA40207"`
+	// The email of the user that opened the session
+	UserEmail string `json:"user" example:"john.wick@bad.org"`
+	// The unique identifier of the user
+	UserID string `json:"user_id" example:"john.wick@bad.org"`
+	// The display name of the user
+	UserName string `json:"user_name" example:"John Wick"`
+	// The name of the connection
+	ConnectionName string `json:"connection" example:"pgdemo"`
+	// The type of the connection
+	ConnectionType string `json:"connection_type" example:"database"`
+	// The verb of the session
+	// * `connect` - an interactive session or a port forward (hoop connect)
+	// * `exec` - a one-off execution
+	Verb string `json:"verb" enums:"connect,exec" example:"connect"`
+	// The origin of the client
+	Origin string `json:"origin" example:"client"`
+	// The hostname of the client
+	Hostname string `json:"hostname" example:"johnwick-laptop"`
+	// When the session started
+	StartedAt time.Time `json:"started_at" example:"2024-07-25T15:56:35.317601Z"`
+	// The amount of bytes sent from the client to the agent
+	BytesSent int64 `json:"bytes_sent" example:"2048"`
+	// The amount of bytes received by the client from the agent
+	BytesReceived int64 `json:"bytes_received" example:"10240"`
+}
+
 type SessionOption struct {
 	OptionKey SessionOptionKey
 	OptionVal any
@@ -449,6 +612,8 @@ type SessionReportParams struct {
 	StartDate string `json:"start_date" format:"date" example:"2024-07-29"`
 	// End Date, default to current date + 1 day
 	EndDate string `json:"end_date" format:"date" example:"2024-07-30"`
+	// Filter the report by sessions opened bypassing the review (break-glass)
+	BreakGlass string `json:"break_glass" enums:"true,false" default:"" example:"true"`
 }
 
 type SessionReport struct {
@@ -457,6 +622,8 @@ type SessionReport struct {
 	TotalRedactCount int64 `json:"total_redact_count" example:"12"`
 	// The sum of `items[].transformed_bytes`
 	TotalTransformedBytes int64 `json:"total_transformed_bytes" example:"40293"`
+	// The total of sessions opened bypassing the review (break-glass) in the date range
+	BreakGlassTotal int64 `json:"break_glass_total" example:"1"`
 }
 
 type SessionReportItem struct {
@@ -482,6 +649,7 @@ const (
 	ReviewStatusRevoked    ReviewStatusType = "REVOKED"
 	ReviewStatusProcessing ReviewStatusType = "PROCESSING"
 	ReviewStatusExecuted   ReviewStatusType = "EXECUTED"
+	ReviewStatusExpired    ReviewStatusType = "EXPIRED"
 	ReviewStatusUnknown    ReviewStatusType = "UNKNOWN"
 
 	ReviewStatusRequestApprovedType ReviewRequestStatusType = ReviewRequestStatusType(ReviewStatusApproved)
@@ -494,7 +662,11 @@ type ReviewRequest struct {
 	// * APPROVED - Approve the review resource
 	// * REJECTED - Reject the review resource
 	// * REVOKED - Revoke an approved review
-	Status ReviewRequestStatusType `json:"status" binding:"required" example:"APPROVED"`
+	Status ReviewRequestStatusType `json:"status" example:"APPROVED"`
+	// The time to execute an approved one time review (RFC3339), it's only allowed when approving
+	// or when the status is omitted. An empty value removes the schedule.
+	// The owner, the reviewers and admins are able to change it, after the approval only the reviewers and admins.
+	ScheduledAt string `json:"scheduled_at" example:"2024-07-25T02:00:00Z"`
 }
 
 type Review struct {
@@ -525,6 +697,7 @@ type Review struct {
 	// * REVOKED - The resource was revoked after being approved
 	// * PROCESSING - The review is being executed
 	// * EXECUTED - The review was executed
+	// * EXPIRED - The review was not fully approved before its expiration time
 	// * UNKNOWN - Unable to know the status of the review
 	Status ReviewStatusType `json:"status"`
 	// The time when this review was revoked
@@ -535,6 +708,22 @@ type Review struct {
 	Connection ReviewConnection `json:"review_connection" readonly:"true"`
 	// Contains the groups that requires to approve this review
 	ReviewGroupsData []ReviewGroup `json:"review_groups_data" readonly:"true"`
+	// The approval workflow of the review and the state of each stage.
+	// It's null when the connection doesn't have a workflow, in this case all groups must approve the review.
+	Workflow *ReviewWorkflow `json:"workflow" readonly:"true"`
+	// The time when this review expires if it's still pending.
+	// It's null when the connection doesn't have a review ttl (`review_ttl_sec`)
+	ExpiresAt *time.Time `json:"expires_at" readonly:"true" example:""`
+	// The time when this review will be executed by the gateway after being approved.
+	// It's only available for one time reviews
+	ScheduledAt *time.Time `json:"scheduled_at" readonly:"true" example:""`
+	// The justification of the user to access the connection without waiting for the approval (break-glass).
+	// When it's set the session was opened when the review was created and the review is performed retrospectively
+	BreakGlassJustification string `json:"break_glass_justification" readonly:"true" example:""`
+	// The reason provided by the user to access the connection
+	Justification string `json:"justification" readonly:"true" example:"investigate the failed payments of the incident INC-123"`
+	// The reference of the ticket provided by the user to access the connection
+	TicketID string `json:"ticket_id" readonly:"true" example:"JIRA-123"`
 }
 
 type ReviewOwner struct {
@@ -571,19 +760,56 @@ type ReviewGroup struct {
 	ReviewDate *string `json:"review_date" readonly:"true" example:"2024-07-25T19:36:41Z"`
 }
 
+type ReviewWorkflow struct {
+	// The stages of the workflow, a stage could only be reviewed after the previous one is approved
+	Stages []ReviewWorkflowStage `json:"stages" binding:"required"`
+	// Allow the owner of the review to approve it
+	AllowSelfApproval bool `json:"allow_self_approval" default:"false" example:"false"`
+	// Prevent the same user approving more than one stage of a review
+	SeparationOfDuties bool `json:"separation_of_duties" default:"false" example:"true"`
+}
+
+type ReviewWorkflowStage struct {
+	// The name of the stage
+	Name string `json:"name" example:"team-lead"`
+	// The groups allowed to review this stage
+	Groups []string `json:"groups" example:"leads,sre"`
+	// The minimum number of approvals from any of the groups to approve the stage
+	MinApprovals int `json:"min_approvals" default:"1" example:"2"`
+	// The status of the stage, it's only available when it's part of a review
+	// * PENDING - The stage is waiting to be reviewed
+	// * APPROVED - The stage reached the minimum number of approvals
+	// * REJECTED - The stage was rejected
+	Status ReviewStatusType `json:"status,omitempty" readonly:"true" example:"PENDING"`
+	// The reviews performed in this stage
+	Approvals []ReviewApproval `json:"approvals,omitempty" readonly:"true"`
+}
+
+type ReviewApproval struct {
+	// The group of the reviewer used to review the stage
+	Group string `json:"group" readonly:"true" example:"sre"`
+	// The status of the review
+	Status ReviewRequestStatusType `json:"status" readonly:"true" example:"APPROVED"`
+	// The reviewer
+	ReviewedBy ReviewOwner `json:"reviewed_by" readonly:"true"`
+	// The date which this review was performed
+	ReviewDate string `json:"review_date" readonly:"true" example:"2024-07-25T19:36:41Z"`
+}
+
 type Plugin struct {
 	// The resource identifier
 	ID string `json:"id" format:"uuid" readonly:"true" example:"15B5A2FD-0706-4A47-B1CF-B93CCFC5B3D7"`
 	// The name of the plugin to enable
 	// * audit - Audit connections
-	// * access_control - Enable access control by groups
+	// * access_control - Enable access control by groups and deny statements matching the rules of connections
+	// * database-credentials-manager - Create temporary database users for each session (postgres and mysql)
 	// * dlp - Enable Google Data Loss Prevention (requires further configuration)
 	// * indexer - Enable indexing session contents
 	// * review - Enable reviewing executions
 	// * runbooks - Enable configuring runbooks
 	// * slack - Enable reviewing execution through Slack
 	// * webhooks - Send events via webhooks
-	Name string `json:"name" binding:"required" enums:"audit,access_control,dlp,indexer,review,runbooks,slack,webhooks" example:"slack"`
+	Name string `json:"name" binding:"required" enums:"audit,access_control,access_rules,database-credentials-manager,dlp,indexer,review,runbooks,slack,ticket_validation,webhooks" example:"slack"`
 	// The list of connections configured for a specific plugin
 	Connections []*PluginConnection `json:"connections" binding:"required"`
 	// The top level plugin configuration. This value is immutable after creation
@@ -763,3 +989,289 @@ type ServerInfo struct {
 type LivenessCheck struct {
 	Liveness string `json:"liveness" enums:"ERR,OK" example:"OK"`
 }
+
+type RetentionPolicy struct {
+	// The unique identifier of this resource
+	ID string `json:"id" readonly:"true" format:"uuid" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// Unique name of the policy
+	Name string `json:"name" binding:"required" example:"prod-databases"`
+	// Apply the policy to sessions of this connection type, empty matches any type
+	ConnectionType string `json:"connection_type" example:"database"`
+	// Apply the policy to sessions with this verb, empty matches any verb
+	Verb string `json:"verb" enums:"connect,exec," example:"exec"`
+	// Apply the policy to sessions of connections having this tag, empty matches any connection
+	ConnectionTag string `json:"connection_tag" example:"prod"`
+	// The amount of days to keep the content of sessions.
+	// When more than one policy matches a session, the longest retention is used.
+	RetentionDays int `json:"retention_days" binding:"required" example:"90"`
+	// The time the resource was created
+	CreatedAt time.Time `json:"created_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+	// The time the resource was updated
+	UpdatedAt time.Time `json:"updated_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+}
+
+type ReviewAutoApprovalRule struct {
+	// The unique identifier of this resource
+	ID string `json:"id" readonly:"true" format:"uuid" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// Unique name of the rule
+	Name string `json:"name" binding:"required" example:"read-only-queries"`
+	// Apply the rule to reviews of these connections, empty matches any connection
+	Connections []string `json:"connections" example:"pgdemo"`
+	// Approve one time reviews of database connections (postgres, mysql and mssql)
+	// when all the statements of the input are read only and only call functions without side effects
+	ReadOnly bool `json:"read_only" example:"true"`
+	// Approve one time reviews of database connections when the whole input matches this regular expression
+	QueryPattern string `json:"query_pattern" example:"(?i)SELECT .+ FROM customers WHERE id = [0-9]+;?"`
+	// Approve reviews created by users that belong to any of these groups
+	Groups []string `json:"groups" example:"sre"`
+	// Approve reviews created within these hours
+	BusinessHours *BusinessHours `json:"business_hours"`
+	// The time the resource was created
+	CreatedAt time.Time `json:"created_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+	// The time the resource was updated
+	UpdatedAt time.Time `json:"updated_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+}
+
+type UserGroupGrantRequest struct {
+	// The name of the group granted to the user
+	Group string `json:"group" binding:"required" example:"dba"`
+	// The amount of time in seconds the user belongs to the group (max 30 days)
+	DurationSec int `json:"duration_sec" binding:"required" example:"28800"`
+	// The reason of the grant
+	Reason string `json:"reason" example:"investigate the replication lag of INC-1234"`
+}
+
+type UserGroupGrant struct {
+	// The unique identifier of this resource
+	ID string `json:"id" readonly:"true" format:"uuid" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// The email of the user
+	UserEmail string `json:"user_email" example:"alice@domain.tld"`
+	// The name of the group granted to the user
+	Group string `json:"group" example:"dba"`
+	// The reason of the grant
+	Reason string `json:"reason" example:"investigate the replication lag of INC-1234"`
+	// The status of the grant
+	// * active - the user belongs to the group
+	// * expired - the membership was removed after it expired
+	// * revoked - the membership was removed before it expired
+	// * replaced - a new grant of the same group replaced this one
+	Status string `json:"status" enums:"active,expired,revoked,replaced" example:"active"`
+	// The email of the user that granted the membership
+	GrantedBy string `json:"granted_by" example:"admin@domain.tld"`
+	// The email of the user that revoked the membership, system when it's expired by the gateway
+	RevokedBy string `json:"revoked_by" example:""`
+	// The time the membership expires
+	ExpiresAt time.Time `json:"expires_at" example:"2024-07-25T23:56:35.317601Z"`
+	// The time the membership was removed
+	RevokedAt *time.Time `json:"revoked_at" example:"2024-07-25T23:56:35.317601Z"`
+	// The time the resource was created
+	CreatedAt time.Time `json:"created_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+}
+
+type AccessRule struct {
+	// The unique identifier of this resource
+	ID string `json:"id" readonly:"true" format:"uuid" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// Unique name of the rule
+	Name string `json:"name" binding:"required" example:"contractors-staging-only"`
+	// Description of the rule
+	Description string `json:"description" example:"contractors may only exec on staging connections during weekdays"`
+	// A matching deny rule refuses the access, when there are allow rules at least one of them must match
+	Effect string `json:"effect" binding:"required" enums:"allow,deny" example:"deny"`
+	// The boolean expression evaluated over the attributes of the user, connection and client
+	Expression string `json:"expression" binding:"required" example:"user.groups.contains(\"contractors\") && !(client.verb == \"exec\" && connection.tags.contains(\"staging\") && now.weekday >= 1 && now.weekday <= 5)"`
+	// Apply the rule to these connections, empty matches any connection
+	Connections []string `json:"connections" example:"pgdemo"`
+	// The IANA timezone used by the now.* attributes, defaults to UTC
+	Timezone string `json:"timezone" example:"America/Sao_Paulo"`
+	// The time the resource was created
+	CreatedAt time.Time `json:"created_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+	// The time the resource was updated
+	UpdatedAt time.Time `json:"updated_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+}
+
+type BusinessHours struct {
+	// The IANA timezone of the hours, defaults to UTC
+	Timezone string `json:"timezone" example:"America/Sao_Paulo"`
+	// The days of the week (0 is sunday), empty matches any day
+	Weekdays []int `json:"weekdays" example:"1,2,3,4,5"`
+	// The start hour in the format HH:MM
+	Start string `json:"start" binding:"required" example:"09:00"`
+	// The end hour in the format HH:MM, an end before the start crosses midnight
+	End string `json:"end" binding:"required" example:"18:00"`
+}
+
+type RetentionReport struct {
+	// The time the report was generated
+	GeneratedAt time.Time `json:"generated_at" example:"2024-07-25T15:56:35.317601Z"`
+	// The total of sessions that will be purged
+	Total int `json:"total" example:"1"`
+	// The sessions that will be purged
+	Items []RetentionReportItem `json:"items"`
+}
+
+type RetentionReportItem struct {
+	// The id of the session
+	SessionID string `json:"session_id" format:"uuid" example:"5701046A-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// The name of the connection
+	Connection string `json:"connection" example:"pgdemo"`
+	// The type of the connection
+	ConnectionType string `json:"connection_type" example:"database"`
+	// The verb of the session
+	Verb string `json:"verb" example:"exec"`
+	// The name of the policy that expired the session
+	Policy string `json:"policy" example:"prod-databases"`
+	// The time the session was created
+	CreatedAt time.Time `json:"created_at" example:"2024-07-25T15:56:35.317601Z"`
+	// The time the session expired based on the policy
+	ExpiredAt time.Time `json:"expired_at" example:"2024-10-23T15:56:35.317601Z"`
+}
+
+type SCIMTokenResponse struct {
+	// The bearer token used by the identity provider to provision users and groups, it's only returned once
+	Token string `json:"token" readonly:"true" example:"xscim-CdRsVsUXPmGdNHr2A7A4kaOY1JsjBlGNbbZFQnZvXQ0"`
+	// The base url of the SCIM 2.0 endpoints
+	BaseURL string `json:"base_url" readonly:"true" example:"https://use.hoop.dev/api/scim/v2"`
+}
+
+type SCIMName struct {
+	// The full name of the user
+	Formatted string `json:"formatted,omitempty" example:"John Wick"`
+	// The given name of the user
+	GivenName string `json:"givenName,omitempty" example:"John"`
+	// The family name of the user
+	FamilyName string `json:"familyName,omitempty" example:"Wick"`
+}
+
+type SCIMEmail struct {
+	// The email address
+	Value string `json:"value" example:"john.wick@bad.org"`
+	// The type of the email
+	Type string `json:"type,omitempty" example:"work"`
+	// If it's the primary email of the user
+	Primary bool `json:"primary" example:"true"`
+}
+
+type SCIMReference struct {
+	// The identifier of the referenced resource
+	Value string `json:"value" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// The display name of the referenced resource
+	Display string `json:"display,omitempty" example:"sre"`
+	// The uri of the referenced resource
+	Ref string `json:"$ref,omitempty" example:"https://use.hoop.dev/api/scim/v2/Groups/D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+}
+
+type SCIMMeta struct {
+	// The name of the resource type
+	ResourceType string `json:"resourceType" example:"User"`
+	// The time the resource was created
+	Created *time.Time `json:"created,omitempty" example:"2024-07-25T15:56:35.317601Z"`
+	// The time the resource was updated
+	LastModified *time.Time `json:"lastModified,omitempty" example:"2024-07-25T15:56:35.317601Z"`
+	// The uri of the resource
+	Location string `json:"location" example:"https://use.hoop.dev/api/scim/v2/Users/D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+}
+
+type SCIMUser struct {
+	// The schemas of the resource
+	Schemas []string `json:"schemas" example:"urn:ietf:params:scim:schemas:core:2.0:User"`
+	// The unique identifier of the user
+	ID string `json:"id" readonly:"true" format:"uuid" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// The email of the user, it's immutable
+	UserName string `json:"userName" example:"john.wick@bad.org"`
+	// The name of the user
+	Name *SCIMName `json:"name,omitempty"`
+	// The display name of the user, it has precedence over name.formatted
+	DisplayName string `json:"displayName,omitempty" example:"John Wick"`
+	// Inactive users are not able to access the system, defaults to true
+	Active *bool `json:"active,omitempty" example:"true"`
+	// The emails of the user
+	Emails []SCIMEmail `json:"emails,omitempty"`
+	// The groups provisioned with SCIM that the user belongs to
+	Groups []SCIMReference `json:"groups,omitempty" readonly:"true"`
+	// The metadata of the resource
+	Meta *SCIMMeta `json:"meta,omitempty" readonly:"true"`
+}
+
+type SCIMGroup struct {
+	// The schemas of the resource
+	Schemas []string `json:"schemas" example:"urn:ietf:params:scim:schemas:core:2.0:Group"`
+	// The unique identifier of the group
+	ID string `json:"id" readonly:"true" format:"uuid" example:"D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"`
+	// The name of the group
+	DisplayName string `json:"displayName" example:"sre"`
+	// The users that belong to the group
+	Members []SCIMReference `json:"members"`
+	// The metadata of the resource
+	Meta *SCIMMeta `json:"meta,omitempty" readonly:"true"`
+}
+
+type SCIMListResponse struct {
+	// The schemas of the resource
+	Schemas []string `json:"schemas" example:"urn:ietf:params:scim:api:messages:2.0:ListResponse"`
+	// The total number of results matching the filter
+	TotalResults int `json:"totalResults" example:"1"`
+	// The 1-based index of the first result
+	StartIndex int `json:"startIndex" example:"1"`
+	// The number of resources returned in this page
+	ItemsPerPage int `json:"itemsPerPage" example:"1"`
+	// The users or groups of the page
+	Resources []any `json:"Resources"`
+}
+
+type SCIMPatchRequest struct {
+	// The schemas of the request
+	Schemas []string `json:"schemas" example:"urn:ietf:params:scim:api:messages:2.0:PatchOp"`
+	// The operations to apply in order
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+type SCIMPatchOperation struct {
+	// The operation to apply
+	Op string `json:"op" enums:"add,replace,remove" example:"replace"`
+	// The attribute path of the operation
+	Path string `json:"path,omitempty" example:"active"`
+	// The value of the operation
+	Value any `json:"value,omitempty" swaggertype:"object"`
+}
+
+type SCIMError struct {
+	// The schemas of the error
+	Schemas []string `json:"schemas" example:"urn:ietf:params:scim:api:messages:2.0:Error"`
+	// The http status code
+	Status string `json:"status" example:"404"`
+	// The SCIM error type
+	ScimType string `json:"scimType,omitempty" example:"uniqueness"`
+	// The error description
+	Detail string `json:"detail" example:"the error description"`
+}
+
+type UserTokenRequest struct {
+	// The name of the token, it must be unique among the tokens of the user
+	Name string `json:"name" binding:"required" example:"ci-pipeline"`
+	// The scopes of the token
+	// * sessions:read - Read the sessions of the user
+	// * exec:<connection> - Execute and connect to the connection, use exec:* to allow any connection
+	// * admin - All the operations allowed to the user, it could only be granted by admin users
+	Scopes []string `json:"scopes" binding:"required" example:"sessions:read,exec:pgdemo"`
+	// The number of days the token is valid, between 1 and 365
+	ExpiresInDays int `json:"expires_in_days" example:"90"`
+}
+
+type UserToken struct {
+	// The unique identifier of this resource
+	ID string `json:"id" readonly:"true" format:"uuid" example:"2E8B1D38-9A0E-4FEE-A1F1-5A8F4B4D9C1F"`
+	// The name of the token
+	Name string `json:"name" example:"ci-pipeline"`
+	// The scopes of the token
+	Scopes []string `json:"scopes" example:"sessions:read,exec:pgdemo"`
+	// The token is only returned when it's created
+	Token string `json:"token,omitempty" readonly:"true" example:"xpat-5nUOJjzCdHPkNGFsZ3ObJ5A1Tv63bRBUwMZd2l0mE2o"`
+	// The expiration date of the token, it's empty when the token doesn't expire
+	ExpiresAt *time.Time `json:"expires_at" readonly:"true" example:"2024-10-23T15:56:35.317601Z"`
+	// The last time the token was used, it's updated at most once per minute
+	LastUsedAt *time.Time `json:"last_used_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+	// The date the token was revoked
+	RevokedAt *time.Time `json:"revoked_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+	// The date the token was created
+	CreatedAt time.Time `json:"created_at" readonly:"true" example:"2024-07-25T15:56:35.317601Z"`
+}
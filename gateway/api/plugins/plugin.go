@@ -15,6 +15,8 @@ import (
 	pgplugins "github.com/hoophq/hoop/gateway/pgrest/plugins"
 	"github.com/hoophq/hoop/gateway/storagev2"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
+	pluginsaccesscontrol "github.com/hoophq/hoop/gateway/transport/plugins/accesscontrol"
+	pluginsdbcredentials "github.com/hoophq/hoop/gateway/transport/plugins/dbcredentials"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 )
 
@@ -315,6 +317,20 @@ func parsePluginConnections(c *gin.Context, req PluginRequest) ([]*types.PluginC
 		if req.Name == plugintypes.PluginDLPName && len(connConfig) == 0 {
 			connConfig = proto.DefaultInfoTypes
 		}
+		if req.Name == plugintypes.PluginAccessControlName {
+			if err := pluginsaccesscontrol.ValidateGroups(connConfig); err != nil {
+				msg := fmt.Sprintf("connection %q has invalid configuration, reason=%v", conn.Name, err)
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"message": msg})
+				return nil, nil, io.EOF
+			}
+		}
+		if req.Name == plugintypes.PluginDatabaseCredentialsManagerName {
+			if err := pluginsdbcredentials.ValidateGrants(connConfig); err != nil {
+				msg := fmt.Sprintf("connection %q has invalid configuration, reason=%v", conn.Name, err)
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"message": msg})
+				return nil, nil, io.EOF
+			}
+		}
 		// create deterministic uuid to allow plugin connection entities
 		// to be updated instead of generating new ones
 		docUUID := uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("%s:%s", req.Name, conn.Id)))
@@ -31,7 +31,8 @@ func SessionReport(c *gin.Context) {
 	}
 	report, err := pgreports.GetSessionReport(ctx, opts...)
 	switch err {
-	case pgreports.ErrInvalidDateFormat, pgreports.ErrInvalidDateRange, pgreports.ErrInvalidGroupByValue:
+	case pgreports.ErrInvalidDateFormat, pgreports.ErrInvalidDateRange, pgreports.ErrInvalidGroupByValue,
+		pgreports.ErrInvalidBreakGlassValue:
 		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	case nil:
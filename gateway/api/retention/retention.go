@@ -0,0 +1,230 @@
+package apiretention
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	jobretention "github.com/hoophq/hoop/gateway/jobs/retention"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgretention "github.com/hoophq/hoop/gateway/pgrest/retention"
+	"github.com/hoophq/hoop/gateway/storagev2"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ListRetentionPolicies
+//
+//	@Summary		List Retention Policies
+//	@Description	List the retention policies of the organization
+//	@Tags			Core
+//	@Produce		json
+//	@Success		200	{array}		openapi.RetentionPolicy
+//	@Failure		500	{object}	openapi.HTTPError
+//	@Router			/retention-policies [get]
+func List(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	items, err := pgretention.New().FetchAll(ctx)
+	if err != nil {
+		log.Errorf("failed listing retention policies, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing retention policies"})
+		return
+	}
+	policies := []openapi.RetentionPolicy{}
+	for _, p := range items {
+		policies = append(policies, toOpenApi(&p))
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// CreateRetentionPolicy
+//
+//	@Summary		Create Retention Policy
+//	@Description	Create a retention policy. The content of the sessions matching the policy (input, event stream, reviews and index)
+//	@Description	is purged once the retention days have passed.
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.RetentionPolicy	true	"The request body resource"
+//	@Success		201				{object}	openapi.RetentionPolicy
+//	@Failure		400,409,422,500	{object}	openapi.HTTPError
+//	@Router			/retention-policies [post]
+func Create(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.RetentionPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := validatePolicy(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	existing, err := pgretention.New().FetchOne(ctx, req.Name)
+	if err != nil {
+		log.Errorf("failed fetching retention policy, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching retention policy"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"message": "retention policy already exists"})
+		return
+	}
+	obj, err := pgretention.New().Create(ctx, toPgrest(&req))
+	if err != nil {
+		log.Errorf("failed creating retention policy, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed creating retention policy"})
+		return
+	}
+	c.JSON(http.StatusCreated, toOpenApi(obj))
+}
+
+// UpdateRetentionPolicy
+//
+//	@Summary		Update Retention Policy
+//	@Description	Update a retention policy
+//	@Tags			Core
+//	@Accept			json
+//	@Produce		json
+//	@Param			name			path		string					true	"The name of the policy"
+//	@Param			request			body		openapi.RetentionPolicy	true	"The request body resource"
+//	@Success		200				{object}	openapi.RetentionPolicy
+//	@Failure		400,404,422,500	{object}	openapi.HTTPError
+//	@Router			/retention-policies/{name} [put]
+func Update(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.RetentionPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	// the name is immutable
+	req.Name = c.Param("name")
+	if err := validatePolicy(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	existing, err := pgretention.New().FetchOne(ctx, req.Name)
+	if err != nil {
+		log.Errorf("failed fetching retention policy, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching retention policy"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "retention policy not found"})
+		return
+	}
+	if err := pgretention.New().Update(ctx, toPgrest(&req)); err != nil {
+		log.Errorf("failed updating retention policy, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed updating retention policy"})
+		return
+	}
+	obj, err := pgretention.New().FetchOne(ctx, req.Name)
+	if err != nil || obj == nil {
+		log.Errorf("failed fetching retention policy, found=%v, err=%v", obj != nil, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching retention policy"})
+		return
+	}
+	c.JSON(http.StatusOK, toOpenApi(obj))
+}
+
+// DeleteRetentionPolicy
+//
+//	@Summary		Delete Retention Policy
+//	@Description	Delete a retention policy
+//	@Tags			Core
+//	@Param			name	path	string	true	"The name of the policy"
+//	@Success		204
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/retention-policies/{name} [delete]
+func Delete(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	existing, err := pgretention.New().FetchOne(ctx, c.Param("name"))
+	if err != nil {
+		log.Errorf("failed fetching retention policy, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching retention policy"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "retention policy not found"})
+		return
+	}
+	if err := pgretention.New().Delete(ctx, existing.Name); err != nil {
+		log.Errorf("failed removing retention policy, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed removing retention policy"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// RetentionReport
+//
+//	@Summary		Retention Dry-Run Report
+//	@Description	List the sessions that will be purged in the next run of the retention job based on the current policies.
+//	@Description	No data is removed by this endpoint.
+//	@Tags			Core
+//	@Produce		json
+//	@Success		200	{object}	openapi.RetentionReport
+//	@Failure		500	{object}	openapi.HTTPError
+//	@Router			/retention-policies/report [get]
+func Report(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	report, err := jobretention.Report(ctx)
+	if err != nil {
+		log.Errorf("failed generating retention report, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating retention report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func validatePolicy(req *openapi.RetentionPolicy) error {
+	if !namePattern.MatchString(req.Name) {
+		return fmt.Errorf("name must contain only alphanumeric characters, dashes or underscores")
+	}
+	if req.RetentionDays <= 0 {
+		return fmt.Errorf("retention_days must be greater than zero")
+	}
+	switch req.Verb {
+	case "", pb.ClientVerbConnect, pb.ClientVerbExec:
+	default:
+		return fmt.Errorf("verb must be one of: %v, %v", pb.ClientVerbConnect, pb.ClientVerbExec)
+	}
+	return nil
+}
+
+func toPgrest(p *openapi.RetentionPolicy) pgrest.RetentionPolicy {
+	return pgrest.RetentionPolicy{
+		Name:           p.Name,
+		ConnectionType: p.ConnectionType,
+		Verb:           p.Verb,
+		ConnectionTag:  p.ConnectionTag,
+		RetentionDays:  p.RetentionDays,
+	}
+}
+
+func toOpenApi(p *pgrest.RetentionPolicy) openapi.RetentionPolicy {
+	return openapi.RetentionPolicy{
+		ID:             p.ID,
+		Name:           p.Name,
+		ConnectionType: p.ConnectionType,
+		Verb:           p.Verb,
+		ConnectionTag:  p.ConnectionTag,
+		RetentionDays:  p.RetentionDays,
+		CreatedAt:      p.GetCreatedAt(),
+		UpdatedAt:      p.GetUpdatedAt(),
+	}
+}
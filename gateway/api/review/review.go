@@ -60,13 +60,13 @@ func (h *handler) List(c *gin.Context) { h.legacy.FindAll(c) }
 // UpdateReview
 //
 //	@Summary		Update Review Status
-//	@Description	Update the status of a review resource
+//	@Description	Update the status of a review resource and the time to execute it (`scheduled_at`)
 //	@Tags			Core
 //	@Param			id	path	string	true	"Resource identifier of the review"
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		openapi.ReviewRequest	true	"The request body resource"
 //	@Success		200		{object}	openapi.Review
-//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Failure		400,404,500	{object}	openapi.HTTPError
 //	@Router			/reviews/{id} [put]
 func (h *handler) Put(c *gin.Context) { h.legacy.Put(c) }
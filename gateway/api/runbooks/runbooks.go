@@ -204,6 +204,9 @@ func RunExec(c *gin.Context) {
 		BearerToken:    getAccessToken(c),
 		UserAgent:      userAgent,
 		Origin:         proto.ConnectionOriginClientAPIRunbooks,
+		Justification:  req.Justification,
+		TicketID:       req.TicketID,
+		ClientIP:       c.ClientIP(),
 	})
 	if err != nil {
 		log.Error(err)
@@ -0,0 +1,132 @@
+package apiscim
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Filter is a subset of the SCIM filter expressions (RFC 7644, section 3.4.2.2),
+// it supports the comparison operators eq, ne, co, sw, ew and pr joined by and.
+// The comparisons are case insensitive.
+//
+// Example: userName eq "john.wick@bad.org" and active eq true
+type Filter []condition
+
+type condition struct {
+	attr  string
+	op    string
+	value string
+}
+
+var filterOperators = []string{"eq", "ne", "co", "sw", "ew", "pr"}
+
+// ParseFilter parses the filter expression validating that the
+// attributes are one of the supported ones (case insensitive)
+func ParseFilter(expr string, attributes ...string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	var filter Filter
+	for len(tokens) > 0 {
+		if len(filter) > 0 {
+			if !strings.EqualFold(tokens[0], "and") {
+				return nil, fmt.Errorf("unsupported logical operator %q, only 'and' is supported", tokens[0])
+			}
+			tokens = tokens[1:]
+		}
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("invalid filter expression")
+		}
+		cond := condition{attr: strings.ToLower(tokens[0]), op: strings.ToLower(tokens[1])}
+		if !slices.ContainsFunc(attributes, func(a string) bool { return strings.EqualFold(a, cond.attr) }) {
+			return nil, fmt.Errorf("unsupported filter attribute %q", tokens[0])
+		}
+		if !slices.Contains(filterOperators, cond.op) {
+			return nil, fmt.Errorf("unsupported filter operator %q", tokens[1])
+		}
+		tokens = tokens[2:]
+		if cond.op != "pr" {
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("missing value for the filter attribute %q", cond.attr)
+			}
+			cond.value = strings.ToLower(tokens[0])
+			tokens = tokens[1:]
+		}
+		filter = append(filter, cond)
+	}
+	return filter, nil
+}
+
+// Match reports if the resource matches all the conditions of the filter,
+// the attrValues function returns the values of an attribute (lower case) of the resource
+func (f Filter) Match(attrValues func(attr string) []string) bool {
+	for _, cond := range f {
+		values := attrValues(cond.attr)
+		if !slices.ContainsFunc(values, cond.match) {
+			// the ne operator matches resources without the attribute
+			if cond.op == "ne" && len(values) == 0 {
+				continue
+			}
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) match(v string) bool {
+	v = strings.ToLower(v)
+	switch c.op {
+	case "eq":
+		return v == c.value
+	case "ne":
+		return v != c.value
+	case "co":
+		return strings.Contains(v, c.value)
+	case "sw":
+		return strings.HasPrefix(v, c.value)
+	case "ew":
+		return strings.HasSuffix(v, c.value)
+	case "pr":
+		return v != ""
+	}
+	return false
+}
+
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case ' ', '\t':
+			i++
+		case '(', ')', '[', ']':
+			return nil, fmt.Errorf("grouping filter expressions are not supported")
+		case '"':
+			end := i + 1
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				if expr[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string in filter expression")
+			}
+			value, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string in filter expression: %v", err)
+			}
+			tokens = append(tokens, value)
+			i = end + 1
+		default:
+			end := strings.IndexAny(expr[i:], " \t")
+			if end == -1 {
+				end = len(expr) - i
+			}
+			tokens = append(tokens, expr[i:i+end])
+			i += end
+		}
+	}
+	return tokens, nil
+}
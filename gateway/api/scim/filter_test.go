@@ -0,0 +1,81 @@
+package apiscim
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		expr    string
+		want    Filter
+		wantErr bool
+	}{
+		{
+			msg:  "it must parse equality expressions",
+			expr: `userName eq "john.wick@bad.org"`,
+			want: Filter{{attr: "username", op: "eq", value: "john.wick@bad.org"}},
+		},
+		{
+			msg:  "it must parse expressions joined by and",
+			expr: `emails.value co "@bad.org" AND active eq true and displayName pr`,
+			want: Filter{
+				{attr: "emails.value", op: "co", value: "@bad.org"},
+				{attr: "active", op: "eq", value: "true"},
+				{attr: "displayname", op: "pr"},
+			},
+		},
+		{
+			msg:  "it must parse escaped quotes",
+			expr: `displayName eq "the \"sre\" team"`,
+			want: Filter{{attr: "displayname", op: "eq", value: `the "sre" team`}},
+		},
+		{msg: "it must reject unknown attributes", expr: `title eq "sre"`, wantErr: true},
+		{msg: "it must reject unknown operators", expr: `userName gt "a"`, wantErr: true},
+		{msg: "it must reject the or operator", expr: `userName eq "a" or userName eq "b"`, wantErr: true},
+		{msg: "it must reject grouping", expr: `emails[type eq "work"]`, wantErr: true},
+		{msg: "it must reject missing values", expr: `userName eq`, wantErr: true},
+		{msg: "it must reject unterminated strings", expr: `userName eq "john`, wantErr: true},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			got, err := ParseFilter(tt.expr, "userName", "emails.value", "displayName", "active")
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	attrs := map[string][]string{
+		"username":     {"John.Wick@bad.org"},
+		"emails.value": {"John.Wick@bad.org"},
+		"active":       {"true"},
+	}
+	attrValues := func(attr string) []string { return attrs[attr] }
+	for _, tt := range []struct {
+		expr string
+		want bool
+	}{
+		{expr: `userName eq "john.wick@bad.org"`, want: true},
+		{expr: `userName eq "john@bad.org"`, want: false},
+		{expr: `userName ne "john@bad.org"`, want: true},
+		{expr: `emails.value ew "@BAD.ORG" and active eq true`, want: true},
+		{expr: `emails.value sw "john" and active eq false`, want: false},
+		{expr: `userName co "wick"`, want: true},
+		{expr: `displayName pr`, want: false},
+		{expr: `displayName ne "john"`, want: true},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr, "userName", "emails.value", "displayName", "active")
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, filter.Match(attrValues), strings.ToLower(tt.expr))
+		})
+	}
+}
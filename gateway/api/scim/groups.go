@@ -0,0 +1,489 @@
+package apiscim
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgscim "github.com/hoophq/hoop/gateway/pgrest/scim"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/storagev2"
+)
+
+const maxGroupNameSize = 100
+
+var (
+	groupFilterAttributes = []string{"id", "displayName"}
+	// members[value eq "<id>"]
+	memberPathRe = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]+)"\s*\]$`)
+)
+
+// ListGroups
+//
+//	@Summary		SCIM List Groups
+//	@Description	List the groups provisioned with SCIM. The filter parameter supports the attributes
+//	@Description	`id` and `displayName` with the operators `eq`, `ne`, `co`, `sw`, `ew` and `pr` joined by `and`.
+//	@Tags			User Management
+//	@Produce		json
+//	@Param			filter				query		string	false	"The filter expression"	example(displayName eq "sre")
+//	@Param			startIndex			query		int		false	"The 1-based index of the first result"
+//	@Param			count				query		int		false	"The maximum number of results per page"
+//	@Param			excludedAttributes	query		string	false	"Use members to omit the members of the groups"
+//	@Success		200					{object}	openapi.SCIMListResponse{Resources=[]openapi.SCIMGroup}
+//	@Failure		400,401,500			{object}	openapi.SCIMError
+//	@Router			/scim/v2/Groups [get]
+func ListGroups(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var filter Filter
+	if expr := c.Query("filter"); expr != "" {
+		var err error
+		if filter, err = ParseFilter(expr, groupFilterAttributes...); err != nil {
+			abortWithError(c, newError(http.StatusBadRequest, "invalidFilter", err.Error()))
+			return
+		}
+	}
+	groups, err := pgscim.New().FetchAllGroups(ctx)
+	if err != nil {
+		writeError(c, "failed fetching groups", err)
+		return
+	}
+	users, err := fetchGroupUsers(c, ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	items := []openapi.SCIMGroup{}
+	for _, g := range groups {
+		if filter.Match(groupAttributes(&g)) {
+			items = append(items, toSCIMGroup(ctx, &g, users))
+		}
+	}
+	startIndex, count := parsePagination(c)
+	writeJSON(c, http.StatusOK, newListResponse(items, startIndex, count))
+}
+
+// GetGroup
+//
+//	@Summary		SCIM Get Group
+//	@Tags			User Management
+//	@Produce		json
+//	@Param			id					path		string	true	"The id of the group"
+//	@Param			excludedAttributes	query		string	false	"Use members to omit the members of the group"
+//	@Success		200					{object}	openapi.SCIMGroup
+//	@Failure		401,404,500			{object}	openapi.SCIMError
+//	@Router			/scim/v2/Groups/{id} [get]
+func GetGroup(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	group, err := fetchGroup(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching group", err)
+		return
+	}
+	users, err := fetchGroupUsers(c, ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	writeJSON(c, http.StatusOK, toSCIMGroup(ctx, group, users))
+}
+
+// CreateGroup
+//
+//	@Summary		SCIM Create Group
+//	@Description	Provision a group in the organization. The members are managed using the groups of the users,
+//	@Description	a group with the same name of an existing group of the users takes ownership of its members.
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.SCIMGroup	true	"The request body resource"
+//	@Success		201				{object}	openapi.SCIMGroup
+//	@Failure		400,401,409,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Groups [post]
+func CreateGroup(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.SCIMGroup
+	if err := bindJSON(c, &req); err != nil {
+		writeError(c, "failed decoding request", err)
+		return
+	}
+	if err := validateGroupName(req.DisplayName); err != nil {
+		writeError(c, "invalid group name", err)
+		return
+	}
+	existingGroup, err := pgscim.New().FetchOneGroupByName(ctx, req.DisplayName)
+	if err != nil {
+		writeError(c, "failed fetching existing group", err)
+		return
+	}
+	if existingGroup != nil {
+		abortWithError(c, newError(http.StatusConflict, "uniqueness", "group already exists with displayName %v", req.DisplayName))
+		return
+	}
+	users, err := pgusers.New().FetchAll(ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	members, err := memberIDs(users, req.Members)
+	if err != nil {
+		writeError(c, "invalid members", err)
+		return
+	}
+	group, err := pgscim.New().CreateGroup(ctx, req.DisplayName)
+	if err != nil {
+		writeError(c, "failed creating group", err)
+		return
+	}
+	if users, err = syncGroupMembers(users, "", group.DisplayName, members); err != nil {
+		writeError(c, "failed updating group members", err)
+		return
+	}
+	log.With("org", ctx.OrgName).Infof("scim: group %v provisioned, members=%v", group.DisplayName, len(members))
+	writeJSON(c, http.StatusCreated, toSCIMGroup(ctx, group, users))
+}
+
+// ReplaceGroup
+//
+//	@Summary		SCIM Replace Group
+//	@Description	Replace the name and the members of a group
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		string				true	"The id of the group"
+//	@Param			request				body		openapi.SCIMGroup	true	"The request body resource"
+//	@Success		200					{object}	openapi.SCIMGroup
+//	@Failure		400,401,404,409,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Groups/{id} [put]
+func ReplaceGroup(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.SCIMGroup
+	if err := bindJSON(c, &req); err != nil {
+		writeError(c, "failed decoding request", err)
+		return
+	}
+	group, err := fetchGroup(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching group", err)
+		return
+	}
+	users, err := pgusers.New().FetchAll(ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	members, err := memberIDs(users, req.Members)
+	if err != nil {
+		writeError(c, "invalid members", err)
+		return
+	}
+	updateGroup(c, ctx, group, req.DisplayName, users, members)
+}
+
+// PatchGroup
+//
+//	@Summary		SCIM Patch Group
+//	@Description	Update the name or add, remove and replace the members of a group.
+//	@Description	The paths `displayName`, `members` and `members[value eq "<id>"]` are supported.
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		string						true	"The id of the group"
+//	@Param			request				body		openapi.SCIMPatchRequest	true	"The request body resource"
+//	@Success		200					{object}	openapi.SCIMGroup
+//	@Failure		400,401,404,409,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Groups/{id} [patch]
+func PatchGroup(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.SCIMPatchRequest
+	if err := bindJSON(c, &req); err != nil {
+		writeError(c, "failed decoding request", err)
+		return
+	}
+	group, err := fetchGroup(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching group", err)
+		return
+	}
+	users, err := pgusers.New().FetchAll(ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	displayName := group.DisplayName
+	members, err := applyGroupPatch(&displayName, currentMembers(users, group.DisplayName), users, req.Operations)
+	if err != nil {
+		writeError(c, "failed applying patch operations", err)
+		return
+	}
+	updateGroup(c, ctx, group, displayName, users, members)
+}
+
+// DeleteGroup
+//
+//	@Summary		SCIM Delete Group
+//	@Description	Remove the group from its members and deprovision it from the organization
+//	@Tags			User Management
+//	@Param			id	path	string	true	"The id of the group"
+//	@Success		204
+//	@Failure		401,404,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Groups/{id} [delete]
+func DeleteGroup(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	group, err := fetchGroup(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching group", err)
+		return
+	}
+	users, err := pgusers.New().FetchAll(ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	if _, err := syncGroupMembers(users, group.DisplayName, "", nil); err != nil {
+		writeError(c, "failed removing group members", err)
+		return
+	}
+	if err := pgscim.New().DeleteGroup(ctx, group.ID); err != nil {
+		writeError(c, "failed removing group", err)
+		return
+	}
+	log.With("org", ctx.OrgName).Infof("scim: group %v deprovisioned", group.DisplayName)
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// updateGroup renames the group and replaces its members
+func updateGroup(c *gin.Context, ctx *storagev2.Context, group *pgrest.SCIMGroup, displayName string, users []pgrest.User, members map[string]bool) {
+	if err := validateGroupName(displayName); err != nil {
+		writeError(c, "invalid group name", err)
+		return
+	}
+	oldName := group.DisplayName
+	if displayName != oldName {
+		existingGroup, err := pgscim.New().FetchOneGroupByName(ctx, displayName)
+		if err != nil {
+			writeError(c, "failed fetching existing group", err)
+			return
+		}
+		if existingGroup != nil {
+			abortWithError(c, newError(http.StatusConflict, "uniqueness", "group already exists with displayName %v", displayName))
+			return
+		}
+		if err := pgscim.New().UpdateGroup(ctx, group.ID, displayName); err != nil {
+			writeError(c, "failed updating group", err)
+			return
+		}
+		group.DisplayName = displayName
+	}
+	users, err := syncGroupMembers(users, oldName, displayName, members)
+	if err != nil {
+		writeError(c, "failed updating group members", err)
+		return
+	}
+	log.With("org", ctx.OrgName).Infof("scim: group %v updated, name=%v, members=%v", oldName, displayName, len(members))
+	writeJSON(c, http.StatusOK, toSCIMGroup(ctx, group, users))
+}
+
+// applyGroupPatch applies the patch operations to the name and
+// the members of the group returning the new members of the group
+func applyGroupPatch(displayName *string, members map[string]bool, users []pgrest.User, operations []openapi.SCIMPatchOperation) (map[string]bool, error) {
+	for _, op := range operations {
+		opName := strings.ToLower(op.Op)
+		path := strings.ToLower(op.Path)
+		switch {
+		case opName != "add" && opName != "replace" && opName != "remove":
+			return nil, newError(http.StatusBadRequest, "invalidSyntax", "unsupported patch operation %q", op.Op)
+		case path == "" && opName != "remove":
+			var group openapi.SCIMGroup
+			if err := decodeValue(op.Value, &group); err != nil {
+				return nil, err
+			}
+			if group.DisplayName != "" {
+				*displayName = group.DisplayName
+			}
+			if group.Members == nil {
+				continue
+			}
+			ids, err := memberIDs(users, group.Members)
+			if err != nil {
+				return nil, err
+			}
+			if opName == "replace" {
+				members = map[string]bool{}
+			}
+			for id := range ids {
+				members[id] = true
+			}
+		case path == "displayname" && opName != "remove":
+			if err := decodeValue(op.Value, displayName); err != nil {
+				return nil, err
+			}
+		case path == "members":
+			var refs []openapi.SCIMReference
+			if op.Value != nil {
+				if err := decodeValue(op.Value, &refs); err != nil {
+					return nil, err
+				}
+			}
+			// removing members doesn't validate the references, they may be deprovisioned already
+			ids := map[string]bool{}
+			if opName != "remove" {
+				var err error
+				if ids, err = memberIDs(users, refs); err != nil {
+					return nil, err
+				}
+			}
+			for _, ref := range refs {
+				ids[ref.Value] = true
+			}
+			switch {
+			case opName == "replace", opName == "remove" && op.Value == nil:
+				members = map[string]bool{}
+			}
+			for id := range ids {
+				members[id] = opName != "remove"
+			}
+		case memberPathRe.MatchString(op.Path) && opName == "remove":
+			members[memberPathRe.FindStringSubmatch(op.Path)[1]] = false
+		default:
+			return nil, newError(http.StatusBadRequest, "invalidPath", "unsupported %v operation for the path %q", opName, op.Path)
+		}
+	}
+	for id, isMember := range members {
+		if !isMember {
+			delete(members, id)
+		}
+	}
+	return members, nil
+}
+
+// syncGroupMembers adds the group to the members and removes it from the other users,
+// renaming the group from oldName to newName. An empty newName removes the group from all users.
+// It returns the users with their updated groups.
+func syncGroupMembers(users []pgrest.User, oldName, newName string, members map[string]bool) ([]pgrest.User, error) {
+	for i, u := range users {
+		groups := slices.DeleteFunc(slices.Clone(u.Groups), func(g string) bool {
+			return g == oldName || g == newName
+		})
+		if newName != "" && members[u.ID] {
+			groups = append(groups, newName)
+		}
+		if sameGroups(u.Groups, groups) {
+			continue
+		}
+		u.Groups = groups
+		if err := pgusers.New().Upsert(u); err != nil {
+			return nil, err
+		}
+		users[i] = u
+	}
+	return users, nil
+}
+
+func fetchGroup(ctx *storagev2.Context, id string) (*pgrest.SCIMGroup, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, newError(http.StatusNotFound, "", "group %v not found", id)
+	}
+	group, err := pgscim.New().FetchOneGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, newError(http.StatusNotFound, "", "group %v not found", id)
+	}
+	return group, nil
+}
+
+// fetchGroupUsers returns the users of the organization unless
+// the members are excluded from the attributes of the response
+func fetchGroupUsers(c *gin.Context, ctx *storagev2.Context) ([]pgrest.User, error) {
+	if slices.ContainsFunc(strings.Split(c.Query("excludedAttributes"), ","), func(attr string) bool {
+		return strings.EqualFold(strings.TrimSpace(attr), "members")
+	}) {
+		return nil, nil
+	}
+	return pgusers.New().FetchAll(ctx)
+}
+
+func toSCIMGroup(ctx *storagev2.Context, group *pgrest.SCIMGroup, users []pgrest.User) openapi.SCIMGroup {
+	createdAt, updatedAt := group.GetCreatedAt(), group.GetUpdatedAt()
+	obj := openapi.SCIMGroup{
+		Schemas:     []string{schemaGroup},
+		ID:          group.ID,
+		DisplayName: group.DisplayName,
+		Members:     []openapi.SCIMReference{},
+		Meta: &openapi.SCIMMeta{
+			ResourceType: "Group",
+			Created:      &createdAt,
+			LastModified: &updatedAt,
+			Location:     baseURL(ctx) + "/Groups/" + group.ID,
+		},
+	}
+	for _, u := range users {
+		if slices.Contains(u.Groups, group.DisplayName) {
+			obj.Members = append(obj.Members, openapi.SCIMReference{
+				Value:   u.ID,
+				Display: u.Email,
+				Ref:     baseURL(ctx) + "/Users/" + u.ID,
+			})
+		}
+	}
+	return obj
+}
+
+// groupAttributes returns the values of the attributes used to filter groups
+func groupAttributes(group *pgrest.SCIMGroup) func(attr string) []string {
+	return func(attr string) []string {
+		switch attr {
+		case "id":
+			return []string{group.ID}
+		case "displayname":
+			return []string{group.DisplayName}
+		}
+		return nil
+	}
+}
+
+// memberIDs validates that the references are users of the organization
+func memberIDs(users []pgrest.User, refs []openapi.SCIMReference) (map[string]bool, error) {
+	ids := map[string]bool{}
+	for _, ref := range refs {
+		if !slices.ContainsFunc(users, func(u pgrest.User) bool { return u.ID == ref.Value }) {
+			return nil, newError(http.StatusBadRequest, "invalidValue", "member %v is not a user of the organization", ref.Value)
+		}
+		ids[ref.Value] = true
+	}
+	return ids, nil
+}
+
+func currentMembers(users []pgrest.User, groupName string) map[string]bool {
+	members := map[string]bool{}
+	for _, u := range users {
+		if slices.Contains(u.Groups, groupName) {
+			members[u.ID] = true
+		}
+	}
+	return members
+}
+
+func sameGroups(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func validateGroupName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	if len(name) > maxGroupNameSize {
+		return newError(http.StatusBadRequest, "invalidValue", "displayName must not contain more than %v characters", maxGroupNameSize)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package apiscim
+
+import (
+	"testing"
+
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyGroupPatch(t *testing.T) {
+	users := []pgrest.User{{ID: "u1"}, {ID: "u2"}, {ID: "u3"}}
+	for _, tt := range []struct {
+		msg         string
+		operations  []openapi.SCIMPatchOperation
+		wantName    string
+		wantMembers map[string]bool
+		wantErr     bool
+	}{
+		{
+			msg: "it must add members",
+			operations: []openapi.SCIMPatchOperation{
+				{Op: "Add", Path: "members", Value: []any{map[string]any{"value": "u3"}}},
+			},
+			wantName:    "sre",
+			wantMembers: map[string]bool{"u1": true, "u3": true},
+		},
+		{
+			msg: "it must remove members by filter and value",
+			operations: []openapi.SCIMPatchOperation{
+				{Op: "remove", Path: `members[value eq "u1"]`},
+				{Op: "remove", Path: "members", Value: []any{map[string]any{"value": "deprovisioned-user"}}},
+			},
+			wantName:    "sre",
+			wantMembers: map[string]bool{},
+		},
+		{
+			msg: "it must replace the name and the members",
+			operations: []openapi.SCIMPatchOperation{
+				{Op: "replace", Value: map[string]any{"id": "g1", "displayName": "dba", "members": []any{map[string]any{"value": "u2"}}}},
+			},
+			wantName:    "dba",
+			wantMembers: map[string]bool{"u2": true},
+		},
+		{
+			msg: "it must replace the name by path",
+			operations: []openapi.SCIMPatchOperation{
+				{Op: "replace", Path: "displayName", Value: "dba"},
+			},
+			wantName:    "dba",
+			wantMembers: map[string]bool{"u1": true},
+		},
+		{
+			msg: "it must reject unknown members",
+			operations: []openapi.SCIMPatchOperation{
+				{Op: "add", Path: "members", Value: []any{map[string]any{"value": "u4"}}},
+			},
+			wantErr: true,
+		},
+		{
+			msg: "it must reject unsupported paths",
+			operations: []openapi.SCIMPatchOperation{
+				{Op: "replace", Path: "externalId", Value: "g1"},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			name := "sre"
+			members, err := applyGroupPatch(&name, map[string]bool{"u1": true}, users, tt.operations)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantMembers, members)
+		})
+	}
+}
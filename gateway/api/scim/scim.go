@@ -0,0 +1,264 @@
+package apiscim
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/appconfig"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgorgs "github.com/hoophq/hoop/gateway/pgrest/orgs"
+	pgscim "github.com/hoophq/hoop/gateway/pgrest/scim"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/transport/streamclient"
+)
+
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	contentType         = "application/scim+json"
+	tokenPrefix         = "xscim-"
+	defaultItemsPerPage = 100
+	maxItemsPerPage     = 1000
+
+	// the subject and name of the context used to audit the provisioning changes
+	scimSubject = "scim"
+)
+
+// scimError is an error returned to the identity provider using the SCIM error schema
+type scimError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (e *scimError) Error() string { return e.detail }
+
+func newError(status int, scimType, format string, a ...any) *scimError {
+	return &scimError{status: status, scimType: scimType, detail: fmt.Sprintf(format, a...)}
+}
+
+// Authenticate validates the bearer token of the organization generated with the
+// endpoint /orgs/scim-token and sets the context of the request
+func Authenticate(c *gin.Context) {
+	token, _ := strings.CutPrefix(c.GetHeader("authorization"), "Bearer ")
+	if !strings.HasPrefix(token, tokenPrefix) {
+		abortWithError(c, newError(http.StatusUnauthorized, "", "missing or invalid bearer token"))
+		return
+	}
+	orgID, err := pgscim.New().FetchOrgIDByToken(hashToken(token))
+	if err != nil {
+		log.Errorf("failed fetching scim token, err=%v", err)
+		sentry.CaptureException(err)
+		abortWithError(c, newError(http.StatusInternalServerError, "", "failed validating bearer token"))
+		return
+	}
+	if orgID == "" {
+		abortWithError(c, newError(http.StatusUnauthorized, "", "missing or invalid bearer token"))
+		return
+	}
+	org, err := pgorgs.New().FetchOrgByContext(pgrest.NewOrgContext(orgID))
+	if err != nil || org == nil {
+		log.Errorf("failed fetching organization %v, err=%v", orgID, err)
+		abortWithError(c, newError(http.StatusInternalServerError, "", "failed fetching organization"))
+		return
+	}
+	c.Set(storagev2.ContextKey,
+		storagev2.NewContext(scimSubject, orgID).
+			WithUserInfo("SCIM", scimSubject, string(openapi.StatusActive), "", nil).
+			WithOrgName(org.Name).
+			WithApiURL(appconfig.Get().ApiURL()),
+	)
+	c.Next()
+}
+
+// CreateToken
+//
+//	@Summary		Create SCIM Token
+//	@Description	Create the bearer token used by identity providers to provision users and groups with SCIM 2.0.
+//	@Description	It replaces any existing token of the organization and it's only returned once.
+//	@Tags			User Management
+//	@Produce		json
+//	@Success		201	{object}	openapi.SCIMTokenResponse
+//	@Failure		500	{object}	openapi.HTTPError
+//	@Router			/orgs/scim-token [post]
+func CreateToken(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	token, err := generateToken()
+	if err != nil {
+		log.Errorf("failed generating scim token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating token"})
+		return
+	}
+	if err := pgscim.New().UpsertToken(ctx, hashToken(token), ctx.UserEmail); err != nil {
+		log.Errorf("failed persisting scim token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed persisting token"})
+		return
+	}
+	c.JSON(http.StatusCreated, openapi.SCIMTokenResponse{
+		Token:   token,
+		BaseURL: baseURL(ctx),
+	})
+}
+
+// RevokeToken
+//
+//	@Summary		Revoke SCIM Token
+//	@Description	Remove the bearer token used by identity providers to provision users and groups
+//	@Tags			User Management
+//	@Success		204
+//	@Failure		500	{object}	openapi.HTTPError
+//	@Router			/orgs/scim-token [delete]
+func RevokeToken(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	if err := pgscim.New().DeleteToken(ctx); err != nil {
+		log.Errorf("failed removing scim token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed removing token"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// GetServiceProviderConfig
+//
+//	@Summary		SCIM Service Provider Config
+//	@Description	The SCIM 2.0 features supported by the service provider
+//	@Tags			User Management
+//	@Produce		json
+//	@Success		200	{object}	object
+//	@Router			/scim/v2/ServiceProviderConfig [get]
+func GetServiceProviderConfig(c *gin.Context) {
+	writeJSON(c, http.StatusOK, gin.H{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": maxItemsPerPage},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the token generated in the endpoint /api/orgs/scim-token",
+			"primary":     true,
+		}},
+	})
+}
+
+func baseURL(ctx *storagev2.Context) string {
+	return strings.TrimSuffix(ctx.ApiURL, "/") + "/api/scim/v2"
+}
+
+func writeJSON(c *gin.Context, status int, obj any) {
+	c.Header("Content-Type", contentType)
+	c.JSON(status, obj)
+}
+
+func abortWithError(c *gin.Context, err *scimError) {
+	c.Abort()
+	writeJSON(c, err.status, openapi.SCIMError{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(err.status),
+		ScimType: err.scimType,
+		Detail:   err.detail,
+	})
+}
+
+// writeError writes SCIM errors as is, any other error is reported as an internal error
+func writeError(c *gin.Context, msg string, err error) {
+	if scimErr, ok := err.(*scimError); ok {
+		abortWithError(c, scimErr)
+		return
+	}
+	log.Errorf("%s, err=%v", msg, err)
+	sentry.CaptureException(err)
+	abortWithError(c, newError(http.StatusInternalServerError, "", msg))
+}
+
+// bindJSON decodes the body of the request, identity providers
+// usually send the content type application/scim+json
+func bindJSON(c *gin.Context, obj any) error {
+	if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "failed decoding request body: %v", err)
+	}
+	return nil
+}
+
+// decodeValue decodes the value of a patch operation into obj
+func decodeValue(value any, obj any) error {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(data, obj)
+	}
+	if err != nil {
+		return newError(http.StatusBadRequest, "invalidValue", "invalid value %v: %v", value, err)
+	}
+	return nil
+}
+
+// parsePagination returns the 1-based start index and the number of items per page
+func parsePagination(c *gin.Context) (startIndex, count int) {
+	startIndex, count = 1, defaultItemsPerPage
+	if v, err := strconv.Atoi(c.Query("startIndex")); err == nil && v > 1 {
+		startIndex = v
+	}
+	if v, err := strconv.Atoi(c.Query("count")); err == nil {
+		count = min(max(v, 0), maxItemsPerPage)
+	}
+	return
+}
+
+func newListResponse[T any](items []T, startIndex, count int) openapi.SCIMListResponse {
+	resources := []any{}
+	for i := startIndex - 1; i < len(items) && len(resources) < count; i++ {
+		resources = append(resources, items[i])
+	}
+	return openapi.SCIMListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(items),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// terminateSessions kills the live sessions of the user connected to this gateway instance
+func terminateSessions(orgID, subject string) {
+	for _, s := range streamclient.ListProxyStreams(orgID) {
+		pctx := s.PluginContext()
+		if pctx.UserID != subject {
+			continue
+		}
+		_ = s.Kill(fmt.Errorf("session terminated, the user was deprovisioned"))
+		log.With("sid", pctx.SID, "connection", pctx.ConnectionName).
+			Infof("session killed, user %v was deprovisioned", pctx.UserEmail)
+	}
+}
+
+func generateToken() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed generating entropy: %v", err)
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+func hashToken(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
@@ -0,0 +1,421 @@
+package apiscim
+
+import (
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgscim "github.com/hoophq/hoop/gateway/pgrest/scim"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	pgusertokens "github.com/hoophq/hoop/gateway/pgrest/usertokens"
+	"github.com/hoophq/hoop/gateway/storagev2"
+)
+
+var userFilterAttributes = []string{"id", "userName", "emails.value", "displayName", "active"}
+
+// ListUsers
+//
+//	@Summary		SCIM List Users
+//	@Description	List the users of the organization. The filter parameter supports the attributes
+//	@Description	`id`, `userName`, `emails.value`, `displayName` and `active` with the operators `eq`, `ne`, `co`, `sw`, `ew` and `pr` joined by `and`.
+//	@Tags			User Management
+//	@Produce		json
+//	@Param			filter		query		string	false	"The filter expression"	example(userName eq "john.wick@bad.org")
+//	@Param			startIndex	query		int		false	"The 1-based index of the first result"
+//	@Param			count		query		int		false	"The maximum number of results per page"
+//	@Success		200			{object}	openapi.SCIMListResponse{Resources=[]openapi.SCIMUser}
+//	@Failure		400,401,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Users [get]
+func ListUsers(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var filter Filter
+	if expr := c.Query("filter"); expr != "" {
+		var err error
+		if filter, err = ParseFilter(expr, userFilterAttributes...); err != nil {
+			abortWithError(c, newError(http.StatusBadRequest, "invalidFilter", err.Error()))
+			return
+		}
+	}
+	users, err := pgusers.New().FetchAll(ctx)
+	if err != nil {
+		writeError(c, "failed fetching users", err)
+		return
+	}
+	groups, err := pgscim.New().FetchAllGroups(ctx)
+	if err != nil {
+		writeError(c, "failed fetching groups", err)
+		return
+	}
+	items := []openapi.SCIMUser{}
+	for _, u := range users {
+		if filter.Match(userAttributes(&u)) {
+			items = append(items, toSCIMUser(ctx, &u, groups))
+		}
+	}
+	startIndex, count := parsePagination(c)
+	writeJSON(c, http.StatusOK, newListResponse(items, startIndex, count))
+}
+
+// GetUser
+//
+//	@Summary		SCIM Get User
+//	@Tags			User Management
+//	@Produce		json
+//	@Param			id			path		string	true	"The id of the user"
+//	@Success		200			{object}	openapi.SCIMUser
+//	@Failure		401,404,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Users/{id} [get]
+func GetUser(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	usr, err := fetchUser(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching user", err)
+		return
+	}
+	writeUser(c, ctx, http.StatusOK, usr)
+}
+
+// CreateUser
+//
+//	@Summary		SCIM Create User
+//	@Description	Provision a user in the organization, the user name must be the email of the user
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			request			body		openapi.SCIMUser	true	"The request body resource"
+//	@Success		201				{object}	openapi.SCIMUser
+//	@Failure		400,401,409,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Users [post]
+func CreateUser(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.SCIMUser
+	if err := bindJSON(c, &req); err != nil {
+		writeError(c, "failed decoding request", err)
+		return
+	}
+	email := strings.ToLower(req.UserName)
+	if _, err := mail.ParseAddress(email); err != nil {
+		abortWithError(c, newError(http.StatusBadRequest, "invalidValue", "userName must be a valid email address"))
+		return
+	}
+	existingUser, err := pgusers.New().FetchOneByEmail(ctx, email)
+	if err != nil {
+		writeError(c, "failed fetching existing user", err)
+		return
+	}
+	if existingUser != nil {
+		abortWithError(c, newError(http.StatusConflict, "uniqueness", "user already exists with userName %v", email))
+		return
+	}
+	usr := pgrest.User{
+		ID:       uuid.NewString(),
+		Subject:  email,
+		OrgID:    ctx.OrgID,
+		Name:     nameFromSCIM(req.DisplayName, req.Name),
+		Email:    email,
+		Verified: false,
+		Status:   toStatus(req.Active),
+	}
+	if err := pgusers.New().Upsert(usr); err != nil {
+		writeError(c, "failed creating user", err)
+		return
+	}
+	log.With("org", ctx.OrgName).Infof("scim: user %v provisioned, status=%v", email, usr.Status)
+	writeUser(c, ctx, http.StatusCreated, &usr)
+}
+
+// ReplaceUser
+//
+//	@Summary		SCIM Replace User
+//	@Description	Replace the attributes of a user, deactivating a user terminates its live sessions
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string				true	"The id of the user"
+//	@Param			request			body		openapi.SCIMUser	true	"The request body resource"
+//	@Success		200				{object}	openapi.SCIMUser
+//	@Failure		400,401,404,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Users/{id} [put]
+func ReplaceUser(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.SCIMUser
+	if err := bindJSON(c, &req); err != nil {
+		writeError(c, "failed decoding request", err)
+		return
+	}
+	usr, err := fetchUser(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching user", err)
+		return
+	}
+	if !strings.EqualFold(req.UserName, usr.Email) {
+		abortWithError(c, newError(http.StatusBadRequest, "mutability", "userName is immutable"))
+		return
+	}
+	newState := *usr
+	newState.Name = nameFromSCIM(req.DisplayName, req.Name)
+	newState.Status = toStatus(req.Active)
+	if err := updateUser(ctx, usr, &newState); err != nil {
+		writeError(c, "failed updating user", err)
+		return
+	}
+	writeUser(c, ctx, http.StatusOK, &newState)
+}
+
+// PatchUser
+//
+//	@Summary		SCIM Patch User
+//	@Description	Update the attributes `active`, `displayName` and `name` of a user, deactivating a user terminates its live sessions
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string						true	"The id of the user"
+//	@Param			request			body		openapi.SCIMPatchRequest	true	"The request body resource"
+//	@Success		200				{object}	openapi.SCIMUser
+//	@Failure		400,401,404,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Users/{id} [patch]
+func PatchUser(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.SCIMPatchRequest
+	if err := bindJSON(c, &req); err != nil {
+		writeError(c, "failed decoding request", err)
+		return
+	}
+	usr, err := fetchUser(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching user", err)
+		return
+	}
+	newState := *usr
+	if err := applyUserPatch(&newState, req.Operations); err != nil {
+		writeError(c, "failed applying patch operations", err)
+		return
+	}
+	if err := updateUser(ctx, usr, &newState); err != nil {
+		writeError(c, "failed updating user", err)
+		return
+	}
+	writeUser(c, ctx, http.StatusOK, &newState)
+}
+
+// DeleteUser
+//
+//	@Summary		SCIM Delete User
+//	@Description	Deprovision a user from the organization and terminate its live sessions
+//	@Tags			User Management
+//	@Param			id	path	string	true	"The id of the user"
+//	@Success		204
+//	@Failure		401,404,500	{object}	openapi.SCIMError
+//	@Router			/scim/v2/Users/{id} [delete]
+func DeleteUser(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	usr, err := fetchUser(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, "failed fetching user", err)
+		return
+	}
+	if err := pgusers.New().Delete(ctx, usr.Subject); err != nil {
+		writeError(c, "failed removing user", err)
+		return
+	}
+	log.With("org", ctx.OrgName).Infof("scim: user %v deprovisioned", usr.Email)
+	terminateSessions(ctx.OrgID, usr.Subject)
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func fetchUser(ctx *storagev2.Context, id string) (*pgrest.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, newError(http.StatusNotFound, "", "user %v not found", id)
+	}
+	usr, err := pgusers.New().FetchOneByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if usr == nil {
+		return nil, newError(http.StatusNotFound, "", "user %v not found", id)
+	}
+	return usr, nil
+}
+
+// updateUser persists the new state of the user keeping its groups, the personal
+// access tokens are revoked and the live sessions are terminated when the user is deactivated
+func updateUser(ctx *storagev2.Context, usr, newState *pgrest.User) error {
+	if err := pgusers.New().Upsert(*newState); err != nil {
+		return err
+	}
+	if usr.Status == newState.Status {
+		return nil
+	}
+	log.With("org", ctx.OrgName).Infof("scim: user %v status changed from %v to %v",
+		usr.Email, usr.Status, newState.Status)
+	if newState.Status != string(openapi.StatusActive) {
+		if err := pgusertokens.New().RevokeAllByUser(ctx, usr.ID); err != nil {
+			return err
+		}
+		terminateSessions(ctx.OrgID, usr.Subject)
+	}
+	return nil
+}
+
+func writeUser(c *gin.Context, ctx *storagev2.Context, status int, usr *pgrest.User) {
+	groups, err := pgscim.New().FetchAllGroups(ctx)
+	if err != nil {
+		writeError(c, "failed fetching groups", err)
+		return
+	}
+	writeJSON(c, status, toSCIMUser(ctx, usr, groups))
+}
+
+// applyUserPatch applies the patch operations to the user. Attributes that are not
+// stored (e.g.: externalId, name.givenName) are ignored and the userName is immutable
+func applyUserPatch(usr *pgrest.User, operations []openapi.SCIMPatchOperation) error {
+	for _, op := range operations {
+		opName := strings.ToLower(op.Op)
+		if opName != "add" && opName != "replace" && opName != "remove" {
+			return newError(http.StatusBadRequest, "invalidSyntax", "unsupported patch operation %q", op.Op)
+		}
+		if op.Path != "" {
+			if err := setUserAttribute(usr, op.Path, op.Value, opName == "remove"); err != nil {
+				return err
+			}
+			continue
+		}
+		var attrs map[string]any
+		if err := decodeValue(op.Value, &attrs); err != nil {
+			return err
+		}
+		for path, value := range attrs {
+			if err := setUserAttribute(usr, path, value, opName == "remove"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setUserAttribute(usr *pgrest.User, path string, value any, remove bool) error {
+	switch strings.ToLower(path) {
+	case "active":
+		if remove {
+			return newError(http.StatusBadRequest, "mutability", "active is a required attribute")
+		}
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		usr.Status = toStatus(&active)
+	case "displayname", "name.formatted":
+		usr.Name = ""
+		if !remove {
+			if err := decodeValue(value, &usr.Name); err != nil {
+				return err
+			}
+		}
+	case "name":
+		usr.Name = ""
+		if !remove {
+			var name openapi.SCIMName
+			if err := decodeValue(value, &name); err != nil {
+				return err
+			}
+			usr.Name = nameFromSCIM("", &name)
+		}
+	case "username":
+		var userName string
+		if err := decodeValue(value, &userName); err != nil {
+			return err
+		}
+		if remove || !strings.EqualFold(userName, usr.Email) {
+			return newError(http.StatusBadRequest, "mutability", "userName is immutable")
+		}
+	}
+	return nil
+}
+
+func toSCIMUser(ctx *storagev2.Context, usr *pgrest.User, groups []pgrest.SCIMGroup) openapi.SCIMUser {
+	active := usr.Status == string(openapi.StatusActive)
+	obj := openapi.SCIMUser{
+		Schemas:     []string{schemaUser},
+		ID:          usr.ID,
+		UserName:    usr.Email,
+		Name:        &openapi.SCIMName{Formatted: usr.Name},
+		DisplayName: usr.Name,
+		Active:      &active,
+		Emails:      []openapi.SCIMEmail{{Value: usr.Email, Type: "work", Primary: true}},
+		Meta: &openapi.SCIMMeta{
+			ResourceType: "User",
+			Location:     baseURL(ctx) + "/Users/" + usr.ID,
+		},
+	}
+	for _, g := range groups {
+		if slices.Contains(usr.Groups, g.DisplayName) {
+			obj.Groups = append(obj.Groups, openapi.SCIMReference{
+				Value:   g.ID,
+				Display: g.DisplayName,
+				Ref:     baseURL(ctx) + "/Groups/" + g.ID,
+			})
+		}
+	}
+	return obj
+}
+
+// userAttributes returns the values of the attributes used to filter users
+func userAttributes(usr *pgrest.User) func(attr string) []string {
+	return func(attr string) []string {
+		switch attr {
+		case "id":
+			return []string{usr.ID}
+		case "username", "emails.value":
+			return []string{usr.Email}
+		case "displayname":
+			if usr.Name == "" {
+				return nil
+			}
+			return []string{usr.Name}
+		case "active":
+			return []string{strconv.FormatBool(usr.Status == string(openapi.StatusActive))}
+		}
+		return nil
+	}
+}
+
+// nameFromSCIM returns the display name of the user, only the full name is stored
+func nameFromSCIM(displayName string, name *openapi.SCIMName) string {
+	switch {
+	case displayName != "":
+		return displayName
+	case name == nil:
+		return ""
+	case name.Formatted != "":
+		return name.Formatted
+	}
+	return strings.TrimSpace(name.GivenName + " " + name.FamilyName)
+}
+
+// toStatus returns the status of the user, users are active by default
+func toStatus(active *bool) string {
+	if active != nil && !*active {
+		return string(openapi.StatusInactive)
+	}
+	return string(openapi.StatusActive)
+}
+
+// parseBool parses boolean values, some identity providers send them as strings (e.g.: "False")
+func parseBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(strings.ToLower(v)); err == nil {
+			return b, nil
+		}
+	}
+	return false, newError(http.StatusBadRequest, "invalidValue", "invalid boolean value %v", value)
+}
@@ -0,0 +1,64 @@
+package apiscim
+
+import (
+	"testing"
+
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyUserPatch(t *testing.T) {
+	for _, tt := range []struct {
+		msg        string
+		operations []openapi.SCIMPatchOperation
+		wantName   string
+		wantStatus string
+		wantErr    bool
+	}{
+		{
+			msg:        "it must deactivate users with boolean strings",
+			operations: []openapi.SCIMPatchOperation{{Op: "Replace", Path: "active", Value: "False"}},
+			wantName:   "John Wick",
+			wantStatus: "inactive",
+		},
+		{
+			msg: "it must update the attributes of operations without path",
+			operations: []openapi.SCIMPatchOperation{{Op: "replace", Value: map[string]any{
+				"active":     false,
+				"name":       map[string]any{"givenName": "Jonathan", "familyName": "Wick"},
+				"externalId": "00u1",
+			}}},
+			wantName:   "Jonathan Wick",
+			wantStatus: "inactive",
+		},
+		{
+			msg:        "it must update the display name",
+			operations: []openapi.SCIMPatchOperation{{Op: "add", Path: "displayName", Value: "Baba Yaga"}},
+			wantName:   "Baba Yaga",
+			wantStatus: "active",
+		},
+		{
+			msg:        "it must reject changes to the user name",
+			operations: []openapi.SCIMPatchOperation{{Op: "replace", Path: "userName", Value: "john@bad.org"}},
+			wantErr:    true,
+		},
+		{
+			msg:        "it must reject invalid boolean values",
+			operations: []openapi.SCIMPatchOperation{{Op: "replace", Path: "active", Value: "no"}},
+			wantErr:    true,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			usr := pgrest.User{Name: "John Wick", Email: "john.wick@bad.org", Status: "active"}
+			err := applyUserPatch(&usr, tt.operations)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantName, usr.Name)
+			assert.Equal(t, tt.wantStatus, usr.Status)
+		})
+	}
+}
@@ -16,27 +16,36 @@ import (
 
 	"github.com/hoophq/hoop/common/log"
 	"github.com/hoophq/hoop/gateway/analytics"
+	apiaccessrules "github.com/hoophq/hoop/gateway/api/accessrules"
 	apiagents "github.com/hoophq/hoop/gateway/api/agents"
+	apiautoapproval "github.com/hoophq/hoop/gateway/api/autoapproval"
 	apiconnections "github.com/hoophq/hoop/gateway/api/connections"
 	apifeatures "github.com/hoophq/hoop/gateway/api/features"
+	apigroupgrants "github.com/hoophq/hoop/gateway/api/groupgrants"
 	apihealthz "github.com/hoophq/hoop/gateway/api/healthz"
+	localauthapi "github.com/hoophq/hoop/gateway/api/localauth"
 	loginapi "github.com/hoophq/hoop/gateway/api/login"
 	"github.com/hoophq/hoop/gateway/api/openapi"
 	apiorgs "github.com/hoophq/hoop/gateway/api/orgs"
 	apiplugins "github.com/hoophq/hoop/gateway/api/plugins"
 	apiproxymanager "github.com/hoophq/hoop/gateway/api/proxymanager"
 	apireports "github.com/hoophq/hoop/gateway/api/reports"
+	apiretention "github.com/hoophq/hoop/gateway/api/retention"
 	reviewapi "github.com/hoophq/hoop/gateway/api/review"
 	apirunbooks "github.com/hoophq/hoop/gateway/api/runbooks"
+	apiscim "github.com/hoophq/hoop/gateway/api/scim"
 	apiserverinfo "github.com/hoophq/hoop/gateway/api/serverinfo"
 	serviceaccountapi "github.com/hoophq/hoop/gateway/api/serviceaccount"
 	sessionapi "github.com/hoophq/hoop/gateway/api/session"
 	signupapi "github.com/hoophq/hoop/gateway/api/signup"
 	userapi "github.com/hoophq/hoop/gateway/api/user"
+	apiusertokens "github.com/hoophq/hoop/gateway/api/usertokens"
 	webhooksapi "github.com/hoophq/hoop/gateway/api/webhooks"
+	"github.com/hoophq/hoop/gateway/appconfig"
 	"github.com/hoophq/hoop/gateway/indexer"
 	"github.com/hoophq/hoop/gateway/review"
 	"github.com/hoophq/hoop/gateway/security/idp"
+	"github.com/hoophq/hoop/gateway/security/usertoken"
 )
 
 type Api struct {
@@ -98,7 +107,10 @@ func (a *Api) StartAPI(sentryInit bool) {
 	}
 	a.logger = zaplogger
 	// https://pkg.go.dev/github.com/gin-gonic/gin#readme-don-t-trust-all-proxies
-	route.SetTrustedProxies(nil)
+	// the address of clients is only obtained from the X-Forwarded-For header of trusted proxies
+	if err := route.SetTrustedProxies(appconfig.Get().TrustedProxies()); err != nil {
+		log.Fatalf("failed configuring trusted proxies, err=%v", err)
+	}
 	route.Use(CORSMiddleware())
 	// route.Use(api.proxyNodeAPIMiddleware())
 	// UI
@@ -143,10 +155,21 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 
 	reviewHandler := reviewapi.NewHandler(&api.ReviewHandler)
 	loginHandler := loginapi.New(api.IDProvider)
+	localAuthHandler := localauthapi.New(api.IDProvider)
 	route.GET("/openapiv2.json", openapi.Handler)
 	route.GET("/openapiv3.json", openapi.HandlerV3)
 	route.GET("/login", loginHandler.Login)
 	route.GET("/callback", loginHandler.LoginCallback)
+	route.GET("/saml/metadata", loginHandler.SAMLMetadata)
+	route.POST("/saml/acs", loginHandler.SAMLCallback)
+	route.POST("/localauth/register", localAuthHandler.Register)
+	route.POST("/localauth/login", localAuthHandler.Login)
+	route.POST("/localauth/mfa", localAuthHandler.VerifyMFA)
+	route.PUT("/localauth/password",
+		DenyTokenAccess,
+		api.Authenticate,
+		AuditApiChanges,
+		localAuthHandler.ChangePassword)
 	route.GET("/healthz", apihealthz.LivenessHandler())
 	route.POST("/signup",
 		AnonAccessRole,
@@ -190,6 +213,47 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 		AuditApiChanges,
 		userapi.Delete)
 
+	route.PUT("/users/:id/password",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		localAuthHandler.SetUserPassword)
+	route.DELETE("/users/:id/mfa",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		localAuthHandler.ResetUserMFA)
+
+	route.POST("/users/:id/group-grants",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apigroupgrants.Create)
+	route.GET("/group-grants",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		apigroupgrants.List)
+	route.DELETE("/group-grants/:id",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apigroupgrants.Revoke)
+
+	route.GET("/tokens",
+		DenyTokenAccess,
+		api.Authenticate,
+		apiusertokens.List)
+	route.POST("/tokens",
+		DenyTokenAccess,
+		api.Authenticate,
+		AuditApiChanges,
+		apiusertokens.Create)
+	route.DELETE("/tokens/:id",
+		DenyTokenAccess,
+		api.Authenticate,
+		AuditApiChanges,
+		apiusertokens.Revoke)
+
 	route.GET("/serviceaccounts",
 		AdminOnlyAccessRole,
 		api.Authenticate,
@@ -221,6 +285,7 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 		apiconnections.Put)
 	// DEPRECATED in flavor of POST /sessions
 	route.POST("/connections/:name/exec",
+		TokenScope(usertoken.ScopeExecPrefix),
 		api.Authenticate,
 		api.TrackRequest(analytics.EventApiExecConnection),
 		sessionapi.Post)
@@ -230,12 +295,26 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 	route.GET("/connections/:nameOrID",
 		api.Authenticate,
 		apiconnections.Get)
-	route.DELETE("/connections/:name",
+	route.DELETE("/connections/:nameOrID",
 		AdminOnlyAccessRole,
 		api.Authenticate,
 		api.TrackRequest(analytics.EventDeleteConnection),
 		AuditApiChanges,
 		apiconnections.Delete)
+	route.GET("/connections/:nameOrID/review-workflow",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		apiconnections.GetReviewWorkflow)
+	route.PUT("/connections/:nameOrID/review-workflow",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiconnections.UpdateReviewWorkflow)
+	route.DELETE("/connections/:nameOrID/review-workflow",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiconnections.DeleteReviewWorkflow)
 
 	route.POST("/proxymanager/connect",
 		api.Authenticate,
@@ -296,6 +375,66 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 		api.Authenticate,
 		apiorgs.RevokeAgentKey)
 
+	route.POST("/orgs/scim-token",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiscim.CreateToken)
+	route.DELETE("/orgs/scim-token",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiscim.RevokeToken)
+
+	// SCIM 2.0 provisioning endpoints authenticated with the token of the organization
+	route.GET("/scim/v2/ServiceProviderConfig",
+		apiscim.Authenticate,
+		apiscim.GetServiceProviderConfig)
+	route.GET("/scim/v2/Users",
+		apiscim.Authenticate,
+		apiscim.ListUsers)
+	route.GET("/scim/v2/Users/:id",
+		apiscim.Authenticate,
+		apiscim.GetUser)
+	route.POST("/scim/v2/Users",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.CreateUser)
+	route.PUT("/scim/v2/Users/:id",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.ReplaceUser)
+	route.PATCH("/scim/v2/Users/:id",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.PatchUser)
+	route.DELETE("/scim/v2/Users/:id",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.DeleteUser)
+	route.GET("/scim/v2/Groups",
+		apiscim.Authenticate,
+		apiscim.ListGroups)
+	route.GET("/scim/v2/Groups/:id",
+		apiscim.Authenticate,
+		apiscim.GetGroup)
+	route.POST("/scim/v2/Groups",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.CreateGroup)
+	route.PUT("/scim/v2/Groups/:id",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.ReplaceGroup)
+	route.PATCH("/scim/v2/Groups/:id",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.PatchGroup)
+	route.DELETE("/scim/v2/Groups/:id",
+		apiscim.Authenticate,
+		AuditApiChanges,
+		apiscim.DeleteGroup)
+
 	route.PUT("/orgs/license",
 		AdminOnlyAccessRole,
 		api.Authenticate,
@@ -347,28 +486,119 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 
 	// alias routes
 	route.GET("/plugins/audit/sessions/:session_id",
+		TokenScope(usertoken.ScopeSessionsRead),
 		api.Authenticate,
 		sessionapi.Get)
 	route.GET("/plugins/audit/sessions",
+		TokenScope(usertoken.ScopeSessionsRead),
 		api.Authenticate,
 		sessionapi.List)
 
 	route.GET("/sessions/:session_id",
+		TokenScope(usertoken.ScopeSessionsRead),
 		api.Authenticate,
 		sessionapi.Get)
 	route.GET("/sessions/:session_id/download", sessionapi.DownloadSession)
+	route.GET("/sessions/export",
+		TokenScope(usertoken.ScopeSessionsRead),
+		api.Authenticate,
+		sessionapi.ExportSessions)
+	route.GET("/sessions/live",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		sessionapi.ListLiveSessions)
+	route.POST("/sessions/:session_id/kill",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		sessionapi.KillSession)
+	route.GET("/sessions/:session_id/watch",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		sessionapi.WatchSession)
+	route.GET("/sessions/:session_id/replay",
+		TokenScope(usertoken.ScopeSessionsRead),
+		api.Authenticate,
+		sessionapi.ReplaySession)
 	route.GET("/sessions",
+		TokenScope(usertoken.ScopeSessionsRead),
 		api.Authenticate,
 		sessionapi.List)
 	route.POST("/sessions",
+		TokenScope(usertoken.ScopeExecPrefix),
 		api.Authenticate,
 		api.TrackRequest(analytics.EventApiExecSession),
 		sessionapi.Post)
 	route.POST("/sessions/:session_id/exec",
+		TokenScope(usertoken.ScopeExecPrefix),
 		api.Authenticate,
 		api.TrackRequest(analytics.EventApiExecReview),
 		sessionapi.RunReviewedExec)
 
+	route.GET("/retention-policies",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		apiretention.List)
+	route.GET("/retention-policies/report",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		apiretention.Report)
+	route.POST("/retention-policies",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiretention.Create)
+	route.PUT("/retention-policies/:name",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiretention.Update)
+	route.DELETE("/retention-policies/:name",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiretention.Delete)
+
+	route.GET("/review-auto-approval-rules",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		apiautoapproval.List)
+	route.POST("/review-auto-approval-rules",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiautoapproval.Create)
+	route.PUT("/review-auto-approval-rules/:name",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiautoapproval.Update)
+	route.DELETE("/review-auto-approval-rules/:name",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiautoapproval.Delete)
+
+	route.GET("/access-rules",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		apiaccessrules.List)
+	route.POST("/access-rules",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiaccessrules.Create)
+	route.PUT("/access-rules/:name",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiaccessrules.Update)
+	route.DELETE("/access-rules/:name",
+		AdminOnlyAccessRole,
+		api.Authenticate,
+		AuditApiChanges,
+		apiaccessrules.Delete)
+
 	route.GET("/reports/sessions",
 		AdminOnlyAccessRole,
 		api.Authenticate,
@@ -392,6 +622,7 @@ func (api *Api) buildRoutes(route *gin.RouterGroup) {
 	)
 
 	route.POST("/plugins/runbooks/connections/:name/exec",
+		TokenScope(usertoken.ScopeExecPrefix),
 		api.Authenticate,
 		api.TrackRequest(analytics.EventExecRunbook),
 		apirunbooks.RunExec)
@@ -0,0 +1,276 @@
+package sessionapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/asciicast"
+	pb "github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	sessionstorage "github.com/hoophq/hoop/gateway/storagev2/session"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+const (
+	exportFormatJSONL     = "jsonl"
+	exportFormatCSV       = "csv"
+	exportFormatAsciicast = "asciicast"
+
+	// the max number of sessions allowed in a bulk export
+	maxBulkExportSessions = 1000
+)
+
+var (
+	exportFormatExtensions = map[string]string{
+		exportFormatJSONL:     "jsonl",
+		exportFormatCSV:       "csv",
+		exportFormatAsciicast: "cast",
+	}
+	errExportFormatNotSupported = errors.New("export format not supported for this session")
+)
+
+type sessionExportEvent struct {
+	SessionID  string         `json:"session_id"`
+	Time       string         `json:"time"`
+	Type       string         `json:"type"`
+	Payload    string         `json:"payload"`
+	Connection string         `json:"connection"`
+	User       string         `json:"user"`
+	Metadata   map[string]any `json:"metadata"`
+}
+
+// exportSession writes the session in one of the export formats
+//
+// - jsonl: one event per line with its time, type, payload and metadata of the session
+// - csv: the output of exec sessions parsed as csv (tab separated columns)
+// - asciicast: the asciinema file format (v2) to replay terminal sessions
+func exportSession(w io.Writer, s *types.Session, format string, eventTypes []string) error {
+	switch format {
+	case exportFormatJSONL:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, event := range sessionEvents(s, eventTypes) {
+			eventTime, _ := event[0].(float64)
+			eventData, _ := event[2].([]byte)
+			err := enc.Encode(sessionExportEvent{
+				SessionID:  s.ID,
+				Time:       s.StartSession.Add(time.Duration(eventTime * float64(time.Second))).Format(time.RFC3339Nano),
+				Type:       fmt.Sprintf("%v", event[1]),
+				Payload:    string(eventData),
+				Connection: s.Connection,
+				User:       s.UserEmail,
+				Metadata:   s.Metadata,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case exportFormatCSV:
+		if s.Verb != pb.ClientVerbExec {
+			return errExportFormatNotSupported
+		}
+		var output []byte
+		for _, event := range sessionEvents(s, eventTypes) {
+			eventData, _ := event[2].([]byte)
+			output = append(output, eventData...)
+		}
+		csvWriter := csv.NewWriter(w)
+		for _, line := range strings.Split(strings.TrimRight(string(output), "\r\n"), "\n") {
+			if err := csvWriter.Write(strings.Split(strings.TrimSuffix(line, "\r"), "\t")); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	case exportFormatAsciicast:
+		header, events := parseSessionToAsciicast(s, eventTypes)
+		enc, err := asciicast.NewEncoder(w, header)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			if err := enc.Encode(ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// sessionEvents returns the events of a session filtered by type: [<time>, <type>, <decoded-data>]
+func sessionEvents(s *types.Session, eventTypes []string) (events []types.SessionEventStream) {
+	for _, eventList := range s.EventStream {
+		event, ok := eventList.(types.SessionEventStream)
+		if !ok || len(event) < 3 {
+			continue
+		}
+		eventType, _ := event[1].(string)
+		if !slices.Contains(eventTypes, eventType) {
+			continue
+		}
+		eventData, _ := base64.StdEncoding.DecodeString(fmt.Sprintf("%v", event[2]))
+		events = append(events, types.SessionEventStream{event[0], eventType, eventData})
+	}
+	return
+}
+
+// ExportSessions
+//
+//	@Summary		Export Sessions
+//	@Description	Export the sessions matching the filters as an archive. Each session is a file in the chosen format.
+//	@Description	The `csv` format only includes sessions with the `exec` verb.
+//	@Tags			Core
+//	@Produce		application/zip,application/x-tar
+//	@Param			format		query		string	false	"The format of each session"	Enums(jsonl, csv, asciicast)	default(jsonl)
+//	@Param			archive		query		string	false	"The type of the archive"		Enums(zip, tar)					default(zip)
+//	@Param			events		query		string	false	"The type of events to include (i,o,e)"	default(o,e)
+//	@Param			user		query		string	false	"Filter by user's subject id"
+//	@Param			connection	query		string	false	"Filter by connection's name"
+//	@Param			type		query		string	false	"Filter by connection's type"
+//	@Param			start_date	query		string	false	"Filter starting on this date"	Format(RFC3339)
+//	@Param			end_date	query		string	false	"Filter ending on this date"	Format(RFC3339)
+//	@Success		200			{string}	string
+//	@Header			200			{string}	Content-Disposition	"attachment; filename=sessions.zip"
+//	@Failure		422,500		{object}	openapi.HTTPError
+//	@Router			/sessions/export [get]
+func ExportSessions(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	log := pgusers.ContextLogger(c)
+
+	format := c.DefaultQuery("format", exportFormatJSONL)
+	fileExt, ok := exportFormatExtensions[format]
+	if !ok {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "format must be one of: jsonl, csv, asciicast"})
+		return
+	}
+	archiveType := c.DefaultQuery("archive", "zip")
+	if archiveType != "zip" && archiveType != "tar" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "archive must be one of: zip, tar"})
+		return
+	}
+	eventTypes := parseEventTypes(c.Query("events"))
+	options, err := parseSessionOptions(c, ctx)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf("failed exporting sessions, %v", err)})
+		return
+	}
+	// pagination is managed when iterating over the sessions
+	options = slices.DeleteFunc(options, func(opt *openapi.SessionOption) bool {
+		return opt.OptionKey == openapi.SessionOptionLimit || opt.OptionKey == openapi.SessionOptionOffset
+	})
+	sessionIDs, err := listSessionIDs(ctx, options)
+	if err != nil {
+		log.Errorf("failed listing sessions, err=%v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing sessions"})
+		return
+	}
+	if len(sessionIDs) > maxBulkExportSessions {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf(
+			"max number of sessions (%v) reached, narrow the filters", maxBulkExportSessions)})
+		return
+	}
+
+	archiveName := fmt.Sprintf("sessions-%s.%s", time.Now().UTC().Format("20060102150405"), archiveType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", archiveName))
+	c.Header("Content-Type", "application/zip")
+	var archive sessionArchiveWriter = &zipArchive{zip.NewWriter(c.Writer)}
+	if archiveType == "tar" {
+		c.Header("Content-Type", "application/x-tar")
+		archive = &tarArchive{tar.NewWriter(c.Writer)}
+	}
+	exported := 0
+	for _, sid := range sessionIDs {
+		session, err := sessionstorage.FindOne(ctx, sid)
+		if err != nil || session == nil {
+			log.With("sid", sid).Warnf("failed fetching session, found=%v, err=%v", session != nil, err)
+			continue
+		}
+		var buf bytes.Buffer
+		switch err := exportSession(&buf, session, format, eventTypes); err {
+		case nil:
+		case errExportFormatNotSupported:
+			continue
+		default:
+			log.With("sid", sid).Warnf("failed exporting session, err=%v", err)
+			continue
+		}
+		fileName := fmt.Sprintf("%s.%s", session.ID, fileExt)
+		if err := archive.WriteFile(fileName, session.StartSession, buf.Bytes()); err != nil {
+			log.Warnf("failed writing session to archive, err=%v", err)
+			return
+		}
+		exported++
+	}
+	if err := archive.Close(); err != nil {
+		log.Warnf("failed closing archive, err=%v", err)
+	}
+	log.Infof("sessions exported, format=%v, archive=%v, total=%v, exported=%v",
+		format, archiveType, len(sessionIDs), exported)
+}
+
+func listSessionIDs(ctx *storagev2.Context, options []*openapi.SessionOption) ([]string, error) {
+	var sessionIDs []string
+	for offset := 0; ; {
+		opts := append(slices.Clone(options), WithOption(openapi.SessionOptionOffset, offset))
+		sessionList, err := sessionstorage.List(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if sessionList == nil {
+			break
+		}
+		for _, s := range sessionList.Items {
+			sessionIDs = append(sessionIDs, s.ID)
+		}
+		if !sessionList.HasNextPage || len(sessionIDs) > maxBulkExportSessions {
+			break
+		}
+		offset += len(sessionList.Items)
+	}
+	return sessionIDs, nil
+}
+
+type sessionArchiveWriter interface {
+	WriteFile(name string, modTime time.Time, data []byte) error
+	Close() error
+}
+
+type zipArchive struct{ w *zip.Writer }
+
+func (a *zipArchive) WriteFile(name string, modTime time.Time, data []byte) error {
+	f, err := a.w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+func (a *zipArchive) Close() error { return a.w.Close() }
+
+type tarArchive struct{ w *tar.Writer }
+
+func (a *tarArchive) WriteFile(name string, modTime time.Time, data []byte) error {
+	err := a.w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = a.w.Write(data)
+	return err
+}
+func (a *tarArchive) Close() error { return a.w.Close() }
@@ -0,0 +1,71 @@
+package sessionapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/transport/streamclient"
+)
+
+// ListLiveSessions
+//
+//	@Summary		List Live Sessions
+//	@Description	List the sessions that are currently connected to this gateway instance
+//	@Tags			Core
+//	@Produce		json
+//	@Success		200	{array}	openapi.LiveSession
+//	@Router			/sessions/live [get]
+func ListLiveSessions(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	items := []openapi.LiveSession{}
+	for _, s := range streamclient.ListProxyStreams(ctx.OrgID) {
+		pctx := s.PluginContext()
+		items = append(items, openapi.LiveSession{
+			ID:             pctx.SID,
+			UserEmail:      pctx.UserEmail,
+			UserID:         pctx.UserID,
+			UserName:       pctx.UserName,
+			ConnectionName: pctx.ConnectionName,
+			ConnectionType: pctx.ConnectionType,
+			Verb:           pctx.ClientVerb,
+			Origin:         pctx.ClientOrigin,
+			Hostname:       s.GetMeta("hostname"),
+			StartedAt:      s.StartedAt(),
+			BytesSent:      s.BytesSent(),
+			BytesReceived:  s.BytesReceived(),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].StartedAt.Before(items[j].StartedAt) })
+	c.JSON(http.StatusOK, items)
+}
+
+// KillSession
+//
+//	@Summary		Kill Session
+//	@Description	Terminate a live session. The client stream is closed and the agent is notified to end the session.
+//	@Tags			Core
+//	@Param			session_id	path	string	true	"The id of the resource"
+//	@Success		204
+//	@Failure		404	{object}	openapi.HTTPError
+//	@Router			/sessions/{session_id}/kill [post]
+func KillSession(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	log := pgusers.ContextLogger(c)
+
+	sessionID := c.Param("session_id")
+	stream := streamclient.GetProxyStream(sessionID)
+	if stream == nil || stream.PluginContext().OrgID != ctx.OrgID {
+		c.JSON(http.StatusNotFound, gin.H{"message": "live session not found"})
+		return
+	}
+	pctx := stream.PluginContext()
+	_ = stream.Kill(fmt.Errorf("session terminated by %v", ctx.UserEmail))
+	log.With("sid", sessionID, "connection", pctx.ConnectionName).
+		Infof("session killed, owner=%v", pctx.UserEmail)
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
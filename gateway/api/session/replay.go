@@ -0,0 +1,132 @@
+package sessionapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/hoophq/hoop/common/asciicast"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	sessionstorage "github.com/hoophq/hoop/gateway/storagev2/session"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+// ReplaySession
+//
+//	@Summary		Replay Session
+//	@Description	Obtain the events of a session with its original timing to replay it.
+//	@Description	The `asciicast` format is compatible with the asciinema player (https://asciinema.org).
+//	@Tags			Core
+//	@Produce		json,application/x-asciicast
+//	@Param			session_id	path		string						true	"The id of the resource"
+//	@Param			params		query		openapi.SessionReplayParams	false	"-"
+//	@Success		200			{object}	openapi.SessionReplay
+//	@Failure		404,422,500	{object}	openapi.HTTPError
+//	@Router			/sessions/{session_id}/replay [get]
+func ReplaySession(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	log := pgusers.ContextLogger(c)
+
+	sessionID := c.Param("session_id")
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "asciicast" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "format must be one of: json, asciicast"})
+		return
+	}
+	width, _ := strconv.Atoi(c.Query("width"))
+	height, _ := strconv.Atoi(c.Query("height"))
+	if width <= 0 || height <= 0 {
+		width, height = asciicast.DefaultWidth, asciicast.DefaultHeight
+	}
+	eventTypes := parseEventTypes(c.Query("events"))
+
+	session, err := sessionstorage.FindOne(ctx, sessionID)
+	if err != nil {
+		log.Errorf("failed fetching session, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching session"})
+		return
+	}
+	if session == nil || (!ctx.IsAdminUser() && session.UserID != ctx.UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "not found"})
+		return
+	}
+
+	header, events := parseSessionToAsciicast(session, eventTypes)
+	header.Width, header.Height = width, height
+	log.With("sid", sessionID).Infof("session replay request, format=%v, events=%v", format, len(events))
+	if format == "asciicast" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.cast", sessionID))
+		c.Header("Content-Type", "application/x-asciicast")
+		enc, err := asciicast.NewEncoder(c.Writer, header)
+		for _, ev := range events {
+			if err != nil {
+				break
+			}
+			err = enc.Encode(ev)
+		}
+		if err != nil {
+			log.With("sid", sessionID).Warnf("failed writing asciicast events, err=%v", err)
+		}
+		return
+	}
+
+	replay := openapi.SessionReplay{
+		ID:           session.ID,
+		Version:      header.Version,
+		Width:        header.Width,
+		Height:       header.Height,
+		Duration:     header.Duration,
+		StartSession: session.StartSession,
+		Events:       []openapi.SessionEventStream{},
+	}
+	for _, ev := range events {
+		replay.Events = append(replay.Events, openapi.SessionEventStream{ev.Time, ev.Type, ev.Data})
+	}
+	c.PureJSON(http.StatusOK, replay)
+}
+
+// parseEventTypes parses a list of event types separated by comma,
+// it defaults to output and error events
+func parseEventTypes(events string) (eventTypes []string) {
+	for _, e := range strings.Split(events, ",") {
+		if e == "i" || e == "o" || e == "e" {
+			eventTypes = append(eventTypes, e)
+		}
+	}
+	if len(eventTypes) == 0 {
+		eventTypes = []string{"o", "e"}
+	}
+	return
+}
+
+// parseSessionToAsciicast converts the event stream of a session to asciicast events.
+// Error events are converted to output events since a terminal doesn't distinguish them.
+func parseSessionToAsciicast(s *types.Session, eventTypes []string) (asciicast.Header, []asciicast.Event) {
+	header := asciicast.Header{
+		Version:   asciicast.Version,
+		Width:     asciicast.DefaultWidth,
+		Height:    asciicast.DefaultHeight,
+		Timestamp: s.StartSession.Unix(),
+		Title:     fmt.Sprintf("%s (%s) - %s", s.Connection, s.Verb, s.UserEmail),
+	}
+	if s.EndSession != nil {
+		header.Duration = s.EndSession.Sub(s.StartSession).Seconds()
+	}
+	var events []asciicast.Event
+	for _, event := range sessionEvents(s, eventTypes) {
+		eventTime, _ := event[0].(float64)
+		eventData, _ := event[2].([]byte)
+		ev := asciicast.Event{Time: eventTime, Type: asciicast.OutputEvent, Data: string(eventData)}
+		if event[1] == "i" {
+			ev.Type = asciicast.InputEvent
+		}
+		events = append(events, ev)
+	}
+	return header, events
+}
@@ -95,6 +95,15 @@ func RunReviewedExec(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "review not approved or already executed"})
 		return
 	}
+	if review.IsBreakGlass() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "the session of this review was executed using break-glass access"})
+		return
+	}
+	if review.ScheduledAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("review is scheduled to run at %s",
+			review.ScheduledAt.Format(time.RFC3339))})
+		return
+	}
 
 	p, err := pgplugins.New().FetchOne(ctx, plugintypes.PluginReviewName)
 	if err != nil {
@@ -133,6 +142,7 @@ func RunReviewedExec(c *gin.Context) {
 		ConnectionName: session.Connection,
 		BearerToken:    getAccessToken(c),
 		UserAgent:      userAgent,
+		ClientIP:       c.ClientIP(),
 	})
 	if err != nil {
 		log.Error(err)
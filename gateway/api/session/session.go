@@ -1,6 +1,7 @@
 package sessionapi
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -33,6 +34,9 @@ type SessionPostBody struct {
 	Labels     types.SessionLabels `json:"labels"`
 	Metadata   map[string]any      `json:"metadata"`
 	ClientArgs []string            `json:"client_args"`
+	// the reason and the ticket reference to open the session
+	Justification string `json:"justification"`
+	TicketID      string `json:"ticket_id"`
 }
 
 // RunExec
@@ -91,6 +95,9 @@ func Post(c *gin.Context) {
 		ConnectionName: conn.Name,
 		BearerToken:    getAccessToken(c),
 		UserAgent:      userAgent,
+		Justification:  body.Justification,
+		TicketID:       body.TicketID,
+		ClientIP:       c.ClientIP(),
 	})
 	if err != nil {
 		log.Error(err)
@@ -176,6 +183,26 @@ func List(c *gin.Context) {
 	ctx := storagev2.ParseContext(c)
 	log := pgusers.ContextLogger(c)
 
+	options, err := parseSessionOptions(c, ctx)
+	if err != nil {
+		log.Warnf("failed listing sessions, err=%v", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf("failed listing sessions, %v", err)})
+		return
+	}
+	sessionList, err := sessionstorage.List(ctx, options...)
+	if err != nil {
+		log.Errorf("failed listing sessions, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing sessions"})
+		return
+	}
+
+	c.PureJSON(http.StatusOK, sessionList)
+}
+
+// parseSessionOptions parses the filter options from the query string.
+// Non admin users are only allowed to see their own sessions.
+func parseSessionOptions(c *gin.Context, ctx *storagev2.Context) ([]*openapi.SessionOption, error) {
 	var options []*openapi.SessionOption
 	for _, optKey := range openapi.AvailableSessionOptions {
 		if queryOptVal, ok := c.GetQuery(string(optKey)); ok {
@@ -184,9 +211,7 @@ func List(c *gin.Context) {
 			case openapi.SessionOptionStartDate, openapi.SessionOptionEndDate:
 				optTimeVal, err := time.Parse(time.RFC3339, queryOptVal)
 				if err != nil {
-					log.Warnf("failed listing sessions, wrong start_date option value, err=%v", err)
-					c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "failed listing sessions, start_date in wrong format"})
-					return
+					return nil, fmt.Errorf("%v in wrong format", optKey)
 				}
 				optVal = optTimeVal
 			case openapi.SessionOptionLimit, openapi.SessionOptionOffset:
@@ -208,15 +233,7 @@ func List(c *gin.Context) {
 	if !ctx.IsAdminUser() {
 		options = append(options, WithOption(openapi.SessionOptionUser, ctx.UserID))
 	}
-	sessionList, err := sessionstorage.List(ctx, options...)
-	if err != nil {
-		log.Errorf("failed listing sessions, err=%v", err)
-		sentry.CaptureException(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing sessions"})
-		return
-	}
-
-	c.PureJSON(http.StatusOK, sessionList)
+	return options, nil
 }
 
 // GetSessionByID
@@ -247,7 +264,17 @@ func Get(c *gin.Context) {
 		return
 	}
 
-	fileExt := c.Query("extension")
+	fileExt, format := c.Query("extension"), c.Query("format")
+	if format != "" {
+		ext, ok := exportFormatExtensions[format]
+		if !ok {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "format must be one of: jsonl, csv, asciicast"})
+			return
+		}
+		if fileExt == "" {
+			fileExt = ext
+		}
+	}
 	if fileExt != "" {
 		if ctx.ApiURL == "" {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating download link, missing api url"})
@@ -256,11 +283,12 @@ func Get(c *gin.Context) {
 		hash := sha256.Sum256([]byte(uuid.NewString()))
 		downloadToken := hex.EncodeToString(hash[:])
 		expireAtTime := time.Now().UTC().Add(defaultDownloadExpireTime).Format(time.RFC3339Nano)
-		downloadURL := fmt.Sprintf("%s/api/sessions/%s/download?token=%s&extension=%v&newline=%v&event-time=%v&events=%v",
+		downloadURL := fmt.Sprintf("%s/api/sessions/%s/download?token=%s&extension=%v&format=%v&newline=%v&event-time=%v&events=%v",
 			ctx.ApiURL,
 			sessionID,
 			downloadToken,
 			fileExt,
+			format,
 			c.Query("newline"),
 			c.Query("event-time"),
 			c.Query("events"),
@@ -288,20 +316,26 @@ func Get(c *gin.Context) {
 	// TODO: refactor to use the postgrest direct function
 	if review != nil {
 		session.Review = &types.ReviewJSON{
-			Id:               review.Id,
-			OrgId:            review.OrgId,
-			CreatedAt:        review.CreatedAt,
-			Type:             review.Type,
-			Session:          review.Session,
-			Input:            review.Input,
-			InputEnvVars:     review.InputEnvVars,
-			InputClientArgs:  review.InputClientArgs,
-			AccessDuration:   review.AccessDuration,
-			Status:           review.Status,
-			RevokeAt:         review.RevokeAt,
-			ReviewOwner:      review.ReviewOwner,
-			Connection:       review.Connection,
-			ReviewGroupsData: review.ReviewGroupsData,
+			Id:                      review.Id,
+			OrgId:                   review.OrgId,
+			CreatedAt:               review.CreatedAt,
+			Type:                    review.Type,
+			Session:                 review.Session,
+			Input:                   review.Input,
+			InputEnvVars:            review.InputEnvVars,
+			InputClientArgs:         review.InputClientArgs,
+			AccessDuration:          review.AccessDuration,
+			Status:                  review.Status,
+			RevokeAt:                review.RevokeAt,
+			ReviewOwner:             review.ReviewOwner,
+			Connection:              review.Connection,
+			ReviewGroupsData:        review.ReviewGroupsData,
+			Workflow:                review.Workflow,
+			ExpiresAt:               review.ExpiresAt,
+			ScheduledAt:             review.ScheduledAt,
+			BreakGlassJustification: review.BreakGlassJustification,
+			Justification:           review.Justification,
+			TicketID:                review.TicketID,
 		}
 	}
 
@@ -349,20 +383,12 @@ func DownloadSession(c *gin.Context) {
 	sid := c.Param("session_id")
 	requestToken := c.Query("token")
 	fileExt := c.Query("extension")
+	format := c.Query("format")
 	withLineBreak := c.Query("newline") == "1"
 	withEventTime := c.Query("event-time") == "1"
 	jsonFmt := strings.HasSuffix(fileExt, "json")
 	csvFmt := strings.HasSuffix(fileExt, "csv")
-	var eventTypes []string
-	for _, e := range strings.Split(c.Query("events"), ",") {
-		if e == "i" || e == "o" || e == "e" {
-			eventTypes = append(eventTypes, e)
-		}
-	}
-	if len(eventTypes) == 0 {
-		// default to output, err
-		eventTypes = []string{"o", "e"}
-	}
+	eventTypes := parseEventTypes(c.Query("events"))
 
 	store, _ := downloadTokenStore.Pop(sid).(map[string]any)
 	if len(store) == 0 {
@@ -401,7 +427,7 @@ func DownloadSession(c *gin.Context) {
 		fmt.Sprintf("%v", store["context-org-id"]))
 	ctx.UserGroups, _ = store["context-user-groups"].([]string)
 	log.With(
-		"sid", sid, "ext", fileExt,
+		"sid", sid, "ext", fileExt, "format", format,
 		"line-break", withLineBreak, "event-time", withEventTime,
 		"jsonfmt", jsonFmt, "csvfmt", csvFmt, "event-types", eventTypes).
 		Infof("session download request, valid=%v, org=%v, user=%v, groups=%#v, user-agent=%v",
@@ -421,6 +447,26 @@ func DownloadSession(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		var buf bytes.Buffer
+		if err := exportSession(&buf, session, format, eventTypes); err != nil {
+			status := http.StatusInternalServerError
+			if err == errExportFormatNotSupported {
+				status = http.StatusUnprocessableEntity
+			}
+			log.With("sid", sid).Warnf("failed exporting session, format=%v, err=%v", format, err)
+			c.JSON(status, gin.H{"status": status, "message": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", sid, fileExt))
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Accept-Length", fmt.Sprintf("%d", buf.Len()))
+		wrote, err := c.Writer.Write(buf.Bytes())
+		log.With("sid", sid).Infof("session exported, format=%v, wrote=%v, success=%v, err=%v",
+			format, wrote, err == nil, err)
+		return
+	}
+
 	opts := sessionParseOption{withLineBreak, withEventTime, jsonFmt, csvFmt, eventTypes}
 	output := parseSessionToFile(session, opts)
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", sid, fileExt))
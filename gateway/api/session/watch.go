@@ -0,0 +1,128 @@
+package sessionapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/hoophq/hoop/common/asciicast"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	"github.com/hoophq/hoop/gateway/session/livestream"
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/transport/streamclient"
+)
+
+const (
+	watchWriteTimeout = time.Second * 10
+	watchPingInterval = time.Second * 30
+)
+
+var watchUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024 * 32,
+}
+
+// WatchSession
+//
+//	@Summary		Watch Session
+//	@Description	Follow a live session in real time (read-only) through a websocket connection.
+//	@Description	The first message is the asciicast (v2) header followed by one message per event in the format: `[<event-time>, <event-type>, <content>]`.
+//	@Description	The connection is closed by the server when the session ends.
+//	@Tags			Core
+//	@Param			session_id	path	string	true	"The id of the resource"
+//	@Param			events		query	string	false	"Choose the type of events to include: input (i), output (o) and error (e)"	default(i,o,e)
+//	@Success		101
+//	@Failure		404	{object}	openapi.HTTPError
+//	@Router			/sessions/{session_id}/watch [get]
+func WatchSession(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	log := pgusers.ContextLogger(c)
+
+	sessionID := c.Param("session_id")
+	stream := streamclient.GetProxyStream(sessionID)
+	if stream == nil || stream.PluginContext().OrgID != ctx.OrgID {
+		c.JSON(http.StatusNotFound, gin.H{"message": "live session not found"})
+		return
+	}
+	eventTypes := parseEventTypes(c.DefaultQuery("events", "i,o,e"))
+	pctx := stream.PluginContext()
+	startedAt := stream.StartedAt()
+
+	sub := livestream.Subscribe(sessionID)
+	defer sub.Close()
+	conn, err := watchUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already replies to the client
+		log.With("sid", sessionID).Warnf("failed upgrading watch connection, err=%v", err)
+		return
+	}
+	defer conn.Close()
+	log.With("sid", sessionID, "connection", pctx.ConnectionName).
+		Infof("watching session, owner=%v", pctx.UserEmail)
+
+	// the client is read-only, reading is required to process control messages
+	readErrCh := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				readErrCh <- err
+				return
+			}
+		}
+	}()
+
+	err = writeWatchMessage(conn, asciicast.Header{
+		Version:   asciicast.Version,
+		Width:     asciicast.DefaultWidth,
+		Height:    asciicast.DefaultHeight,
+		Timestamp: startedAt.Unix(),
+		Title:     fmt.Sprintf("%s (%s) - %s", pctx.ConnectionName, pctx.ClientVerb, pctx.UserEmail),
+	})
+	pingTicker := time.NewTicker(watchPingInterval)
+	defer pingTicker.Stop()
+	for err == nil {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				reason := "session ended"
+				if sub.Err() != nil {
+					reason = sub.Err().Error()
+				}
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason),
+					time.Now().Add(watchWriteTimeout))
+				log.With("sid", sessionID).Infof("stopped watching session, reason=%v", reason)
+				return
+			}
+			if !slices.Contains(eventTypes, string(ev.Type)) {
+				continue
+			}
+			event := asciicast.Event{
+				Time: ev.Time.Sub(startedAt).Seconds(),
+				Type: asciicast.OutputEvent,
+				Data: string(ev.Payload),
+			}
+			if ev.Type == 'i' {
+				event.Type = asciicast.InputEvent
+			}
+			err = writeWatchMessage(conn, event)
+		case <-pingTicker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(watchWriteTimeout))
+		case err = <-readErrCh:
+		}
+	}
+	log.With("sid", sessionID).Infof("stopped watching session, reason=%v", err)
+}
+
+func writeWatchMessage(conn *websocket.Conn, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/hoophq/hoop/gateway/pgrest"
 	pgaudit "github.com/hoophq/hoop/gateway/pgrest/audit"
 	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	pgusertokens "github.com/hoophq/hoop/gateway/pgrest/usertokens"
 	"github.com/hoophq/hoop/gateway/storagev2"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
 )
@@ -161,6 +162,15 @@ func Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	// the personal access tokens of inactive users must not be used again
+	if existingUser.Status != string(types.UserStatusActive) {
+		if err := pgusertokens.New().RevokeAllByUser(ctx, existingUser.ID); err != nil {
+			log.Errorf("failed revoking tokens of user %s, err=%v", userID, err)
+			sentry.CaptureException(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed revoking tokens of the user"})
+			return
+		}
+	}
 
 	analytics.New().Identify(&types.APIContext{
 		OrgID:      ctx.OrgID,
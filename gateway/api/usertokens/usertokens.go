@@ -0,0 +1,209 @@
+package apiusertokens
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	pgusertokens "github.com/hoophq/hoop/gateway/pgrest/usertokens"
+	"github.com/hoophq/hoop/gateway/security/usertoken"
+	"github.com/hoophq/hoop/gateway/storagev2"
+)
+
+const (
+	maxNameSize      = 128
+	maxTokensPerUser = 50
+)
+
+// ListTokens
+//
+//	@Summary		List Personal Access Tokens
+//	@Description	List the personal access tokens of the authenticated user, including the revoked and expired ones
+//	@Tags			User Management
+//	@Produce		json
+//	@Success		200	{array}		openapi.UserToken
+//	@Failure		400,500	{object}	openapi.HTTPError
+//	@Router			/tokens [get]
+func List(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	usr, ok := fetchUser(c, ctx)
+	if !ok {
+		return
+	}
+	items, err := pgusertokens.New().FetchAllByUser(ctx, usr.ID)
+	if err != nil {
+		log.Errorf("failed listing user tokens, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing tokens"})
+		return
+	}
+	tokens := []openapi.UserToken{}
+	for _, t := range items {
+		tokens = append(tokens, toOpenAPI(&t))
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateToken
+//
+//	@Summary		Create Personal Access Token
+//	@Description	Create a personal access token owned by the authenticated user to automate the access to the gateway.
+//	@Description	The token is accepted as a bearer token by the api and by the clients of the gateway and it's only returned once.
+//	@Tags			User Management
+//	@Accept			json
+//	@Produce		json
+//	@Param			request				body		openapi.UserTokenRequest	true	"The request body resource"
+//	@Success		201					{object}	openapi.UserToken
+//	@Failure		400,403,409,422,500	{object}	openapi.HTTPError
+//	@Router			/tokens [post]
+func Create(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	var req openapi.UserTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxNameSize {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf("the name must contain between 1 and %v characters", maxNameSize)})
+		return
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > usertoken.MaxExpiresInDays {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf("expires_in_days must be between 1 and %v", usertoken.MaxExpiresInDays)})
+		return
+	}
+	scopes, err := usertoken.ParseScopes(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	if slices.Contains(scopes, usertoken.ScopeAdmin) && !ctx.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"message": "only admin users could create tokens with the admin scope"})
+		return
+	}
+	usr, ok := fetchUser(c, ctx)
+	if !ok {
+		return
+	}
+	existingTokens, err := pgusertokens.New().FetchAllByUser(ctx, usr.ID)
+	if err != nil {
+		log.Errorf("failed listing user tokens, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed listing tokens"})
+		return
+	}
+	if slices.ContainsFunc(existingTokens, func(t pgrest.UserToken) bool { return t.Name == req.Name }) {
+		c.JSON(http.StatusConflict, gin.H{"message": fmt.Sprintf("token %v already exists", req.Name)})
+		return
+	}
+	now := time.Now().UTC()
+	activeTokens := slices.DeleteFunc(existingTokens, func(t pgrest.UserToken) bool { return !usertoken.IsActive(&t, now) })
+	if len(activeTokens) >= maxTokensPerUser {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf("reached the limit of %v active tokens per user, revoke unused tokens", maxTokensPerUser)})
+		return
+	}
+
+	token, err := usertoken.NewToken()
+	if err != nil {
+		log.Errorf("failed generating user token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed generating token"})
+		return
+	}
+	expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+	obj, err := pgusertokens.New().Create(ctx, usr.ID, req.Name, usertoken.Hash(token), scopes, &expiresAt)
+	if err != nil {
+		log.Errorf("failed persisting user token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed persisting token"})
+		return
+	}
+	resp := toOpenAPI(obj)
+	resp.Token = token
+	c.JSON(http.StatusCreated, resp)
+}
+
+// RevokeToken
+//
+//	@Summary		Revoke Personal Access Token
+//	@Description	Revoke a personal access token of the authenticated user, admin users could revoke tokens of any user.
+//	@Tags			User Management
+//	@Param			id	path	string	true	"The id of the token"
+//	@Success		204
+//	@Failure		404,500	{object}	openapi.HTTPError
+//	@Router			/tokens/{id} [delete]
+func Revoke(c *gin.Context) {
+	ctx := storagev2.ParseContext(c)
+	tokenID := c.Param("id")
+	if _, err := uuid.Parse(tokenID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "token not found"})
+		return
+	}
+	obj, err := pgusertokens.New().FetchOne(ctx, tokenID)
+	if err != nil {
+		log.Errorf("failed fetching user token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching token"})
+		return
+	}
+	if obj != nil && !ctx.IsAdmin() {
+		usr, err := pgusers.New().FetchOneBySubject(ctx, ctx.UserID)
+		if err != nil {
+			log.Errorf("failed fetching user, err=%v", err)
+			sentry.CaptureException(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user"})
+			return
+		}
+		if usr == nil || usr.ID != obj.UserID {
+			obj = nil
+		}
+	}
+	if obj == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "token not found"})
+		return
+	}
+	if err := pgusertokens.New().Revoke(ctx, obj.ID); err != nil {
+		log.Errorf("failed revoking user token, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed revoking token"})
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// fetchUser returns the user owning the tokens, service accounts don't own tokens
+func fetchUser(c *gin.Context, ctx *storagev2.Context) (*pgrest.User, bool) {
+	usr, err := pgusers.New().FetchOneBySubject(ctx, ctx.UserID)
+	if err != nil {
+		log.Errorf("failed fetching user, err=%v", err)
+		sentry.CaptureException(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed fetching user"})
+		return nil, false
+	}
+	if usr == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "personal access tokens are only available to users"})
+		return nil, false
+	}
+	return usr, true
+}
+
+func toOpenAPI(t *pgrest.UserToken) openapi.UserToken {
+	return openapi.UserToken{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.GetExpiresAt(),
+		LastUsedAt: t.GetLastUsedAt(),
+		RevokedAt:  t.GetRevokedAt(),
+		CreatedAt:  t.GetCreatedAt(),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"strings"
@@ -37,6 +38,7 @@ type Config struct {
 	apiHost               string
 	apiScheme             string
 	webappUsersManagement string
+	trustedProxies        []string
 
 	isLoaded bool
 }
@@ -84,6 +86,10 @@ func Load() error {
 	if err != nil {
 		return err
 	}
+	trustedProxies, err := loadTrustedProxies()
+	if err != nil {
+		return err
+	}
 	webappUsersManagement := os.Getenv("WEBAPP_USERS_MANAGEMENT")
 	if webappUsersManagement == "" {
 		webappUsersManagement = "on"
@@ -101,6 +107,7 @@ func Load() error {
 		gcpDLPJsonCredentials: gcpJsonCred,
 		webhookAppKey:         os.Getenv("WEBHOOK_APPKEY"),
 		webappUsersManagement: webappUsersManagement,
+		trustedProxies:        trustedProxies,
 		isLoaded:              true,
 	}
 	return nil
@@ -125,6 +132,21 @@ func loadPostgresCredentials() (*pgCredentials, error) {
 	return &pgCredentials{connectionString: pgConnectionURI, username: pgUser}, nil
 }
 
+func loadTrustedProxies() ([]string, error) {
+	var proxies []string
+	for _, addr := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(addr); err != nil && net.ParseIP(addr) == nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES env has an invalid ip or cidr %q", addr)
+		}
+		proxies = append(proxies, addr)
+	}
+	return proxies, nil
+}
+
 func loadAskAICredentials() (*url.URL, error) {
 	askAICred := os.Getenv("ASK_AI_CREDENTIALS")
 	if askAICred == "" {
@@ -196,6 +218,10 @@ func (c Config) PostgRESTRole() string         { return c.pgCred.postgrestRole }
 
 func (c Config) MigrationPathFiles() string { return c.migrationPathFiles }
 
+// TrustedProxies returns the addresses (ip or cidr) of the proxies allowed
+// to forward the address of clients in the X-Forwarded-For header
+func (c Config) TrustedProxies() []string { return c.trustedProxies }
+
 func (c Config) WebappUsersManagement() string { return c.webappUsersManagement }
 func (c Config) IsAskAIAvailable() bool        { return c.askAICredentials != nil }
 func (c Config) AskAIApiURL() (u string) {
@@ -40,6 +40,9 @@ type clientExec struct {
 	ctx        context.Context
 	cancelFn   context.CancelFunc
 	sessionID  string
+	// the reason and the ticket reference to open the session
+	justification string
+	ticketID      string
 }
 
 type Options struct {
@@ -49,16 +52,21 @@ type Options struct {
 	BearerToken    string
 	Origin         string
 	UserAgent      string
+	Justification  string
+	TicketID       string
+	// ClientIP is the address of the client requesting the execution
+	ClientIP string
 }
 
 type Response struct {
-	HasReview         bool   `json:"has_review"`
-	SessionID         string `json:"session_id"`
-	Output            string `json:"output"`
-	OutputStatus      string `json:"output_status"`
-	Truncated         bool   `json:"truncated"`
-	ExecutionTimeMili int64  `json:"execution_time"`
-	ExitCode          int    `json:"exit_code"`
+	HasReview          bool   `json:"has_review"`
+	SessionID          string `json:"session_id"`
+	Output             string `json:"output"`
+	OutputStatus       string `json:"output_status"`
+	Truncated          bool   `json:"truncated"`
+	ExecutionTimeMili  int64  `json:"execution_time"`
+	ExitCode           int    `json:"exit_code"`
+	StepUpAuthRequired bool   `json:"step_up_auth_required"`
 
 	err error
 }
@@ -103,26 +111,31 @@ func New(opts *Options) (*clientExec, error) {
 	if opts.UserAgent != "" {
 		userAgent = opts.UserAgent
 	}
-	client, err := grpc.ConnectLocalhost(
-		opts.BearerToken,
-		userAgent,
+	clientOpts := []*grpc.ClientOptions{
 		grpc.WithOption(grpc.OptionConnectionName, opts.ConnectionName),
 		grpc.WithOption("origin", opts.Origin),
 		grpc.WithOption("verb", pb.ClientVerbExec),
 		grpc.WithOption("session-id", opts.SessionID),
-	)
+	}
+	// the gateway trusts this value only from local connections
+	if opts.ClientIP != "" {
+		clientOpts = append(clientOpts, grpc.WithOption("client-ip", opts.ClientIP))
+	}
+	client, err := grpc.ConnectLocalhost(opts.BearerToken, userAgent, clientOpts...)
 	if err != nil {
 		_ = wlog.Close()
 		return nil, err
 	}
 	ctx, cancelFn := context.WithCancel(context.Background())
 	return &clientExec{
-		folderName: folderName,
-		wlog:       wlog,
-		client:     client,
-		ctx:        ctx,
-		cancelFn:   cancelFn,
-		sessionID:  opts.SessionID}, nil
+		folderName:    folderName,
+		wlog:          wlog,
+		client:        client,
+		ctx:           ctx,
+		cancelFn:      cancelFn,
+		sessionID:     opts.SessionID,
+		justification: opts.Justification,
+		ticketID:      opts.TicketID}, nil
 }
 
 func (c *clientExec) Run(inputPayload []byte, clientEnvVars map[string]string, clientArgs ...string) *Response {
@@ -141,6 +154,12 @@ func (c *clientExec) Run(inputPayload []byte, clientEnvVars map[string]string, c
 		}
 		openSessionSpec[pb.SpecClientExecArgsKey] = encClientArgs
 	}
+	if c.justification != "" {
+		openSessionSpec[pb.SpecSessionJustification] = []byte(c.justification)
+	}
+	if c.ticketID != "" {
+		openSessionSpec[pb.SpecSessionTicketID] = []byte(c.ticketID)
+	}
 	now := time.Now().UTC()
 	resp := c.run(inputPayload, openSessionSpec)
 	resp.ExecutionTimeMili = time.Since(now).Milliseconds()
@@ -149,6 +168,7 @@ func (c *clientExec) Run(inputPayload []byte, clientEnvVars map[string]string, c
 	if resp.err != nil {
 		resp.Output = resp.err.Error()
 		resp.OutputStatus = "failed"
+		resp.StepUpAuthRequired = pb.IsStepUpAuthError(resp.err)
 	}
 	// mark as failed when the exit code is above 0 or different from nil
 	if resp.ExitCode != nilExitCode && resp.ExitCode > 0 {
@@ -180,7 +200,7 @@ func (c *clientExec) run(inputPayload []byte, openSessionSpec map[string][]byte)
 
 		pkt, err := dstream.Recv()
 		if err != nil {
-			return newErr(err.Error())
+			return newRawErr(err)
 		}
 		if pkt == nil {
 			continue
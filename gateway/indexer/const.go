@@ -18,6 +18,7 @@ var registeredFields = []string{
 	searchquery.QualifierFilterUser, searchquery.QualifierFilterVerb,
 	searchquery.QualifierFilterSize, searchquery.QualifierFilterDuration,
 	searchquery.QualifierFilterStartDate, searchquery.QualifierFilterCompleteDate,
+	searchquery.QualifierFilterTicket,
 }
 
 var defaultFields = []string{
@@ -25,4 +26,5 @@ var defaultFields = []string{
 	searchquery.QualifierFilterUser, searchquery.QualifierFilterVerb,
 	searchquery.QualifierFilterSize, searchquery.QualifierFilterDuration,
 	searchquery.QualifierFilterStartDate, searchquery.QualifierFilterCompleteDate,
+	searchquery.QualifierFilterTicket,
 }
@@ -44,6 +44,7 @@ type Session struct {
 	StartDate         string `json:"started"`
 	EndDate           string `json:"completed"`
 	Duration          int64  `json:"duration"`
+	TicketID          string `json:"ticket"`
 }
 
 func newDefautFieldMapping(fieldType, fieldAnalyzer string) *mapping.FieldMapping {
@@ -62,6 +63,7 @@ func newSessionMapping() mapping.IndexMapping {
 	m.DefaultMapping.AddFieldMappingsAt(searchquery.QualifierFilterConnection, newDefautFieldMapping("text", "keyword"))
 	m.DefaultMapping.AddFieldMappingsAt(searchquery.QualifierFilterConnectionType, newDefautFieldMapping("text", "keyword"))
 	m.DefaultMapping.AddFieldMappingsAt(searchquery.QualifierFilterVerb, newDefautFieldMapping("text", "keyword"))
+	m.DefaultMapping.AddFieldMappingsAt(searchquery.QualifierFilterTicket, newDefautFieldMapping("text", "keyword"))
 	m.DefaultMapping.AddFieldMappingsAt(searchquery.QualifierFilterSize, newDefautFieldMapping("number", ""))
 
 	stdinFieldMapping := newDefautFieldMapping("text", "en")
@@ -184,6 +186,10 @@ func (i *Indexer) Index(sessionID string, data any) error {
 	return i.idx.Index(sessionID, data)
 }
 
+func (i *Indexer) Delete(sessionID string) error {
+	return i.idx.Delete(sessionID)
+}
+
 func (i *Indexer) Search(req *bleve.SearchRequest) (*bleve.SearchResult, error) {
 	ctx, cancelFn := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancelFn()
@@ -174,6 +174,7 @@ func parseSessionToIndexObject(orgID string, s *types.Session) *Session {
 		}
 	}
 	durationInSecs := int64(s.EndSession.Sub(s.StartSession).Seconds())
+	ticketID, _ := s.Metadata[plugintypes.MetadataTicketIDKey].(string)
 	return &Session{
 		OrgID:             orgID,
 		ID:                s.ID,
@@ -191,6 +192,7 @@ func parseSessionToIndexObject(orgID string, s *types.Session) *Session {
 		StartDate: s.StartSession.Format(time.RFC3339),
 		EndDate:   s.EndSession.Format(time.RFC3339),
 		Duration:  durationInSecs,
+		TicketID:  ticketID,
 	}
 }
 
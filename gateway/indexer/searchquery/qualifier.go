@@ -72,7 +72,7 @@ func (q *qualifier) parseQualifierFilter() (filter query.Query, err error) {
 	}
 	switch q.attribute {
 	case QualifierFilterUser, QualifierFilterConnection, QualifierFilterConnectionType,
-		QualifierFilterVerb, QualifierFilterSession:
+		QualifierFilterVerb, QualifierFilterSession, QualifierFilterTicket:
 		filter = &query.TermQuery{
 			Term:     q.value,
 			FieldVal: q.attribute,
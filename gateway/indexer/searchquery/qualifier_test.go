@@ -51,6 +51,13 @@ func TestParseQualifier(t *testing.T) {
 				"user":            {FieldVal: "user", Term: "johndoe@corp.tld"},
 			},
 		},
+		{
+			msg:   "it must parse filters ticket:JIRA-123",
+			query: "ticket:JIRA-123",
+			want: map[string]*query.TermQuery{
+				"ticket": {FieldVal: "ticket", Term: "JIRA-123"},
+			},
+		},
 		{
 			msg:   "it must add a qualifier binding the scope to the provided user",
 			query: "connection:bash",
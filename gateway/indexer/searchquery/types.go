@@ -32,6 +32,7 @@ const (
 	QualifierFilterDuration       = "duration"
 	QualifierFilterStartDate      = "started"
 	QualifierFilterCompleteDate   = "completed"
+	QualifierFilterTicket         = "ticket"
 )
 
 var (
@@ -52,6 +53,7 @@ var registeredQualifiers = map[string]any{
 	QualifierFilterDuration:       nil,
 	QualifierFilterStartDate:      nil,
 	QualifierFilterCompleteDate:   nil,
+	QualifierFilterTicket:         nil,
 
 	QualifierQueryFuzzy: nil,
 }
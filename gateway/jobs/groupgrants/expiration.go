@@ -0,0 +1,54 @@
+package jobgroupgrants
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pggroupgrants "github.com/hoophq/hoop/gateway/pgrest/groupgrants"
+)
+
+const (
+	// the max number of grants expired in each run
+	maxExpiredGrants = 500
+	revokedBySystem  = "system"
+)
+
+// Run schedules the expiration of the time-bound group memberships every minute.
+// The groups of a user ignore expired memberships, the job removes them and records it in the grants history.
+func Run() {
+	log.Infof("starting group grants scheduler, expiration (every 1m)")
+	scheduler := gocron.NewScheduler(time.UTC)
+	_, err := scheduler.
+		SingletonMode().
+		Every(1).Minute().
+		DoWithJobDetails(ExpireGroupGrants)
+	if err != nil {
+		log.Fatalf("failed scheduling group grants expiration job, reason=%v", err)
+	}
+	scheduler.StartAsync()
+}
+
+// ExpireGroupGrants removes the memberships of the active grants that are past their expiration time
+func ExpireGroupGrants(_ gocron.Job) {
+	log := log.With("job", "groupgrantsexpiration")
+	items, err := pggroupgrants.New().FetchExpired(time.Now().UTC(), maxExpiredGrants)
+	if err != nil {
+		log.Warnf("failed fetching expired group grants, err=%v", err)
+		return
+	}
+	for _, grant := range items {
+		ctx := pgrest.NewOrgContext(grant.OrgID)
+		err := pggroupgrants.New().Revoke(ctx, grant, pggroupgrants.StatusExpired, revokedBySystem)
+		switch err {
+		case nil:
+			log.With("org", grant.OrgID, "user", grant.UserEmail).Infof("group grant %v expired, group=%v, expires-at=%v",
+				grant.ID, grant.GroupName, grant.GetExpiresAt().Format(time.RFC3339))
+		// it was revoked in the meantime
+		case pgrest.ErrNotFound:
+		default:
+			log.With("org", grant.OrgID, "user", grant.UserEmail).Warnf("failed expiring group grant %v, err=%v", grant.ID, err)
+		}
+	}
+}
@@ -1,16 +1,32 @@
 package jobs
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-co-op/gocron"
 	"github.com/hoophq/hoop/common/log"
+	jobretention "github.com/hoophq/hoop/gateway/jobs/retention"
 	jobsessions "github.com/hoophq/hoop/gateway/jobs/sessions"
+	"github.com/hoophq/hoop/gateway/session/blobstorage"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 )
 
+// Load configures the dependencies shared with the gateway,
+// it must be called before running any job.
+func Load() error {
+	if err := blobstorage.Load(); err != nil {
+		return fmt.Errorf("failed loading session blob storage, reason=%v", err)
+	}
+	log.Infof("session blob storage=%v", blobstorage.Get().Name())
+	return nil
+}
+
 func Run() {
-	log.Infof("starting job scheduler (every 30m)")
+	if err := Load(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("starting job scheduler, walsessions (every 30m), retention (daily at 04:00 UTC)")
 	scheduler := gocron.NewScheduler(time.UTC)
 
 	_, err := scheduler.
@@ -20,5 +36,12 @@ func Run() {
 	if err != nil {
 		log.Fatalf("failed scheduling wal sessions job, reason=%v", err)
 	}
+	_, err = scheduler.
+		SingletonMode().
+		Cron("0 4 * * *").
+		DoWithJobDetails(jobretention.PurgeExpiredSessions)
+	if err != nil {
+		log.Fatalf("failed scheduling retention job, reason=%v", err)
+	}
 	scheduler.StartBlocking()
 }
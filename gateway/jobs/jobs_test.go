@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	jobsessions "github.com/hoophq/hoop/gateway/jobs/sessions"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/hoophq/hoop/gateway/session/blobstorage"
+	eventlogv0 "github.com/hoophq/hoop/gateway/session/eventlog/v0"
+	sessionwal "github.com/hoophq/hoop/gateway/session/wal"
+)
+
+// fakePgRest answers the requests of the wal sessions job keeping the created blobs
+type fakePgRest struct {
+	mu    sync.Mutex
+	blobs []map[string]any
+}
+
+func (f *fakePgRest) Do(req *http.Request) (*http.Response, error) {
+	statusCode := http.StatusNoContent
+	switch {
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/sessions"):
+		// the session doesn't exist
+		statusCode = http.StatusNotAcceptable
+	case req.Method == "POST" && req.URL.Path == "/blobs":
+		var blob map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&blob); err != nil {
+			return nil, err
+		}
+		f.mu.Lock()
+		f.blobs = append(f.blobs, blob)
+		f.mu.Unlock()
+		statusCode = http.StatusCreated
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(nil)),
+	}, nil
+}
+
+func writeWalSession(t *testing.T, auditPath, orgID, sid string) {
+	startDate := time.Now().UTC().Add(-time.Minute)
+	walog, err := sessionwal.OpenWriteHeader(filepath.Join(auditPath, sid+"-wal"), &sessionwal.Header{
+		OrgID:          orgID,
+		SessionID:      sid,
+		UserEmail:      "john.doe@hoop.dev",
+		ConnectionName: "pgdemo",
+		ConnectionType: "database",
+		Verb:           "exec",
+		StartDate:      &startDate,
+	})
+	if err != nil {
+		t.Fatalf("failed opening wal: %v", err)
+	}
+	defer walog.Close()
+	if err := walog.Write(eventlogv0.New(startDate, eventlogv0.InputType, 0, []byte("SELECT 1"))); err != nil {
+		t.Fatalf("failed writing event: %v", err)
+	}
+	endDate := time.Now().UTC()
+	if err := walog.Write(&eventlogv0.EventLog{CommitEndDate: &endDate, CommitError: "failed committing session"}); err != nil {
+		t.Fatalf("failed writing commit event: %v", err)
+	}
+}
+
+func TestWalSessionsWriteToConfiguredBlobStorage(t *testing.T) {
+	blobPath, auditPath := t.TempDir(), t.TempDir()
+	t.Setenv("SESSION_BLOB_STORAGE", blobstorage.TypeFilesystem)
+	t.Setenv("SESSION_BLOB_STORAGE_PATH", blobPath)
+	defer blobstorage.Set(blobstorage.NewPostgres())
+
+	fake := &fakePgRest{}
+	pgrest.WithHttpClient(fake)
+	pgrest.WithBaseURL(&url.URL{Host: "127.0.0.1:3008"})
+	defer pgrest.WithHttpClient(http.DefaultClient)
+
+	if err := Load(); err != nil {
+		t.Fatalf("failed loading jobs: %v", err)
+	}
+	orgID, sid := "ffffffff-0000-0000-0000-000000000001", "ffffffff-0000-0000-0000-000000000002"
+	writeWalSession(t, auditPath, orgID, sid)
+	jobsessions.ProcessWalSessions(auditPath, gocron.Job{})
+
+	if len(fake.blobs) != 1 {
+		t.Fatalf("expected to create one blob, got=%v", len(fake.blobs))
+	}
+	blob := fake.blobs[0]
+	if blob["blob_storage"] != blobstorage.TypeFilesystem {
+		t.Errorf("expected blob to be stored in %v, got=%v", blobstorage.TypeFilesystem, blob["blob_storage"])
+	}
+	if stream, _ := blob["blob_stream"].([]any); len(stream) != 0 {
+		t.Errorf("expected empty stream in postgres, got=%v", stream)
+	}
+	data, err := blobstorage.Get().Get(blobstorage.SessionStreamKey(orgID, sid))
+	if err != nil {
+		t.Fatalf("expected to find the stream in the filesystem storage, err=%v", err)
+	}
+	if !bytes.Contains(data, []byte(base64.StdEncoding.EncodeToString([]byte("SELECT 1")))) {
+		t.Errorf("expected input event in the stream, got=%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(auditPath, sid+"-wal")); !os.IsNotExist(err) {
+		t.Errorf("expected wal folder to be removed after processing, err=%v", err)
+	}
+}
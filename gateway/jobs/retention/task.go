@@ -0,0 +1,172 @@
+package jobretention
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/api/openapi"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgaudit "github.com/hoophq/hoop/gateway/pgrest/audit"
+	pgconnections "github.com/hoophq/hoop/gateway/pgrest/connections"
+	pgretention "github.com/hoophq/hoop/gateway/pgrest/retention"
+)
+
+const (
+	AuditEventPurge = "session-retention-purge"
+
+	// the max number of sessions purged per organization in each run
+	maxPurgeSessions = 5000
+	pageSize         = 500
+)
+
+type expiredSession struct {
+	session   pgrest.Session
+	policy    pgrest.RetentionPolicy
+	expiredAt time.Time
+}
+
+// PurgeExpiredSessions removes the content of sessions that are past the retention
+// of the policies of each organization. The purged sessions are recorded in the audit trail.
+func PurgeExpiredSessions(_ gocron.Job) {
+	log := log.With("job", "retention")
+	orgIDs, err := pgretention.New().FetchOrgIDs()
+	if err != nil {
+		log.Warnf("failed fetching organizations with retention policies, err=%v", err)
+		return
+	}
+	log.Infof("job started, organizations=%v", len(orgIDs))
+	for _, orgID := range orgIDs {
+		ctx := pgrest.NewOrgContext(orgID)
+		items, err := plan(ctx, time.Now().UTC())
+		if err != nil {
+			log.With("org", orgID).Warnf("failed planning expired sessions, err=%v", err)
+			continue
+		}
+		if len(items) == 0 {
+			continue
+		}
+		purged, purgedByPolicy, failed := purgeSessions(items, func(sess pgrest.Session) error {
+			return pgretention.New().PurgeSession(ctx, sess)
+		})
+		for sid, errMsg := range failed {
+			log.With("org", orgID, "sid", sid).Warnf("failed purging session, err=%v", errMsg)
+		}
+		metadata := map[string]any{
+			"total":    len(purged),
+			"policies": purgedByPolicy,
+			"sessions": purged,
+			"failed":   failed,
+		}
+		err = pgaudit.New().Create(pgrest.NewAuditContext(orgID, AuditEventPurge, "system").WithMetadata(metadata))
+		// the index of the gateway is locked by its process, the content of purged sessions
+		// is removed from it when the indexer job of the gateway rebuilds the index
+		log.With("org", orgID).Infof("purged sessions=%v/%v, failed=%v, audit-success=%v, audit-err=%v, "+
+			"index=deferred to the indexer job of the gateway", len(purged), len(items), len(failed), err == nil, err)
+	}
+	log.Infof("job finished")
+}
+
+// purgeSessions purges each session with purgeFn, a session that fails to be purged
+// is skipped to be retried in the next run. It returns the purged sessions,
+// the count of purged sessions by policy and the errors of failed sessions.
+func purgeSessions(items []expiredSession, purgeFn func(sess pgrest.Session) error) ([]string, map[string]int, map[string]string) {
+	purged := []string{}
+	purgedByPolicy := map[string]int{}
+	failed := map[string]string{}
+	for _, item := range items {
+		if err := purgeFn(item.session); err != nil {
+			failed[item.session.ID] = err.Error()
+			continue
+		}
+		purged = append(purged, item.session.ID)
+		purgedByPolicy[item.policy.Name]++
+	}
+	return purged, purgedByPolicy, failed
+}
+
+// Report returns the sessions that will be purged in the next run (dry-run)
+func Report(ctx pgrest.OrgContext) (*openapi.RetentionReport, error) {
+	now := time.Now().UTC()
+	items, err := plan(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	report := &openapi.RetentionReport{GeneratedAt: now, Total: len(items), Items: []openapi.RetentionReportItem{}}
+	for _, item := range items {
+		report.Items = append(report.Items, openapi.RetentionReportItem{
+			SessionID:      item.session.ID,
+			Connection:     item.session.Connection,
+			ConnectionType: item.session.ConnectionType,
+			Verb:           item.session.Verb,
+			Policy:         item.policy.Name,
+			CreatedAt:      item.session.GetCreatedAt(),
+			ExpiredAt:      item.expiredAt,
+		})
+	}
+	return report, nil
+}
+
+// plan returns the sessions of the organization which are expired based on the retention policies
+func plan(ctx pgrest.OrgContext, now time.Time) ([]expiredSession, error) {
+	policies, err := pgretention.New().FetchAll(ctx)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	connections, err := pgconnections.New().FetchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching connections, reason=%v", err)
+	}
+	connectionTags := map[string][]string{}
+	for _, conn := range connections {
+		connectionTags[conn.Name] = conn.Tags
+	}
+	minRetentionDays := policies[0].RetentionDays
+	for _, p := range policies {
+		minRetentionDays = min(minRetentionDays, p.RetentionDays)
+	}
+	createdBefore := now.AddDate(0, 0, -minRetentionDays)
+	var items []expiredSession
+	for offset := 0; len(items) < maxPurgeSessions; offset += pageSize {
+		sessionList, err := pgretention.New().FetchExpirationCandidates(ctx, createdBefore, pageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching sessions, reason=%v", err)
+		}
+		for _, s := range sessionList {
+			policy := matchPolicy(policies, s, connectionTags[s.Connection])
+			if policy == nil {
+				continue
+			}
+			expiredAt := s.GetCreatedAt().AddDate(0, 0, policy.RetentionDays)
+			if expiredAt.Before(now) && len(items) < maxPurgeSessions {
+				items = append(items, expiredSession{session: s, policy: *policy, expiredAt: expiredAt})
+			}
+		}
+		if len(sessionList) < pageSize {
+			break
+		}
+	}
+	return items, nil
+}
+
+// matchPolicy returns the policy with the longest retention that matches the session,
+// it returns nil when there's no policy for the session
+func matchPolicy(policies []pgrest.RetentionPolicy, s pgrest.Session, tags []string) (policy *pgrest.RetentionPolicy) {
+	for i, p := range policies {
+		if p.ConnectionType != "" && p.ConnectionType != s.ConnectionType {
+			continue
+		}
+		if p.Verb != "" && p.Verb != s.Verb {
+			continue
+		}
+		if p.ConnectionTag != "" && !slices.Contains(tags, p.ConnectionTag) {
+			continue
+		}
+		if policy == nil || p.RetentionDays > policy.RetentionDays {
+			policy = &policies[i]
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package jobretention
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+func TestMatchPolicy(t *testing.T) {
+	policies := []pgrest.RetentionPolicy{
+		{Name: "exec", Verb: "exec", RetentionDays: 90},
+		{Name: "prod-databases", ConnectionType: "database", ConnectionTag: "prod", RetentionDays: 365},
+		{Name: "applications", ConnectionType: "application", RetentionDays: 30},
+	}
+	for _, tt := range []struct {
+		msg     string
+		session pgrest.Session
+		tags    []string
+		want    string
+	}{
+		{
+			msg:     "it must match the exec policy",
+			session: pgrest.Session{ConnectionType: "database", Verb: "exec"},
+			tags:    []string{"dev"},
+			want:    "exec",
+		},
+		{
+			msg:     "it must use the longest retention when more than one policy matches",
+			session: pgrest.Session{ConnectionType: "database", Verb: "exec"},
+			tags:    []string{"prod"},
+			want:    "prod-databases",
+		},
+		{
+			msg:     "it must match by connection type",
+			session: pgrest.Session{ConnectionType: "application", Verb: "connect"},
+			want:    "applications",
+		},
+		{
+			msg:     "it must not match any policy",
+			session: pgrest.Session{ConnectionType: "database", Verb: "connect"},
+			tags:    []string{"dev"},
+			want:    "",
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			var got string
+			if p := matchPolicy(policies, tt.session, tt.tags); p != nil {
+				got = p.Name
+			}
+			if got != tt.want {
+				t.Errorf("expected policy %q, got=%q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPurgeSessionsSkipFailures(t *testing.T) {
+	items := []expiredSession{
+		{session: pgrest.Session{ID: "s1"}, policy: pgrest.RetentionPolicy{Name: "exec"}},
+		{session: pgrest.Session{ID: "s2"}, policy: pgrest.RetentionPolicy{Name: "exec"}},
+		{session: pgrest.Session{ID: "s3"}, policy: pgrest.RetentionPolicy{Name: "applications"}},
+	}
+	var attempts []string
+	purged, purgedByPolicy, failed := purgeSessions(items, func(sess pgrest.Session) error {
+		attempts = append(attempts, sess.ID)
+		if sess.ID == "s2" {
+			return fmt.Errorf("blob storage unavailable")
+		}
+		return nil
+	})
+	if want := []string{"s1", "s2", "s3"}; !slices.Equal(attempts, want) {
+		t.Errorf("expected to attempt purging %v, got=%v", want, attempts)
+	}
+	if want := []string{"s1", "s3"}; !slices.Equal(purged, want) {
+		t.Errorf("expected purged sessions %v, got=%v", want, purged)
+	}
+	if purgedByPolicy["exec"] != 1 || purgedByPolicy["applications"] != 1 {
+		t.Errorf("expected one purged session by policy, got=%v", purgedByPolicy)
+	}
+	if len(failed) != 1 || failed["s2"] != "blob storage unavailable" {
+		t.Errorf("expected s2 to fail, got=%v", failed)
+	}
+}
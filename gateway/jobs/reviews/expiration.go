@@ -0,0 +1,46 @@
+package jobreviews
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/review"
+)
+
+// Run schedules the expiration and the execution of scheduled reviews every minute.
+// It runs within the gateway process because the clients waiting for a review
+// are connected to it and the executions are performed through the local gRPC server.
+func Run(svc *review.Service) {
+	log.Infof("starting review scheduler, expiration (every 1m), scheduled executions (every 1m)")
+	scheduler := gocron.NewScheduler(time.UTC)
+	_, err := scheduler.
+		SingletonMode().
+		Every(1).Minute().
+		DoWithJobDetails(ExpireStaleReviews, svc)
+	if err != nil {
+		log.Fatalf("failed scheduling review expiration job, reason=%v", err)
+	}
+	_, err = scheduler.
+		SingletonMode().
+		Every(1).Minute().
+		DoWithJobDetails(ExecuteScheduledReviews)
+	if err != nil {
+		log.Fatalf("failed scheduling review executions job, reason=%v", err)
+	}
+	scheduler.StartAsync()
+}
+
+// ExpireStaleReviews changes the pending reviews that are past their expiration time
+// to the expired state, the clients waiting for them and the integrations are notified.
+func ExpireStaleReviews(svc *review.Service, _ gocron.Job) {
+	log := log.With("job", "reviewexpiration")
+	items, err := svc.ExpireStaleReviews(time.Now().UTC())
+	for _, rev := range items {
+		log.With("org", rev.OrgId, "sid", rev.Session).Infof("review %v expired, connection=%v, created-at=%v",
+			rev.Id, rev.Connection.Name, rev.CreatedAt.Format(time.RFC3339))
+	}
+	if err != nil {
+		log.Warnf("failed expiring reviews, expired=%v, err=%v", len(items), err)
+	}
+}
@@ -0,0 +1,92 @@
+package jobreviews
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/clientexec"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgreview "github.com/hoophq/hoop/gateway/pgrest/review"
+	"github.com/hoophq/hoop/gateway/security/localauth"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+)
+
+const (
+	// the max number of scheduled reviews started in each run
+	maxScheduledReviews = 100
+	// the token is only used to open the session
+	execTokenTTL = time.Minute * 5
+)
+
+// ExecuteScheduledReviews runs the approved one time reviews that reached their scheduled time
+// on behalf of their owners. Each review is claimed by changing its status to processing,
+// it prevents executing the same review more than once.
+func ExecuteScheduledReviews(_ gocron.Job) {
+	log := log.With("job", "reviewschedule")
+	items, err := pgreview.New().FetchScheduled(time.Now().UTC(), maxScheduledReviews)
+	if err != nil {
+		log.Warnf("failed fetching scheduled reviews, err=%v", err)
+		return
+	}
+	for _, rev := range items {
+		ctx := pgrest.NewOrgContext(rev.OrgId)
+		err := pgreview.New().UpdateStatusIf(ctx, rev.Id, types.ReviewStatusApproved, types.ReviewStatusProcessing)
+		switch err {
+		case nil:
+		// it was executed or revoked in the meantime
+		case pgrest.ErrNotFound:
+			continue
+		default:
+			log.With("org", rev.OrgId, "sid", rev.Session).Warnf("failed claiming scheduled review %v, err=%v", rev.Id, err)
+			continue
+		}
+		rev.Status = types.ReviewStatusProcessing
+		go executeReview(ctx, rev)
+	}
+}
+
+func executeReview(ctx pgrest.OrgContext, rev types.Review) {
+	log := log.With("job", "reviewschedule", "org", rev.OrgId, "sid", rev.Session)
+	log.Infof("executing scheduled review %v, connection=%v, owner=%v, scheduled-at=%v",
+		rev.Id, rev.Connection.Name, rev.ReviewOwner.Email, rev.ScheduledAt.Format(time.RFC3339))
+
+	resp, err := runExec(rev)
+	rev.Status = types.ReviewStatusExecuted
+	if err != nil {
+		log.Warnf("failed executing scheduled review %v, err=%v", rev.Id, err)
+		rev.Status = types.ReviewStatusUnknown
+		resp = &clientexec.Response{SessionID: rev.Session, Output: err.Error(), OutputStatus: "failed", ExitCode: -1}
+	}
+	if err := pgreview.New().PatchStatus(ctx, rev.Id, rev.Status); err != nil {
+		log.Warnf("failed updating scheduled review %v to status %v, err=%v", rev.Id, rev.Status, err)
+	}
+	log.Infof("scheduled review %v finished, status=%v, %v", rev.Id, rev.Status, resp)
+	for _, p := range plugintypes.RegisteredPlugins {
+		if h, ok := p.(plugintypes.ReviewScheduledExecHandler); ok {
+			h.OnReviewScheduledExec(&rev, resp.ExitCode, resp.OutputStatus)
+		}
+	}
+}
+
+func runExec(rev types.Review) (*clientexec.Response, error) {
+	accessToken, err := localauth.NewToken(rev.ReviewOwner.Id, execTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed issuing access token, reason=%v", err)
+	}
+	client, err := clientexec.New(&clientexec.Options{
+		OrgID:          rev.OrgId,
+		SessionID:      rev.Session,
+		ConnectionName: rev.Connection.Name,
+		BearerToken:    accessToken,
+		UserAgent:      "gateway.review.schedule",
+		// scheduled executions are started by the gateway, there's no client address
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return client.Run([]byte(rev.Input), rev.InputEnvVars, rev.InputClientArgs...), nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/go-co-op/gocron"
 	"github.com/hoophq/hoop/common/log"
 	pgsession "github.com/hoophq/hoop/gateway/pgrest/session"
+	"github.com/hoophq/hoop/gateway/session/blobstorage"
 	"github.com/hoophq/hoop/gateway/session/eventlog"
 	sessionwal "github.com/hoophq/hoop/gateway/session/wal"
 	"github.com/hoophq/hoop/gateway/storagev2"
@@ -117,6 +118,7 @@ func ProcessWalSessions(auditPath string, _ gocron.Job) {
 			"sid", wh.SessionID, "org", wh.OrgID, "email", wh.UserEmail, "connection", wh.ConnectionName,
 			"verb", wh.Verb, "size", fmt.Sprintf("%v", ev.size), "started-at", wh.StartDate.Format(time.RFC3339),
 			"truncated", ev.truncated, "success", err == nil, "commit-error", commitErrorMsg != "",
+			"blob-storage", blobstorage.Get().Name(),
 		).Infof("processed %v/%v, commit-error-msg:[%s]",
 			count, len(walFolders), commitErrorMsg)
 		if err != nil {
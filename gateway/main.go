@@ -20,21 +20,27 @@ import (
 	apiorgs "github.com/hoophq/hoop/gateway/api/orgs"
 	"github.com/hoophq/hoop/gateway/appconfig"
 	"github.com/hoophq/hoop/gateway/indexer"
+	jobgroupgrants "github.com/hoophq/hoop/gateway/jobs/groupgrants"
+	jobreviews "github.com/hoophq/hoop/gateway/jobs/reviews"
 	"github.com/hoophq/hoop/gateway/pgrest"
 	pgorgs "github.com/hoophq/hoop/gateway/pgrest/orgs"
 	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
 	"github.com/hoophq/hoop/gateway/review"
 	"github.com/hoophq/hoop/gateway/security/idp"
+	"github.com/hoophq/hoop/gateway/session/blobstorage"
 	"github.com/hoophq/hoop/gateway/transport"
 
 	// plugins
 	"github.com/hoophq/hoop/gateway/transport/connectionstatus"
 	pluginsrbac "github.com/hoophq/hoop/gateway/transport/plugins/accesscontrol"
+	pluginsaccessrules "github.com/hoophq/hoop/gateway/transport/plugins/accessrules"
 	pluginsaudit "github.com/hoophq/hoop/gateway/transport/plugins/audit"
+	pluginsdbcredentials "github.com/hoophq/hoop/gateway/transport/plugins/dbcredentials"
 	pluginsdlp "github.com/hoophq/hoop/gateway/transport/plugins/dlp"
 	pluginsindex "github.com/hoophq/hoop/gateway/transport/plugins/index"
 	pluginsreview "github.com/hoophq/hoop/gateway/transport/plugins/review"
 	pluginsslack "github.com/hoophq/hoop/gateway/transport/plugins/slack"
+	pluginsticketvalidation "github.com/hoophq/hoop/gateway/transport/plugins/ticketvalidation"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 	pluginswebhooks "github.com/hoophq/hoop/gateway/transport/plugins/webhooks"
 	"github.com/hoophq/hoop/gateway/transport/streamclient"
@@ -63,6 +69,10 @@ func Run() {
 	if err := pgrest.Run(); err != nil {
 		log.Fatal(err)
 	}
+	if err := blobstorage.Load(); err != nil {
+		log.Fatalf("failed loading session blob storage, reason=%v", err)
+	}
+	log.Infof("session blob storage=%v", blobstorage.Get().Name())
 
 	idProvider := idp.NewProvider(apiURL)
 	grpcURL := os.Getenv("GRPC_URL")
@@ -103,6 +113,8 @@ func Run() {
 	}
 	// order matters
 	plugintypes.RegisteredPlugins = []plugintypes.Plugin{
+		// it must run before the review plugin to validate tickets prior to creating reviews
+		pluginsticketvalidation.New(),
 		pluginsreview.New(
 			&review.Service{TransportService: g},
 			idProvider.ApiURL,
@@ -111,6 +123,8 @@ func Run() {
 		pluginsindex.New(),
 		pluginsdlp.New(),
 		pluginsrbac.New(),
+		pluginsaccessrules.New(),
+		pluginsdbcredentials.New(),
 		pluginswebhooks.New(),
 		pluginsslack.New(
 			&review.Service{TransportService: g},
@@ -132,6 +146,8 @@ func Run() {
 	}
 	connectionstatus.InitConciliationProcess()
 	streamclient.InitProxyMemoryCleanup()
+	jobreviews.Run(&review.Service{TransportService: g})
+	jobgroupgrants.Run()
 
 	if grpc.ShouldDebugGrpc() {
 		log.SetGrpcLogger()
@@ -0,0 +1,77 @@
+package pgaccessrules
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+type accessRules struct{}
+
+func New() *accessRules { return &accessRules{} }
+
+func (a *accessRules) FetchAll(ctx pgrest.OrgContext) ([]pgrest.AccessRule, error) {
+	var items []pgrest.AccessRule
+	err := pgrest.New("/access_rules?org_id=eq.%s&order=name.asc", ctx.GetOrgID()).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (a *accessRules) FetchOne(ctx pgrest.OrgContext, name string) (*pgrest.AccessRule, error) {
+	var rule pgrest.AccessRule
+	err := pgrest.New("/access_rules?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(name)).
+		FetchOne().
+		DecodeInto(&rule)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (a *accessRules) Create(ctx pgrest.OrgContext, rule pgrest.AccessRule) (*pgrest.AccessRule, error) {
+	var obj pgrest.AccessRule
+	err := pgrest.New("/access_rules").Create(map[string]any{
+		"org_id":      ctx.GetOrgID(),
+		"name":        rule.Name,
+		"description": rule.Description,
+		"effect":      rule.Effect,
+		"expression":  rule.Expression,
+		"connections": toArray(rule.Connections),
+		"timezone":    rule.Timezone,
+	}).DecodeInto(&obj)
+	return &obj, err
+}
+
+func (a *accessRules) Update(ctx pgrest.OrgContext, rule pgrest.AccessRule) error {
+	return pgrest.New("/access_rules?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(rule.Name)).
+		Patch(map[string]any{
+			"description": rule.Description,
+			"effect":      rule.Effect,
+			"expression":  rule.Expression,
+			"connections": toArray(rule.Connections),
+			"timezone":    rule.Timezone,
+			"updated_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
+
+func (a *accessRules) Delete(ctx pgrest.OrgContext, name string) error {
+	return pgrest.New("/access_rules?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(name)).
+		Delete().
+		Error()
+}
+
+// the column is not nullable, a nil slice is encoded as null
+func toArray(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
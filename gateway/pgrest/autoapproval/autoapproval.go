@@ -0,0 +1,77 @@
+package pgautoapproval
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+type autoApproval struct{}
+
+func New() *autoApproval { return &autoApproval{} }
+
+func (a *autoApproval) FetchAll(ctx pgrest.OrgContext) ([]pgrest.ReviewAutoApprovalRule, error) {
+	var items []pgrest.ReviewAutoApprovalRule
+	err := pgrest.New("/review_auto_approval_rules?org_id=eq.%s&order=name.asc", ctx.GetOrgID()).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (a *autoApproval) FetchOne(ctx pgrest.OrgContext, name string) (*pgrest.ReviewAutoApprovalRule, error) {
+	var rule pgrest.ReviewAutoApprovalRule
+	err := pgrest.New("/review_auto_approval_rules?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(name)).
+		FetchOne().
+		DecodeInto(&rule)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (a *autoApproval) Create(ctx pgrest.OrgContext, rule pgrest.ReviewAutoApprovalRule) (*pgrest.ReviewAutoApprovalRule, error) {
+	var obj pgrest.ReviewAutoApprovalRule
+	err := pgrest.New("/review_auto_approval_rules").Create(map[string]any{
+		"org_id":         ctx.GetOrgID(),
+		"name":           rule.Name,
+		"connections":    toArray(rule.Connections),
+		"read_only":      rule.ReadOnly,
+		"query_pattern":  rule.QueryPattern,
+		"groups":         toArray(rule.Groups),
+		"business_hours": rule.BusinessHours,
+	}).DecodeInto(&obj)
+	return &obj, err
+}
+
+func (a *autoApproval) Update(ctx pgrest.OrgContext, rule pgrest.ReviewAutoApprovalRule) error {
+	return pgrest.New("/review_auto_approval_rules?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(rule.Name)).
+		Patch(map[string]any{
+			"connections":    toArray(rule.Connections),
+			"read_only":      rule.ReadOnly,
+			"query_pattern":  rule.QueryPattern,
+			"groups":         toArray(rule.Groups),
+			"business_hours": rule.BusinessHours,
+			"updated_at":     time.Now().UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
+
+func (a *autoApproval) Delete(ctx pgrest.OrgContext, name string) error {
+	return pgrest.New("/review_auto_approval_rules?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(name)).
+		Delete().
+		Error()
+}
+
+// the columns are not nullable, a nil slice is encoded as null
+func toArray(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
@@ -104,21 +104,27 @@ func (c *connections) Upsert(ctx pgrest.OrgContext, conn pgrest.Connection) erro
 	}
 
 	return pgrest.New("/rpc/update_connection").RpcCreate(map[string]any{
-		"id":                   conn.ID,
-		"org_id":               ctx.GetOrgID(),
-		"name":                 conn.Name,
-		"agent_id":             toAgentID(conn.AgentID),
-		"type":                 conn.Type,
-		"subtype":              subType,
-		"command":              conn.Command,
-		"envs":                 conn.Envs,
-		"status":               conn.Status,
-		"managed_by":           conn.ManagedBy,
-		"tags":                 conn.Tags,
-		"access_mode_runbooks": conn.AccessModeRunbooks,
-		"access_mode_exec":     conn.AccessModeExec,
-		"access_mode_connect":  conn.AccessModeConnect,
-		"access_schema":        conn.AccessSchema,
+		"id":                    conn.ID,
+		"org_id":                ctx.GetOrgID(),
+		"name":                  conn.Name,
+		"agent_id":              toAgentID(conn.AgentID),
+		"type":                  conn.Type,
+		"subtype":               subType,
+		"command":               conn.Command,
+		"envs":                  conn.Envs,
+		"status":                conn.Status,
+		"managed_by":            conn.ManagedBy,
+		"tags":                  conn.Tags,
+		"access_mode_runbooks":  conn.AccessModeRunbooks,
+		"access_mode_exec":      conn.AccessModeExec,
+		"access_mode_connect":   conn.AccessModeConnect,
+		"access_schema":         conn.AccessSchema,
+		"review_ttl_sec":        conn.ReviewTTLSec,
+		"require_justification": conn.RequireJustification,
+		"ticket_id_pattern":     conn.TicketIDPattern,
+		"require_recent_auth":   conn.RequireRecentAuth,
+		"deny_rules":            conn.DenyRules,
+		"break_glass_groups":    conn.BreakGlassGroups,
 	}).Error()
 }
 
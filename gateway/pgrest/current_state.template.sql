@@ -16,7 +16,7 @@ CREATE VIEW audit AS
 -- LOGIN
 --
 CREATE VIEW login AS
-    SELECT id, redirect, slack_id, outcome, updated_at, created_at FROM private.login;
+    SELECT id, redirect, slack_id, outcome, provider, updated_at, created_at FROM private.login;
 
 -- USERS
 --
@@ -24,16 +24,52 @@ CREATE VIEW users AS
     SELECT id, org_id, subject, email, name, picture, verified, status, slack_id, created_at, updated_at
     FROM private.users;
 
-CREATE VIEW user_groups AS SELECT org_id, user_id, service_account_id, name FROM private.user_groups;
+CREATE VIEW user_groups AS SELECT org_id, user_id, service_account_id, name, expires_at FROM private.user_groups;
 
+-- time-bound memberships are ignored after they expire
 CREATE FUNCTION groups(users) RETURNS TEXT[] AS $$
     SELECT ARRAY(
         SELECT name FROM user_groups
         WHERE org_id = $1.org_id
         AND user_id = $1.id
+        AND (expires_at IS NULL OR expires_at > NOW())
     )
 $$ LANGUAGE SQL;
 
+CREATE VIEW user_group_grants AS
+    SELECT id, org_id, user_id, user_email, group_name, reason, status, granted_by, revoked_by, expires_at, revoked_at, created_at
+    FROM private.user_group_grants;
+
+-- grant_user_group adds a time-bound membership to a user and records it in the grants history.
+-- An active grant of the same group is replaced, permanent memberships are kept as they are.
+CREATE FUNCTION grant_user_group(params json) RETURNS SETOF user_group_grants ROWS 1 AS $$
+    WITH user_input AS (
+        SELECT
+            (params->>'org_id')::UUID AS org_id,
+            (params->>'user_id')::UUID AS user_id,
+            params->>'user_email' AS user_email,
+            params->>'group_name' AS group_name,
+            params->>'reason' AS reason,
+            params->>'granted_by' AS granted_by,
+            (params->>'expires_at')::TIMESTAMP AS expires_at
+    ), upsert_user_groups AS (
+        INSERT INTO user_groups (org_id, user_id, name, expires_at)
+            SELECT org_id, user_id, group_name, expires_at FROM user_input
+        ON CONFLICT (user_id, name)
+            DO UPDATE SET expires_at = EXCLUDED.expires_at
+            WHERE user_groups.expires_at IS NOT NULL
+    ), replace_grants AS (
+        UPDATE user_group_grants SET status = 'replaced', revoked_at = NOW()
+        WHERE org_id = (SELECT org_id FROM user_input)
+        AND user_id = (SELECT user_id FROM user_input)
+        AND group_name = (SELECT group_name FROM user_input)
+        AND status = 'active'
+    )
+    INSERT INTO user_group_grants (org_id, user_id, user_email, group_name, reason, granted_by, expires_at)
+        SELECT org_id, user_id, user_email, group_name, reason, granted_by, expires_at FROM user_input
+    RETURNING *
+$$ LANGUAGE SQL;
+
 CREATE FUNCTION update_users(params json) RETURNS SETOF users AS $$
     WITH user_input AS (
         SELECT
@@ -73,6 +109,8 @@ CREATE FUNCTION update_users(params json) RETURNS SETOF users AS $$
         WHERE user_id = (params->>'id')::UUID
         AND org_id = (params->>'org_id')::UUID
         AND name NOT IN (SELECT name::TEXT FROM grps)
+        -- time-bound memberships are managed by their grants
+        AND expires_at IS NULL
     )
     SELECT * FROM upsert_users
 $$ LANGUAGE SQL;
@@ -117,6 +155,7 @@ CREATE FUNCTION
         WHERE service_account_id = id
         AND org_id = org_id
         AND name NOT IN (SELECT name FROM grps)
+        AND expires_at IS NULL
     )
     SELECT * FROM upsert_svc_account
 $$ LANGUAGE SQL;
@@ -135,7 +174,8 @@ CREATE VIEW connections AS
     SELECT id, org_id, agent_id, name, command, type, subtype,
         (SELECT envs FROM env_vars WHERE id = c.id) AS envs,
         status, managed_by, _tags AS tags, access_mode_connect, access_mode_exec, 
-        access_mode_runbooks, access_schema, created_at, updated_at
+        access_mode_runbooks, access_schema, review_ttl_sec, require_justification, ticket_id_pattern,
+        require_recent_auth, deny_rules, break_glass_groups, created_at, updated_at
     FROM private.connections c;
 
 CREATE FUNCTION agents(connections) RETURNS SETOF agents ROWS 1 AS $$
@@ -165,10 +205,22 @@ CREATE FUNCTION update_connection(params json) RETURNS SETOF connections ROWS 1
             (params->>'access_mode_connect')::private.enum_access_status AS access_mode_connect,
             (params->>'access_mode_exec')::private.enum_access_status AS access_mode_exec,
             (params->>'access_mode_runbooks')::private.enum_access_status AS access_mode_runbooks,
-            (params->>'access_schema')::private.enum_access_status AS access_schema
+            (params->>'access_schema')::private.enum_access_status AS access_schema,
+            (params->>'review_ttl_sec')::INT AS review_ttl_sec,
+            COALESCE((params->>'require_justification')::BOOLEAN, FALSE) AS require_justification,
+            params->>'ticket_id_pattern' AS ticket_id_pattern,
+            COALESCE((params->>'require_recent_auth')::INT, 0) AS require_recent_auth,
+            COALESCE((
+                SELECT array_agg(v)::TEXT[]
+                FROM jsonb_array_elements_text((params->>'deny_rules')::JSONB) AS v
+            ), '{}') AS deny_rules,
+            COALESCE((
+                SELECT array_agg(v)::TEXT[]
+                FROM jsonb_array_elements_text((params->>'break_glass_groups')::JSONB) AS v
+            ), '{}') AS break_glass_groups
     ), conn AS (
-        INSERT INTO connections (id, org_id, agent_id, name, command, type, subtype, status, managed_by, tags, access_mode_runbooks, access_mode_connect, access_mode_exec, access_schema)
-            (SELECT id, org_id, agent_id, name, command, type, subtype, status, managed_by, tags, access_mode_runbooks, access_mode_connect, access_mode_exec, access_schema FROM user_input)
+        INSERT INTO connections (id, org_id, agent_id, name, command, type, subtype, status, managed_by, tags, access_mode_runbooks, access_mode_connect, access_mode_exec, access_schema, review_ttl_sec, require_justification, ticket_id_pattern, require_recent_auth, deny_rules, break_glass_groups)
+            (SELECT id, org_id, agent_id, name, command, type, subtype, status, managed_by, tags, access_mode_runbooks, access_mode_connect, access_mode_exec, access_schema, review_ttl_sec, require_justification, ticket_id_pattern, require_recent_auth, deny_rules, break_glass_groups FROM user_input)
         ON CONFLICT (org_id, name)
             DO UPDATE SET
                 agent_id = (SELECT agent_id FROM user_input),
@@ -182,6 +234,12 @@ CREATE FUNCTION update_connection(params json) RETURNS SETOF connections ROWS 1
                 access_mode_exec = (SELECT access_mode_exec FROM user_input),
                 access_mode_runbooks = (SELECT access_mode_runbooks FROM user_input),
                 access_schema = (SELECT access_schema FROM user_input),
+                review_ttl_sec = (SELECT review_ttl_sec FROM user_input),
+                require_justification = (SELECT require_justification FROM user_input),
+                ticket_id_pattern = (SELECT ticket_id_pattern FROM user_input),
+                require_recent_auth = (SELECT require_recent_auth FROM user_input),
+                deny_rules = (SELECT deny_rules FROM user_input),
+                break_glass_groups = (SELECT break_glass_groups FROM user_input),
                 updated_at = NOW()
         RETURNING *
     ), envs AS (
@@ -191,7 +249,7 @@ CREATE FUNCTION update_connection(params json) RETURNS SETOF connections ROWS 1
                 DO UPDATE SET envs = (SELECT envs FROM user_input)
             RETURNING *
     )
-    SELECT c.id, c.org_id, c.agent_id, c.name, c.command, c.type, c.subtype, e.envs, c.status, c.managed_by, c.tags, c.access_mode_runbooks, c.access_mode_connect, c.access_mode_exec, c.access_schema, c.created_at, c.updated_at
+    SELECT c.id, c.org_id, c.agent_id, c.name, c.command, c.type, c.subtype, e.envs, c.status, c.managed_by, c.tags, c.access_mode_runbooks, c.access_mode_connect, c.access_mode_exec, c.access_schema, c.review_ttl_sec, c.require_justification, c.ticket_id_pattern, c.require_recent_auth, c.deny_rules, c.break_glass_groups, c.created_at, c.updated_at
     FROM conn c
     INNER JOIN envs e
         ON e.id = c.id;
@@ -217,11 +275,14 @@ $$ stable language sql;
 CREATE VIEW sessions AS
     SELECT
         id, org_id, labels, connection, connection_type, verb, user_id, user_name, user_email, status,
-        blob_input_id, blob_stream_id, metadata, metrics, created_at, ended_at
+        blob_input_id, blob_stream_id, metadata, metrics, break_glass, created_at, ended_at
     FROM private.sessions;
 
 CREATE VIEW blobs AS
-    SELECT id, org_id, type, pg_column_size(blob_stream) AS size, blob_stream, created_at FROM private.blobs;
+    SELECT
+        id, org_id, type, COALESCE(blob_size, pg_column_size(blob_stream)) AS size,
+        blob_stream, blob_storage, blob_key, blob_size, created_at
+    FROM private.blobs;
 
 CREATE FUNCTION blob_input(sessions) RETURNS SETOF blobs ROWS 1 AS $$
   SELECT * FROM blobs WHERE id = $1.blob_input_id
@@ -263,6 +324,7 @@ CREATE FUNCTION session_report(p json) RETURNS TABLE (resource text, info_type t
         AND CASE WHEN p->>'connection_type' != '' THEN s.connection_type::TEXT = p->>'connection_type'::TEXT ELSE true END
         AND CASE WHEN p->>'verb' != '' THEN s.verb::TEXT = p->>'verb' ELSE true END
         AND CASE WHEN p->>'user_email' != '' THEN s.user_email = p->>'user_email' ELSE true END
+        AND CASE WHEN p->>'break_glass' != '' THEN s.break_glass = (p->>'break_glass')::BOOLEAN ELSE true END
         GROUP BY 1, 2
     ) SELECT * FROM metrics
 $$ LANGUAGE SQL;
@@ -273,7 +335,8 @@ CREATE VIEW reviews AS
     SELECT
         id, org_id, session_id, connection_id, connection_name, type, blob_input_id,
         input_env_vars, input_client_args, access_duration_sec, status,
-        owner_id, owner_email, owner_name, owner_slack_id, created_at, revoked_at
+        owner_id, owner_email, owner_name, owner_slack_id, created_at, revoked_at, workflow, expires_at,
+        scheduled_at, break_glass_justification, justification, ticket_id
     FROM private.reviews;
 
 CREATE VIEW review_groups AS
@@ -282,6 +345,12 @@ CREATE VIEW review_groups AS
         owner_id, owner_email, owner_name, owner_slack_id, reviewed_at
     FROM private.review_groups;
 
+CREATE VIEW review_workflows AS
+    SELECT
+        id, org_id, connection_id, stages, allow_self_approval, separation_of_duties,
+        created_at, updated_at
+    FROM private.review_workflows;
+
 CREATE FUNCTION blob_input(reviews) RETURNS SETOF blobs ROWS 1 AS $$
   SELECT * FROM blobs WHERE id = $1.blob_input_id
 $$ stable language sql;
@@ -292,6 +361,104 @@ CREATE VIEW proxymanager_state AS
     SELECT id, org_id, status, connection, port, access_duration, metadata, connected_at
     FROM private.proxymanager_state;
 
+-- RETENTION POLICIES
+--
+CREATE VIEW retention_policies AS
+    SELECT id, org_id, name, connection_type, verb, connection_tag, retention_days, created_at, updated_at
+    FROM private.retention_policies;
+
+-- REVIEW AUTO APPROVAL RULES
+--
+CREATE VIEW review_auto_approval_rules AS
+    SELECT id, org_id, name, connections, read_only, query_pattern, groups, business_hours, created_at, updated_at
+    FROM private.review_auto_approval_rules;
+
+-- SCIM
+--
+CREATE VIEW scim_tokens AS
+    SELECT org_id, token_hash, created_by, created_at FROM private.scim_tokens;
+
+CREATE VIEW scim_groups AS
+    SELECT id, org_id, display_name, created_at, updated_at FROM private.scim_groups;
+
+-- LOCAL AUTHENTICATION
+--
+CREATE VIEW user_credentials AS
+    SELECT user_id, org_id, password_hash, totp_secret, totp_enabled, totp_last_step, recovery_codes,
+    failed_attempts, locked_until, created_at, updated_at
+    FROM private.user_credentials;
+
+-- register_login_failure increments the failed attempts of the user atomically,
+-- the account is locked when the maximum number of attempts is reached
+CREATE FUNCTION register_login_failure(params json) RETURNS SETOF user_credentials ROWS 1 AS $$
+    UPDATE user_credentials SET
+        failed_attempts = CASE
+            WHEN failed_attempts + 1 >= (params->>'max_attempts')::INT THEN 0
+            ELSE failed_attempts + 1
+        END,
+        locked_until = CASE
+            WHEN failed_attempts + 1 >= (params->>'max_attempts')::INT
+                THEN (NOW() AT TIME ZONE 'UTC') + make_interval(secs => (params->>'lockout_seconds')::INT)
+            ELSE locked_until
+        END,
+        updated_at = NOW()
+    WHERE org_id = (params->>'org_id')::UUID
+    AND user_id = (params->>'user_id')::UUID
+    RETURNING *
+$$ LANGUAGE SQL;
+
+-- use_recovery_code removes a recovery code of the user, it returns no rows
+-- if the code doesn't exist or it was already used
+CREATE FUNCTION use_recovery_code(params json) RETURNS SETOF user_credentials ROWS 1 AS $$
+    UPDATE user_credentials SET
+        recovery_codes = array_remove(recovery_codes, params->>'code_hash'),
+        updated_at = NOW()
+    WHERE org_id = (params->>'org_id')::UUID
+    AND user_id = (params->>'user_id')::UUID
+    AND (params->>'code_hash') = ANY(recovery_codes)
+    RETURNING *
+$$ LANGUAGE SQL;
+
+-- register_first_user creates the first user of the organization with its groups and credentials,
+-- concurrent registrations are serialized by a lock of the organization and it returns no rows
+-- if the organization already has users
+CREATE FUNCTION register_first_user(params json) RETURNS SETOF users ROWS 1 AS $$
+    SELECT pg_advisory_xact_lock(hashtext('register_first_user:' || (params->>'org_id')));
+
+    WITH new_user AS (
+        INSERT INTO users (id, org_id, subject, email, name, verified, status)
+            SELECT
+                (params->>'id')::UUID,
+                (params->>'org_id')::UUID,
+                params->>'subject',
+                params->>'email',
+                params->>'name',
+                (params->>'verified')::BOOL,
+                (params->>'status')::private.enum_user_status
+            WHERE NOT EXISTS (SELECT 1 FROM users WHERE org_id = (params->>'org_id')::UUID)
+        RETURNING *
+    ), new_groups AS (
+        INSERT INTO user_groups (org_id, user_id, name)
+            SELECT org_id, id, jsonb_array_elements_text((params->>'groups')::JSONB) FROM new_user
+    ), new_credentials AS (
+        INSERT INTO user_credentials (user_id, org_id, password_hash)
+            SELECT id, org_id, params->>'password_hash' FROM new_user
+    )
+    SELECT * FROM new_user
+$$ LANGUAGE SQL;
+
+-- PERSONAL ACCESS TOKENS
+--
+CREATE VIEW user_tokens AS
+    SELECT id, org_id, user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+    FROM private.user_tokens;
+
+-- ACCESS RULES
+--
+CREATE VIEW access_rules AS
+    SELECT id, org_id, name, description, effect, expression, connections, timezone, created_at, updated_at
+    FROM private.access_rules;
+
 -- -----------------
 -- ROLE PERMISSIONS
 -- -----------------
@@ -323,6 +490,7 @@ GRANT SELECT, INSERT, UPDATE ON orgs TO {{ .pgrest_role }};
 GRANT INSERT, SELECT, UPDATE on login TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE, DELETE ON users TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE, DELETE ON user_groups TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE ON user_group_grants TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE ON serviceaccounts TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE, DELETE ON connections TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE, DELETE ON env_vars TO {{ .pgrest_role }};
@@ -330,11 +498,19 @@ GRANT SELECT, INSERT, UPDATE, DELETE ON agents TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE, DELETE ON plugin_connections TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE ON plugins TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE ON sessions TO {{ .pgrest_role }};
-GRANT SELECT, INSERT, UPDATE ON blobs TO {{ .pgrest_role }};
-GRANT SELECT, INSERT, UPDATE ON reviews TO {{ .pgrest_role }};
-GRANT SELECT, INSERT, UPDATE ON review_groups TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON blobs TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON reviews TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON review_groups TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON review_workflows TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE, DELETE ON proxymanager_state TO {{ .pgrest_role }};
 GRANT SELECT, INSERT, UPDATE ON audit TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON retention_policies TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON review_auto_approval_rules TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON access_rules TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON scim_tokens TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON scim_groups TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON user_credentials TO {{ .pgrest_role }};
+GRANT SELECT, INSERT, UPDATE, DELETE ON user_tokens TO {{ .pgrest_role }};
 
 -- allow the main role to impersonate the apiuser role
 GRANT {{ .pgrest_role }} TO {{ .pg_app_user }};
@@ -0,0 +1,121 @@
+package pggroupgrants
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+const (
+	StatusActive   = "active"
+	StatusExpired  = "expired"
+	StatusRevoked  = "revoked"
+	StatusReplaced = "replaced"
+)
+
+type groupGrants struct{}
+
+func New() *groupGrants { return &groupGrants{} }
+
+// Create adds a time-bound membership to the user and records the grant.
+// An active grant of the same group is replaced by the new one.
+func (g *groupGrants) Create(ctx pgrest.OrgContext, grant pgrest.UserGroupGrant) (*pgrest.UserGroupGrant, error) {
+	var items []pgrest.UserGroupGrant
+	err := pgrest.New("/rpc/grant_user_group").RpcCreate(map[string]any{
+		"org_id":     ctx.GetOrgID(),
+		"user_id":    grant.UserID,
+		"user_email": grant.UserEmail,
+		"group_name": grant.GroupName,
+		"reason":     grant.Reason,
+		"granted_by": grant.GrantedBy,
+		"expires_at": grant.ExpiresAt,
+	}).DecodeInto(&items)
+	if err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// FetchAll returns the grants history of an organization filtered by the non empty attributes
+func (g *groupGrants) FetchAll(ctx pgrest.OrgContext, userEmail, groupName, status string) ([]pgrest.UserGroupGrant, error) {
+	path := fmt.Sprintf("/user_group_grants?org_id=eq.%s&order=created_at.desc", ctx.GetOrgID())
+	if userEmail != "" {
+		path += fmt.Sprintf("&user_email=eq.%s", url.QueryEscape(userEmail))
+	}
+	if groupName != "" {
+		path += fmt.Sprintf("&group_name=eq.%s", url.QueryEscape(groupName))
+	}
+	if status != "" {
+		path += fmt.Sprintf("&status=eq.%s", url.QueryEscape(status))
+	}
+	var items []pgrest.UserGroupGrant
+	if err := pgrest.New(path).List().DecodeInto(&items); err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (g *groupGrants) FetchOne(ctx pgrest.OrgContext, id string) (*pgrest.UserGroupGrant, error) {
+	var grant pgrest.UserGroupGrant
+	err := pgrest.New("/user_group_grants?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), url.QueryEscape(id)).
+		FetchOne().
+		DecodeInto(&grant)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// FetchExpired returns the active grants of all organizations that are past their expiration
+func (g *groupGrants) FetchExpired(now time.Time, limit int) ([]pgrest.UserGroupGrant, error) {
+	var items []pgrest.UserGroupGrant
+	err := pgrest.New("/user_group_grants?status=eq.active&expires_at=lte.%s&order=expires_at.asc&limit=%v",
+		url.QueryEscape(now.UTC().Format(time.RFC3339)), limit).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+// HasPermanentMembership returns true when the user belongs to the group without an expiration
+func (g *groupGrants) HasPermanentMembership(ctx pgrest.OrgContext, userID, groupName string) (bool, error) {
+	var items []map[string]any
+	err := pgrest.New("/user_groups?select=name&org_id=eq.%s&user_id=eq.%s&name=eq.%s&expires_at=is.null",
+		ctx.GetOrgID(), url.QueryEscape(userID), url.QueryEscape(groupName)).
+		List().
+		DecodeInto(&items)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(items) > 0, nil
+}
+
+// Revoke removes the time-bound membership of an active grant and changes it to the status.
+// It returns pgrest.ErrNotFound if the grant is not active anymore.
+func (g *groupGrants) Revoke(ctx pgrest.OrgContext, grant pgrest.UserGroupGrant, status, revokedBy string) error {
+	err := pgrest.New("/user_groups?org_id=eq.%s&user_id=eq.%s&name=eq.%s&expires_at=not.is.null",
+		ctx.GetOrgID(), url.QueryEscape(grant.UserID), url.QueryEscape(grant.GroupName)).
+		Delete().
+		Error()
+	if err != nil && err != pgrest.ErrNotFound {
+		return fmt.Errorf("failed removing membership: %v", err)
+	}
+	var items []pgrest.UserGroupGrant
+	return pgrest.New("/user_group_grants?org_id=eq.%s&id=eq.%s&status=eq.active", ctx.GetOrgID(), url.QueryEscape(grant.ID)).
+		Patch(map[string]any{
+			"status":     status,
+			"revoked_by": revokedBy,
+			"revoked_at": time.Now().UTC().Format(time.RFC3339Nano),
+		}).
+		DecodeInto(&items)
+}
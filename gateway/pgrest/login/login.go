@@ -27,10 +27,13 @@ func (l *login) FetchOne(state string) (*pgrest.Login, error) {
 }
 
 func (l *login) Upsert(lg *types.Login) error {
-	var slackID *string
+	var slackID, provider *string
 	if lg.SlackID != "" {
 		slackID = &lg.SlackID
 	}
+	if lg.Provider != "" {
+		provider = &lg.Provider
+	}
 	// store accepts max of 200 chars for this attribute
 	if len(lg.Outcome) >= 200 {
 		lg.Outcome = lg.Outcome[:195] + " ..."
@@ -40,6 +43,7 @@ func (l *login) Upsert(lg *types.Login) error {
 		"redirect":   lg.Redirect,
 		"outcome":    lg.Outcome,
 		"slack_id":   slackID,
+		"provider":   provider,
 		"updated_at": time.Now().UTC().Format(time.RFC3339Nano),
 	}).Error()
 }
@@ -67,6 +67,96 @@ func (s *Session) GetRedactCount() (count int64) {
 	return
 }
 
+func (r *RetentionPolicy) GetCreatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", r.CreatedAt, time.UTC)
+	return
+}
+
+func (r *RetentionPolicy) GetUpdatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", r.UpdatedAt, time.UTC)
+	return
+}
+
+func (r *ReviewAutoApprovalRule) GetCreatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", r.CreatedAt, time.UTC)
+	return
+}
+
+func (r *ReviewAutoApprovalRule) GetUpdatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", r.UpdatedAt, time.UTC)
+	return
+}
+
+func (g *UserGroupGrant) GetExpiresAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", g.ExpiresAt, time.UTC)
+	return
+}
+
+func (g *UserGroupGrant) GetRevokedAt() (t *time.Time) {
+	if g.RevokedAt != nil {
+		revokedAt, err := time.ParseInLocation("2006-01-02T15:04:05", *g.RevokedAt, time.UTC)
+		if err == nil {
+			t = &revokedAt
+		}
+	}
+	return
+}
+
+func (g *UserGroupGrant) GetCreatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", g.CreatedAt, time.UTC)
+	return
+}
+
+func (g *SCIMGroup) GetCreatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", g.CreatedAt, time.UTC)
+	return
+}
+
+func (g *SCIMGroup) GetUpdatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", g.UpdatedAt, time.UTC)
+	return
+}
+
+func (c *UserCredentials) GetLockedUntil() (t *time.Time) {
+	if c.LockedUntil != nil {
+		lockedUntil, err := time.ParseInLocation("2006-01-02T15:04:05", *c.LockedUntil, time.UTC)
+		if err != nil {
+			return
+		}
+		return &lockedUntil
+	}
+	return
+}
+
+func (t *UserToken) GetExpiresAt() *time.Time  { return parseOptionalTime(t.ExpiresAt) }
+func (t *UserToken) GetLastUsedAt() *time.Time { return parseOptionalTime(t.LastUsedAt) }
+func (t *UserToken) GetRevokedAt() *time.Time  { return parseOptionalTime(t.RevokedAt) }
+func (t *UserToken) GetCreatedAt() (v time.Time) {
+	v, _ = time.ParseInLocation("2006-01-02T15:04:05", t.CreatedAt, time.UTC)
+	return
+}
+
+func parseOptionalTime(v *string) *time.Time {
+	if v == nil {
+		return nil
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", *v, time.UTC)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func (r *AccessRule) GetCreatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", r.CreatedAt, time.UTC)
+	return
+}
+
+func (r *AccessRule) GetUpdatedAt() (t time.Time) {
+	t, _ = time.ParseInLocation("2006-01-02T15:04:05", r.UpdatedAt, time.UTC)
+	return
+}
+
 func (s *ProxyManagerState) GetConnectedAt() (t time.Time) {
 	t, _ = time.ParseInLocation("2006-01-02T15:04:05", s.ConnectedAt, time.UTC)
 	return
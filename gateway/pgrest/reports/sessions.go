@@ -3,6 +3,7 @@ package pgreports
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/hoophq/hoop/gateway/pgrest"
@@ -16,11 +17,12 @@ type SessionOption struct {
 }
 
 const (
-	OptionGroupBy   OptionKey = "group_by"
-	OptionID        OptionKey = "id"
-	OptionVerb      OptionKey = "verb"
-	OptionStartDate OptionKey = "start_date"
-	OptionEndDate   OptionKey = "end_date"
+	OptionGroupBy    OptionKey = "group_by"
+	OptionID         OptionKey = "id"
+	OptionVerb       OptionKey = "verb"
+	OptionStartDate  OptionKey = "start_date"
+	OptionEndDate    OptionKey = "end_date"
+	OptionBreakGlass OptionKey = "break_glass"
 
 	GroupByID         string = "id"
 	GroupByUser       string = "user_email"
@@ -35,6 +37,7 @@ var (
 	ErrInvalidDateFormat   = errors.New("invalid date format, expected format YYYY-MM-DD")
 	ErrInvalidGroupByValue = fmt.Errorf("invalid group_by value, expected=%v",
 		[]string{GroupByConnection, GroupByID, GroupByType, GroupByUser})
+	ErrInvalidBreakGlassValue = errors.New("invalid break_glass value, expected=true or false")
 )
 
 func GetSessionReport(ctx pgrest.OrgContext, opts ...*SessionOption) (*pgrest.SessionReport, error) {
@@ -46,6 +49,7 @@ func GetSessionReport(ctx pgrest.OrgContext, opts ...*SessionOption) (*pgrest.Se
 		"connection_type": "",
 		"verb":            "",
 		"user_email":      "",
+		"break_glass":     "",
 		"start_date":      today.Format(time.DateOnly),
 		"end_date":        today.AddDate(0, 0, 1).Format(time.DateOnly),
 	}
@@ -61,6 +65,13 @@ func GetSessionReport(ctx pgrest.OrgContext, opts ...*SessionOption) (*pgrest.Se
 				return nil, ErrInvalidGroupByValue
 			}
 		}
+		if opt.OptionKey == OptionBreakGlass {
+			switch fmt.Sprintf("%v", opt.OptionVal) {
+			case "", "true", "false":
+			default:
+				return nil, ErrInvalidBreakGlassValue
+			}
+		}
 		request[opt.OptionKey] = opt.OptionVal
 	}
 	t1, t1Err := time.Parse(time.DateOnly, fmt.Sprintf("%v", request["start_date"]))
@@ -74,6 +85,12 @@ func GetSessionReport(ctx pgrest.OrgContext, opts ...*SessionOption) (*pgrest.Se
 
 	request["org_id"] = ctx.GetOrgID()
 	sessionReport := pgrest.SessionReport{Items: []pgrest.SessionReportItem{}}
+	if request[OptionBreakGlass] != "false" {
+		sessionReport.BreakGlassTotal = countBreakGlassSessions(request)
+		if sessionReport.BreakGlassTotal == -1 {
+			return nil, fmt.Errorf("failed counting break-glass sessions")
+		}
+	}
 	err := pgrest.New("/rpc/session_report").RpcCreate(request).DecodeInto(&sessionReport.Items)
 	switch err {
 	case pgrest.ErrNotFound:
@@ -86,3 +103,25 @@ func GetSessionReport(ctx pgrest.OrgContext, opts ...*SessionOption) (*pgrest.Se
 		return &sessionReport, err
 	}
 }
+
+// countBreakGlassSessions counts the sessions opened bypassing the review in the date range
+// of the report. Differently from the items, sessions without data masking metrics are counted.
+func countBreakGlassSessions(request map[OptionKey]any) int64 {
+	vals := url.Values{}
+	vals.Set("org_id", fmt.Sprintf("eq.%v", request["org_id"]))
+	vals.Set("break_glass", "is.true")
+	vals.Add("created_at", fmt.Sprintf("gte.%v", request[OptionStartDate]))
+	vals.Add("created_at", fmt.Sprintf("lt.%v", request[OptionEndDate]))
+	for key, column := range map[OptionKey]string{
+		OptionID:          "id",
+		OptionVerb:        "verb",
+		"connection_name": "connection",
+		"connection_type": "connection_type",
+		"user_email":      "user_email",
+	} {
+		if val := fmt.Sprintf("%v", request[key]); val != "" {
+			vals.Set(column, fmt.Sprintf("eq.%s", val))
+		}
+	}
+	return pgrest.New("/sessions?%s", vals.Encode()).ExactCount()
+}
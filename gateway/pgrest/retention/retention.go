@@ -0,0 +1,152 @@
+package pgretention
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgreview "github.com/hoophq/hoop/gateway/pgrest/review"
+	"github.com/hoophq/hoop/gateway/session/blobstorage"
+)
+
+type retention struct{}
+
+func New() *retention { return &retention{} }
+
+func (r *retention) FetchAll(ctx pgrest.OrgContext) ([]pgrest.RetentionPolicy, error) {
+	var items []pgrest.RetentionPolicy
+	err := pgrest.New("/retention_policies?org_id=eq.%s&order=name.asc", ctx.GetOrgID()).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *retention) FetchOne(ctx pgrest.OrgContext, name string) (*pgrest.RetentionPolicy, error) {
+	var policy pgrest.RetentionPolicy
+	err := pgrest.New("/retention_policies?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(name)).
+		FetchOne().
+		DecodeInto(&policy)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *retention) Create(ctx pgrest.OrgContext, policy pgrest.RetentionPolicy) (*pgrest.RetentionPolicy, error) {
+	var obj pgrest.RetentionPolicy
+	err := pgrest.New("/retention_policies").Create(map[string]any{
+		"org_id":          ctx.GetOrgID(),
+		"name":            policy.Name,
+		"connection_type": policy.ConnectionType,
+		"verb":            policy.Verb,
+		"connection_tag":  policy.ConnectionTag,
+		"retention_days":  policy.RetentionDays,
+	}).DecodeInto(&obj)
+	return &obj, err
+}
+
+func (r *retention) Update(ctx pgrest.OrgContext, policy pgrest.RetentionPolicy) error {
+	return pgrest.New("/retention_policies?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(policy.Name)).
+		Patch(map[string]any{
+			"connection_type": policy.ConnectionType,
+			"verb":            policy.Verb,
+			"connection_tag":  policy.ConnectionTag,
+			"retention_days":  policy.RetentionDays,
+			"updated_at":      time.Now().UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
+
+func (r *retention) Delete(ctx pgrest.OrgContext, name string) error {
+	return pgrest.New("/retention_policies?org_id=eq.%s&name=eq.%s", ctx.GetOrgID(), url.QueryEscape(name)).
+		Delete().
+		Error()
+}
+
+// FetchOrgIDs returns the organizations that have at least one retention policy
+func (r *retention) FetchOrgIDs() ([]string, error) {
+	var items []pgrest.RetentionPolicy
+	err := pgrest.New("/retention_policies?select=org_id").List().DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	var orgIDs []string
+	seen := map[string]bool{}
+	for _, p := range items {
+		if !seen[p.OrgID] {
+			seen[p.OrgID] = true
+			orgIDs = append(orgIDs, p.OrgID)
+		}
+	}
+	return orgIDs, nil
+}
+
+// FetchExpirationCandidates returns closed sessions created before the date that still have content
+func (r *retention) FetchExpirationCandidates(ctx pgrest.OrgContext, createdBefore time.Time, limit, offset int) ([]pgrest.Session, error) {
+	var items []pgrest.Session
+	err := pgrest.New("/sessions?select=id,org_id,connection,connection_type,verb,status,created_at,blob_input_id,blob_stream_id,"+
+		"blob_stream(id,org_id,type,blob_storage,blob_key)"+
+		"&org_id=eq.%s&status=eq.done&created_at=lt.%s&or=(blob_input_id.not.is.null,blob_stream_id.not.is.null)"+
+		"&order=created_at.asc&limit=%v&offset=%v",
+		ctx.GetOrgID(), createdBefore.UTC().Format(time.RFC3339), limit, offset).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+// PurgeSession removes the content of a session (input and event stream) and its review.
+// The session record is kept to preserve who accessed what and when.
+func (r *retention) PurgeSession(ctx pgrest.OrgContext, sess pgrest.Session) error {
+	if blob := sess.BlobStream; blob != nil && blob.BlobKey != "" && blob.BlobStorage != blobstorage.TypePostgres {
+		store, err := blobstorage.Lookup(blob.BlobStorage)
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(blob.BlobKey); err != nil {
+			return fmt.Errorf("failed removing blob stream from %v, reason=%v", store.Name(), err)
+		}
+	}
+	err := pgrest.New("/sessions?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), sess.ID).
+		Patch(map[string]any{"blob_input_id": nil, "blob_stream_id": nil}).
+		Error()
+	if err != nil {
+		return fmt.Errorf("failed updating session, reason=%v", err)
+	}
+	for _, blobID := range []string{sess.BlobInputID, sess.BlobStreamID} {
+		if blobID == "" {
+			continue
+		}
+		if err := pgrest.New("/blobs?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), blobID).Delete().Error(); err != nil {
+			return fmt.Errorf("failed removing blob %v, reason=%v", blobID, err)
+		}
+	}
+	var reviews []pgreview.Review
+	err = pgrest.New("/reviews?select=id,blob_input_id&org_id=eq.%s&session_id=eq.%s", ctx.GetOrgID(), sess.ID).
+		List().
+		DecodeInto(&reviews)
+	if err != nil && err != pgrest.ErrNotFound {
+		return fmt.Errorf("failed fetching reviews, reason=%v", err)
+	}
+	for _, rev := range reviews {
+		// review groups are removed in cascade
+		if err := pgrest.New("/reviews?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), rev.ID).Delete().Error(); err != nil {
+			return fmt.Errorf("failed removing review %v, reason=%v", rev.ID, err)
+		}
+		if rev.BlobInputID == nil {
+			continue
+		}
+		if err := pgrest.New("/blobs?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), *rev.BlobInputID).Delete().Error(); err != nil {
+			return fmt.Errorf("failed removing review blob %v, reason=%v", *rev.BlobInputID, err)
+		}
+	}
+	return nil
+}
@@ -25,22 +25,28 @@ func (r *review) Upsert(rev *types.Review) error {
 	}
 
 	err := pgrest.New("/reviews?on_conflict=org_id,session_id").Upsert(map[string]any{
-		"id":                  rev.Id,
-		"org_id":              rev.OrgId,
-		"connection_id":       toStringPtr(rev.Connection.Id),
-		"connection_name":     rev.Connection.Name,
-		"session_id":          toStringPtr(rev.Session),
-		"type":                rev.Type,
-		"input_env_vars":      rev.InputEnvVars,
-		"input_client_args":   rev.InputClientArgs,
-		"access_duration_sec": int(rev.AccessDuration.Seconds()),
-		"blob_input_id":       blobInputID,
-		"status":              rev.Status,
-		"owner_id":            rev.ReviewOwner.Id,
-		"owner_email":         rev.ReviewOwner.Email,
-		"owner_name":          rev.ReviewOwner.Name,
-		"owner_slack_id":      rev.ReviewOwner.SlackID,
-		"revoked_at":          rev.RevokeAt,
+		"id":                        rev.Id,
+		"org_id":                    rev.OrgId,
+		"connection_id":             toStringPtr(rev.Connection.Id),
+		"connection_name":           rev.Connection.Name,
+		"session_id":                toStringPtr(rev.Session),
+		"type":                      rev.Type,
+		"input_env_vars":            rev.InputEnvVars,
+		"input_client_args":         rev.InputClientArgs,
+		"access_duration_sec":       int(rev.AccessDuration.Seconds()),
+		"blob_input_id":             blobInputID,
+		"status":                    rev.Status,
+		"owner_id":                  rev.ReviewOwner.Id,
+		"owner_email":               rev.ReviewOwner.Email,
+		"owner_name":                rev.ReviewOwner.Name,
+		"owner_slack_id":            rev.ReviewOwner.SlackID,
+		"revoked_at":                rev.RevokeAt,
+		"workflow":                  rev.Workflow,
+		"expires_at":                rev.ExpiresAt,
+		"scheduled_at":              rev.ScheduledAt,
+		"break_glass_justification": toStringPtr(rev.BreakGlassJustification),
+		"justification":             toStringPtr(rev.Justification),
+		"ticket_id":                 toStringPtr(rev.TicketID),
 		// required only for migrating resources from xtdb to postgrest
 		"created_at": toStringPtr(createdAt),
 	}).Error()
@@ -135,7 +141,7 @@ func (r *review) FetchOneBySid(ctx pgrest.OrgContext, sid string) (*types.Review
 
 func (r *review) FetchJit(ctx pgrest.OrgContext, connectionID string) (*types.Review, error) {
 	var rev Review
-	err := pgrest.New("/reviews?org_id=eq.%s&connection_id=eq.%s&type=eq.jit&status=eq.APPROVED&revoked_at=gt.now&select=*,review_groups(*)",
+	err := pgrest.New("/reviews?org_id=eq.%s&connection_id=eq.%s&type=eq.jit&status=eq.APPROVED&revoked_at=gt.now&break_glass_justification=is.null&select=*,review_groups(*)",
 		ctx.GetOrgID(), url.QueryEscape(connectionID)).
 		FetchOne().
 		DecodeInto(&rev)
@@ -150,19 +156,25 @@ func (r *review) FetchJit(ctx pgrest.OrgContext, connectionID string) (*types.Re
 
 func ToJson(rev types.Review) *types.ReviewJSON {
 	return &types.ReviewJSON{
-		Id:               rev.Id,
-		OrgId:            rev.OrgId,
-		CreatedAt:        rev.CreatedAt,
-		Type:             rev.Type,
-		Session:          rev.Session,
-		Input:            rev.Input,
-		InputEnvVars:     rev.InputEnvVars,
-		InputClientArgs:  rev.InputClientArgs,
-		AccessDuration:   rev.AccessDuration,
-		Status:           rev.Status,
-		RevokeAt:         rev.RevokeAt,
-		ReviewOwner:      rev.ReviewOwner,
-		ReviewGroupsData: rev.ReviewGroupsData,
+		Id:                      rev.Id,
+		OrgId:                   rev.OrgId,
+		CreatedAt:               rev.CreatedAt,
+		Type:                    rev.Type,
+		Session:                 rev.Session,
+		Input:                   rev.Input,
+		InputEnvVars:            rev.InputEnvVars,
+		InputClientArgs:         rev.InputClientArgs,
+		AccessDuration:          rev.AccessDuration,
+		Status:                  rev.Status,
+		RevokeAt:                rev.RevokeAt,
+		ReviewOwner:             rev.ReviewOwner,
+		ReviewGroupsData:        rev.ReviewGroupsData,
+		Workflow:                rev.Workflow,
+		ExpiresAt:               rev.ExpiresAt,
+		ScheduledAt:             rev.ScheduledAt,
+		BreakGlassJustification: rev.BreakGlassJustification,
+		Justification:           rev.Justification,
+		TicketID:                rev.TicketID,
 		Connection: types.ReviewConnection{
 			Id:   rev.Connection.Id,
 			Name: rev.Connection.Name,
@@ -196,8 +208,14 @@ func parseReview(r Review) *types.Review {
 		},
 		// the connection id is expanded is used to perform a join on xtdb
 		// when the entity exists this field is a map, otherwise is a string containing the xtid
-		ConnectionId:    r.ConnectionID,
-		ReviewGroupsIds: []string{},
+		ConnectionId:            r.ConnectionID,
+		ReviewGroupsIds:         []string{},
+		Workflow:                r.Workflow,
+		ExpiresAt:               r.GetExpiresAt(),
+		ScheduledAt:             r.GetScheduledAt(),
+		BreakGlassJustification: toString(r.BreakGlassJustification),
+		Justification:           toString(r.Justification),
+		TicketID:                toString(r.TicketID),
 	}
 	for _, rg := range r.ReviewGroups {
 		revGroup := types.ReviewGroup{
@@ -226,3 +244,100 @@ func (r *review) PatchStatus(ctx pgrest.OrgContext, reviewID string, status type
 		Patch(map[string]any{"status": status}).
 		Error()
 }
+
+// FetchExpired returns the pending reviews of all organizations that are past their expiration time
+func (r *review) FetchExpired(now time.Time, limit int) ([]types.Review, error) {
+	var items []Review
+	err := pgrest.New("/reviews?status=eq.PENDING&expires_at=lt.%s&select=*,review_groups(*)&order=expires_at.asc&limit=%v",
+		url.QueryEscape(now.UTC().Format(time.RFC3339)), limit).
+		List().
+		DecodeInto(&items)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result []types.Review
+	for _, r := range items {
+		result = append(result, *parseReview(r))
+	}
+	return result, nil
+}
+
+// Expire changes the status of a pending review to expired.
+// It returns pgrest.ErrNotFound if the review is not pending anymore.
+func (r *review) Expire(ctx pgrest.OrgContext, reviewID string) error {
+	return r.UpdateStatusIf(ctx, reviewID, types.ReviewStatusPending, types.ReviewStatusExpired)
+}
+
+// FetchScheduled returns the approved one time reviews of all organizations that reached their scheduled time
+func (r *review) FetchScheduled(now time.Time, limit int) ([]types.Review, error) {
+	var items []Review
+	err := pgrest.New("/reviews?status=eq.APPROVED&type=eq.onetime&scheduled_at=lte.%s&select=*,review_groups(*),blob_input(*)&order=scheduled_at.asc&limit=%v",
+		url.QueryEscape(now.UTC().Format(time.RFC3339)), limit).
+		List().
+		DecodeInto(&items)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result []types.Review
+	for _, r := range items {
+		result = append(result, *parseReview(r))
+	}
+	return result, nil
+}
+
+// PatchScheduledAtIf updates the time to execute a review only if it's in the expected state,
+// a nil value removes the schedule. It returns pgrest.ErrNotFound if the review is in a different state.
+func (r *review) PatchScheduledAtIf(ctx pgrest.OrgContext, reviewID string, status types.ReviewStatus, scheduledAt *time.Time) error {
+	var items []Review
+	return pgrest.New("/reviews?org_id=eq.%s&id=eq.%s&status=eq.%s", ctx.GetOrgID(), url.QueryEscape(reviewID), status).
+		Patch(map[string]any{"scheduled_at": scheduledAt}).
+		DecodeInto(&items)
+}
+
+// UpdateStatusIf changes the status of a review only if it's in the expected state.
+// It returns pgrest.ErrNotFound if the review is in a different state.
+func (r *review) UpdateStatusIf(ctx pgrest.OrgContext, reviewID string, from, to types.ReviewStatus) error {
+	var items []Review
+	return pgrest.New("/reviews?org_id=eq.%s&id=eq.%s&status=eq.%s", ctx.GetOrgID(), url.QueryEscape(reviewID), from).
+		Patch(map[string]any{"status": to}).
+		DecodeInto(&items)
+}
+
+// FetchWorkflow returns the approval workflow of a connection, it returns nil if it's not found
+func (r *review) FetchWorkflow(ctx pgrest.OrgContext, connectionID string) (*Workflow, error) {
+	var wf Workflow
+	err := pgrest.New("/review_workflows?org_id=eq.%s&connection_id=eq.%s", ctx.GetOrgID(), url.QueryEscape(connectionID)).
+		FetchOne().
+		DecodeInto(&wf)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &wf, nil
+}
+
+// UpsertWorkflow creates or replaces the approval workflow of a connection
+func (r *review) UpsertWorkflow(ctx pgrest.OrgContext, wf Workflow) error {
+	return pgrest.New("/review_workflows?on_conflict=org_id,connection_id").Upsert(map[string]any{
+		"org_id":               ctx.GetOrgID(),
+		"connection_id":        wf.ConnectionID,
+		"stages":               wf.Stages,
+		"allow_self_approval":  wf.AllowSelfApproval,
+		"separation_of_duties": wf.SeparationOfDuties,
+		"updated_at":           time.Now().UTC().Format(time.RFC3339Nano),
+	}).Error()
+}
+
+func (r *review) DeleteWorkflow(ctx pgrest.OrgContext, connectionID string) error {
+	return pgrest.New("/review_workflows?org_id=eq.%s&connection_id=eq.%s", ctx.GetOrgID(), url.QueryEscape(connectionID)).
+		Delete().
+		Error()
+}
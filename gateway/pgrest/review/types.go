@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
 )
 
 type ReviewGroup struct {
@@ -21,28 +22,62 @@ type ReviewGroup struct {
 }
 
 type Review struct {
-	ID                string            `json:"id"`
-	OrgID             string            `json:"org_id"`
-	SessionID         *string           `json:"session_id"`
-	ConnectionID      *string           `json:"connection_id"`
-	ConnectionName    string            `json:"connection_name"`
-	Type              string            `json:"type"`
-	BlobInputID       *string           `json:"blob_input_id"`
-	InputEnvVars      map[string]string `json:"input_env_vars"`
-	InputClientArgs   []string          `json:"input_client_args"`
-	AccessDurationSec int               `json:"access_duration_sec"`
-	Status            string            `json:"status"`
-	OwnerUserID       string            `json:"owner_id"`
-	OwnerEmail        string            `json:"owner_email"`
-	OwnerName         *string           `json:"owner_name"`
-	OwnerSlackID      *string           `json:"owner_slack_id"`
-	CreatedAt         string            `json:"created_at"`
-	RevokedAt         *string           `json:"revoked_at"`
+	ID                      string                `json:"id"`
+	OrgID                   string                `json:"org_id"`
+	SessionID               *string               `json:"session_id"`
+	ConnectionID            *string               `json:"connection_id"`
+	ConnectionName          string                `json:"connection_name"`
+	Type                    string                `json:"type"`
+	BlobInputID             *string               `json:"blob_input_id"`
+	InputEnvVars            map[string]string     `json:"input_env_vars"`
+	InputClientArgs         []string              `json:"input_client_args"`
+	AccessDurationSec       int                   `json:"access_duration_sec"`
+	Status                  string                `json:"status"`
+	OwnerUserID             string                `json:"owner_id"`
+	OwnerEmail              string                `json:"owner_email"`
+	OwnerName               *string               `json:"owner_name"`
+	OwnerSlackID            *string               `json:"owner_slack_id"`
+	CreatedAt               string                `json:"created_at"`
+	RevokedAt               *string               `json:"revoked_at"`
+	Workflow                *types.ReviewWorkflow `json:"workflow"`
+	ExpiresAt               *string               `json:"expires_at"`
+	ScheduledAt             *string               `json:"scheduled_at"`
+	BreakGlassJustification *string               `json:"break_glass_justification"`
+	Justification           *string               `json:"justification"`
+	TicketID                *string               `json:"ticket_id"`
 
 	BlobInput    *pgrest.Blob  `json:"blob_input"`
 	ReviewGroups []ReviewGroup `json:"review_groups"`
 }
 
+type Workflow struct {
+	ID                 string              `json:"id"`
+	OrgID              string              `json:"org_id"`
+	ConnectionID       string              `json:"connection_id"`
+	Stages             []types.ReviewStage `json:"stages"`
+	AllowSelfApproval  bool                `json:"allow_self_approval"`
+	SeparationOfDuties bool                `json:"separation_of_duties"`
+	CreatedAt          string              `json:"created_at"`
+	UpdatedAt          string              `json:"updated_at"`
+}
+
+// ReviewWorkflow returns a new workflow state to be attached to a review
+func (w *Workflow) ReviewWorkflow() *types.ReviewWorkflow {
+	wf := &types.ReviewWorkflow{
+		AllowSelfApproval:  w.AllowSelfApproval,
+		SeparationOfDuties: w.SeparationOfDuties,
+	}
+	for _, stage := range w.Stages {
+		wf.Stages = append(wf.Stages, types.ReviewStage{
+			Name:         stage.Name,
+			Groups:       stage.Groups,
+			MinApprovals: stage.MinApprovals,
+			Status:       types.ReviewStatusPending,
+		})
+	}
+	return wf
+}
+
 // func (r *Review) GetSessionID() string {
 // 	if r.SessionID != nil {
 // 		return *r.SessionID
@@ -63,6 +98,22 @@ func (r *Review) GetRevokedAt() *time.Time {
 	return nil
 }
 
+func (r *Review) GetExpiresAt() *time.Time {
+	if r.ExpiresAt != nil {
+		expiresAt, _ := time.ParseInLocation("2006-01-02T15:04:05", *r.ExpiresAt, time.UTC)
+		return &expiresAt
+	}
+	return nil
+}
+
+func (r *Review) GetScheduledAt() *time.Time {
+	if r.ScheduledAt != nil {
+		scheduledAt, _ := time.ParseInLocation("2006-01-02T15:04:05", *r.ScheduledAt, time.UTC)
+		return &scheduledAt
+	}
+	return nil
+}
+
 func (r *Review) GetBlobInput() (v string) {
 	if r.BlobInput != nil {
 		if len(r.BlobInput.BlobStream) > 0 {
@@ -0,0 +1,102 @@
+package pgscim
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+type scimToken struct {
+	OrgID     string `json:"org_id"`
+	TokenHash string `json:"token_hash"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type scim struct{}
+
+func New() *scim { return &scim{} }
+
+// UpsertToken replaces the SCIM token of the organization
+func (s *scim) UpsertToken(ctx pgrest.OrgContext, tokenHash, createdBy string) error {
+	return pgrest.New("/scim_tokens?on_conflict=org_id").Upsert(map[string]any{
+		"org_id":     ctx.GetOrgID(),
+		"token_hash": tokenHash,
+		"created_by": createdBy,
+		"created_at": time.Now().UTC().Format(time.RFC3339Nano),
+	}).Error()
+}
+
+// FetchOrgIDByToken returns the organization of the token hash
+// or an empty string if the token doesn't exist
+func (s *scim) FetchOrgIDByToken(tokenHash string) (string, error) {
+	var token scimToken
+	err := pgrest.New("/scim_tokens?token_hash=eq.%s", url.QueryEscape(tokenHash)).
+		FetchOne().
+		DecodeInto(&token)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return token.OrgID, nil
+}
+
+func (s *scim) DeleteToken(ctx pgrest.OrgContext) error {
+	return pgrest.New("/scim_tokens?org_id=eq.%s", ctx.GetOrgID()).Delete().Error()
+}
+
+func (s *scim) FetchAllGroups(ctx pgrest.OrgContext) ([]pgrest.SCIMGroup, error) {
+	var items []pgrest.SCIMGroup
+	err := pgrest.New("/scim_groups?org_id=eq.%s&order=display_name.asc", ctx.GetOrgID()).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *scim) FetchOneGroup(ctx pgrest.OrgContext, id string) (*pgrest.SCIMGroup, error) {
+	return s.fetchOneGroup("/scim_groups?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), url.QueryEscape(id))
+}
+
+func (s *scim) FetchOneGroupByName(ctx pgrest.OrgContext, displayName string) (*pgrest.SCIMGroup, error) {
+	return s.fetchOneGroup("/scim_groups?org_id=eq.%s&display_name=eq.%s", ctx.GetOrgID(), url.QueryEscape(displayName))
+}
+
+func (s *scim) fetchOneGroup(path string, a ...any) (*pgrest.SCIMGroup, error) {
+	var group pgrest.SCIMGroup
+	if err := pgrest.New(path, a...).FetchOne().DecodeInto(&group); err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (s *scim) CreateGroup(ctx pgrest.OrgContext, displayName string) (*pgrest.SCIMGroup, error) {
+	var group pgrest.SCIMGroup
+	err := pgrest.New("/scim_groups").Create(map[string]any{
+		"org_id":       ctx.GetOrgID(),
+		"display_name": displayName,
+	}).DecodeInto(&group)
+	return &group, err
+}
+
+func (s *scim) UpdateGroup(ctx pgrest.OrgContext, id, displayName string) error {
+	return pgrest.New("/scim_groups?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), url.QueryEscape(id)).
+		Patch(map[string]any{
+			"display_name": displayName,
+			"updated_at":   time.Now().UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
+
+func (s *scim) DeleteGroup(ctx pgrest.OrgContext, id string) error {
+	return pgrest.New("/scim_groups?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), url.QueryEscape(id)).
+		Delete().
+		Error()
+}
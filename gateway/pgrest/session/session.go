@@ -1,6 +1,7 @@
 package pgsession
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"sort"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/hoophq/hoop/gateway/session/blobstorage"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
 )
 
@@ -23,6 +25,20 @@ func (s *session) UpdateStatus(ctx pgrest.OrgContext, sessionID, status string)
 		Error()
 }
 
+// UpdateBreakGlass flags the session as opened bypassing the review
+func (s *session) UpdateBreakGlass(ctx pgrest.OrgContext, sessionID string) error {
+	return pgrest.New("/sessions?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), sessionID).
+		Patch(map[string]any{"break_glass": true}).
+		Error()
+}
+
+// UpdateMetadata replaces the metadata attributes of the session
+func (s *session) UpdateMetadata(ctx pgrest.OrgContext, sessionID string, metadata map[string]any) error {
+	return pgrest.New("/sessions?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), sessionID).
+		Patch(map[string]any{"metadata": metadata}).
+		Error()
+}
+
 func (s *session) Upsert(ctx pgrest.OrgContext, sess types.Session) (err error) {
 	switch sess.Status {
 	// this will be executed in distinct flows
@@ -62,12 +78,7 @@ func (s *session) Upsert(ctx pgrest.OrgContext, sess types.Session) (err error)
 			if err != nil || len(sess.NonIndexedStream["stream"]) == 0 {
 				return
 			}
-			err = pgrest.New("/blobs").Create(map[string]any{
-				"id":          blobStreamID,
-				"org_id":      sess.OrgID,
-				"type":        "session-stream",
-				"blob_stream": sess.NonIndexedStream["stream"],
-			}).Error()
+			err = writeBlobStream(blobStreamID, sess)
 		}()
 		if sess.Metadata == nil {
 			sess.Metadata = map[string]any{}
@@ -109,7 +120,7 @@ func (s *session) FetchAll(ctx pgrest.OrgContext, opts ...*pgrest.SessionOption)
 
 func (s *session) FetchOne(ctx pgrest.OrgContext, sessionID string) (*types.Session, error) {
 	var sess pgrest.Session
-	err := pgrest.New("/sessions?select=*,blob_input(id,org_id,type,type,size,blob_stream),blob_stream(id,org_id,type,size,blob_stream)&org_id=eq.%s&id=eq.%s",
+	err := pgrest.New("/sessions?select=*,blob_input(id,org_id,type,type,size,blob_stream),blob_stream(id,org_id,type,size,blob_stream,blob_storage,blob_key)&org_id=eq.%s&id=eq.%s",
 		ctx.GetOrgID(), url.QueryEscape(sessionID)).
 		FetchOne().
 		DecodeInto(&sess)
@@ -120,6 +131,9 @@ func (s *session) FetchOne(ctx pgrest.OrgContext, sessionID string) (*types.Sess
 		return nil, err
 	}
 
+	if err := readBlobStream(sess.BlobStream); err != nil {
+		return nil, fmt.Errorf("failed reading session blob stream, reason=%v", err)
+	}
 	blobStream, blobStreamSize := sess.GetBlobStream()
 	return &types.Session{
 		ID:               sess.ID,
@@ -143,6 +157,51 @@ func (s *session) FetchOne(ctx pgrest.OrgContext, sessionID string) (*types.Sess
 	}, nil
 }
 
+// writeBlobStream persists the event stream of a session using the configured
+// storage backend. The blob record is always created to keep track of the
+// location of its content.
+func writeBlobStream(blobStreamID string, sess types.Session) error {
+	store := blobstorage.Get()
+	blob := map[string]any{
+		"id":           blobStreamID,
+		"org_id":       sess.OrgID,
+		"type":         "session-stream",
+		"blob_stream":  sess.NonIndexedStream["stream"],
+		"blob_storage": store.Name(),
+	}
+	if store.Name() == blobstorage.TypePostgres {
+		return pgrest.New("/blobs").Create(blob).Error()
+	}
+	data, err := json.Marshal(sess.NonIndexedStream["stream"])
+	if err != nil {
+		return fmt.Errorf("failed encoding session stream, reason=%v", err)
+	}
+	blobKey := blobstorage.SessionStreamKey(sess.OrgID, sess.ID)
+	if err := store.Put(blobKey, data); err != nil {
+		return fmt.Errorf("failed storing session stream in %v, reason=%v", store.Name(), err)
+	}
+	blob["blob_stream"] = []any{}
+	blob["blob_key"] = blobKey
+	blob["blob_size"] = len(data)
+	return pgrest.New("/blobs").Create(blob).Error()
+}
+
+// readBlobStream fetches the content of the blob when it's stored in an external storage
+func readBlobStream(blob *pgrest.Blob) error {
+	if blob == nil || blob.BlobKey == "" || blob.BlobStorage == "" || blob.BlobStorage == blobstorage.TypePostgres {
+		return nil
+	}
+	store, err := blobstorage.Lookup(blob.BlobStorage)
+	if err != nil {
+		return err
+	}
+	data, err := store.Get(blob.BlobKey)
+	if err != nil {
+		return fmt.Errorf("failed fetching blob %v from %v, reason=%v", blob.BlobKey, store.Name(), err)
+	}
+	return json.Unmarshal(data, &blob.BlobStream)
+}
+
 func (s *session) FetchAllFromDate(fromDate time.Time) ([]*types.Session, error) {
 	var sessionList []*types.Session
 	err := pgrest.New("/sessions?select=id,org_id&created_at=gt.%s", fromDate.Format(time.RFC3339)).List().DecodeInto(&sessionList)
@@ -33,6 +33,7 @@ type Login struct {
 	Outcome  string `json:"outcome"`
 	Redirect string `json:"redirect"`
 	SlackID  string `json:"slack_id"`
+	Provider string `json:"provider"`
 }
 
 type Org struct {
@@ -88,21 +89,27 @@ const (
 )
 
 type Connection struct {
-	ID                 string            `json:"id"`
-	OrgID              string            `json:"org_id"`
-	AgentID            string            `json:"agent_id"`
-	Name               string            `json:"name"`
-	Command            []string          `json:"command"`
-	Type               string            `json:"type"`
-	SubType            string            `json:"subtype"`
-	Envs               map[string]string `json:"envs"`
-	Status             string            `json:"status"` // read only field
-	ManagedBy          *string           `json:"managed_by"`
-	Tags               []string          `json:"tags"`
-	AccessModeRunbooks string            `json:"access_mode_runbooks"`
-	AccessModeExec     string            `json:"access_mode_exec"`
-	AccessModeConnect  string            `json:"access_mode_connect"`
-	AccessSchema       string            `json:"access_schema"`
+	ID                   string            `json:"id"`
+	OrgID                string            `json:"org_id"`
+	AgentID              string            `json:"agent_id"`
+	Name                 string            `json:"name"`
+	Command              []string          `json:"command"`
+	Type                 string            `json:"type"`
+	SubType              string            `json:"subtype"`
+	Envs                 map[string]string `json:"envs"`
+	Status               string            `json:"status"` // read only field
+	ManagedBy            *string           `json:"managed_by"`
+	Tags                 []string          `json:"tags"`
+	AccessModeRunbooks   string            `json:"access_mode_runbooks"`
+	AccessModeExec       string            `json:"access_mode_exec"`
+	AccessModeConnect    string            `json:"access_mode_connect"`
+	AccessSchema         string            `json:"access_schema"`
+	ReviewTTLSec         int               `json:"review_ttl_sec"`
+	RequireJustification bool              `json:"require_justification"`
+	TicketIDPattern      string            `json:"ticket_id_pattern"`
+	RequireRecentAuth    int               `json:"require_recent_auth"`
+	DenyRules            []string          `json:"deny_rules"`
+	BreakGlassGroups     []string          `json:"break_glass_groups"`
 
 	// read only attributes
 	Org              Org                `json:"orgs"`
@@ -146,6 +153,10 @@ type Blob struct {
 	Type       string `json:"type"`
 	Size       int64  `json:"size"`
 	BlobStream []any  `json:"blob_stream"`
+	// the storage backend that has the content of the blob stream,
+	// it's empty or postgres when the content is stored inline
+	BlobStorage string `json:"blob_storage"`
+	BlobKey     string `json:"blob_key"`
 }
 
 type Session struct {
@@ -170,6 +181,101 @@ type Session struct {
 	EndedAt   *string `json:"ended_at"`
 }
 
+type RetentionPolicy struct {
+	ID             string `json:"id"`
+	OrgID          string `json:"org_id"`
+	Name           string `json:"name"`
+	ConnectionType string `json:"connection_type"`
+	Verb           string `json:"verb"`
+	ConnectionTag  string `json:"connection_tag"`
+	RetentionDays  int    `json:"retention_days"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+type ReviewAutoApprovalRule struct {
+	ID            string         `json:"id"`
+	OrgID         string         `json:"org_id"`
+	Name          string         `json:"name"`
+	Connections   []string       `json:"connections"`
+	ReadOnly      bool           `json:"read_only"`
+	QueryPattern  string         `json:"query_pattern"`
+	Groups        []string       `json:"groups"`
+	BusinessHours *BusinessHours `json:"business_hours"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
+}
+
+type UserGroupGrant struct {
+	ID        string  `json:"id"`
+	OrgID     string  `json:"org_id"`
+	UserID    string  `json:"user_id"`
+	UserEmail string  `json:"user_email"`
+	GroupName string  `json:"group_name"`
+	Reason    string  `json:"reason"`
+	Status    string  `json:"status"`
+	GrantedBy string  `json:"granted_by"`
+	RevokedBy string  `json:"revoked_by"`
+	ExpiresAt string  `json:"expires_at"`
+	RevokedAt *string `json:"revoked_at"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type SCIMGroup struct {
+	ID          string `json:"id"`
+	OrgID       string `json:"org_id"`
+	DisplayName string `json:"display_name"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type UserCredentials struct {
+	UserID         string   `json:"user_id"`
+	OrgID          string   `json:"org_id"`
+	PasswordHash   string   `json:"password_hash"`
+	TOTPSecret     string   `json:"totp_secret"`
+	TOTPEnabled    bool     `json:"totp_enabled"`
+	TOTPLastStep   int64    `json:"totp_last_step"`
+	RecoveryCodes  []string `json:"recovery_codes"`
+	FailedAttempts int      `json:"failed_attempts"`
+	LockedUntil    *string  `json:"locked_until"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+type UserToken struct {
+	ID         string   `json:"id"`
+	OrgID      string   `json:"org_id"`
+	UserID     string   `json:"user_id"`
+	Name       string   `json:"name"`
+	TokenHash  string   `json:"token_hash"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type AccessRule struct {
+	ID          string   `json:"id"`
+	OrgID       string   `json:"org_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Effect      string   `json:"effect"`
+	Expression  string   `json:"expression"`
+	Connections []string `json:"connections"`
+	Timezone    string   `json:"timezone"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type BusinessHours struct {
+	Timezone string `json:"timezone"`
+	Weekdays []int  `json:"weekdays"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+}
+
 type SessionList struct {
 	Total       int64     `json:"total"`
 	HasNextPage bool      `json:"has_next_page"`
@@ -180,6 +286,7 @@ type SessionReport struct {
 	Items                 []SessionReportItem `json:"items"`
 	TotalRedactCount      int64               `json:"total_redact_count"`
 	TotalTransformedBytes int64               `json:"total_transformed_bytes"`
+	BreakGlassTotal       int64               `json:"break_glass_total"`
 }
 
 type SessionReportItem struct {
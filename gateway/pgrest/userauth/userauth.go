@@ -13,6 +13,7 @@ func New() *userAuth { return &userAuth{} }
 
 // FetchUserContext fetches the user context based on the subject, which is usually
 // the OIDC subject. In case the user doesn't exists, try to load a service account.
+// The groups of the context don't contain the time-bound memberships that are expired.
 func (u *userAuth) FetchUserContext(subject string) (*Context, error) {
 	usr, err := fetchOneBySubject(subject)
 	if err != nil {
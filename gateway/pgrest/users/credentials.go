@@ -0,0 +1,109 @@
+package pgusers
+
+import (
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+// FetchCredentials returns the local credentials of the user or nil if the user doesn't have a password
+func (u *user) FetchCredentials(ctx pgrest.OrgContext, userID string) (*pgrest.UserCredentials, error) {
+	var creds pgrest.UserCredentials
+	err := pgrest.New("/user_credentials?org_id=eq.%s&user_id=eq.%s", ctx.GetOrgID(), userID).
+		FetchOne().
+		DecodeInto(&creds)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// UpsertCredentials creates or replaces the local credentials of a user
+func (u *user) UpsertCredentials(creds *pgrest.UserCredentials) error {
+	recoveryCodes := creds.RecoveryCodes
+	if recoveryCodes == nil {
+		recoveryCodes = []string{}
+	}
+	return pgrest.New("/user_credentials?on_conflict=user_id").Upsert(map[string]any{
+		"user_id":         creds.UserID,
+		"org_id":          creds.OrgID,
+		"password_hash":   creds.PasswordHash,
+		"totp_secret":     creds.TOTPSecret,
+		"totp_enabled":    creds.TOTPEnabled,
+		"totp_last_step":  creds.TOTPLastStep,
+		"recovery_codes":  recoveryCodes,
+		"failed_attempts": creds.FailedAttempts,
+		"locked_until":    creds.LockedUntil,
+		"updated_at":      time.Now().UTC().Format(time.RFC3339Nano),
+	}).Error()
+}
+
+// RegisterLoginFailure increments the failed attempts of the user atomically and locks the account
+// for the lockout duration when the maximum number of attempts is reached. It returns the updated credentials.
+func (u *user) RegisterLoginFailure(ctx pgrest.OrgContext, userID string, maxAttempts int, lockout time.Duration) (*pgrest.UserCredentials, error) {
+	var items []pgrest.UserCredentials
+	err := pgrest.New("/rpc/register_login_failure").RpcCreate(map[string]any{
+		"org_id":          ctx.GetOrgID(),
+		"user_id":         userID,
+		"max_attempts":    maxAttempts,
+		"lockout_seconds": int(lockout.Seconds()),
+	}).DecodeInto(&items)
+	if err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// UpdateTOTPLastStepIf stores the last time step used by the user only if it's greater than the
+// stored one, it returns pgrest.ErrNotFound if the time step was already used (replay).
+func (u *user) UpdateTOTPLastStepIf(ctx pgrest.OrgContext, userID string, step int64) error {
+	var items []pgrest.UserCredentials
+	return pgrest.New("/user_credentials?org_id=eq.%s&user_id=eq.%s&totp_last_step=lt.%v", ctx.GetOrgID(), userID, step).
+		Patch(map[string]any{
+			"totp_last_step": step,
+			"updated_at":     time.Now().UTC().Format(time.RFC3339Nano),
+		}).
+		DecodeInto(&items)
+}
+
+// UseRecoveryCode removes the hash of a recovery code from the credentials atomically,
+// it returns pgrest.ErrNotFound if the code doesn't exist or it was already used.
+func (u *user) UseRecoveryCode(ctx pgrest.OrgContext, userID, codeHash string) error {
+	var items []pgrest.UserCredentials
+	return pgrest.New("/rpc/use_recovery_code").RpcCreate(map[string]any{
+		"org_id":    ctx.GetOrgID(),
+		"user_id":   userID,
+		"code_hash": codeHash,
+	}).DecodeInto(&items)
+}
+
+// RegisterFirstUser creates the user with its credentials only if the organization doesn't have users,
+// it returns pgrest.ErrNotFound if the organization already has users.
+func (u *user) RegisterFirstUser(v pgrest.User, passwordHash string) error {
+	var items []pgrest.User
+	return pgrest.New("/rpc/register_first_user").RpcCreate(map[string]any{
+		"id":            v.ID,
+		"org_id":        v.OrgID,
+		"subject":       v.Subject,
+		"email":         v.Email,
+		"name":          v.Name,
+		"verified":      v.Verified,
+		"status":        v.Status,
+		"groups":        v.Groups,
+		"password_hash": passwordHash,
+	}).DecodeInto(&items)
+}
+
+// ResetLoginFailures removes the failed attempts and the lock of the account
+func (u *user) ResetLoginFailures(ctx pgrest.OrgContext, userID string) error {
+	return pgrest.New("/user_credentials?org_id=eq.%s&user_id=eq.%s", ctx.GetOrgID(), userID).
+		Patch(map[string]any{
+			"failed_attempts": 0,
+			"locked_until":    nil,
+			"updated_at":      time.Now().UTC().Format(time.RFC3339Nano),
+		}).
+		Error()
+}
@@ -0,0 +1,79 @@
+package pgusers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePgRest answers the requests with the response body and keeps the last request
+type fakePgRest struct {
+	body string
+	req  *http.Request
+}
+
+func (f *fakePgRest) Do(req *http.Request) (*http.Response, error) {
+	f.req = req
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader([]byte(f.body))),
+	}, nil
+}
+
+func withFakePgRest(t *testing.T, body string) *fakePgRest {
+	fake := &fakePgRest{body: body}
+	pgrest.WithHttpClient(fake)
+	pgrest.WithBaseURL(&url.URL{Host: "127.0.0.1:3008"})
+	t.Cleanup(func() { pgrest.WithHttpClient(http.DefaultClient) })
+	return fake
+}
+
+func TestUpdateTOTPLastStepIf(t *testing.T) {
+	ctx := pgrest.NewOrgContext("org-id")
+	fake := withFakePgRest(t, `[{"user_id": "user-id", "totp_last_step": 10}]`)
+	assert.Nil(t, New().UpdateTOTPLastStepIf(ctx, "user-id", 10))
+	assert.Equal(t, "PATCH", fake.req.Method)
+	assert.Equal(t, "lt.10", fake.req.URL.Query().Get("totp_last_step"), "it must only update previous time steps")
+
+	withFakePgRest(t, `[]`)
+	assert.Equal(t, pgrest.ErrNotFound, New().UpdateTOTPLastStepIf(ctx, "user-id", 10), "it must report used time steps")
+}
+
+func TestRegisterLoginFailure(t *testing.T) {
+	ctx := pgrest.NewOrgContext("org-id")
+	fake := withFakePgRest(t, `[{"user_id": "user-id", "failed_attempts": 2}]`)
+	creds, err := New().RegisterLoginFailure(ctx, "user-id", 5, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, creds.FailedAttempts)
+	assert.Equal(t, "/rpc/register_login_failure", fake.req.URL.Path)
+
+	withFakePgRest(t, `[]`)
+	_, err = New().RegisterLoginFailure(ctx, "user-id", 5, 0)
+	assert.Equal(t, pgrest.ErrNotFound, err)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	ctx := pgrest.NewOrgContext("org-id")
+	fake := withFakePgRest(t, `[{"user_id": "user-id"}]`)
+	assert.Nil(t, New().UseRecoveryCode(ctx, "user-id", "code-hash"))
+	assert.Equal(t, "/rpc/use_recovery_code", fake.req.URL.Path)
+
+	withFakePgRest(t, `[]`)
+	assert.Equal(t, pgrest.ErrNotFound, New().UseRecoveryCode(ctx, "user-id", "code-hash"), "it must report used codes")
+}
+
+func TestRegisterFirstUser(t *testing.T) {
+	usr := pgrest.User{ID: "user-id", OrgID: "org-id", Email: "admin@example.com", Groups: []string{"admin"}}
+	fake := withFakePgRest(t, `[{"id": "user-id"}]`)
+	assert.Nil(t, New().RegisterFirstUser(usr, "password-hash"))
+	assert.Equal(t, "/rpc/register_first_user", fake.req.URL.Path)
+
+	withFakePgRest(t, `[]`)
+	assert.Equal(t, pgrest.ErrNotFound, New().RegisterFirstUser(usr, "password-hash"), "it must report organizations with users")
+}
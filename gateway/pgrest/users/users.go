@@ -103,6 +103,20 @@ func (u *user) FetchUnverifiedUser(ctx pgrest.OrgContext, email string) (*pgrest
 	return &usr, nil
 }
 
+func (u *user) FetchOneByID(ctx pgrest.OrgContext, id string) (*pgrest.User, error) {
+	var usr pgrest.User
+	err := pgrest.New("/users?select=*,groups,orgs(id,name)&org_id=eq.%v&id=eq.%v", ctx.GetOrgID(), url.QueryEscape(id)).
+		FetchOne().
+		DecodeInto(&usr)
+	if err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &usr, nil
+}
+
 func (u *user) FetchOneByEmail(ctx pgrest.OrgContext, email string) (*pgrest.User, error) {
 	var usr pgrest.User
 	err := pgrest.New("/users?select=*,groups,orgs(id,name)&org_id=eq.%v&email=eq.%v", ctx.GetOrgID(), url.QueryEscape(email)).
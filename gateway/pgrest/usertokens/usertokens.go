@@ -0,0 +1,93 @@
+package pgusertokens
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+type userTokens struct{}
+
+func New() *userTokens { return &userTokens{} }
+
+func (u *userTokens) Create(ctx pgrest.OrgContext, userID, name, tokenHash string, scopes []string, expiresAt *time.Time) (*pgrest.UserToken, error) {
+	reqBody := map[string]any{
+		"org_id":     ctx.GetOrgID(),
+		"user_id":    userID,
+		"name":       name,
+		"token_hash": tokenHash,
+		"scopes":     scopes,
+		"expires_at": nil,
+		"created_at": time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if expiresAt != nil {
+		reqBody["expires_at"] = expiresAt.UTC().Format(time.RFC3339Nano)
+	}
+	var token pgrest.UserToken
+	if err := pgrest.New("/user_tokens").Create(reqBody).DecodeInto(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FetchAllByUser returns the tokens of a user, including the revoked and expired ones
+func (u *userTokens) FetchAllByUser(ctx pgrest.OrgContext, userID string) ([]pgrest.UserToken, error) {
+	var items []pgrest.UserToken
+	err := pgrest.New("/user_tokens?org_id=eq.%s&user_id=eq.%s&order=created_at.desc",
+		ctx.GetOrgID(), url.QueryEscape(userID)).
+		List().
+		DecodeInto(&items)
+	if err != nil && err != pgrest.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (u *userTokens) FetchOne(ctx pgrest.OrgContext, id string) (*pgrest.UserToken, error) {
+	return fetchOne("/user_tokens?org_id=eq.%s&id=eq.%s", ctx.GetOrgID(), url.QueryEscape(id))
+}
+
+func (u *userTokens) FetchOneByName(ctx pgrest.OrgContext, userID, name string) (*pgrest.UserToken, error) {
+	return fetchOne("/user_tokens?org_id=eq.%s&user_id=eq.%s&name=eq.%s",
+		ctx.GetOrgID(), url.QueryEscape(userID), url.QueryEscape(name))
+}
+
+// FetchOneByHash returns the token of any organization matching the hash
+func (u *userTokens) FetchOneByHash(tokenHash string) (*pgrest.UserToken, error) {
+	return fetchOne("/user_tokens?token_hash=eq.%s", url.QueryEscape(tokenHash))
+}
+
+func fetchOne(path string, a ...any) (*pgrest.UserToken, error) {
+	var token pgrest.UserToken
+	if err := pgrest.New(path, a...).FetchOne().DecodeInto(&token); err != nil {
+		if err == pgrest.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoke marks the token as revoked, the record is kept to audit its usage
+func (u *userTokens) Revoke(ctx pgrest.OrgContext, id string) error {
+	return pgrest.New("/user_tokens?org_id=eq.%s&id=eq.%s&revoked_at=is.null", ctx.GetOrgID(), url.QueryEscape(id)).
+		Patch(map[string]any{
+			"revoked_at": time.Now().UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
+
+// RevokeAllByUser marks all the tokens of a user as revoked
+func (u *userTokens) RevokeAllByUser(ctx pgrest.OrgContext, userID string) error {
+	return pgrest.New("/user_tokens?org_id=eq.%s&user_id=eq.%s&revoked_at=is.null", ctx.GetOrgID(), url.QueryEscape(userID)).
+		Patch(map[string]any{
+			"revoked_at": time.Now().UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
+
+func (u *userTokens) UpdateLastUsed(id string, lastUsedAt time.Time) error {
+	return pgrest.New("/user_tokens?id=eq.%s", url.QueryEscape(id)).
+		Patch(map[string]any{
+			"last_used_at": lastUsedAt.UTC().Format(time.RFC3339Nano),
+		}).Error()
+}
@@ -3,6 +3,7 @@ package review
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hoophq/hoop/gateway/pgrest"
@@ -22,6 +23,7 @@ type (
 		Review(ctx *storagev2.Context, id string, status types.ReviewStatus) (*types.Review, error)
 		Revoke(ctx pgrest.OrgContext, id string) (*types.Review, error)
 		Persist(ctx pgrest.OrgContext, review *types.Review) error
+		Schedule(ctx *storagev2.Context, id string, scheduledAt *time.Time) (*types.Review, error)
 	}
 )
 
@@ -39,24 +41,51 @@ func (h *Handler) Put(c *gin.Context) {
 
 	var review *types.Review
 	status := types.ReviewStatus(strings.ToUpper(string(req["status"])))
-	switch status {
-	case types.ReviewStatusApproved, types.ReviewStatusRejected:
-		review, err = h.Service.Review(ctx, id, status)
-	case types.ReviewStatusRevoked:
-		if !ctx.IsAdmin() {
-			c.AbortWithStatus(http.StatusForbidden)
+	// an empty value removes the schedule of the review
+	scheduledAtStr, hasSchedule := req["scheduled_at"]
+	var scheduledAt *time.Time
+	if scheduledAtStr != "" {
+		t, err := time.Parse(time.RFC3339, scheduledAtStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "scheduled_at must be in RFC3339 format"})
 			return
 		}
-		review, err = h.Service.Revoke(ctx, id)
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid status"})
+		if !t.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "scheduled_at must be in the future"})
+			return
+		}
+		t = t.UTC()
+		scheduledAt = &t
+	}
+	if hasSchedule && status != "" && status != types.ReviewStatusApproved {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "scheduled_at could only be set when approving a review"})
 		return
 	}
+	// schedule it before approving to prevent releasing clients waiting for the review
+	if hasSchedule {
+		review, err = h.Service.Schedule(ctx, id, scheduledAt)
+	}
+	if err == nil && (status != "" || !hasSchedule) {
+		switch status {
+		case types.ReviewStatusApproved, types.ReviewStatusRejected:
+			review, err = h.Service.Review(ctx, id, status)
+		case types.ReviewStatusRevoked:
+			if !ctx.IsAdmin() {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			review, err = h.Service.Revoke(ctx, id)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid status"})
+			return
+		}
+	}
 
 	switch err {
 	case ErrNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"message": "not found"})
-	case ErrNotEligible, ErrWrongState:
+	case ErrNotEligible, ErrWrongState, ErrSelfApproval, ErrAlreadyReviewed, ErrSeparationOfDuties, ErrNotSchedulable,
+		ErrScheduleLocked:
 		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 	case nil:
 		c.JSON(http.StatusOK, sanitizeReview(review))
@@ -133,6 +162,12 @@ func sanitizeReview(review *types.Review) types.ReviewJSON {
 			Id:   connectionToStringFn("xt/id"),
 			Name: connectionToStringFn("connection/name"),
 		},
-		ReviewGroupsData: review.ReviewGroupsData,
+		ReviewGroupsData:        review.ReviewGroupsData,
+		Workflow:                review.Workflow,
+		ExpiresAt:               review.ExpiresAt,
+		ScheduledAt:             review.ScheduledAt,
+		BreakGlassJustification: review.BreakGlassJustification,
+		Justification:           review.Justification,
+		TicketID:                review.TicketID,
 	}
 }
@@ -0,0 +1,338 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+// AutoApprovalRequest contains the attributes of a review evaluated by the auto approval rules
+type AutoApprovalRequest struct {
+	ConnectionName string
+	ConnectionType pb.ConnectionType
+	ReviewType     string
+	Input          string
+	UserGroups     []string
+}
+
+var (
+	readOnlyStatements = []string{"SELECT", "SHOW", "EXPLAIN", "DESCRIBE", "DESC", "WITH", "VALUES", "TABLE"}
+	// keywords that could change the state of the database,
+	// any statement containing them is not considered read only
+	writeKeywords = []string{
+		"INSERT", "UPDATE", "DELETE", "MERGE", "UPSERT", "INTO", "CREATE", "ALTER", "DROP", "TRUNCATE",
+		"RENAME", "GRANT", "REVOKE", "COPY", "CALL", "EXEC", "EXECUTE", "DO", "LOCK", "SET", "LOAD",
+		// mssql doesn't require a separator between statements of a batch
+		"SHUTDOWN", "KILL", "BACKUP", "RESTORE", "DBCC", "BULK", "DENY", "RECONFIGURE", "DECLARE", "SETUSER",
+		"WRITETEXT", "UPDATETEXT",
+	}
+	// words allowed before parentheses: keywords and functions without side effects.
+	// Any other function is not considered read only, they could change the state of
+	// the database (e.g.: setval, lo_unlink, dblink_exec) and so do user defined functions.
+	readOnlyFunctions = []string{
+		// keywords
+		"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "IN", "EXISTS", "ANY", "ALL", "SOME", "AS", "ON", "USING",
+		"JOIN", "LATERAL", "VALUES", "ROW", "ARRAY", "CASE", "WHEN", "THEN", "ELSE", "IS", "LIKE", "ILIKE", "BETWEEN",
+		"BY", "HAVING", "UNION", "INTERSECT", "EXCEPT", "DISTINCT", "OVER", "FILTER", "WITHIN", "PARTITION", "ORDER",
+		"LIMIT", "OFFSET", "FETCH", "TOP", "EXPLAIN", "INTERVAL", "CAST", "TRY_CAST", "CONVERT", "TRY_CONVERT", "EXTRACT",
+		"CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "VARYING", "NUMERIC", "DECIMAL", "FLOAT", "TIMESTAMP", "TIME", "DATE",
+		// aggregate and window functions
+		"COUNT", "SUM", "AVG", "MIN", "MAX", "STRING_AGG", "ARRAY_AGG", "GROUP_CONCAT", "JSON_AGG", "JSONB_AGG",
+		"ROW_NUMBER", "RANK", "DENSE_RANK", "NTILE", "LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE",
+		// scalar functions
+		"COALESCE", "NULLIF", "GREATEST", "LEAST", "ISNULL", "IFNULL", "NVL", "IIF", "IF",
+		"LOWER", "UPPER", "LENGTH", "CHAR_LENGTH", "LEN", "DATALENGTH", "SUBSTRING", "SUBSTR", "TRIM", "LTRIM", "RTRIM",
+		"REPLACE", "CONCAT", "CONCAT_WS", "LEFT", "RIGHT", "POSITION", "STRPOS", "CHARINDEX", "SPLIT_PART", "FORMAT",
+		"REGEXP_REPLACE", "REGEXP_MATCHES", "MD5", "ROUND", "FLOOR", "CEIL", "CEILING", "ABS", "MOD", "POWER", "SQRT",
+		"NOW", "GETDATE", "SYSDATETIME", "DATE_TRUNC", "DATE_PART", "DATEADD", "DATEDIFF", "DATE_FORMAT",
+		"TO_CHAR", "TO_DATE", "TO_TIMESTAMP", "TO_NUMBER", "GENERATE_SERIES", "UNNEST", "ARRAY_LENGTH", "CARDINALITY",
+		"JSON_BUILD_OBJECT", "JSONB_BUILD_OBJECT", "JSON_EXTRACT", "JSON_VALUE", "JSON_QUERY",
+		"PG_SIZE_PRETTY", "PG_RELATION_SIZE", "PG_TOTAL_RELATION_SIZE", "PG_DATABASE_SIZE",
+	}
+	sqlConnectionTypes = []pb.ConnectionType{pb.ConnectionTypePostgres, pb.ConnectionTypeMySQL, pb.ConnectionTypeMSSQL}
+)
+
+// MatchAutoApprovalRule returns the first rule that approves the review, nil if none matches.
+// A rule matches when all of its non empty attributes match the review, the read only and the
+// query pattern attributes only apply to one time reviews of database connections.
+func MatchAutoApprovalRule(rules []pgrest.ReviewAutoApprovalRule, req AutoApprovalRequest, now time.Time) *pgrest.ReviewAutoApprovalRule {
+	for i := range rules {
+		if ruleMatches(&rules[i], req, now) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func ruleMatches(rule *pgrest.ReviewAutoApprovalRule, req AutoApprovalRequest, now time.Time) bool {
+	// a rule without conditions would approve every review
+	if !rule.ReadOnly && rule.QueryPattern == "" && len(rule.Groups) == 0 && rule.BusinessHours == nil {
+		return false
+	}
+	if len(rule.Connections) > 0 && !slices.Contains(rule.Connections, req.ConnectionName) {
+		return false
+	}
+	hasQueryCondition := rule.ReadOnly || rule.QueryPattern != ""
+	if hasQueryCondition && (req.ReviewType != ReviewTypeOneTime || !slices.Contains(sqlConnectionTypes, req.ConnectionType)) {
+		return false
+	}
+	if rule.ReadOnly && !IsReadOnlyQuery(req.ConnectionType, req.Input) {
+		return false
+	}
+	if rule.QueryPattern != "" {
+		// the pattern must match the whole input
+		re, err := regexp.Compile(fmt.Sprintf(`^(?:%s)$`, rule.QueryPattern))
+		if err != nil || !re.MatchString(strings.TrimSpace(req.Input)) {
+			return false
+		}
+	}
+	if len(rule.Groups) > 0 && !slices.ContainsFunc(req.UserGroups, func(g string) bool { return slices.Contains(rule.Groups, g) }) {
+		return false
+	}
+	if rule.BusinessHours != nil && !WithinBusinessHours(rule.BusinessHours, now) {
+		return false
+	}
+	return true
+}
+
+// WithinBusinessHours reports if the time is inside the business hours. An end hour before
+// the start hour represents a range crossing midnight, the weekday is evaluated by the start day.
+func WithinBusinessHours(bh *pgrest.BusinessHours, now time.Time) bool {
+	loc, err := time.LoadLocation(bh.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err := time.Parse("15:04", bh.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", bh.End)
+	if err != nil {
+		return false
+	}
+	t := now.In(loc)
+	minutes := t.Hour()*60 + t.Minute()
+	startMinutes, endMinutes := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	weekday := int(t.Weekday())
+	switch {
+	case startMinutes < endMinutes:
+		if minutes < startMinutes || minutes >= endMinutes {
+			return false
+		}
+	case minutes >= startMinutes:
+	case minutes < endMinutes:
+		weekday = (weekday + 6) % 7
+	default:
+		return false
+	}
+	return len(bh.Weekdays) == 0 || slices.Contains(bh.Weekdays, weekday)
+}
+
+// IsReadOnlyQuery reports if all the statements of a SQL input are read only.
+// The classification is conservative, inputs that couldn't be parsed unambiguously, that
+// contain any keyword able to change the state of the database or that call functions
+// which aren't known to be free of side effects are not read only.
+func IsReadOnlyQuery(connType pb.ConnectionType, input string) bool {
+	statements, ok := sqlStatements(connType, input)
+	if !ok || len(statements) == 0 {
+		return false
+	}
+	for _, tokens := range statements {
+		if !slices.Contains(readOnlyStatements, tokens[0]) {
+			return false
+		}
+		for i, tok := range tokens {
+			isCall := i+1 < len(tokens) && tokens[i+1] == callToken
+			switch {
+			case strings.HasPrefix(tok, quotedIdentifierPrefix):
+				// quoted identifiers are never keywords, but they could name any function
+				if isCall {
+					return false
+				}
+			case slices.Contains(writeKeywords, tok):
+				return false
+			case isCall && !slices.Contains(readOnlyFunctions, tok):
+				return false
+			}
+		}
+	}
+	return true
+}
+
+const (
+	// callToken follows the words preceding parentheses, e.g. function calls
+	callToken = "("
+	// quotedIdentifierPrefix marks the content of quoted identifiers
+	quotedIdentifierPrefix = `"`
+)
+
+// sqlStatements splits the input in statements containing the uppercase words of each one.
+// The content of quoted identifiers is prefixed with quotedIdentifierPrefix and the words followed
+// by parentheses are followed by callToken. Comments and quoted literals are ignored, it returns
+// false when the input contains constructs that are interpreted differently by each database,
+// e.g.: backslash escapes and mysql executable comments, or when a literal or comment is not terminated.
+func sqlStatements(connType pb.ConnectionType, input string) ([][]string, bool) {
+	var statements [][]string
+	var tokens []string
+	var word strings.Builder
+	// it reports if the last token is a word separated from the current position only by
+	// whitespaces or comments, parentheses at this position are a call of the word
+	afterWord := false
+	flushWord := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, strings.ToUpper(word.String()))
+			word.Reset()
+			afterWord = true
+		}
+	}
+	flushStatement := func() {
+		flushWord()
+		if len(tokens) > 0 {
+			statements = append(statements, tokens)
+			tokens = nil
+		}
+		afterWord = false
+	}
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		// mysql requires a whitespace after the double dash to start a comment
+		case c == '-' && strings.HasPrefix(input[i:], "--") && (i+2 == len(input) || isSpace(input[i+2])):
+			flushWord()
+			idx := strings.IndexByte(input[i:], '\n')
+			if idx == -1 {
+				i = len(input)
+				continue
+			}
+			i += idx
+		case c == '/' && strings.HasPrefix(input[i:], "/*"):
+			if strings.HasPrefix(input[i:], "/*!") {
+				return nil, false
+			}
+			flushWord()
+			idx := strings.Index(input[i+2:], "*/")
+			if idx == -1 {
+				return nil, false
+			}
+			i += idx + 3
+		case c == '\'':
+			flushWord()
+			end := closingQuote(input, i+1, c)
+			if end == -1 {
+				return nil, false
+			}
+			i = end
+			afterWord = false
+		case c == '"' || c == '`' || (c == '[' && connType == pb.ConnectionTypeMSSQL):
+			// quoted identifiers are words as well, they could name a function
+			flushWord()
+			quote := c
+			if c == '[' {
+				quote = ']'
+			}
+			end := closingQuote(input, i+1, quote)
+			if end == -1 {
+				return nil, false
+			}
+			tokens = append(tokens, quotedIdentifierPrefix+strings.ToUpper(input[i+1:end]))
+			i = end
+			afterWord = true
+		case c == '$' && word.Len() == 0 && connType == pb.ConnectionTypePostgres:
+			// postgres dollar quoted strings, e.g.: $$text$$ or $tag$text$tag$
+			idx := strings.IndexByte(input[i+1:], '$')
+			if idx == -1 {
+				continue
+			}
+			// positional parameters ($1) are not tags
+			tag := input[i : i+idx+2]
+			if (len(tag) > 2 && tag[1] >= '0' && tag[1] <= '9') ||
+				strings.ContainsFunc(tag[1:len(tag)-1], func(r rune) bool { return !isWordChar(r) }) {
+				continue
+			}
+			end := strings.Index(input[i+len(tag):], tag)
+			if end == -1 {
+				return nil, false
+			}
+			i += len(tag) + end + len(tag) - 1
+			afterWord = false
+		case c == ';':
+			flushStatement()
+		case isWordChar(rune(c)):
+			word.WriteByte(c)
+		case isSpace(c):
+			flushWord()
+		case c == '(':
+			flushWord()
+			if afterWord {
+				tokens = append(tokens, callToken)
+			}
+			afterWord = false
+		default:
+			flushWord()
+			afterWord = false
+		}
+	}
+	flushStatement()
+	return statements, true
+}
+
+// closingQuote returns the index of the quote closing a literal, doubled quotes are escapes.
+// Backslashes are escapes only in some databases, -1 is returned when a literal contains one.
+func closingQuote(input string, start int, quote byte) int {
+	for i := start; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			return -1
+		case quote:
+			if i+1 < len(input) && input[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+func isWordChar(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// AutoApprove approves all the groups and stages of a review on behalf of the rule,
+// the rule is recorded as the reviewer for audit purposes.
+func AutoApprove(rev *types.Review, rule *pgrest.ReviewAutoApprovalRule, now time.Time) {
+	reviewedBy := types.ReviewOwner{
+		Id:   rule.ID,
+		Name: fmt.Sprintf("auto-approval rule %s", rule.Name),
+	}
+	reviewDate := now.UTC().Format(time.RFC3339)
+	for i := range rev.ReviewGroupsData {
+		rev.ReviewGroupsData[i].Status = types.ReviewStatusApproved
+		rev.ReviewGroupsData[i].ReviewedBy = &reviewedBy
+		rev.ReviewGroupsData[i].ReviewDate = &reviewDate
+	}
+	if rev.Workflow != nil {
+		for i := range rev.Workflow.Stages {
+			stage := &rev.Workflow.Stages[i]
+			stage.Status = types.ReviewStatusApproved
+			stage.Approvals = append(stage.Approvals, types.ReviewApproval{
+				Status:     types.ReviewStatusApproved,
+				ReviewedBy: reviewedBy,
+				ReviewDate: reviewDate,
+			})
+		}
+	}
+	rev.Status = types.ReviewStatusApproved
+	if rev.Type == ReviewTypeJit {
+		revokeAt := now.UTC().Add(rev.AccessDuration)
+		rev.RevokeAt = &revokeAt
+	}
+}
@@ -0,0 +1,206 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	for _, tt := range []struct {
+		msg      string
+		connType pb.ConnectionType
+		input    string
+		want     bool
+	}{
+		{msg: "it must allow select statements", connType: pb.ConnectionTypePostgres, input: "SELECT * FROM customers WHERE id = 1", want: true},
+		{msg: "it must allow multiple read only statements", connType: pb.ConnectionTypeMySQL, input: "show tables; select 1;", want: true},
+		{msg: "it must allow common table expressions", connType: pb.ConnectionTypePostgres, input: "WITH c AS (SELECT 1) SELECT * FROM c", want: true},
+		{msg: "it must ignore keywords in literals", connType: pb.ConnectionTypePostgres, input: "SELECT 'delete' AS \"update\"", want: true},
+		{msg: "it must ignore keywords in comments", connType: pb.ConnectionTypeMSSQL, input: "-- drop table\nSELECT 1 /* insert */", want: true},
+		{msg: "it must ignore keywords in dollar quoted literals", connType: pb.ConnectionTypePostgres, input: "SELECT $tag$ ; delete $tag$", want: true},
+		{msg: "it must allow positional parameters", connType: pb.ConnectionTypePostgres, input: "SELECT $1, $2", want: true},
+		{msg: "it must reject empty inputs", connType: pb.ConnectionTypePostgres, input: " ; -- comment", want: false},
+		{msg: "it must reject write statements", connType: pb.ConnectionTypePostgres, input: "DELETE FROM customers", want: false},
+		{msg: "it must reject write statements after read only ones", connType: pb.ConnectionTypePostgres, input: "SELECT 1; DROP TABLE customers", want: false},
+		{msg: "it must reject writable common table expressions", connType: pb.ConnectionTypePostgres, input: "WITH d AS (DELETE FROM c RETURNING *) SELECT * FROM d", want: false},
+		{msg: "it must reject select into", connType: pb.ConnectionTypeMSSQL, input: "SELECT * INTO backup FROM customers", want: false},
+		{msg: "it must reject batches without separators", connType: pb.ConnectionTypeMSSQL, input: "SELECT 1 SHUTDOWN", want: false},
+		{msg: "it must reject locking reads", connType: pb.ConnectionTypeMySQL, input: "SELECT * FROM c FOR UPDATE", want: false},
+		{msg: "it must reject literals with backslashes", connType: pb.ConnectionTypePostgres, input: `SELECT 'a\'; DELETE FROM c; --'`, want: false},
+		{msg: "it must reject unterminated literals", connType: pb.ConnectionTypePostgres, input: "SELECT 'abc", want: false},
+		{msg: "it must reject mysql executable comments", connType: pb.ConnectionTypeMySQL, input: "SELECT 1 /*! ; DELETE FROM c */", want: false},
+		{msg: "it must not hide double dash expressions", connType: pb.ConnectionTypeMySQL, input: "SELECT 1 --1; DELETE FROM c", want: false},
+		{msg: "it must not use dollar quotes outside postgres", connType: pb.ConnectionTypeMySQL, input: "SELECT $a$ ; DELETE FROM c; SELECT $a$", want: false},
+		{msg: "it must allow read only functions", connType: pb.ConnectionTypePostgres, input: "SELECT count(*), lower(name), COALESCE (a, b) FROM c WHERE id IN (1, 2)", want: true},
+		{msg: "it must allow parentheses in expressions", connType: pb.ConnectionTypePostgres, input: "SELECT a + (b * 2) FROM c WHERE (a > 1)", want: true},
+		{msg: "it must allow quoted identifiers", connType: pb.ConnectionTypeMySQL, input: "SELECT `delete` FROM `setval`", want: true},
+		{msg: "it must reject quoted function calls", connType: pb.ConnectionTypePostgres, input: `SELECT "dblink_exec"('host=x', 'DROP TABLE users')`, want: false},
+		{msg: "it must reject quoted sequence functions", connType: pb.ConnectionTypePostgres, input: `SELECT "setval"('seq', 1)`, want: false},
+		{msg: "it must reject functions that are not known to be read only", connType: pb.ConnectionTypePostgres, input: "SELECT lo_unlink(16400)", want: false},
+		{msg: "it must reject replication functions", connType: pb.ConnectionTypePostgres, input: "SELECT pg_drop_replication_slot('s')", want: false},
+		{msg: "it must reject schema qualified functions", connType: pb.ConnectionTypePostgres, input: "SELECT pg_catalog.setval /* x */ ('seq', 1)", want: false},
+		{msg: "it must reject backtick quoted function calls", connType: pb.ConnectionTypeMySQL, input: "SELECT `sleep`(10)", want: false},
+		{msg: "it must reject bracket quoted function calls", connType: pb.ConnectionTypeMSSQL, input: "SELECT * FROM [openrowset]('SQLNCLI', 'x', 'y')", want: false},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := IsReadOnlyQuery(tt.connType, tt.input); got != tt.want {
+				t.Errorf("expected read only=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithinBusinessHours(t *testing.T) {
+	// monday
+	monday := time.Date(2024, 7, 22, 0, 0, 0, 0, time.UTC)
+	workdays := []int{1, 2, 3, 4, 5}
+	for _, tt := range []struct {
+		msg  string
+		bh   pgrest.BusinessHours
+		now  time.Time
+		want bool
+	}{
+		{
+			msg:  "it must match inside the business hours",
+			bh:   pgrest.BusinessHours{Timezone: "UTC", Weekdays: workdays, Start: "09:00", End: "18:00"},
+			now:  monday.Add(10 * time.Hour),
+			want: true,
+		},
+		{
+			msg:  "it must not match at the end hour",
+			bh:   pgrest.BusinessHours{Timezone: "UTC", Weekdays: workdays, Start: "09:00", End: "18:00"},
+			now:  monday.Add(18 * time.Hour),
+			want: false,
+		},
+		{
+			msg:  "it must not match outside the weekdays",
+			bh:   pgrest.BusinessHours{Timezone: "UTC", Weekdays: workdays, Start: "09:00", End: "18:00"},
+			now:  monday.Add(-14 * time.Hour),
+			want: false,
+		},
+		{
+			msg:  "it must evaluate the hours in the timezone",
+			bh:   pgrest.BusinessHours{Timezone: "America/Sao_Paulo", Weekdays: workdays, Start: "09:00", End: "18:00"},
+			now:  monday.Add(10 * time.Hour),
+			want: false,
+		},
+		{
+			msg:  "it must match ranges crossing midnight by the start day",
+			bh:   pgrest.BusinessHours{Timezone: "UTC", Weekdays: []int{0}, Start: "22:00", End: "06:00"},
+			now:  monday.Add(2 * time.Hour),
+			want: true,
+		},
+		{
+			msg:  "it must not match with an invalid timezone",
+			bh:   pgrest.BusinessHours{Timezone: "Invalid/Zone", Start: "00:00", End: "23:59"},
+			now:  monday.Add(10 * time.Hour),
+			want: false,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := WithinBusinessHours(&tt.bh, tt.now); got != tt.want {
+				t.Errorf("expected within business hours=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchAutoApprovalRule(t *testing.T) {
+	now := time.Date(2024, 7, 22, 10, 0, 0, 0, time.UTC)
+	onetime := AutoApprovalRequest{
+		ConnectionName: "pg-prod",
+		ConnectionType: pb.ConnectionTypePostgres,
+		ReviewType:     ReviewTypeOneTime,
+		Input:          "SELECT 1",
+		UserGroups:     []string{"sre"},
+	}
+	jit := onetime
+	jit.ReviewType, jit.Input = ReviewTypeJit, ""
+	for _, tt := range []struct {
+		msg      string
+		rules    []pgrest.ReviewAutoApprovalRule
+		req      AutoApprovalRequest
+		wantRule string
+	}{
+		{
+			msg:      "it must match read only rules",
+			rules:    []pgrest.ReviewAutoApprovalRule{{Name: "read-only", ReadOnly: true}},
+			req:      onetime,
+			wantRule: "read-only",
+		},
+		{
+			msg:   "it must not match rules without conditions",
+			rules: []pgrest.ReviewAutoApprovalRule{{Name: "empty"}},
+			req:   onetime,
+		},
+		{
+			msg:   "it must not match rules of other connections",
+			rules: []pgrest.ReviewAutoApprovalRule{{Name: "read-only", ReadOnly: true, Connections: []string{"mysql-prod"}}},
+			req:   onetime,
+		},
+		{
+			msg:   "it must not match query conditions of jit reviews",
+			rules: []pgrest.ReviewAutoApprovalRule{{Name: "read-only", ReadOnly: true}},
+			req:   jit,
+		},
+		{
+			msg:      "it must match the whole input with the query pattern",
+			rules:    []pgrest.ReviewAutoApprovalRule{{Name: "partial", QueryPattern: "SELECT"}, {Name: "full", QueryPattern: `SELECT \d+`}},
+			req:      onetime,
+			wantRule: "full",
+		},
+		{
+			msg: "it must match all conditions of a rule",
+			rules: []pgrest.ReviewAutoApprovalRule{
+				{Name: "dba", Groups: []string{"dba"}},
+				{Name: "sre-hours", Groups: []string{"sre"}, BusinessHours: &pgrest.BusinessHours{Timezone: "UTC", Start: "09:00", End: "18:00"}},
+			},
+			req:      jit,
+			wantRule: "sre-hours",
+		},
+		{
+			msg:   "it must not match groups outside business hours",
+			rules: []pgrest.ReviewAutoApprovalRule{{Name: "sre-hours", Groups: []string{"sre"}, BusinessHours: &pgrest.BusinessHours{Timezone: "UTC", Start: "12:00", End: "18:00"}}},
+			req:   jit,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			var gotRule string
+			if rule := MatchAutoApprovalRule(tt.rules, tt.req, now); rule != nil {
+				gotRule = rule.Name
+			}
+			if gotRule != tt.wantRule {
+				t.Errorf("expected rule=%q, got=%q", tt.wantRule, gotRule)
+			}
+		})
+	}
+}
+
+func TestAutoApprove(t *testing.T) {
+	now := time.Now().UTC()
+	rev := &types.Review{
+		Type:             ReviewTypeJit,
+		AccessDuration:   time.Hour,
+		Status:           types.ReviewStatusPending,
+		ReviewGroupsData: []types.ReviewGroup{{Group: "dba", Status: types.ReviewStatusPending}},
+		Workflow:         &types.ReviewWorkflow{Stages: []types.ReviewStage{{Name: "dba", Groups: []string{"dba"}, MinApprovals: 1}}},
+	}
+	AutoApprove(rev, &pgrest.ReviewAutoApprovalRule{ID: "rule-id", Name: "read-only"}, now)
+	if rev.Status != types.ReviewStatusApproved {
+		t.Fatalf("expected review to be approved, got=%v", rev.Status)
+	}
+	if rev.RevokeAt == nil || !rev.RevokeAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected revoke at to be %v, got=%v", now.Add(time.Hour), rev.RevokeAt)
+	}
+	group := rev.ReviewGroupsData[0]
+	if group.Status != types.ReviewStatusApproved || group.ReviewedBy == nil || group.ReviewedBy.Id != "rule-id" {
+		t.Errorf("expected group to be approved by the rule, got=%+v", group)
+	}
+	if stage := rev.Workflow.Stages[0]; stage.Status != types.ReviewStatusApproved || len(stage.Approvals) != 1 {
+		t.Errorf("expected stage to be approved by the rule, got=%+v", stage)
+	}
+}
@@ -25,15 +25,20 @@ type (
 )
 
 var (
-	ErrNotFound     = errors.New("review not found")
-	ErrWrongState   = errors.New("review in wrong state")
-	ErrNotEligible  = errors.New("not eligible for review")
-	ErrSelfApproval = errors.New("unable to self approve review")
+	ErrNotFound       = errors.New("review not found")
+	ErrWrongState     = errors.New("review in wrong state")
+	ErrNotEligible    = errors.New("not eligible for review")
+	ErrSelfApproval   = errors.New("unable to self approve review")
+	ErrNotSchedulable = errors.New("only one time reviews could be scheduled")
+	ErrScheduleLocked = errors.New("the schedule of an approved review could only be changed by its reviewers")
 )
 
 const (
 	ReviewTypeJit     = "jit"
 	ReviewTypeOneTime = "onetime"
+
+	// the max number of reviews expired in each run
+	maxExpireReviews = 500
 )
 
 func (s *Service) FindOne(ctx pgrest.OrgContext, id string) (*types.Review, error) {
@@ -57,23 +62,29 @@ func (s *Service) Persist(ctx pgrest.OrgContext, review *types.Review) error {
 	}
 
 	parsedReview := &types.Review{
-		Id:               review.Id,
-		CreatedAt:        review.CreatedAt,
-		OrgId:            review.OrgId,
-		Type:             review.Type,
-		Session:          review.Session,
-		Connection:       review.Connection,
-		ConnectionId:     review.Connection.Id,
-		CreatedBy:        review.ReviewOwner.Id,
-		ReviewOwner:      review.ReviewOwner,
-		Input:            review.Input,
-		InputEnvVars:     review.InputEnvVars,
-		InputClientArgs:  review.InputClientArgs,
-		AccessDuration:   review.AccessDuration,
-		RevokeAt:         review.RevokeAt,
-		Status:           review.Status,
-		ReviewGroupsIds:  review.ReviewGroupsIds,
-		ReviewGroupsData: review.ReviewGroupsData,
+		Id:                      review.Id,
+		CreatedAt:               review.CreatedAt,
+		OrgId:                   review.OrgId,
+		Type:                    review.Type,
+		Session:                 review.Session,
+		Connection:              review.Connection,
+		ConnectionId:            review.Connection.Id,
+		CreatedBy:               review.ReviewOwner.Id,
+		ReviewOwner:             review.ReviewOwner,
+		Input:                   review.Input,
+		InputEnvVars:            review.InputEnvVars,
+		InputClientArgs:         review.InputClientArgs,
+		AccessDuration:          review.AccessDuration,
+		RevokeAt:                review.RevokeAt,
+		Status:                  review.Status,
+		ReviewGroupsIds:         review.ReviewGroupsIds,
+		ReviewGroupsData:        review.ReviewGroupsData,
+		Workflow:                review.Workflow,
+		ExpiresAt:               review.ExpiresAt,
+		ScheduledAt:             review.ScheduledAt,
+		BreakGlassJustification: review.BreakGlassJustification,
+		Justification:           review.Justification,
+		TicketID:                review.TicketID,
 	}
 
 	if err := pgreview.New().Upsert(parsedReview); err != nil {
@@ -116,6 +127,19 @@ func (s *Service) Review(ctx *storagev2.Context, reviewID string, status types.R
 	if rev.Status != types.ReviewStatusPending {
 		return rev, ErrWrongState
 	}
+	// the job may not have expired it yet
+	if isExpired(rev, time.Now().UTC()) {
+		if err := s.expire(ctx, rev); err != nil && err != pgrest.ErrNotFound {
+			return nil, fmt.Errorf("expire review error: %v", err)
+		}
+		return rev, ErrWrongState
+	}
+	if rev.Workflow != nil {
+		if err := reviewWorkflow(ctx, rev, status); err != nil {
+			return nil, err
+		}
+		return rev, s.persistReviewed(ctx, rev)
+	}
 	if rev.ReviewOwner.Id == ctx.UserID && !ctx.IsAdmin() {
 		return nil, ErrSelfApproval
 	}
@@ -155,20 +179,135 @@ func (s *Service) Review(ctx *storagev2.Context, reviewID string, status types.R
 	}
 
 	if reviewsCount == approvedCount {
-		rev.RevokeAt = func() *time.Time { t := time.Now().UTC().Add(rev.AccessDuration); return &t }()
 		rev.Status = types.ReviewStatusApproved
 	}
 
+	if err := s.persistReviewed(ctx, rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// persistReviewed saves the review and releases the session when the review is finished
+func (s *Service) persistReviewed(ctx *storagev2.Context, rev *types.Review) error {
+	// break-glass sessions have already been released, the review is only recorded
+	if rev.IsBreakGlass() {
+		if err := s.Persist(ctx, rev); err != nil {
+			return fmt.Errorf("saving review error: %v", err)
+		}
+		return nil
+	}
+	if rev.Status == types.ReviewStatusApproved {
+		rev.RevokeAt = func() *time.Time { t := time.Now().UTC().Add(rev.AccessDuration); return &t }()
+	}
 	if err := s.Persist(ctx, rev); err != nil {
-		return nil, fmt.Errorf("saving review error: %v", err)
+		return fmt.Errorf("saving review error: %v", err)
 	}
 
 	if rev.Status == types.ReviewStatusApproved || rev.Status == types.ReviewStatusRejected {
 		if err := pgsession.New().UpdateStatus(ctx, rev.Session, types.SessionStatusReady); err != nil {
-			return nil, fmt.Errorf("save sesession as ready error: %v", err)
+			return fmt.Errorf("save sesession as ready error: %v", err)
 		}
 		// release the connection if there's a client waiting
 		s.TransportService.ReviewStatusChange(rev)
 	}
+	return nil
+}
+
+// Schedule sets the time to execute an approved one time review, a nil value removes the schedule.
+// The owner of the review, its reviewers and admins are able to change it while it's pending,
+// after the approval only the reviewers and admins are able to change it.
+func (s *Service) Schedule(ctx *storagev2.Context, reviewID string, scheduledAt *time.Time) (*types.Review, error) {
+	rev, err := s.FindOne(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch review error: %v", err)
+	}
+	if rev == nil {
+		return nil, ErrNotFound
+	}
+	if err := canSchedule(ctx, rev); err != nil {
+		return rev, err
+	}
+	// the review could be approved in the meantime, the schedule is only changed in the validated state
+	switch err := pgreview.New().PatchScheduledAtIf(ctx, rev.Id, rev.Status, scheduledAt); err {
+	case nil:
+	case pgrest.ErrNotFound:
+		return rev, ErrWrongState
+	default:
+		return nil, fmt.Errorf("save review schedule error: %v", err)
+	}
+	rev.ScheduledAt = scheduledAt
 	return rev, nil
 }
+
+// canSchedule validates if the user is able to change the schedule of the review
+func canSchedule(ctx *storagev2.Context, rev *types.Review) error {
+	if rev.Type != ReviewTypeOneTime {
+		return ErrNotSchedulable
+	}
+	// break-glass sessions were already executed
+	if rev.IsBreakGlass() || (rev.Status != types.ReviewStatusPending && rev.Status != types.ReviewStatusApproved) {
+		return ErrWrongState
+	}
+	isReviewer := false
+	for _, r := range rev.ReviewGroupsData {
+		if pb.IsInList(r.Group, ctx.UserGroups) {
+			isReviewer = true
+			break
+		}
+	}
+	if isReviewer || ctx.IsAdmin() {
+		return nil
+	}
+	if rev.ReviewOwner.Id != ctx.UserID {
+		return ErrNotEligible
+	}
+	// the reviewers approved the execution at the time they've reviewed
+	if rev.Status == types.ReviewStatusApproved {
+		return ErrScheduleLocked
+	}
+	return nil
+}
+
+// ExpireStaleReviews changes the pending reviews of all organizations that are
+// past their expiration time to the expired state and returns them.
+func (s *Service) ExpireStaleReviews(now time.Time) ([]types.Review, error) {
+	items, err := pgreview.New().FetchExpired(now, maxExpireReviews)
+	if err != nil {
+		return nil, fmt.Errorf("fetch expired reviews error: %v", err)
+	}
+	var expired []types.Review
+	for _, rev := range items {
+		switch err := s.expire(pgrest.NewOrgContext(rev.OrgId), &rev); err {
+		case nil:
+			expired = append(expired, rev)
+		// it was reviewed in the meantime
+		case pgrest.ErrNotFound:
+		default:
+			return expired, fmt.Errorf("review=%v, %v", rev.Id, err)
+		}
+	}
+	return expired, nil
+}
+
+// expire transitions a pending review to the expired state, closes its session
+// and notifies the client waiting for it. It returns pgrest.ErrNotFound if the review
+// is not pending anymore.
+func (s *Service) expire(ctx pgrest.OrgContext, rev *types.Review) error {
+	if err := pgreview.New().Expire(ctx, rev.Id); err != nil {
+		return err
+	}
+	rev.Status = types.ReviewStatusExpired
+	if err := pgsession.New().UpdateStatus(ctx, rev.Session, types.SessionStatusDone); err != nil {
+		return fmt.Errorf("save session as done error: %v", err)
+	}
+	if s.TransportService != nil {
+		s.TransportService.ReviewStatusChange(rev)
+	}
+	return nil
+}
+
+// isExpired reports if a pending review is past its expiration time
+func isExpired(rev *types.Review, now time.Time) bool {
+	return rev.Status == types.ReviewStatusPending && rev.ExpiresAt != nil && now.After(*rev.ExpiresAt)
+}
@@ -0,0 +1,77 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	for _, tt := range []struct {
+		msg  string
+		rev  *types.Review
+		want bool
+	}{
+		{
+			msg:  "it must expire pending reviews past the expiration time",
+			rev:  &types.Review{Status: types.ReviewStatusPending, ExpiresAt: &past},
+			want: true,
+		},
+		{
+			msg:  "it must not expire reviews before the expiration time",
+			rev:  &types.Review{Status: types.ReviewStatusPending, ExpiresAt: &future},
+			want: false,
+		},
+		{
+			msg:  "it must not expire reviews without expiration time",
+			rev:  &types.Review{Status: types.ReviewStatusPending},
+			want: false,
+		},
+		{
+			msg:  "it must not expire reviews that are not pending",
+			rev:  &types.Review{Status: types.ReviewStatusApproved, ExpiresAt: &past},
+			want: false,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := isExpired(tt.rev, now); got != tt.want {
+				t.Errorf("expected expired=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCanSchedule(t *testing.T) {
+	newReview := func(status types.ReviewStatus) *types.Review {
+		return &types.Review{
+			Type:             ReviewTypeOneTime,
+			Status:           status,
+			ReviewOwner:      types.ReviewOwner{Id: "owner"},
+			ReviewGroupsData: []types.ReviewGroup{{Group: "dba", Status: status}},
+		}
+	}
+	for _, tt := range []struct {
+		msg    string
+		ctx    *storagev2.Context
+		status types.ReviewStatus
+		want   error
+	}{
+		{msg: "it must allow the owner to schedule a pending review", ctx: newReviewerContext("owner", "developers"), status: types.ReviewStatusPending},
+		{msg: "it must deny the owner to change the schedule of an approved review", ctx: newReviewerContext("owner", "developers"), status: types.ReviewStatusApproved, want: ErrScheduleLocked},
+		{msg: "it must allow reviewers to change the schedule of an approved review", ctx: newReviewerContext("dba-1", "dba"), status: types.ReviewStatusApproved},
+		{msg: "it must allow admins to change the schedule of an approved review", ctx: newReviewerContext("admin-1", types.GroupAdmin), status: types.ReviewStatusApproved},
+		{msg: "it must allow the owner when it's also a reviewer", ctx: newReviewerContext("owner", "dba"), status: types.ReviewStatusApproved},
+		{msg: "it must deny other users", ctx: newReviewerContext("other", "developers"), status: types.ReviewStatusPending, want: ErrNotEligible},
+		{msg: "it must deny rejected reviews", ctx: newReviewerContext("dba-1", "dba"), status: types.ReviewStatusRejected, want: ErrWrongState},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			if err := canSchedule(tt.ctx, newReview(tt.status)); err != tt.want {
+				t.Errorf("expected error %v, got=%v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package review
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+var (
+	ErrAlreadyReviewed    = errors.New("the review has already been performed by this user in the current stage")
+	ErrSeparationOfDuties = errors.New("unable to review more than one stage of the same review")
+)
+
+// reviewWorkflow applies the review of the user to the current stage of the workflow.
+// The stages are reviewed in order, a stage is approved when it reaches the minimum
+// number of approvals and the review is approved when all stages are approved.
+// A rejection of any eligible reviewer rejects the review.
+func reviewWorkflow(ctx *storagev2.Context, rev *types.Review, status types.ReviewStatus) error {
+	wf := rev.Workflow
+	if rev.ReviewOwner.Id == ctx.UserID && !wf.AllowSelfApproval {
+		return ErrSelfApproval
+	}
+	stageIdx := slices.IndexFunc(wf.Stages, func(s types.ReviewStage) bool {
+		return s.Status != types.ReviewStatusApproved
+	})
+	if stageIdx == -1 {
+		return ErrWrongState
+	}
+	stage := &wf.Stages[stageIdx]
+	groupIdx := slices.IndexFunc(stage.Groups, func(g string) bool { return slices.Contains(ctx.UserGroups, g) })
+	if groupIdx == -1 {
+		return ErrNotEligible
+	}
+	for _, a := range stage.Approvals {
+		if a.ReviewedBy.Id == ctx.UserID {
+			return ErrAlreadyReviewed
+		}
+	}
+	if wf.SeparationOfDuties {
+		for _, s := range wf.Stages[:stageIdx] {
+			for _, a := range s.Approvals {
+				if a.ReviewedBy.Id == ctx.UserID {
+					return ErrSeparationOfDuties
+				}
+			}
+		}
+	}
+
+	reviewDate := time.Now().UTC().Format(time.RFC3339)
+	approval := types.ReviewApproval{
+		Group:  stage.Groups[groupIdx],
+		Status: status,
+		ReviewedBy: types.ReviewOwner{
+			Id:      ctx.UserID,
+			Name:    ctx.UserName,
+			Email:   ctx.UserEmail,
+			SlackID: ctx.SlackID,
+		},
+		ReviewDate: reviewDate,
+	}
+	stage.Approvals = append(stage.Approvals, approval)
+	switch {
+	case status == types.ReviewStatusRejected:
+		stage.Status = types.ReviewStatusRejected
+		rev.Status = types.ReviewStatusRejected
+	case approvalCount(stage) >= max(stage.MinApprovals, 1):
+		stage.Status = types.ReviewStatusApproved
+		if stageIdx == len(wf.Stages)-1 {
+			rev.Status = types.ReviewStatusApproved
+		}
+	}
+
+	// keep the review groups in sync with the last review of each group
+	for i, g := range rev.ReviewGroupsData {
+		if g.Group != approval.Group {
+			continue
+		}
+		rev.ReviewGroupsData[i].Status = status
+		rev.ReviewGroupsData[i].ReviewedBy = &approval.ReviewedBy
+		rev.ReviewGroupsData[i].ReviewDate = &reviewDate
+	}
+	return nil
+}
+
+func approvalCount(stage *types.ReviewStage) (count int) {
+	for _, a := range stage.Approvals {
+		if a.Status == types.ReviewStatusApproved {
+			count++
+		}
+	}
+	return
+}
+
+// WorkflowGroups returns the distinct groups of all stages of a workflow
+func WorkflowGroups(wf *types.ReviewWorkflow) (groups []string) {
+	for _, stage := range wf.Stages {
+		for _, g := range stage.Groups {
+			if !slices.Contains(groups, g) {
+				groups = append(groups, g)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/hoophq/hoop/gateway/storagev2"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+func newReviewerContext(userID string, groups ...string) *storagev2.Context {
+	ctx := storagev2.NewContext(userID, "org-id")
+	ctx.UserGroups = groups
+	return ctx
+}
+
+func newWorkflowReview(wf types.ReviewWorkflow) *types.Review {
+	rev := &types.Review{
+		Status:      types.ReviewStatusPending,
+		ReviewOwner: types.ReviewOwner{Id: "owner"},
+		Workflow:    &wf,
+	}
+	for _, g := range WorkflowGroups(&wf) {
+		rev.ReviewGroupsData = append(rev.ReviewGroupsData, types.ReviewGroup{Group: g, Status: types.ReviewStatusPending})
+	}
+	return rev
+}
+
+func TestReviewWorkflowStages(t *testing.T) {
+	rev := newWorkflowReview(types.ReviewWorkflow{
+		Stages: []types.ReviewStage{
+			{Name: "team-lead", Groups: []string{"leads"}, MinApprovals: 1},
+			{Name: "dba", Groups: []string{"dba", "sre"}, MinApprovals: 2},
+		},
+	})
+	if err := reviewWorkflow(newReviewerContext("dba-1", "dba"), rev, types.ReviewStatusApproved); err != ErrNotEligible {
+		t.Fatalf("expected not eligible error when reviewing a stage out of order, got=%v", err)
+	}
+	if err := reviewWorkflow(newReviewerContext("lead-1", "leads"), rev, types.ReviewStatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	if rev.Workflow.Stages[0].Status != types.ReviewStatusApproved || rev.Status != types.ReviewStatusPending {
+		t.Fatalf("expected first stage to be approved, stage=%v, review=%v", rev.Workflow.Stages[0].Status, rev.Status)
+	}
+	if err := reviewWorkflow(newReviewerContext("dba-1", "dba"), rev, types.ReviewStatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	if err := reviewWorkflow(newReviewerContext("dba-1", "dba"), rev, types.ReviewStatusApproved); err != ErrAlreadyReviewed {
+		t.Fatalf("expected already reviewed error, got=%v", err)
+	}
+	if rev.Status != types.ReviewStatusPending {
+		t.Fatalf("expected review to wait for the quorum, got=%v", rev.Status)
+	}
+	if err := reviewWorkflow(newReviewerContext("sre-1", "sre"), rev, types.ReviewStatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	if rev.Status != types.ReviewStatusApproved {
+		t.Errorf("expected review to be approved, got=%v", rev.Status)
+	}
+	for _, g := range rev.ReviewGroupsData {
+		if g.Status != types.ReviewStatusApproved || g.ReviewedBy == nil {
+			t.Errorf("expected group %v to be approved, got=%v", g.Group, g.Status)
+		}
+	}
+}
+
+func TestReviewWorkflowRules(t *testing.T) {
+	for _, tt := range []struct {
+		msg        string
+		wf         types.ReviewWorkflow
+		reviews    []*storagev2.Context
+		status     types.ReviewStatus
+		wantErr    error
+		wantStatus types.ReviewStatus
+	}{
+		{
+			msg:     "it must not allow self approval",
+			wf:      types.ReviewWorkflow{Stages: []types.ReviewStage{{Groups: []string{"admin"}}}},
+			reviews: []*storagev2.Context{newReviewerContext("owner", "admin")},
+			status:  types.ReviewStatusApproved,
+			wantErr: ErrSelfApproval,
+		},
+		{
+			msg: "it must allow self approval when enabled",
+			wf: types.ReviewWorkflow{AllowSelfApproval: true,
+				Stages: []types.ReviewStage{{Groups: []string{"admin"}}}},
+			reviews:    []*storagev2.Context{newReviewerContext("owner", "admin")},
+			status:     types.ReviewStatusApproved,
+			wantStatus: types.ReviewStatusApproved,
+		},
+		{
+			msg: "it must not allow the same user approving more than one stage",
+			wf: types.ReviewWorkflow{SeparationOfDuties: true, Stages: []types.ReviewStage{
+				{Groups: []string{"leads"}}, {Groups: []string{"dba"}}}},
+			reviews: []*storagev2.Context{
+				newReviewerContext("user-1", "leads", "dba"),
+				newReviewerContext("user-1", "leads", "dba"),
+			},
+			status:  types.ReviewStatusApproved,
+			wantErr: ErrSeparationOfDuties,
+		},
+		{
+			msg: "it must reject the review when a reviewer rejects it",
+			wf: types.ReviewWorkflow{Stages: []types.ReviewStage{
+				{Groups: []string{"dba"}, MinApprovals: 3}}},
+			reviews:    []*storagev2.Context{newReviewerContext("dba-1", "dba")},
+			status:     types.ReviewStatusRejected,
+			wantStatus: types.ReviewStatusRejected,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			rev := newWorkflowReview(tt.wf)
+			var err error
+			for _, ctx := range tt.reviews {
+				if err = reviewWorkflow(ctx, rev, tt.status); err != nil {
+					break
+				}
+			}
+			if err != tt.wantErr {
+				t.Fatalf("expected error %v, got=%v", tt.wantErr, err)
+			}
+			if tt.wantStatus != "" && rev.Status != tt.wantStatus {
+				t.Errorf("expected review status %v, got=%v", tt.wantStatus, rev.Status)
+			}
+		})
+	}
+}
@@ -14,11 +14,17 @@ import (
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/hoophq/hoop/common/log"
 	"github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/security/saml"
 	"golang.org/x/oauth2"
 )
 
 type (
 	Provider struct {
+		// Name identifies the provider, the provider configured by
+		// the IDP_URI or IDP_ISSUER envs is the default provider
+		Name string
+		// Domains are the email domains routed to the provider at login
+		Domains          []string
 		Issuer           string
 		Audience         string
 		ClientID         string
@@ -27,6 +33,17 @@ type (
 		GroupsClaim      string
 		ApiURL           string
 		authWithUserInfo bool
+		sessionSecret    []byte
+
+		// SAML is the service provider configuration when the
+		// gateway is configured with a SAML identity provider
+		SAML *saml.ServiceProvider
+		// Local reports if the users are authenticated with
+		// passwords managed by the gateway (AUTH_METHOD=local)
+		Local bool
+		// providers are the additional identity providers,
+		// it's only set in the default provider
+		providers []*Provider
 
 		*oidc.Provider
 		oauth2.Config
@@ -44,6 +61,9 @@ type (
 		Groups        []string
 		Profile       string
 		Picture       string
+		// AuthTime is the time the user has authenticated in
+		// the identity provider, it's zero when it's unknown
+		AuthTime time.Time
 
 		MustSyncGroups bool
 	}
@@ -58,43 +78,102 @@ func (p *Provider) VerifyIDToken(token *oauth2.Token) (*oidc.IDToken, error) {
 	return p.Verify(p.Context, rawIDToken)
 }
 
+// VerifyAccessTokenWithUserInfo validates the access token at the userinfo endpoint
+// of the provider that issued it
 func (p *Provider) VerifyAccessTokenWithUserInfo(accessToken string) (*ProviderUserInfo, error) {
-	return p.userInfoEndpoint(accessToken)
+	if IsSessionToken(accessToken) {
+		return p.verifySessionToken(accessToken)
+	}
+	providers := p.tokenProviders(accessToken)
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("invalid access token, only session tokens are accepted")
+	}
+	var errs []string
+	for _, prov := range providers {
+		uinfo, err := prov.userInfoEndpoint(accessToken)
+		if err == nil || len(providers) == 1 {
+			return uinfo, err
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", prov.Name, err))
+	}
+	return nil, fmt.Errorf("failed validating token with the identity providers: %v", strings.Join(errs, "; "))
 }
 
+// VerifyAccessToken validates the access token with the provider that issued it
+// and returns the subject of the token
 func (p *Provider) VerifyAccessToken(accessToken string) (string, error) {
+	subject, _, err := p.VerifyAccessTokenWithAuthTime(accessToken)
+	return subject, err
+}
+
+// VerifyAccessTokenWithAuthTime validates the access token and returns the subject and the time
+// the user has authenticated in the identity provider. It's obtained from the auth_time claim
+// falling back to the time the token was issued, the time is zero for opaque tokens.
+func (p *Provider) VerifyAccessTokenWithAuthTime(accessToken string) (string, time.Time, error) {
+	if IsSessionToken(accessToken) {
+		claims, err := p.parseSessionToken(accessToken)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return claims.Subject, claims.authTime(), nil
+	}
+	providers := p.tokenProviders(accessToken)
+	if len(providers) == 0 {
+		return "", time.Time{}, fmt.Errorf("invalid access token, only session tokens are accepted")
+	}
+	var errs []string
+	for _, prov := range providers {
+		subject, authTime, err := prov.verifyAccessToken(accessToken)
+		if err == nil || len(providers) == 1 {
+			return subject, authTime, err
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", prov.Name, err))
+	}
+	return "", time.Time{}, fmt.Errorf("failed validating token with the identity providers: %v", strings.Join(errs, "; "))
+}
+
+func (p *Provider) verifyAccessToken(accessToken string) (string, time.Time, error) {
 	if len(strings.Split(accessToken, ".")) != 3 || p.authWithUserInfo {
 		uinfo, err := p.userInfoEndpoint(accessToken)
 		if err != nil {
-			return "", err
+			return "", time.Time{}, err
 		}
-		return uinfo.Subject, nil
+		return uinfo.Subject, time.Time{}, nil
 	}
 
 	token, err := jwt.Parse(accessToken, p.JWKS.Keyfunc)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	if !token.Valid {
-		return "", fmt.Errorf("parse error, token invalid")
+		return "", time.Time{}, fmt.Errorf("parse error, token invalid")
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok {
 		subject, ok := claims["sub"].(string)
 		if !ok || subject == "" {
-			return "", fmt.Errorf("'sub' not found or has an empty value")
+			return "", time.Time{}, fmt.Errorf("'sub' not found or has an empty value")
 		}
 		// https://openid.net/specs/openid-connect-core-1_0.html
 		// If an azp (authorized party) Claim is present, the Client SHOULD verify that its client_id is the Claim Value.
 		if authorizedParty, ok := claims["azp"].(string); ok {
 			if authorizedParty != p.ClientID {
-				return "", fmt.Errorf("it's not an authorized party")
+				return "", time.Time{}, fmt.Errorf("it's not an authorized party")
 			}
 		}
-		return subject, nil
+		return p.userSubject(subject), authTimeClaim(claims), nil
 	}
-	return "", fmt.Errorf("failed type casting token.Claims (%T) to jwt.MapClaims", token.Claims)
+	return "", time.Time{}, fmt.Errorf("failed type casting token.Claims (%T) to jwt.MapClaims", token.Claims)
+}
+
+// authTimeClaim returns the time of the auth_time claim (OIDC) or zero if it's not present.
+// The issued at claim is not used, tokens are refreshed without a new authentication.
+func authTimeClaim(claims map[string]any) time.Time {
+	if val, ok := claims["auth_time"].(float64); ok && val > 0 {
+		return time.Unix(int64(val), 0).UTC()
+	}
+	return time.Time{}
 }
 
 func (p *Provider) userInfoEndpoint(accessToken string) (*ProviderUserInfo, error) {
@@ -111,7 +190,7 @@ func (p *Provider) userInfoEndpoint(accessToken string) (*ProviderUserInfo, erro
 	}
 	uinfo := ParseIDTokenClaims(claims, p.GroupsClaim)
 	uinfo.Email = user.Email
-	uinfo.Subject = user.Subject
+	uinfo.Subject = p.userSubject(user.Subject)
 	uinfo.EmailVerified = &user.EmailVerified
 	if len(user.Profile) > 0 {
 		uinfo.Profile = user.Profile
@@ -124,26 +203,59 @@ func NewProvider(apiURL string) *Provider {
 	apiURL = strings.TrimSuffix(apiURL, "/")
 
 	provider := &Provider{
+		Name:    DefaultProviderName,
 		Context: ctx,
 		ApiURL:  apiURL,
 	}
 
-	if err := setProviderConfFromEnvs(provider); err != nil {
+	sessionSecret, err := loadSessionSecret()
+	if err != nil {
 		log.Fatal(err)
 	}
+	provider.sessionSecret = sessionSecret
+	if authMethod := os.Getenv("AUTH_METHOD"); authMethod == AuthMethodLocal {
+		if err := setLocalProviderConf(provider); err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("loaded local authentication configuration, issuer=%v", provider.Issuer)
+	} else if metadataURL := os.Getenv("IDP_SAML_METADATA_URL"); metadataURL != "" {
+		if err := setSAMLProviderConf(provider, metadataURL); err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("loaded saml provider configuration, issuer=%v, sso=%v, certificates=%v, groupsattribute=%v, metadata=%v",
+			provider.Issuer, provider.SAML.IDP.SSOURL, len(provider.SAML.IDP.Certificates),
+			provider.GroupsClaim, provider.SAML.EntityID)
+	} else {
+		if err := setProviderConfFromEnvs(provider); err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("issuer-url=%s, audience=%v, custom-scopes=%v, domains=%v, idp-uri-set=%v",
+			provider.Issuer, provider.Audience, provider.CustomScopes, provider.Domains, os.Getenv("IDP_URI") != "")
+		if err := setOIDCProviderConf(provider); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	log.Infof("issuer-url=%s, audience=%v, custom-scopes=%v, idp-uri-set=%v",
-		provider.Issuer, provider.Audience, provider.CustomScopes, os.Getenv("IDP_URI") != "")
-	oidcProviderConfig, err := newProviderConfig(provider.Context, provider.Issuer)
+	provider.providers, err = loadAdditionalProviders(provider)
 	if err != nil {
 		log.Fatal(err)
 	}
-	oidcProvider := oidcProviderConfig.NewProvider(ctx)
+	return provider
+}
+
+// setOIDCProviderConf discovers the endpoints of the OIDC provider and configures the oauth2 client
+func setOIDCProviderConf(provider *Provider) error {
+	oidcProviderConfig, err := newProviderConfig(provider.Context, provider.Issuer)
+	if err != nil {
+		return err
+	}
+	oidcProvider := oidcProviderConfig.NewProvider(provider.Context)
 	scopes := []string{oidc.ScopeOpenID, "profile", "email"}
 	if provider.CustomScopes != "" {
 		scopes = addCustomScopes(scopes, provider.CustomScopes)
 	}
-	log.Infof("loaded oidc provider configuration, with-user-info=%v, auth=%v, token=%v, userinfo=%v, jwks=%v, algorithms=%v, groupsclaim=%v, scopes=%v",
+	log.Infof("loaded oidc provider configuration, name=%v, with-user-info=%v, auth=%v, token=%v, userinfo=%v, jwks=%v, algorithms=%v, groupsclaim=%v, scopes=%v",
+		provider.Name,
 		provider.authWithUserInfo,
 		oidcProviderConfig.AuthURL,
 		oidcProviderConfig.TokenURL,
@@ -156,7 +268,7 @@ func NewProvider(apiURL string) *Provider {
 	conf := oauth2.Config{
 		ClientID:     provider.ClientID,
 		ClientSecret: provider.ClientSecret,
-		RedirectURL:  apiURL + "/api/callback",
+		RedirectURL:  provider.ApiURL + "/api/callback",
 		Endpoint:     oidcProvider.Endpoint(),
 		Scopes:       scopes,
 	}
@@ -169,46 +281,12 @@ func NewProvider(apiURL string) *Provider {
 	provider.Provider = oidcProvider
 	provider.IDTokenVerifier = provider.Verifier(oidcConfig)
 	provider.JWKS = downloadJWKS(oidcProviderConfig.JWKSURL)
-	return provider
+	return nil
 }
 
 func setProviderConfFromEnvs(p *Provider) error {
 	if idpURI := os.Getenv("IDP_URI"); idpURI != "" {
-		u, err := url.Parse(idpURI)
-		if err != nil {
-			return fmt.Errorf("failed parsing IDP_URI env, reason=%v. Valid format is: <scheme>://<client-id>:<client-secret>@<issuer-host>?<options>=", err)
-		}
-		if u.User != nil {
-			p.ClientID = u.User.Username()
-			p.ClientSecret, _ = u.User.Password()
-		}
-		if p.ClientID == "" || p.ClientSecret == "" {
-			return fmt.Errorf("missing credentials for IDP_URI env. Valid format is: <scheme>://<client-id>:<client-secret>@<issuer-host>?<options>=")
-		}
-		p.Audience = os.Getenv("IDP_AUDIENCE")
-		p.GroupsClaim = u.Query().Get("groupsclaim")
-		if p.GroupsClaim == "" {
-			// keep default value
-			p.GroupsClaim = proto.CustomClaimGroups
-		}
-		p.CustomScopes = u.Query().Get("scopes")
-		p.authWithUserInfo = u.Query().Get("_userinfo") == "1"
-		qs := u.Query()
-		qs.Del("scopes")
-		qs.Del("_userinfo")
-		qs.Del("groupsclaim")
-		encQueryStr := qs.Encode()
-		if encQueryStr != "" {
-			encQueryStr = "?" + encQueryStr
-		}
-		// scheme://host:port/path?query#fragment
-		p.Issuer = fmt.Sprintf("%s://%s%s%s",
-			u.Scheme,
-			u.Host,
-			u.Path,
-			encQueryStr,
-		)
-		return nil
+		return parseProviderURI(p, "IDP_URI", idpURI)
 	}
 	p.Issuer = os.Getenv("IDP_ISSUER")
 	p.ClientID = os.Getenv("IDP_CLIENT_ID")
@@ -241,6 +319,58 @@ func setProviderConfFromEnvs(p *Provider) error {
 	return nil
 }
 
+// parseProviderURI configures the provider from an uri in the format:
+// <scheme>://<client-id>:<client-secret>@<issuer-host>?<options>=
+func parseProviderURI(p *Provider, envName, idpURI string) error {
+	u, err := url.Parse(idpURI)
+	if err != nil {
+		return fmt.Errorf("failed parsing %v env, reason=%v. Valid format is: <scheme>://<client-id>:<client-secret>@<issuer-host>?<options>=", envName, err)
+	}
+	if u.User != nil {
+		p.ClientID = u.User.Username()
+		p.ClientSecret, _ = u.User.Password()
+	}
+	if p.ClientID == "" || p.ClientSecret == "" {
+		return fmt.Errorf("missing credentials for %v env. Valid format is: <scheme>://<client-id>:<client-secret>@<issuer-host>?<options>=", envName)
+	}
+	// the audience of additional providers is an option of the uri
+	p.Audience = os.Getenv("IDP_AUDIENCE")
+	if envName != "IDP_URI" {
+		p.Audience = u.Query().Get("audience")
+	}
+	p.GroupsClaim = u.Query().Get("groupsclaim")
+	if p.GroupsClaim == "" {
+		// keep default value
+		p.GroupsClaim = proto.CustomClaimGroups
+	}
+	p.Domains, err = parseDomains(u.Query().Get("domains"))
+	if err != nil {
+		return fmt.Errorf("failed parsing domains option of %v env, reason=%v", envName, err)
+	}
+	p.CustomScopes = u.Query().Get("scopes")
+	p.authWithUserInfo = u.Query().Get("_userinfo") == "1"
+	qs := u.Query()
+	qs.Del("scopes")
+	qs.Del("_userinfo")
+	qs.Del("groupsclaim")
+	qs.Del("domains")
+	if envName != "IDP_URI" {
+		qs.Del("audience")
+	}
+	encQueryStr := qs.Encode()
+	if encQueryStr != "" {
+		encQueryStr = "?" + encQueryStr
+	}
+	// scheme://host:port/path?query#fragment
+	p.Issuer = fmt.Sprintf("%s://%s%s%s",
+		u.Scheme,
+		u.Host,
+		u.Path,
+		encQueryStr,
+	)
+	return nil
+}
+
 func addCustomScopes(scopes []string, customScope string) []string {
 	custom := strings.Split(customScope, ",")
 	for _, c := range custom {
@@ -279,6 +409,7 @@ func ParseIDTokenClaims(idTokenClaims map[string]any, groupsClaimName string) (u
 	}
 	u.Picture = profilePicture
 	u.Email = email
+	u.AuthTime = authTimeClaim(idTokenClaims)
 	switch groupsClaim := idTokenClaims[groupsClaimName].(type) {
 	case string:
 		u.MustSyncGroups = true
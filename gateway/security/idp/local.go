@@ -0,0 +1,20 @@
+package idp
+
+import (
+	"fmt"
+	"os"
+)
+
+// setLocalProviderConf configures the provider to authenticate users with the credentials
+// stored by the gateway. The gateway issues session tokens after the users complete the login.
+func setLocalProviderConf(p *Provider) error {
+	if len(p.sessionSecret) == 0 {
+		return fmt.Errorf("%v env is required with local authentication", sessionSecretEnvName)
+	}
+	if os.Getenv("ORG_MULTI_TENANT") == "true" {
+		return fmt.Errorf("local authentication is not available in multi tenant mode")
+	}
+	p.Local = true
+	p.Issuer = p.ApiURL
+	return nil
+}
@@ -0,0 +1,180 @@
+package idp
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/hoophq/hoop/common/log"
+)
+
+const (
+	// DefaultProviderName is the name of the provider configured by the IDP_URI or IDP_ISSUER envs
+	DefaultProviderName = "default"
+
+	AuthMethodOIDC  = "oidc"
+	AuthMethodSAML  = "saml"
+	AuthMethodLocal = "local"
+
+	// additional providers are configured by environment variables with this prefix, e.g.:
+	// IDP_URI_CONTRACTORS=https://<client-id>:<client-secret>@<issuer-host>?domains=partner.tld
+	providerURIEnvPrefix = "IDP_URI_"
+)
+
+var (
+	providerNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+	domainRe       = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+)
+
+// Providers returns the default provider followed by the additional providers
+func (p *Provider) Providers() []*Provider { return append([]*Provider{p}, p.providers...) }
+
+// Lookup returns the provider to perform the login of a user. The provider is selected by its name,
+// when the name is empty it's routed by the domain of the email falling back to the default provider.
+func (p *Provider) Lookup(name, email string) (*Provider, error) {
+	if name != "" {
+		for _, prov := range p.Providers() {
+			if prov.Name == name {
+				return prov, nil
+			}
+		}
+		return nil, fmt.Errorf("identity provider %q not found", name)
+	}
+	if _, domain, found := strings.Cut(email, "@"); found {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		for _, prov := range p.Providers() {
+			if slices.Contains(prov.Domains, domain) {
+				return prov, nil
+			}
+		}
+	}
+	return p, nil
+}
+
+// VerifyEmailDomain validates if the provider is allowed to sign in the user of the email.
+// A domain routed to a provider can't be used by other providers, and additional providers
+// with domains configured only sign in users of these domains. The default provider signs in
+// the users of domains that aren't routed.
+func (p *Provider) VerifyEmailDomain(prov *Provider, email string) error {
+	_, domain, _ := strings.Cut(email, "@")
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	for _, other := range p.Providers() {
+		if other.Name != prov.Name && slices.Contains(other.Domains, domain) {
+			return fmt.Errorf("the domain %q is routed to the identity provider %q", domain, other.Name)
+		}
+	}
+	if prov.Name != DefaultProviderName && len(prov.Domains) > 0 && !slices.Contains(prov.Domains, domain) {
+		return fmt.Errorf("the domain %q is not allowed by the identity provider %q", domain, prov.Name)
+	}
+	return nil
+}
+
+// userSubject namespaces the subject of the users of additional providers, the same
+// subject could be issued by distinct providers. The subjects of the default provider
+// are kept as they are to preserve the existing users.
+func (p *Provider) userSubject(subject string) string {
+	if p.Name == DefaultProviderName || subject == "" {
+		return subject
+	}
+	return fmt.Sprintf("idp:%s|%s", p.Name, subject)
+}
+
+// AuthMethod returns how the users of the provider are authenticated
+func (p *Provider) AuthMethod() string {
+	switch {
+	case p.Local:
+		return AuthMethodLocal
+	case p.SAML != nil:
+		return AuthMethodSAML
+	}
+	return AuthMethodOIDC
+}
+
+// tokenProviders returns the OIDC providers that could verify the access token.
+// JWT access tokens are routed by the issuer claim, otherwise each provider is tried in order.
+func (p *Provider) tokenProviders(accessToken string) []*Provider {
+	var providers []*Provider
+	for _, prov := range p.Providers() {
+		if prov.AuthMethod() == AuthMethodOIDC {
+			providers = append(providers, prov)
+		}
+	}
+	if len(providers) <= 1 {
+		return providers
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(accessToken, claims); err == nil {
+		issuer, _ := claims["iss"].(string)
+		for _, prov := range providers {
+			if issuer != "" && strings.TrimSuffix(prov.Issuer, "/") == strings.TrimSuffix(issuer, "/") {
+				return []*Provider{prov}
+			}
+		}
+	}
+	return providers
+}
+
+// loadAdditionalProviders configures the OIDC providers of the IDP_URI_<NAME> envs.
+// The name of the provider is the lower case suffix of the environment variable.
+func loadAdditionalProviders(defaultProvider *Provider) ([]*Provider, error) {
+	var providers []*Provider
+	domains := map[string]string{}
+	for _, domain := range defaultProvider.Domains {
+		domains[domain] = defaultProvider.Name
+	}
+	for _, env := range os.Environ() {
+		envName, idpURI, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(envName, providerURIEnvPrefix) || idpURI == "" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(envName, providerURIEnvPrefix))
+		if !providerNameRe.MatchString(name) || name == DefaultProviderName {
+			return nil, fmt.Errorf("invalid identity provider name %q of %v env", name, envName)
+		}
+		prov := &Provider{
+			Name:          name,
+			Context:       defaultProvider.Context,
+			ApiURL:        defaultProvider.ApiURL,
+			sessionSecret: defaultProvider.sessionSecret,
+		}
+		if err := parseProviderURI(prov, envName, idpURI); err != nil {
+			return nil, err
+		}
+		for _, domain := range prov.Domains {
+			if other, ok := domains[domain]; ok {
+				return nil, fmt.Errorf("the domain %v of %v env is already routed to the provider %v", domain, envName, other)
+			}
+			domains[domain] = name
+		}
+		log.Infof("loading identity provider %v, issuer-url=%s, audience=%v, custom-scopes=%v, domains=%v",
+			prov.Name, prov.Issuer, prov.Audience, prov.CustomScopes, prov.Domains)
+		if err := setOIDCProviderConf(prov); err != nil {
+			return nil, fmt.Errorf("failed loading identity provider %v: %v", name, err)
+		}
+		providers = append(providers, prov)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name < providers[j].Name })
+	return providers, nil
+}
+
+// parseDomains parses a comma separated list of email domains
+func parseDomains(value string) ([]string, error) {
+	var domains []string
+	for _, domain := range strings.Split(value, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" {
+			continue
+		}
+		if !domainRe.MatchString(domain) {
+			return nil, fmt.Errorf("invalid domain %q", domain)
+		}
+		if !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
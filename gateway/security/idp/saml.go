@@ -0,0 +1,118 @@
+package idp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/security/saml"
+)
+
+const (
+	samlDefaultGroupsAttribute = "groups"
+	samlMetadataTimeout        = 10 * time.Second
+	samlMaxMetadataSize        = 1024 * 1024
+)
+
+// samlClaimAttributes maps the claims of id tokens to the attribute names
+// commonly used by identity providers in SAML assertions
+var samlClaimAttributes = map[string][]string{
+	"email": {
+		"email", "mail", "emailAddress",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+	},
+	"name": {
+		"name", "displayName",
+		"http://schemas.microsoft.com/identity/claims/displayname",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+	},
+	"picture": {"picture"},
+}
+
+// setSAMLProviderConf configures the gateway as a SAML service provider of the identity
+// provider described by the metadata url (http, https or file scheme). The metadata of the
+// service provider is available at <api-url>/api/saml/metadata.
+func setSAMLProviderConf(p *Provider, metadataURL string) error {
+	if len(p.sessionSecret) == 0 {
+		return fmt.Errorf("%v env is required to issue session tokens with SAML", sessionSecretEnvName)
+	}
+	data, err := loadSAMLMetadata(metadataURL)
+	if err != nil {
+		return fmt.Errorf("failed loading IDP_SAML_METADATA_URL: %v", err)
+	}
+	idpConf, err := saml.ParseMetadata(data)
+	if err != nil {
+		return fmt.Errorf("failed parsing SAML metadata: %v", err)
+	}
+	p.Issuer = idpConf.EntityID
+	p.GroupsClaim = os.Getenv("IDP_SAML_GROUPS_ATTRIBUTE")
+	if p.GroupsClaim == "" {
+		p.GroupsClaim = samlDefaultGroupsAttribute
+	}
+	p.SAML = saml.NewServiceProvider(p.ApiURL+"/api/saml/metadata", p.ApiURL+"/api/saml/acs", idpConf)
+	return nil
+}
+
+func loadSAMLMetadata(metadataURL string) ([]byte, error) {
+	u, err := url.Parse(metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "file":
+		return os.ReadFile(u.Path)
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	client := &http.Client{Timeout: samlMetadataTimeout}
+	resp, err := client.Get(metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, samlMaxMetadataSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed reading response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v: %s", resp.Status, data)
+	}
+	return data, nil
+}
+
+// ParseSAMLAssertion returns the profile of the user with the same semantics of the id token claims.
+// The subject is the name id of the assertion, transient name ids are replaced by the email of the user.
+func ParseSAMLAssertion(assertion *saml.Assertion, groupsAttributeName string) ProviderUserInfo {
+	claims := map[string]any{}
+	for claim, attributeNames := range samlClaimAttributes {
+		for _, name := range attributeNames {
+			if values := assertion.Attributes[name]; len(values) > 0 {
+				claims[claim] = values[0]
+				break
+			}
+		}
+	}
+	if values, ok := assertion.Attributes[groupsAttributeName]; ok {
+		groups := []any{}
+		for _, v := range values {
+			groups = append(groups, v)
+		}
+		claims[groupsAttributeName] = groups
+	}
+	uinfo := ParseIDTokenClaims(claims, groupsAttributeName)
+	if uinfo.Email == "" && (assertion.NameIDFormat == saml.NameIDFormatEmail || strings.Contains(assertion.NameID, "@")) {
+		uinfo.Email = assertion.NameID
+	}
+	uinfo.Subject = assertion.NameID
+	uinfo.AuthTime = assertion.AuthnInstant
+	if assertion.NameIDFormat == saml.NameIDFormatTransient {
+		uinfo.Subject = uinfo.Email
+	}
+	return uinfo
+}
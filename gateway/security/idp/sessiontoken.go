@@ -0,0 +1,164 @@
+package idp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	sessionTokenPrefix   = "x-session-"
+	sessionTokenTTL      = 12 * time.Hour
+	minSessionSecretSize = 32
+	sessionSecretEnvName = "SESSION_TOKEN_SECRET"
+
+	mfaTokenPrefix   = "x-mfa-"
+	mfaTokenAudience = "mfa"
+	mfaTokenTTL      = 5 * time.Minute
+)
+
+type sessionClaims struct {
+	jwt.RegisteredClaims
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	// the time the user has authenticated in the identity provider
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+}
+
+// authTime returns the time the user has authenticated or zero if it's unknown
+func (c *sessionClaims) authTime() time.Time {
+	if c.AuthTime != nil {
+		return c.AuthTime.Time.UTC()
+	}
+	return time.Time{}
+}
+
+// loadSessionSecret loads the key signing the session tokens issued by the gateway
+func loadSessionSecret() ([]byte, error) {
+	secret := os.Getenv(sessionSecretEnvName)
+	if secret == "" {
+		return nil, nil
+	}
+	if len(secret) < minSessionSecretSize {
+		return nil, fmt.Errorf("%v env must contain at least %v characters", sessionSecretEnvName, minSessionSecretSize)
+	}
+	return []byte(secret), nil
+}
+
+// IsSessionToken reports if the token was issued by the gateway
+func IsSessionToken(token string) bool { return strings.HasPrefix(token, sessionTokenPrefix) }
+
+// HasSessionTokens reports if the gateway is able to issue session tokens
+func (p *Provider) HasSessionTokens() bool { return len(p.sessionSecret) > 0 }
+
+// NewSessionToken issues a token signed by the gateway for users authenticated by identity
+// providers that don't issue access tokens (SAML) or to carry the time of the authentication.
+// The token is valid for 12 hours.
+func (p *Provider) NewSessionToken(uinfo ProviderUserInfo) (string, error) {
+	if len(p.sessionSecret) == 0 {
+		return "", fmt.Errorf("session tokens are not available, missing %v env", sessionSecretEnvName)
+	}
+	if uinfo.Subject == "" {
+		return "", fmt.Errorf("missing subject")
+	}
+	var authTime *jwt.NumericDate
+	if !uinfo.AuthTime.IsZero() {
+		authTime = jwt.NewNumericDate(uinfo.AuthTime)
+	}
+	now := time.Now().UTC()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.ApiURL,
+			Subject:   uinfo.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(sessionTokenTTL)),
+		},
+		Email:    uinfo.Email,
+		Name:     uinfo.Profile,
+		AuthTime: authTime,
+	}).SignedString(p.sessionSecret)
+	if err != nil {
+		return "", err
+	}
+	return sessionTokenPrefix + token, nil
+}
+
+func (p *Provider) verifySessionToken(token string) (*ProviderUserInfo, error) {
+	claims, err := p.parseSessionToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderUserInfo{
+		Subject: claims.Subject,
+		Email:   claims.Email,
+		Profile: claims.Name,
+	}, nil
+}
+
+// parseSessionToken validates the session token and returns its claims
+func (p *Provider) parseSessionToken(token string) (*sessionClaims, error) {
+	if len(p.sessionSecret) == 0 {
+		return nil, fmt.Errorf("session tokens are not available, missing %v env", sessionSecretEnvName)
+	}
+	var claims sessionClaims
+	_, err := jwt.ParseWithClaims(strings.TrimPrefix(token, sessionTokenPrefix), &claims,
+		func(t *jwt.Token) (any, error) { return p.sessionSecret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(p.ApiURL),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("missing subject or expiration claims")
+	}
+	// tokens with an audience are issued for other purposes
+	if len(claims.Audience) > 0 {
+		return nil, fmt.Errorf("token is not a session token")
+	}
+	return &claims, nil
+}
+
+// NewMFAToken issues a short lived token for a user that has verified the password. It's only
+// accepted to complete the second factor of the local authentication, never as an access token.
+func (p *Provider) NewMFAToken(userID string) (string, error) {
+	if len(p.sessionSecret) == 0 {
+		return "", fmt.Errorf("mfa tokens are not available, missing %v env", sessionSecretEnvName)
+	}
+	now := time.Now().UTC()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    p.ApiURL,
+		Subject:   userID,
+		Audience:  jwt.ClaimStrings{mfaTokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(mfaTokenTTL)),
+	}).SignedString(p.sessionSecret)
+	if err != nil {
+		return "", err
+	}
+	return mfaTokenPrefix + token, nil
+}
+
+// VerifyMFAToken validates the token and returns the id of the user
+func (p *Provider) VerifyMFAToken(token string) (string, error) {
+	if len(p.sessionSecret) == 0 || !strings.HasPrefix(token, mfaTokenPrefix) {
+		return "", fmt.Errorf("invalid mfa token")
+	}
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(strings.TrimPrefix(token, mfaTokenPrefix), &claims,
+		func(t *jwt.Token) (any, error) { return p.sessionSecret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(p.ApiURL),
+		jwt.WithAudience(mfaTokenAudience),
+	)
+	if err != nil {
+		return "", err
+	}
+	if claims.Subject == "" || claims.ExpiresAt == nil {
+		return "", fmt.Errorf("missing subject or expiration claims")
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,123 @@
+package idp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/hoophq/hoop/gateway/security/saml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionToken(t *testing.T) {
+	p := &Provider{ApiURL: "https://gateway.domain.tld", sessionSecret: []byte("01234567890123456789012345678901")}
+	token, err := p.NewSessionToken(ProviderUserInfo{Subject: "john@domain.tld", Email: "john@domain.tld", Profile: "John"})
+	assert.Nil(t, err)
+	assert.True(t, IsSessionToken(token))
+
+	uinfo, err := p.verifySessionToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, &ProviderUserInfo{Subject: "john@domain.tld", Email: "john@domain.tld", Profile: "John"}, uinfo)
+
+	subject, authTime, err := p.VerifyAccessTokenWithAuthTime(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "john@domain.tld", subject)
+	assert.True(t, authTime.IsZero(), "it must not use the time the token was issued as the authentication time")
+
+	token, err = p.NewSessionToken(ProviderUserInfo{Subject: "john@domain.tld", AuthTime: time.Unix(1700000000, 0)})
+	assert.Nil(t, err)
+	_, authTime, err = p.VerifyAccessTokenWithAuthTime(token)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), authTime)
+
+	other := &Provider{ApiURL: p.ApiURL, sessionSecret: []byte("abcdefghijabcdefghijabcdefghijab")}
+	_, err = other.verifySessionToken(token)
+	assert.NotNil(t, err, "it must reject tokens signed with other secrets")
+
+	other = &Provider{ApiURL: "https://other.domain.tld", sessionSecret: p.sessionSecret}
+	_, err = other.verifySessionToken(token)
+	assert.NotNil(t, err, "it must reject tokens issued by other gateways")
+
+	_, err = (&Provider{}).NewSessionToken(ProviderUserInfo{Subject: "john@domain.tld"})
+	assert.NotNil(t, err, "it must fail when the secret is not configured")
+}
+
+func TestMFAToken(t *testing.T) {
+	p := &Provider{ApiURL: "https://gateway.domain.tld", sessionSecret: []byte("01234567890123456789012345678901")}
+	token, err := p.NewMFAToken("c8d2b4e6-2f07-4f32-9a3c-0c0c7e7c8a11")
+	assert.Nil(t, err)
+	assert.False(t, IsSessionToken(token))
+
+	userID, err := p.VerifyMFAToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "c8d2b4e6-2f07-4f32-9a3c-0c0c7e7c8a11", userID)
+
+	_, err = p.VerifyAccessToken(token)
+	assert.NotNil(t, err, "it must not accept mfa tokens as access tokens")
+	_, err = p.verifySessionToken(sessionTokenPrefix + strings.TrimPrefix(token, mfaTokenPrefix))
+	assert.NotNil(t, err, "it must not accept mfa tokens as session tokens")
+
+	sessionToken, err := p.NewSessionToken(ProviderUserInfo{Subject: "john@domain.tld"})
+	assert.Nil(t, err)
+	_, err = p.VerifyMFAToken(mfaTokenPrefix + strings.TrimPrefix(sessionToken, sessionTokenPrefix))
+	assert.NotNil(t, err, "it must not accept session tokens as mfa tokens")
+}
+
+func TestParseSAMLAssertion(t *testing.T) {
+	for _, tt := range []struct {
+		msg       string
+		assertion *saml.Assertion
+		want      ProviderUserInfo
+	}{
+		{
+			msg: "it must map the attributes and the groups of the assertion",
+			assertion: &saml.Assertion{
+				NameID:       "00u1ab2",
+				NameIDFormat: "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent",
+				AuthnInstant: time.Unix(1700000000, 0).UTC(),
+				Attributes: map[string][]string{
+					"email":  {"john@domain.tld"},
+					"name":   {"John Doe"},
+					"groups": {"admin", "sre"},
+				},
+			},
+			want: ProviderUserInfo{
+				Subject:        "00u1ab2",
+				Email:          "john@domain.tld",
+				Profile:        "John Doe",
+				Groups:         []string{"admin", "sre"},
+				AuthTime:       time.Unix(1700000000, 0).UTC(),
+				MustSyncGroups: true,
+			},
+		},
+		{
+			msg: "it must use the email as subject when the name id is transient",
+			assertion: &saml.Assertion{
+				NameID:       "_5f1d",
+				NameIDFormat: saml.NameIDFormatTransient,
+				Attributes:   map[string][]string{"email": {"john@domain.tld"}},
+			},
+			want: ProviderUserInfo{Subject: "john@domain.tld", Email: "john@domain.tld"},
+		},
+		{
+			msg:       "it must use the name id as email",
+			assertion: &saml.Assertion{NameID: "john@domain.tld", NameIDFormat: saml.NameIDFormatEmail},
+			want:      ProviderUserInfo{Subject: "john@domain.tld", Email: "john@domain.tld"},
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			got := ParseSAMLAssertion(tt.assertion, "groups")
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuthTimeClaim(t *testing.T) {
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), authTimeClaim(jwt.MapClaims{"auth_time": float64(1700000000), "iat": float64(1700000600)}))
+	assert.True(t, authTimeClaim(jwt.MapClaims{"iat": float64(1700000600)}).IsZero(),
+		"it must not fallback to the issued at claim")
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), ParseIDTokenClaims(map[string]any{"auth_time": float64(1700000000)}, "groups").AuthTime)
+	assert.True(t, authTimeClaim(jwt.MapClaims{}).IsZero())
+}
@@ -0,0 +1,42 @@
+package localauth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyToken(t *testing.T) {
+	token, err := NewToken("user-subject", time.Minute)
+	if err != nil {
+		t.Fatalf("failed generating token: %v", err)
+	}
+	if !IsLocalToken(token) {
+		t.Fatalf("expected token to have the local prefix, got=%v", token)
+	}
+	subject, err := VerifyToken(token)
+	if err != nil {
+		t.Fatalf("failed verifying token: %v", err)
+	}
+	if subject != "user-subject" {
+		t.Errorf("expected subject user-subject, got=%v", subject)
+	}
+}
+
+func TestVerifyTokenErrors(t *testing.T) {
+	expired, _ := NewToken("user-subject", -time.Minute)
+	valid, _ := NewToken("user-subject", time.Minute)
+	for _, tt := range []struct {
+		msg   string
+		token string
+	}{
+		{msg: "it must fail with expired tokens", token: expired},
+		{msg: "it must fail with tampered tokens", token: valid + "x"},
+		{msg: "it must fail with invalid tokens", token: tokenPrefix + "abc"},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			if _, err := VerifyToken(tt.token); err == nil {
+				t.Errorf("expected error verifying token")
+			}
+		})
+	}
+}
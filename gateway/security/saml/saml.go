@@ -0,0 +1,402 @@
+// Package saml implements the web browser single sign-on profile of a SAML 2.0
+// service provider. Authentication requests are sent with the HTTP-Redirect binding
+// and the responses are received with the HTTP-POST binding. The response or the
+// assertion must be signed by the identity provider, encrypted assertions are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const (
+	nsSAML     = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsSAMLP    = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsMetadata = "urn:oasis:names:tc:SAML:2.0:metadata"
+
+	bindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	bindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess       = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer  = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+
+	NameIDFormatEmail       = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIDFormatTransient   = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+	nameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+
+	maxClockSkew = 3 * time.Minute
+)
+
+// IdentityProvider is the configuration of the identity provider loaded from its metadata
+type IdentityProvider struct {
+	EntityID     string
+	SSOURL       string
+	Certificates []*x509.Certificate
+}
+
+// ServiceProvider validates the authentication responses of the identity provider
+type ServiceProvider struct {
+	// EntityID is the unique identifier of the service provider, usually the url of its metadata
+	EntityID string
+	// ACSURL is the url of the assertion consumer service receiving the responses
+	ACSURL string
+	IDP    *IdentityProvider
+
+	now func() time.Time
+}
+
+// Assertion contains the subject and the attributes authenticated by the identity provider
+type Assertion struct {
+	NameID       string
+	NameIDFormat string
+	SessionIndex string
+	// AuthnInstant is the time the user has authenticated in the
+	// identity provider, it's zero when it's not informed
+	AuthnInstant time.Time
+	Attributes   map[string][]string
+}
+
+func NewServiceProvider(entityID, acsURL string, idp *IdentityProvider) *ServiceProvider {
+	return &ServiceProvider{EntityID: entityID, ACSURL: acsURL, IDP: idp, now: time.Now}
+}
+
+// ParseMetadata parses the metadata of an identity provider, the signing certificates
+// and the single sign-on service with the HTTP-Redirect binding are required
+func ParseMetadata(data []byte) (*IdentityProvider, error) {
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+	entity := root
+	if root.is(nsMetadata, "EntitiesDescriptor") {
+		entity = nil
+		for _, el := range root.childElements(nsMetadata, "EntityDescriptor") {
+			if el.child(nsMetadata, "IDPSSODescriptor") != nil {
+				entity = el
+				break
+			}
+		}
+	}
+	if entity == nil || !entity.is(nsMetadata, "EntityDescriptor") {
+		return nil, fmt.Errorf("metadata doesn't contain an entity descriptor")
+	}
+	descriptor := entity.child(nsMetadata, "IDPSSODescriptor")
+	if descriptor == nil {
+		return nil, fmt.Errorf("metadata doesn't contain an identity provider descriptor")
+	}
+	idp := &IdentityProvider{EntityID: entity.attr("entityID")}
+	if idp.EntityID == "" {
+		return nil, fmt.Errorf("metadata doesn't contain the entity id")
+	}
+	for _, keyDescriptor := range descriptor.childElements(nsMetadata, "KeyDescriptor") {
+		if use := keyDescriptor.attr("use"); use != "" && use != "signing" {
+			continue
+		}
+		x509Data := keyDescriptor.find(nsDSig, "KeyInfo", "X509Data")
+		if x509Data == nil {
+			continue
+		}
+		for _, el := range x509Data.childElements(nsDSig, "X509Certificate") {
+			cert, err := parseCertificate(el.text())
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing signing certificate: %v", err)
+			}
+			idp.Certificates = append(idp.Certificates, cert)
+		}
+	}
+	if len(idp.Certificates) == 0 {
+		return nil, fmt.Errorf("metadata doesn't contain signing certificates")
+	}
+	for _, sso := range descriptor.childElements(nsMetadata, "SingleSignOnService") {
+		if sso.attr("Binding") == bindingHTTPRedirect {
+			idp.SSOURL = sso.attr("Location")
+			break
+		}
+	}
+	if idp.SSOURL == "" {
+		return nil, fmt.Errorf("metadata doesn't contain a single sign-on service with the HTTP-Redirect binding")
+	}
+	return idp, nil
+}
+
+type spMetadata struct {
+	XMLName    xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID   string   `xml:"entityID,attr"`
+	Descriptor struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"NameIDFormat"`
+		AssertionConsumerService   struct {
+			Binding   string `xml:"Binding,attr"`
+			Location  string `xml:"Location,attr"`
+			Index     int    `xml:"index,attr"`
+			IsDefault bool   `xml:"isDefault,attr"`
+		} `xml:"AssertionConsumerService"`
+	} `xml:"SPSSODescriptor"`
+}
+
+// Metadata returns the metadata document of the service provider
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	var md spMetadata
+	md.EntityID = sp.EntityID
+	md.Descriptor.WantAssertionsSigned = true
+	md.Descriptor.ProtocolSupportEnumeration = nsSAMLP
+	md.Descriptor.NameIDFormat = NameIDFormatEmail
+	md.Descriptor.AssertionConsumerService.Binding = bindingHTTPPost
+	md.Descriptor.AssertionConsumerService.Location = sp.ACSURL
+	md.Descriptor.AssertionConsumerService.IsDefault = true
+	data, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	ForceAuthn                  bool     `xml:"ForceAuthn,attr,omitempty"`
+	Issuer                      struct {
+		XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+		Value   string   `xml:",chardata"`
+	}
+	NameIDPolicy struct {
+		Format      string `xml:"Format,attr"`
+		AllowCreate bool   `xml:"AllowCreate,attr"`
+	} `xml:"NameIDPolicy"`
+}
+
+// AuthnRequestURL returns the url redirecting the user to authenticate in the identity provider.
+// The request id must be a valid xml id (it can't start with a digit) and it's validated
+// against the response of the identity provider. When forceAuthn is set, the identity provider
+// must authenticate the user again instead of relying on a previous security context.
+func (sp *ServiceProvider) AuthnRequestURL(requestID, relayState string, forceAuthn bool) (string, error) {
+	req := authnRequest{
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                sp.now().UTC().Format(time.RFC3339),
+		Destination:                 sp.IDP.SSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		ProtocolBinding:             bindingHTTPPost,
+		ForceAuthn:                  forceAuthn,
+	}
+	req.Issuer.Value = sp.EntityID
+	req.NameIDPolicy.Format = nameIDFormatUnspecified
+	req.NameIDPolicy.AllowCreate = true
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(sp.IDP.SSOURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid single sign-on url: %v", err)
+	}
+	qs := u.Query()
+	qs.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		qs.Set("RelayState", relayState)
+	}
+	u.RawQuery = qs.Encode()
+	return u.String(), nil
+}
+
+// ParseResponse validates the base64 encoded response posted by the identity provider
+// in reply to the authentication request and returns the authenticated assertion
+func (sp *ServiceProvider) ParseResponse(encodedResponse, requestID string) (*Assertion, error) {
+	data, err := base64.StdEncoding.DecodeString(encodedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding response: %v", err)
+	}
+	resp, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.is(nsSAMLP, "Response") {
+		return nil, fmt.Errorf("expected a Response element, got %v", resp.local)
+	}
+	if dest := resp.attr("Destination"); dest != "" && dest != sp.ACSURL {
+		return nil, fmt.Errorf("response destination %q doesn't match the assertion consumer service url", dest)
+	}
+	if resp.attr("InResponseTo") != requestID {
+		return nil, fmt.Errorf("response is not in reply to the authentication request")
+	}
+	if issuer := resp.child(nsSAML, "Issuer"); issuer != nil && issuer.text() != sp.IDP.EntityID {
+		return nil, fmt.Errorf("response issuer %q doesn't match the identity provider", issuer.text())
+	}
+	statusCode := resp.find(nsSAMLP, "Status", "StatusCode")
+	if statusCode == nil || statusCode.attr("Value") != statusSuccess {
+		var code, msg string
+		if statusCode != nil {
+			code = statusCode.attr("Value")
+			if subCode := statusCode.child(nsSAMLP, "StatusCode"); subCode != nil {
+				code = subCode.attr("Value")
+			}
+		}
+		if status := resp.child(nsSAMLP, "Status"); status != nil {
+			msg = status.child(nsSAMLP, "StatusMessage").text()
+		}
+		return nil, fmt.Errorf("authentication failed in the identity provider, status=%v, message=%v", code, msg)
+	}
+	if resp.child(nsSAML, "EncryptedAssertion") != nil {
+		return nil, fmt.Errorf("encrypted assertions are not supported")
+	}
+	assertions := resp.childElements(nsSAML, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("expected one assertion, found %v", len(assertions))
+	}
+
+	// the content of the assertion is read from the signed elements,
+	// the signature of the response covers the assertion
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("failed parsing xml: %v", err)
+	}
+	signedResp := doc.Root()
+	responseSigned := resp.child(nsDSig, "Signature") != nil
+	if responseSigned {
+		if signedResp, err = sp.verifySignature(signedResp); err != nil {
+			return nil, fmt.Errorf("invalid response signature: %v", err)
+		}
+	}
+	signedAssertion, err := etreeutils.NSFindOneChild(signedResp, nsSAML, "Assertion")
+	if err != nil || signedAssertion == nil {
+		return nil, fmt.Errorf("failed reading the signed assertion: %v", err)
+	}
+	if !responseSigned || assertions[0].child(nsDSig, "Signature") != nil {
+		signedAssertion, err = sp.verifySignature(signedAssertion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assertion signature: %v", err)
+		}
+	} else if signedAssertion, err = detach(signedAssertion); err != nil {
+		return nil, fmt.Errorf("failed reading the signed assertion: %v", err)
+	}
+	assertion, err := toElement(signedAssertion)
+	if err != nil {
+		return nil, err
+	}
+	return sp.validateAssertion(assertion, requestID)
+}
+
+func (sp *ServiceProvider) validateAssertion(assertion *element, requestID string) (*Assertion, error) {
+	now := sp.now().UTC()
+	if issuer := assertion.child(nsSAML, "Issuer").text(); issuer != sp.IDP.EntityID {
+		return nil, fmt.Errorf("assertion issuer %q doesn't match the identity provider", issuer)
+	}
+	subject := assertion.child(nsSAML, "Subject")
+	if subject == nil {
+		return nil, fmt.Errorf("missing subject in the assertion")
+	}
+	nameID := subject.child(nsSAML, "NameID")
+	if nameID.text() == "" {
+		return nil, fmt.Errorf("missing name id in the assertion")
+	}
+	confirmed := false
+	for _, confirmation := range subject.childElements(nsSAML, "SubjectConfirmation") {
+		data := confirmation.child(nsSAML, "SubjectConfirmationData")
+		if confirmation.attr("Method") != confirmationBearer || data == nil {
+			continue
+		}
+		if data.attr("Recipient") != sp.ACSURL {
+			continue
+		}
+		if inResponseTo := data.attr("InResponseTo"); inResponseTo != "" && inResponseTo != requestID {
+			continue
+		}
+		if err := validateNotOnOrAfter(data.attr("NotOnOrAfter"), now); err != nil {
+			return nil, fmt.Errorf("subject confirmation %v", err)
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, fmt.Errorf("missing bearer subject confirmation to the assertion consumer service url")
+	}
+	conditions := assertion.child(nsSAML, "Conditions")
+	if conditions == nil {
+		return nil, fmt.Errorf("missing conditions in the assertion")
+	}
+	if notBefore := conditions.attr("NotBefore"); notBefore != "" {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NotBefore condition: %v", err)
+		}
+		if now.Add(maxClockSkew).Before(t) {
+			return nil, fmt.Errorf("assertion is not valid before %v", notBefore)
+		}
+	}
+	if err := validateNotOnOrAfter(conditions.attr("NotOnOrAfter"), now); err != nil {
+		return nil, fmt.Errorf("assertion %v", err)
+	}
+	restrictions := conditions.childElements(nsSAML, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, fmt.Errorf("missing audience restriction in the assertion")
+	}
+	// each restriction must be satisfied
+	for _, restriction := range restrictions {
+		var audiences []string
+		for _, audience := range restriction.childElements(nsSAML, "Audience") {
+			audiences = append(audiences, audience.text())
+		}
+		if !slices.Contains(audiences, sp.EntityID) {
+			return nil, fmt.Errorf("the assertion audience %v doesn't contain the service provider", audiences)
+		}
+	}
+
+	result := &Assertion{
+		NameID:       nameID.text(),
+		NameIDFormat: nameID.attr("Format"),
+		Attributes:   map[string][]string{},
+	}
+	if authnStatement := assertion.child(nsSAML, "AuthnStatement"); authnStatement != nil {
+		result.SessionIndex = authnStatement.attr("SessionIndex")
+		if authnInstant, err := time.Parse(time.RFC3339, authnStatement.attr("AuthnInstant")); err == nil {
+			result.AuthnInstant = authnInstant.UTC()
+		}
+	}
+	for _, statement := range assertion.childElements(nsSAML, "AttributeStatement") {
+		for _, attr := range statement.childElements(nsSAML, "Attribute") {
+			name := attr.attr("Name")
+			for _, value := range attr.childElements(nsSAML, "AttributeValue") {
+				result.Attributes[name] = append(result.Attributes[name], value.text())
+			}
+		}
+	}
+	return result, nil
+}
+
+func validateNotOnOrAfter(value string, now time.Time) error {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fmt.Errorf("has an invalid NotOnOrAfter: %v", err)
+	}
+	if !now.Add(-maxClockSkew).Before(t) {
+		return fmt.Errorf("expired at %v", value)
+	}
+	return nil
+}
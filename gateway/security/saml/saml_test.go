@@ -0,0 +1,406 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testIDPEntityID = "https://idp.bad.org/saml"
+	testSPEntityID  = "https://gateway.bad.org/api/saml/metadata"
+	testACSURL      = "https://gateway.bad.org/api/saml/acs"
+	testRequestID   = "_D9B5A8E1-7B7A-4A78-ABB0-A24C95E6FE54"
+)
+
+var testNow = time.Date(2024, 7, 25, 15, 0, 0, 0, time.UTC)
+
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.bad.org"},
+		NotBefore:    testNow.Add(-time.Hour),
+		NotAfter:     testNow.Add(time.Hour * 24 * 365),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return key, cert
+}
+
+const (
+	testAlgExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	testAlgEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	testAlgRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	testAlgSHA256    = "http://www.w3.org/2001/04/xmlenc#sha256"
+)
+
+type responseOptions struct {
+	requestID string
+	audience  string
+	nameID    string
+	// replaces the name id after signing the assertion
+	tamperedNameID string
+	unsigned       bool
+	// signs the response with the key after it's built, the certificate is sent in the signature
+	signResponse *x509.Certificate
+	// changes the content of the response (after the status) containing the signed assertion
+	wrap func(assertion string) string
+}
+
+// newTestAssertion returns an assertion of the test identity provider, the namespace declaration,
+// the signature and the name id are formatted in the template
+func newTestAssertion(opts responseOptions, id, nsDecl, signature, nameID string) string {
+	ts := func(d time.Duration) string { return testNow.Add(d).Format(time.RFC3339) }
+	return `<saml:Assertion` + nsDecl + ` ID="` + id + `" IssueInstant="` + ts(0) + `" Version="2.0">` +
+		`<saml:Issuer>` + testIDPEntityID + `</saml:Issuer>` + signature +
+		`<saml:Subject><saml:NameID Format="` + NameIDFormatEmail + `">` + nameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="` + confirmationBearer + `">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + opts.requestID + `" NotOnOrAfter="` + ts(5*time.Minute) + `" Recipient="` + testACSURL + `"></saml:SubjectConfirmationData>` +
+		`</saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + ts(-time.Minute) + `" NotOnOrAfter="` + ts(5*time.Minute) + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + opts.audience + `</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+		`<saml:AuthnStatement AuthnInstant="` + ts(0) + `" SessionIndex="_s1"></saml:AuthnStatement>` +
+		`<saml:AttributeStatement><saml:Attribute Name="groups">` +
+		`<saml:AttributeValue>sre</saml:AttributeValue><saml:AttributeValue>dba</saml:AttributeValue>` +
+		`</saml:Attribute></saml:AttributeStatement></saml:Assertion>`
+}
+
+// newTestResponse returns a response with a signed assertion. The assertion is written in its
+// canonical form (the namespace is pushed down), then the digest and the signature are
+// computed over literals that don't depend on the canonicalization implementation.
+func newTestResponse(t *testing.T, key *rsa.PrivateKey, opts responseOptions) string {
+	if opts.requestID == "" {
+		opts.requestID = testRequestID
+	}
+	if opts.audience == "" {
+		opts.audience = testSPEntityID
+	}
+	if opts.nameID == "" {
+		opts.nameID = "john.wick@bad.org"
+	}
+	canonicalAssertion := newTestAssertion(opts, "_a1", ` xmlns:saml="`+nsSAML+`"`, "", opts.nameID)
+	digest := sha256.Sum256([]byte(canonicalAssertion))
+	signedInfo := `<ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="` + testAlgExcC14N + `"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="` + testAlgRSASHA256 + `"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#_a1"><ds:Transforms>` +
+		`<ds:Transform Algorithm="` + testAlgEnveloped + `"></ds:Transform>` +
+		`<ds:Transform Algorithm="` + testAlgExcC14N + `"></ds:Transform>` +
+		`</ds:Transforms><ds:DigestMethod Algorithm="` + testAlgSHA256 + `"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+	canonicalSignedInfo := strings.Replace(signedInfo, `<ds:SignedInfo>`, `<ds:SignedInfo xmlns:ds="`+nsDSig+`">`, 1)
+	hashed := sha256.Sum256([]byte(canonicalSignedInfo))
+	signatureValue, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	assert.Nil(t, err)
+	signature := `<ds:Signature xmlns:ds="` + nsDSig + `">` + signedInfo +
+		"<ds:SignatureValue>\n" + base64.StdEncoding.EncodeToString(signatureValue) + "\n</ds:SignatureValue></ds:Signature>"
+	if opts.unsigned {
+		signature = ""
+	}
+	nameID := opts.nameID
+	if opts.tamperedNameID != "" {
+		nameID = opts.tamperedNameID
+	}
+	// the namespace of the assertion is declared by the response
+	content := "\n  " + newTestAssertion(opts, "_a1", "", signature, nameID) + "\n"
+	if opts.wrap != nil {
+		content = opts.wrap(content)
+	}
+	resp := `<samlp:Response xmlns:samlp="` + nsSAMLP + `" xmlns:saml="` + nsSAML + `" Destination="` + testACSURL + `"` +
+		` ID="_r1" InResponseTo="` + opts.requestID + `" IssueInstant="` + testNow.Format(time.RFC3339) + `" Version="2.0">` +
+		`<saml:Issuer>` + testIDPEntityID + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="` + statusSuccess + `"/></samlp:Status>` +
+		content + "</samlp:Response>"
+	if opts.signResponse != nil {
+		resp = signTestResponse(t, key, opts.signResponse, resp)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + resp))
+}
+
+// signTestResponse adds an enveloped signature to the response
+func signTestResponse(t *testing.T, key *rsa.PrivateKey, cert *x509.Certificate, resp string) string {
+	doc := etree.NewDocument()
+	assert.Nil(t, doc.ReadFromString(resp))
+	signingCtx, err := dsig.NewSigningContext(key, [][]byte{cert.Raw})
+	assert.Nil(t, err)
+	signingCtx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	assert.Nil(t, signingCtx.SetSignatureMethod(dsig.RSASHA256SignatureMethod))
+	signed, err := signingCtx.SignEnveloped(doc.Root())
+	assert.Nil(t, err)
+	doc.SetRoot(signed)
+	data, err := doc.WriteToString()
+	assert.Nil(t, err)
+	return data
+}
+
+// newForgedAssertion returns an unsigned assertion of an attacker impersonating the admin user
+func newForgedAssertion(id, content string) string {
+	forged := newTestAssertion(responseOptions{requestID: testRequestID, audience: testSPEntityID}, id, ` xmlns:saml="`+nsSAML+`"`, "", "admin@bad.org")
+	return strings.Replace(forged, `<saml:Subject>`, content+`<saml:Subject>`, 1)
+}
+
+func newTestServiceProvider(cert *x509.Certificate) *ServiceProvider {
+	sp := NewServiceProvider(testSPEntityID, testACSURL, &IdentityProvider{
+		EntityID:     testIDPEntityID,
+		SSOURL:       "https://idp.bad.org/sso?tenant=1",
+		Certificates: []*x509.Certificate{cert},
+	})
+	sp.now = func() time.Time { return testNow }
+	return sp
+}
+
+func TestParseResponse(t *testing.T) {
+	key, cert := newTestCertificate(t)
+	otherKey, _ := newTestCertificate(t)
+	sp := newTestServiceProvider(cert)
+
+	assertion, err := sp.ParseResponse(newTestResponse(t, key, responseOptions{}), testRequestID)
+	assert.Nil(t, err)
+	if assertion != nil {
+		assert.Equal(t, "john.wick@bad.org", assertion.NameID)
+		assert.Equal(t, NameIDFormatEmail, assertion.NameIDFormat)
+		assert.Equal(t, "_s1", assertion.SessionIndex)
+		assert.Equal(t, testNow.Truncate(time.Second).UTC(), assertion.AuthnInstant)
+		assert.Equal(t, []string{"sre", "dba"}, assertion.Attributes["groups"])
+	}
+
+	for _, tt := range []struct {
+		msg       string
+		resp      string
+		requestID string
+		now       time.Time
+	}{
+		{
+			msg:  "it must fail when the assertion is tampered",
+			resp: newTestResponse(t, key, responseOptions{tamperedNameID: "admin@bad.org"}),
+		},
+		{
+			msg:  "it must fail when the assertion is signed by another key",
+			resp: newTestResponse(t, otherKey, responseOptions{}),
+		},
+		{
+			msg:  "it must fail when the assertion is not signed",
+			resp: newTestResponse(t, key, responseOptions{unsigned: true}),
+		},
+		{
+			msg:       "it must fail when the response is not in reply to the request",
+			resp:      newTestResponse(t, key, responseOptions{}),
+			requestID: "_other-request",
+		},
+		{
+			msg:  "it must fail when the audience is another service provider",
+			resp: newTestResponse(t, key, responseOptions{audience: "https://other.bad.org"}),
+		},
+		{
+			msg:  "it must fail when the assertion is expired",
+			resp: newTestResponse(t, key, responseOptions{}),
+			now:  testNow.Add(time.Hour),
+		},
+		{
+			msg:  "it must fail when the assertion is not valid yet",
+			resp: newTestResponse(t, key, responseOptions{}),
+			now:  testNow.Add(-time.Hour),
+		},
+		{
+			msg:  "it must fail with invalid responses",
+			resp: base64.StdEncoding.EncodeToString([]byte(`<samlp:Response xmlns:samlp="` + nsSAMLP + `"/>`)),
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			sp := newTestServiceProvider(cert)
+			if !tt.now.IsZero() {
+				sp.now = func() time.Time { return tt.now }
+			}
+			if tt.requestID == "" {
+				tt.requestID = testRequestID
+			}
+			_, err := sp.ParseResponse(tt.resp, tt.requestID)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+// tamperResponse replaces the content of an encoded response
+func tamperResponse(encodedResponse, old, new string) string {
+	data, _ := base64.StdEncoding.DecodeString(encodedResponse)
+	return base64.StdEncoding.EncodeToString([]byte(strings.Replace(string(data), old, new, 1)))
+}
+
+func TestParseResponseAttacks(t *testing.T) {
+	key, cert := newTestCertificate(t)
+	otherKey, otherCert := newTestCertificate(t)
+	for _, tt := range []struct {
+		msg        string
+		resp       string
+		wantNameID string
+	}{
+		{
+			msg:        "it must accept responses signed by the identity provider",
+			resp:       newTestResponse(t, key, responseOptions{unsigned: true, signResponse: cert}),
+			wantNameID: "john.wick@bad.org",
+		},
+		{
+			msg:        "it must accept signed responses containing signed assertions",
+			resp:       newTestResponse(t, key, responseOptions{signResponse: cert}),
+			wantNameID: "john.wick@bad.org",
+		},
+		{
+			msg: "it must read the whole name id when a comment is inserted after signing",
+			resp: newTestResponse(t, key, responseOptions{
+				nameID:         "admin@bad.org.evil.com",
+				tamperedNameID: "admin@bad.org<!---->.evil.com",
+			}),
+			wantNameID: "admin@bad.org.evil.com",
+		},
+		{
+			msg: "it must fail when the signed assertion is wrapped by a forged one with the same id",
+			resp: newTestResponse(t, key, responseOptions{wrap: func(assertion string) string {
+				return newForgedAssertion("_a1", "<saml:Advice>"+assertion+"</saml:Advice>")
+			}}),
+		},
+		{
+			msg: "it must fail when the signed assertion is wrapped by a forged one",
+			resp: newTestResponse(t, key, responseOptions{wrap: func(assertion string) string {
+				return newForgedAssertion("_evil", "<saml:Advice>"+assertion+"</saml:Advice>")
+			}}),
+		},
+		{
+			msg: "it must fail when the signed assertion is moved to the extensions of the response",
+			resp: newTestResponse(t, key, responseOptions{wrap: func(assertion string) string {
+				return "<samlp:Extensions>" + assertion + "</samlp:Extensions>" + newForgedAssertion("_evil", "")
+			}}),
+		},
+		{
+			msg: "it must fail when a forged assertion is added before the signed one",
+			resp: newTestResponse(t, key, responseOptions{wrap: func(assertion string) string {
+				return newForgedAssertion("_evil", "") + assertion
+			}}),
+		},
+		{
+			msg: "it must fail when a forged assertion is added after the signed one",
+			resp: newTestResponse(t, key, responseOptions{wrap: func(assertion string) string {
+				return assertion + newForgedAssertion("_evil", "")
+			}}),
+		},
+		{
+			msg: "it must fail when a forged assertion is added to a signed response",
+			resp: tamperResponse(newTestResponse(t, key, responseOptions{unsigned: true, signResponse: cert}),
+				"</samlp:Status>", "</samlp:Status>"+newForgedAssertion("_evil", "")),
+		},
+		{
+			msg: "it must fail when the assertion of a signed response is tampered",
+			resp: tamperResponse(newTestResponse(t, key, responseOptions{unsigned: true, signResponse: cert}),
+				"john.wick@bad.org", "admin@bad.org"),
+		},
+		{
+			msg:  "it must fail when the response is signed by another key",
+			resp: newTestResponse(t, otherKey, responseOptions{unsigned: true, signResponse: otherCert}),
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			assertion, err := newTestServiceProvider(cert).ParseResponse(tt.resp, testRequestID)
+			if tt.wantNameID == "" {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			if assertion != nil {
+				assert.Equal(t, tt.wantNameID, assertion.NameID)
+			}
+		})
+	}
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	_, cert := newTestCertificate(t)
+	sp := newTestServiceProvider(cert)
+	loginURL, err := sp.AuthnRequestURL(testRequestID, "state-1", false)
+	assert.Nil(t, err)
+	u, err := url.Parse(loginURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "idp.bad.org", u.Host)
+	assert.Equal(t, "1", u.Query().Get("tenant"))
+	assert.Equal(t, "state-1", u.Query().Get("RelayState"))
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	assert.Nil(t, err)
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	assert.Nil(t, err)
+	req, err := parseXML(data)
+	assert.Nil(t, err)
+	assert.True(t, req.is(nsSAMLP, "AuthnRequest"))
+	assert.Equal(t, testRequestID, req.attr("ID"))
+	assert.Equal(t, testACSURL, req.attr("AssertionConsumerServiceURL"))
+	assert.Equal(t, testSPEntityID, req.child(nsSAML, "Issuer").text())
+	assert.Empty(t, req.attr("ForceAuthn"))
+
+	loginURL, err = sp.AuthnRequestURL(testRequestID, "state-1", true)
+	assert.Nil(t, err)
+	u, err = url.Parse(loginURL)
+	assert.Nil(t, err)
+	compressed, err = base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	assert.Nil(t, err)
+	data, err = io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	assert.Nil(t, err)
+	req, err = parseXML(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "true", req.attr("ForceAuthn"), "it must request a new authentication")
+}
+
+func TestParseMetadata(t *testing.T) {
+	_, cert := newTestCertificate(t)
+	encodedCert := base64.StdEncoding.EncodeToString(cert.Raw)
+	metadata := `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + testIDPEntityID + `">
+  <md:IDPSSODescriptor WantAuthnRequestsSigned="false" protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="encryption"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>invalid</ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor>
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data><ds:X509Certificate>
+          ` + encodedCert[:64] + "\n" + encodedCert[64:] + `
+        </ds:X509Certificate></ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.bad.org/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.bad.org/sso/redirect"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+	idp, err := ParseMetadata([]byte(metadata))
+	assert.Nil(t, err)
+	if idp != nil {
+		assert.Equal(t, testIDPEntityID, idp.EntityID)
+		assert.Equal(t, "https://idp.bad.org/sso/redirect", idp.SSOURL)
+		assert.Equal(t, 1, len(idp.Certificates))
+	}
+
+	_, err = ParseMetadata([]byte(strings.Replace(metadata, "HTTP-Redirect", "SOAP", 1)))
+	assert.NotNil(t, err, "it must require the HTTP-Redirect binding")
+
+	spMetadata, err := newTestServiceProvider(cert).Metadata()
+	assert.Nil(t, err)
+	root, err := parseXML(spMetadata)
+	assert.Nil(t, err)
+	assert.Equal(t, testSPEntityID, root.attr("entityID"))
+	assert.Equal(t, testACSURL, root.find(nsMetadata, "SPSSODescriptor", "AssertionConsumerService").attr("Location"))
+}
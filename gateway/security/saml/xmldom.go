@@ -0,0 +1,156 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// element is a minimal document tree preserving the namespace prefixes
+// and declarations required to canonicalize signed elements
+type element struct {
+	prefix   string
+	local    string
+	attrs    []attribute
+	nsDecls  map[string]string
+	children []any // *element or text (string)
+	parent   *element
+}
+
+type attribute struct {
+	prefix string
+	local  string
+	value  string
+}
+
+// parseXML parses a document returning its root element,
+// documents with directives (DTD) are not accepted
+func parseXML(data []byte) (*element, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *element
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing xml: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil && current == nil {
+				return nil, fmt.Errorf("failed parsing xml: multiple root elements")
+			}
+			el := &element{prefix: t.Name.Space, local: t.Name.Local, nsDecls: map[string]string{}, parent: current}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					el.nsDecls[""] = a.Value
+				case a.Name.Space == "xmlns":
+					el.nsDecls[a.Name.Local] = a.Value
+				default:
+					el.attrs = append(el.attrs, attribute{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+				}
+			}
+			if current == nil {
+				root = el
+			} else {
+				current.children = append(current.children, el)
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, fmt.Errorf("failed parsing xml: unexpected end element %v", t.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		case xml.Directive:
+			return nil, fmt.Errorf("failed parsing xml: directives are not supported")
+		}
+	}
+	if root == nil || current != nil {
+		return nil, fmt.Errorf("failed parsing xml: incomplete document")
+	}
+	return root, nil
+}
+
+// lookupNamespace returns the namespace bound to the prefix in the scope of the element
+func (e *element) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+	for el := e; el != nil; el = el.parent {
+		if uri, ok := el.nsDecls[prefix]; ok {
+			return uri, true
+		}
+	}
+	return "", prefix == ""
+}
+
+// namespace returns the namespace of the element
+func (e *element) namespace() string {
+	uri, _ := e.lookupNamespace(e.prefix)
+	return uri
+}
+
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// attr returns the value of an attribute without namespace
+func (e *element) attr(local string) string {
+	for _, a := range e.attrs {
+		if a.prefix == "" && a.local == local {
+			return a.value
+		}
+	}
+	return ""
+}
+
+func (e *element) childElements(namespace, local string) []*element {
+	var items []*element
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok && el.is(namespace, local) {
+			items = append(items, el)
+		}
+	}
+	return items
+}
+
+func (e *element) child(namespace, local string) *element {
+	if items := e.childElements(namespace, local); len(items) > 0 {
+		return items[0]
+	}
+	return nil
+}
+
+// find returns the first descendant element following the path of names in the namespace
+func (e *element) find(namespace string, path ...string) *element {
+	el := e
+	for _, local := range path {
+		if el = el.child(namespace, local); el == nil {
+			return nil
+		}
+	}
+	return el
+}
+
+func (e *element) text() string {
+	if e == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, child := range e.children {
+		if text, ok := child.(string); ok {
+			sb.WriteString(text)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
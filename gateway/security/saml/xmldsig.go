@@ -0,0 +1,66 @@
+package saml
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+// verifySignature validates the enveloped signature of the element with the certificates
+// of the identity provider and returns a copy of the signed content. Only the returned
+// element must be trusted, it prevents signature wrapping attacks.
+func (sp *ServiceProvider) verifySignature(el *etree.Element) (*etree.Element, error) {
+	el, err := detach(el)
+	if err != nil {
+		return nil, err
+	}
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: sp.IDP.Certificates})
+	ctx.IdAttribute = "ID"
+	ctx.Clock = dsig.NewFakeClockAt(sp.now())
+	return ctx.Validate(el)
+}
+
+// detach returns a copy of the element declaring the namespaces in its scope
+func detach(el *etree.Element) (*etree.Element, error) {
+	ctx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+	return etreeutils.NSDetatch(ctx, el)
+}
+
+// toElement converts a detached element to the document tree used to read its content
+func toElement(el *etree.Element) (*element, error) {
+	doc := etree.NewDocument()
+	doc.SetRoot(el)
+	data, err := doc.WriteToBytes()
+	if err != nil {
+		return nil, err
+	}
+	return parseXML(data)
+}
+
+// parseCertificate parses a base64 encoded (DER) certificate
+func parseCertificate(encoded string) (*x509.Certificate, error) {
+	data, err := base64.StdEncoding.DecodeString(stripSpaces(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding certificate: %v", err)
+	}
+	return x509.ParseCertificate(data)
+}
+
+func stripSpaces(v string) string {
+	return string(bytes.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, []byte(v)))
+}
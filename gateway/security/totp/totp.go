@@ -0,0 +1,86 @@
+// Package totp implements time-based one-time passwords (RFC 6238) compatible
+// with authenticator apps: HMAC-SHA1, 6 digits and a period of 30 seconds.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30
+
+	secretSize = 20
+	// the number of periods accepted before and after the current time
+	// to tolerate clock drift between the server and the device
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 encoded secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns the key uri used to enroll the secret in authenticator apps, e.g.: by a QR code
+func URI(issuer, accountName, secret string) string {
+	qs := url.Values{}
+	qs.Set("secret", secret)
+	qs.Set("issuer", issuer)
+	qs.Set("algorithm", "SHA1")
+	qs.Set("digits", fmt.Sprintf("%v", Digits))
+	qs.Set("period", fmt.Sprintf("%v", Period))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, qs.Encode())
+}
+
+// Validate reports if the code is valid at the given time. Codes of periods lower or equal
+// than lastStep are rejected to prevent reusing codes, the period of the valid code is
+// returned and it must be stored as the last step after a successful validation.
+func Validate(secret, code string, now time.Time, lastStep int64) (step int64, valid bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+	current := now.Unix() / Period
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(generate(key, step, Digits)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// generate returns the code of a period (RFC 4226 - HOTP)
+func generate(key []byte, step int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,67 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 6238 - Appendix B (SHA1)
+func TestGenerateRFCVectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, tt := range []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	} {
+		assert.Equal(t, tt.want, generate(key, tt.unix/Period, 8), "time=%v", tt.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := encoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+	current := now.Unix() / Period
+
+	step, valid := Validate(secret, "081804", now, 0)
+	assert.True(t, valid)
+	assert.Equal(t, current, step)
+
+	_, valid = Validate(secret, "081804", now, current)
+	assert.False(t, valid, "it must reject codes already used")
+
+	_, valid = Validate(secret, "081804", now.Add(Period*time.Second), 0)
+	assert.True(t, valid, "it must accept codes of the previous period")
+
+	_, valid = Validate(secret, "081804", now.Add(2*Period*time.Second), 0)
+	assert.False(t, valid, "it must reject codes out of the skew")
+
+	for _, code := range []string{"", "000000", "81804", "0818045"} {
+		_, valid = Validate(secret, code, now, 0)
+		assert.False(t, valid, "code=%q", code)
+	}
+	_, valid = Validate("not-base32!", "081804", now, 0)
+	assert.False(t, valid)
+}
+
+func TestSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.Nil(t, err)
+	assert.Len(t, secret, 32)
+
+	u, err := url.Parse(URI("hoop.dev", "john@domain.tld", secret))
+	assert.Nil(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/hoop.dev:john@domain.tld", u.Path)
+	assert.Equal(t, secret, u.Query().Get("secret"))
+	assert.Equal(t, "hoop.dev", u.Query().Get("issuer"))
+}
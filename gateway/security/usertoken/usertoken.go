@@ -0,0 +1,148 @@
+// Package usertoken manages personal access tokens. They are owned by users to
+// automate the access to the gateway and the scopes of a token limit what it's allowed to do:
+//
+//   - sessions:read - read the sessions of the user
+//   - exec:<connection> - execute and connect to a connection, exec:* allows any connection
+//   - admin - all the operations allowed to the user
+//
+// Only the sha256 hash of the tokens is stored.
+package usertoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgusers "github.com/hoophq/hoop/gateway/pgrest/users"
+	pgusertokens "github.com/hoophq/hoop/gateway/pgrest/usertokens"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+)
+
+const (
+	tokenPrefix = "xpat-"
+
+	ScopeSessionsRead = "sessions:read"
+	ScopeExecPrefix   = "exec:"
+	ScopeExecAll      = "exec:*"
+	ScopeAdmin        = "admin"
+
+	// MaxExpiresInDays is the maximum lifetime of a token, tokens
+	// created without an expiration expire after it as well
+	MaxExpiresInDays = 365
+
+	// the last usage of a token is persisted at most once in this interval
+	lastUsedInterval = time.Minute
+)
+
+var ErrInvalidToken = errors.New("invalid, expired or revoked token")
+
+// NewToken generates a random personal access token
+func NewToken() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// IsToken reports if the token has the format of a personal access token
+func IsToken(token string) bool { return strings.HasPrefix(token, tokenPrefix) }
+
+func Hash(token string) string { return fmt.Sprintf("%x", sha256.Sum256([]byte(token))) }
+
+// ParseScopes validates and normalizes the scopes of a token
+func ParseScopes(scopes []string) ([]string, error) {
+	var items []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		switch {
+		case scope == ScopeSessionsRead, scope == ScopeAdmin:
+		case strings.HasPrefix(scope, ScopeExecPrefix):
+			connectionName := strings.TrimPrefix(scope, ScopeExecPrefix)
+			if connectionName == "" || strings.ContainsAny(connectionName, " ,") {
+				return nil, fmt.Errorf("invalid scope %q, it must be in the format exec:<connection>", scope)
+			}
+		default:
+			return nil, fmt.Errorf("unknown scope %q, accepted values are %v, %v<connection> or %v",
+				scope, ScopeSessionsRead, ScopeExecPrefix, ScopeAdmin)
+		}
+		if !slices.Contains(items, scope) {
+			items = append(items, scope)
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return items, nil
+}
+
+// HasScope reports if the scopes grant the scope, the admin scope grants all of them
+func HasScope(scopes []string, scope string) bool {
+	return slices.Contains(scopes, ScopeAdmin) || slices.Contains(scopes, scope)
+}
+
+// HasExecScope reports if the scopes allow executing in any connection
+func HasExecScope(scopes []string) bool {
+	return slices.ContainsFunc(scopes, func(scope string) bool {
+		return scope == ScopeAdmin || strings.HasPrefix(scope, ScopeExecPrefix)
+	})
+}
+
+// AllowExec reports if the scopes allow executing in the connection
+func AllowExec(scopes []string, connectionName string) bool {
+	return HasScope(scopes, ScopeExecAll) || slices.Contains(scopes, ScopeExecPrefix+connectionName)
+}
+
+// IsActive reports if the token isn't revoked or expired
+func IsActive(t *pgrest.UserToken, now time.Time) bool {
+	if t.GetRevokedAt() != nil {
+		return false
+	}
+	expiresAt := t.GetExpiresAt()
+	if expiresAt == nil {
+		v := t.GetCreatedAt().AddDate(0, 0, MaxExpiresInDays)
+		expiresAt = &v
+	}
+	return now.Before(*expiresAt)
+}
+
+// Verify validates the token and returns the subject of its owner and the scopes of the token
+func Verify(token string) (subject string, scopes []string, err error) {
+	if !IsToken(token) {
+		return "", nil, ErrInvalidToken
+	}
+	t, err := pgusertokens.New().FetchOneByHash(Hash(token))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed fetching token: %v", err)
+	}
+	now := time.Now().UTC()
+	if t == nil || !IsActive(t, now) {
+		return "", nil, ErrInvalidToken
+	}
+	usr, err := pgusers.New().FetchOneByID(pgrest.NewOrgContext(t.OrgID), t.UserID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed fetching owner of token: %v", err)
+	}
+	// tokens of inactive users are refused even if they weren't revoked
+	if usr == nil || usr.Status != string(types.UserStatusActive) {
+		return "", nil, ErrInvalidToken
+	}
+	if lastUsedAt := t.GetLastUsedAt(); lastUsedAt == nil || now.Sub(*lastUsedAt) >= lastUsedInterval {
+		if err := pgusertokens.New().UpdateLastUsed(t.ID, now); err != nil {
+			log.Warnf("failed updating last usage of token %v, reason=%v", t.ID, err)
+		}
+	}
+	// a non nil value distinguishes the authentication of personal access tokens
+	scopes = t.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return usr.Subject, scopes, nil
+}
@@ -0,0 +1,129 @@
+package usertoken
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewToken(t *testing.T) {
+	token, err := NewToken()
+	assert.Nil(t, err)
+	assert.True(t, IsToken(token))
+	assert.Regexp(t, `^xpat-[A-Za-z0-9_-]{43}$`, token)
+	assert.Len(t, Hash(token), 64)
+
+	other, _ := NewToken()
+	assert.NotEqual(t, token, other)
+	assert.NotEqual(t, Hash(token), Hash(other))
+}
+
+func TestParseScopes(t *testing.T) {
+	for _, tt := range []struct {
+		msg    string
+		scopes []string
+		want   []string
+		err    bool
+	}{
+		{msg: "it must normalize the scopes", scopes: []string{" sessions:read", "exec:pgdemo ", "sessions:read"}, want: []string{"sessions:read", "exec:pgdemo"}},
+		{msg: "it must accept the admin and exec all scopes", scopes: []string{"admin", "exec:*"}, want: []string{"admin", "exec:*"}},
+		{msg: "it must fail with unknown scopes", scopes: []string{"sessions:write"}, err: true},
+		{msg: "it must fail with exec scope without connection", scopes: []string{"exec:"}, err: true},
+		{msg: "it must fail with invalid connection names", scopes: []string{"exec:pg demo"}, err: true},
+		{msg: "it must fail without scopes", scopes: []string{" "}, err: true},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			got, err := ParseScopes(tt.scopes)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScopes(t *testing.T) {
+	readOnly := []string{ScopeSessionsRead}
+	assert.True(t, HasScope(readOnly, ScopeSessionsRead))
+	assert.False(t, HasScope(readOnly, ScopeAdmin))
+	assert.False(t, HasExecScope(readOnly))
+	assert.False(t, AllowExec(readOnly, "pgdemo"))
+
+	execOne := []string{"exec:pgdemo"}
+	assert.True(t, HasExecScope(execOne))
+	assert.True(t, AllowExec(execOne, "pgdemo"))
+	assert.False(t, AllowExec(execOne, "pgprod"))
+	assert.False(t, HasScope(execOne, ScopeExecAll))
+
+	execAll := []string{ScopeExecAll}
+	assert.True(t, AllowExec(execAll, "pgprod"))
+	assert.False(t, HasScope(execAll, ScopeSessionsRead))
+
+	admin := []string{ScopeAdmin}
+	assert.True(t, HasScope(admin, ScopeSessionsRead))
+	assert.True(t, HasExecScope(admin))
+	assert.True(t, AllowExec(admin, "pgprod"))
+}
+
+func TestIsActive(t *testing.T) {
+	now := time.Now().UTC()
+	format := func(t time.Time) *string {
+		v := t.Format("2006-01-02T15:04:05.999999")
+		return &v
+	}
+	assert.True(t, IsActive(&pgrest.UserToken{CreatedAt: *format(now.Add(-time.Hour))}, now), "it must be active without expiration")
+	assert.False(t, IsActive(&pgrest.UserToken{CreatedAt: *format(now.AddDate(0, 0, -MaxExpiresInDays-1))}, now),
+		"it must expire tokens without expiration after the maximum lifetime")
+	assert.True(t, IsActive(&pgrest.UserToken{ExpiresAt: format(now.Add(time.Hour))}, now))
+	assert.False(t, IsActive(&pgrest.UserToken{ExpiresAt: format(now.Add(-time.Second))}, now), "it must be inactive when expired")
+	assert.False(t, IsActive(&pgrest.UserToken{RevokedAt: format(now.Add(-time.Hour))}, now), "it must be inactive when revoked")
+}
+
+// fakePgRest answers the requests of a token and its owner
+type fakePgRest struct {
+	userStatus string
+}
+
+func (f *fakePgRest) Do(req *http.Request) (*http.Response, error) {
+	var body string
+	switch {
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/user_tokens"):
+		createdAt := time.Now().UTC().Add(-time.Hour).Format("2006-01-02T15:04:05")
+		body = fmt.Sprintf(`{"id": "token-id", "org_id": "org-id", "user_id": "user-id", "scopes": ["admin"], "created_at": %q, "last_used_at": %q}`,
+			createdAt, time.Now().UTC().Format("2006-01-02T15:04:05"))
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/users"):
+		body = fmt.Sprintf(`{"id": "user-id", "org_id": "org-id", "subject": "john", "status": %q}`, f.userStatus)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil
+}
+
+func TestVerifyOwnerStatus(t *testing.T) {
+	pgrest.WithBaseURL(&url.URL{Host: "127.0.0.1:3008"})
+	defer pgrest.WithHttpClient(http.DefaultClient)
+	token, err := NewToken()
+	assert.Nil(t, err)
+
+	pgrest.WithHttpClient(&fakePgRest{userStatus: "active"})
+	subject, scopes, err := Verify(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "john", subject)
+	assert.Equal(t, []string{"admin"}, scopes)
+
+	pgrest.WithHttpClient(&fakePgRest{userStatus: "inactive"})
+	_, _, err = Verify(token)
+	assert.Equal(t, ErrInvalidToken, err, "it must refuse tokens of inactive users")
+}
@@ -0,0 +1,122 @@
+// Package blobstorage persists the content of session blobs (event streams)
+// in a storage backend. The metadata of a blob is always kept in Postgres,
+// the content could be stored inline (postgres), in the local filesystem or
+// in an object storage compatible with the S3 API.
+package blobstorage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	TypePostgres   = "postgres"
+	TypeFilesystem = "filesystem"
+	TypeS3         = "s3"
+
+	defaultFilesystemPath = "/opt/hoop/sessions"
+	defaultS3Region       = "us-east-1"
+)
+
+var (
+	ErrNotFound = errors.New("blob not found")
+
+	mu           sync.RWMutex
+	defaultStore Storage = NewPostgres()
+)
+
+// Storage stores the content of blobs identified by a key
+type Storage interface {
+	// Name is persisted with the blob metadata to know
+	// which backend has the content of the blob
+	Name() string
+	Put(key string, data []byte) error
+	// Get returns ErrNotFound when the key doesn't exist
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// Load configures the storage backend based on environment variables.
+//
+// SESSION_BLOB_STORAGE - postgres (default), filesystem or s3
+// SESSION_BLOB_STORAGE_PATH - the base directory when using the filesystem backend
+// SESSION_BLOB_S3_BUCKET - the name of the bucket when using the s3 backend
+// SESSION_BLOB_S3_REGION - the region of the bucket, defaults to us-east-1
+// SESSION_BLOB_S3_ENDPOINT - a custom endpoint (MinIO, etc), it uses path-style requests
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN - the credentials to the s3 backend
+func Load() (err error) {
+	var store Storage
+	switch storageType := os.Getenv("SESSION_BLOB_STORAGE"); storageType {
+	case "", TypePostgres:
+		store = NewPostgres()
+	case TypeFilesystem:
+		basePath := os.Getenv("SESSION_BLOB_STORAGE_PATH")
+		if basePath == "" {
+			basePath = defaultFilesystemPath
+		}
+		store, err = NewFilesystem(basePath)
+	case TypeS3:
+		region := os.Getenv("SESSION_BLOB_S3_REGION")
+		if region == "" {
+			region = defaultS3Region
+		}
+		store, err = NewS3(S3Config{
+			Bucket:          os.Getenv("SESSION_BLOB_S3_BUCKET"),
+			Region:          region,
+			Endpoint:        os.Getenv("SESSION_BLOB_S3_ENDPOINT"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		})
+	default:
+		return fmt.Errorf("unknown session blob storage %q, accepted values are: %v, %v, %v",
+			storageType, TypePostgres, TypeFilesystem, TypeS3)
+	}
+	if err != nil {
+		return err
+	}
+	Set(store)
+	return nil
+}
+
+// Get returns the configured storage backend
+func Get() Storage {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultStore
+}
+
+// Set changes the storage backend used to persist blobs
+func Set(store Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultStore = store
+}
+
+// Lookup returns the storage backend that has the content of a blob,
+// it fails if the blob was stored in a backend that isn't the configured one.
+func Lookup(name string) (Storage, error) {
+	if name == "" || name == TypePostgres {
+		return NewPostgres(), nil
+	}
+	if store := Get(); store.Name() == name {
+		return store, nil
+	}
+	return nil, fmt.Errorf("blob storage %q is not configured, current=%v", name, Get().Name())
+}
+
+// SessionStreamKey is the key used to store the event stream of a session
+// in external storage backends
+func SessionStreamKey(orgID, sessionID string) string {
+	return fmt.Sprintf("sessions/%s/%s.json", orgID, sessionID)
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package blobstorage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type filesystemStorage struct {
+	basePath string
+}
+
+// NewFilesystem stores the content as files inside the base path
+func NewFilesystem(basePath string) (Storage, error) {
+	if err := os.MkdirAll(basePath, 0700); err != nil {
+		return nil, fmt.Errorf("failed creating blob storage directory %v, reason=%v", basePath, err)
+	}
+	return &filesystemStorage{basePath: basePath}, nil
+}
+
+func (s *filesystemStorage) Name() string { return TypeFilesystem }
+
+func (s *filesystemStorage) Put(key string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	filePath := filepath.Join(s.basePath, key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+	// write it to a temporary file first to avoid partial content
+	tmpFile := filePath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filePath)
+}
+
+func (s *filesystemStorage) Get(key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.basePath, key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *filesystemStorage) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.basePath, key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package blobstorage
+
+import (
+	"encoding/json"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+)
+
+type postgresStorage struct{}
+
+// NewPostgres stores the content inline in the blobs table,
+// the key is the id of the blob which must exist prior to Put.
+func NewPostgres() Storage { return &postgresStorage{} }
+
+func (s *postgresStorage) Name() string { return TypePostgres }
+
+func (s *postgresStorage) Put(key string, data []byte) error {
+	return pgrest.New("/blobs?id=eq.%s", key).
+		Patch(map[string]any{"blob_stream": json.RawMessage(data)}).
+		Error()
+}
+
+func (s *postgresStorage) Get(key string) ([]byte, error) {
+	var blob pgrest.Blob
+	err := pgrest.New("/blobs?select=id,blob_stream&id=eq.%s", key).
+		FetchOne().
+		DecodeInto(&blob)
+	switch err {
+	case nil:
+	case pgrest.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+	return json.Marshal(blob.BlobStream)
+}
+
+func (s *postgresStorage) Delete(key string) error {
+	return pgrest.New("/blobs?id=eq.%s", key).
+		Patch(map[string]any{"blob_stream": []any{}}).
+		Error()
+}
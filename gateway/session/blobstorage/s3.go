@@ -0,0 +1,202 @@
+package blobstorage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const s3RequestTimeout = time.Second * 60
+
+type S3Config struct {
+	Bucket string
+	Region string
+	// Endpoint of a S3 compatible service (e.g.: http://127.0.0.1:9000),
+	// when it's set the requests are performed using path-style urls.
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type s3Storage struct {
+	config  S3Config
+	baseURL *url.URL
+	client  *http.Client
+	// it allows overriding the time when signing requests
+	now func() time.Time
+}
+
+// NewS3 stores the content as objects in a bucket compatible with the S3 API.
+// The requests are signed using the AWS Signature Version 4.
+func NewS3(config S3Config) (Storage, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, fmt.Errorf("missing s3 bucket or region for blob storage")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("missing s3 credentials (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY) for blob storage")
+	}
+	rawURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", config.Bucket, config.Region)
+	if config.Endpoint != "" {
+		rawURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(config.Endpoint, "/"), config.Bucket)
+	}
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing s3 endpoint, reason=%v", err)
+	}
+	return &s3Storage{
+		config:  config,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: s3RequestTimeout},
+		now:     time.Now,
+	}, nil
+}
+
+func (s *s3Storage) Name() string { return TypeS3 }
+
+func (s *s3Storage) Put(key string, data []byte) error {
+	resp, err := s.do(http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.decodeError("put", key, resp)
+	}
+	return nil
+}
+
+func (s *s3Storage) Get(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	}
+	return nil, s.decodeError("get", key, resp)
+}
+
+func (s *s3Storage) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return s.decodeError("delete", key, resp)
+}
+
+func (s *s3Storage) decodeError(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("failed to %s s3 object %v, status=%v, body=%v", op, key, resp.StatusCode, string(body))
+}
+
+func (s *s3Storage) do(method, key string, data []byte) (*http.Response, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	objURL := *s.baseURL
+	objURL.Path = strings.TrimSuffix(objURL.Path, "/") + "/" + key
+	objURL.RawPath = s3EscapePath(objURL.Path)
+	req, err := http.NewRequest(method, objURL.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	s.sign(req, data)
+	return s.client.Do(req)
+}
+
+// sign adds the authorization header to the request
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *s3Storage) sign(req *http.Request, payload []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.config.SessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		val := req.Header.Get(h)
+		if h == "host" {
+			val = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(val) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", shortDate, s.config.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), shortDate)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// s3EscapePath encodes each segment of the path according to RFC 3986
+func s3EscapePath(p string) string {
+	var buf strings.Builder
+	for _, c := range []byte(p) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,112 @@
+// Package livestream fans out the events of active sessions to subscribers
+// that want to follow a session in real time (e.g.: shadowing a console).
+package livestream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// subscriptionBufferSize is the amount of events a subscriber could lag behind
+// before being disconnected.
+const subscriptionBufferSize = 1024
+
+var (
+	ErrSessionEnded = errors.New("session ended")
+	ErrSlowConsumer = errors.New("subscriber is not able to keep up with the session events")
+)
+
+type Event struct {
+	Time    time.Time
+	Type    byte
+	Payload []byte
+}
+
+type Subscription struct {
+	sid    string
+	events chan Event
+	err    error
+	once   sync.Once
+}
+
+var (
+	mu            sync.RWMutex
+	subscriptions = map[string]map[*Subscription]struct{}{}
+)
+
+// Subscribe starts receiving the events published to the session
+func Subscribe(sid string) *Subscription {
+	s := &Subscription{sid: sid, events: make(chan Event, subscriptionBufferSize)}
+	mu.Lock()
+	defer mu.Unlock()
+	if subscriptions[sid] == nil {
+		subscriptions[sid] = map[*Subscription]struct{}{}
+	}
+	subscriptions[sid][s] = struct{}{}
+	return s
+}
+
+// Events returns a channel that is closed when the subscription ends,
+// the reason is available calling Err.
+func (s *Subscription) Events() <-chan Event { return s.events }
+
+// Err returns the reason of why the subscription has ended
+func (s *Subscription) Err() error {
+	mu.RLock()
+	defer mu.RUnlock()
+	return s.err
+}
+
+// Close ends the subscription, it's safe to call it more than once
+func (s *Subscription) Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	s.closeWithErr(nil)
+}
+
+// closeWithErr must be called holding the lock
+func (s *Subscription) closeWithErr(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.events)
+		delete(subscriptions[s.sid], s)
+		if len(subscriptions[s.sid]) == 0 {
+			delete(subscriptions, s.sid)
+		}
+	})
+}
+
+// HasSubscribers reports if there's anyone watching the session
+func HasSubscribers(sid string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(subscriptions[sid]) > 0
+}
+
+// Publish sends the event to all subscribers of the session without blocking.
+// Subscribers that have their buffer full are disconnected.
+func Publish(sid string, eventTime time.Time, eventType byte, payload []byte) {
+	if !HasSubscribers(sid) {
+		return
+	}
+	ev := Event{Time: eventTime, Type: eventType, Payload: append([]byte(nil), payload...)}
+	mu.Lock()
+	defer mu.Unlock()
+	for s := range subscriptions[sid] {
+		select {
+		case s.events <- ev:
+		default:
+			s.closeWithErr(ErrSlowConsumer)
+		}
+	}
+}
+
+// End closes all the subscriptions of the session
+func End(sid string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for s := range subscriptions[sid] {
+		s.closeWithErr(ErrSessionEnded)
+	}
+}
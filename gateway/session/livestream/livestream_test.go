@@ -0,0 +1,54 @@
+package livestream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	sub := Subscribe("sid-01")
+	other := Subscribe("sid-02")
+	defer other.Close()
+
+	payload := []byte("hello")
+	Publish("sid-01", time.Now(), 'o', payload)
+	payload[0] = 'j'
+	ev := <-sub.Events()
+	if string(ev.Payload) != "hello" || ev.Type != 'o' {
+		t.Errorf("expected to receive a copy of the published event, got=%q (%c)", ev.Payload, ev.Type)
+	}
+	if len(other.Events()) != 0 {
+		t.Errorf("expected no events from other sessions")
+	}
+
+	End("sid-01")
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("expected subscription to be closed")
+	}
+	if sub.Err() != ErrSessionEnded {
+		t.Errorf("expected session ended error, got=%v", sub.Err())
+	}
+	if HasSubscribers("sid-01") {
+		t.Errorf("expected subscribers to be removed")
+	}
+	// it must not panic
+	sub.Close()
+	Publish("sid-01", time.Now(), 'o', payload)
+}
+
+func TestSlowConsumer(t *testing.T) {
+	sub := Subscribe("sid-slow")
+	for i := 0; i <= subscriptionBufferSize; i++ {
+		Publish("sid-slow", time.Now(), 'o', []byte("data"))
+	}
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	if count != subscriptionBufferSize {
+		t.Errorf("expected %v buffered events, got=%v", subscriptionBufferSize, count)
+	}
+	if sub.Err() != ErrSlowConsumer {
+		t.Errorf("expected slow consumer error, got=%v", sub.Err())
+	}
+}
@@ -79,6 +79,8 @@ type MessageReviewRequest struct {
 	Email          string
 	UserGroups     []string
 	ApprovalGroups []string
+	// the description of each stage when the review has a workflow
+	ApprovalStages []string
 	Connection     string
 	ConnectionType string
 	Script         string
@@ -86,6 +88,25 @@ type MessageReviewRequest struct {
 	WebappURL      string
 	SessionID      string
 	SlackChannels  []string
+	// the reason and the ticket reference provided by the user
+	Justification string
+	TicketID      string
+}
+
+type MessageReviewExpired struct {
+	ID            string
+	SessionID     string
+	Connection    string
+	OwnerSlackID  string
+	SlackChannels []string
+}
+
+type MessageBreakGlass struct {
+	SessionID     string
+	Connection    string
+	UserEmail     string
+	Justification string
+	SlackChannels []string
 }
 
 type MessageReviewResponse struct {
@@ -167,13 +188,42 @@ func (s *SlackService) SendMessageReview(msg *MessageReviewRequest) error {
 		metaSection1,
 		metaSection2,
 		metaSection3,
+	}
 
+	// justification and ticket metadata
+	var justificationFields []*slack.TextBlockObject
+	if justification := msg.Justification; justification != "" {
+		if len(justification) > maxLabelSize {
+			justification = justification[:maxLabelSize] + " ..."
+		}
+		justificationFields = append(justificationFields,
+			&slack.TextBlockObject{Type: slack.MarkdownType, Text: fmt.Sprintf("justification\n*%s*", justification)})
+	}
+	if msg.TicketID != "" {
+		justificationFields = append(justificationFields,
+			&slack.TextBlockObject{Type: slack.MarkdownType, Text: fmt.Sprintf("ticket\n*%s*", msg.TicketID)})
+	}
+	if len(justificationFields) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(nil, justificationFields, nil))
+	}
+
+	blocks = append(blocks,
 		scriptBlock,
 
 		slack.NewDividerBlock(),
 
 		reviewLocation,
 		slack.NewDividerBlock(),
+	)
+
+	if len(msg.ApprovalStages) > 0 {
+		blocks = append(blocks,
+			slack.NewSectionBlock(&slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: fmt.Sprintf("_approval workflow_\n%s", strings.Join(msg.ApprovalStages, "\n")),
+			}, nil, nil),
+			slack.NewDividerBlock(),
+		)
 	}
 
 	// add groups button
@@ -227,6 +277,55 @@ func (s *SlackService) SendMessageReview(msg *MessageReviewRequest) error {
 	return nil
 }
 
+// SendMessageReviewExpired notifies the review channels and the owner of the review
+// that it has expired without being fully approved
+func (s *SlackService) SendMessageReviewExpired(msg *MessageReviewExpired) error {
+	text := fmt.Sprintf("⌛ The review of session <%s/sessions/%s|%s> for connection *%s* has expired.",
+		s.apiURL, msg.SessionID, msg.SessionID, msg.Connection)
+
+	slackChannels := msg.SlackChannels
+	if s.slackChannel != "" && !slices.Contains(slackChannels, s.slackChannel) {
+		slackChannels = append(slackChannels, s.slackChannel)
+	}
+	if msg.OwnerSlackID != "" {
+		slackChannels = append(slackChannels, msg.OwnerSlackID)
+	}
+	for _, slackChannel := range slackChannels {
+		_, _, err := s.apiClient.PostMessage(slackChannel, slack.MsgOptionText(text, false))
+		if err != nil {
+			return fmt.Errorf("failed sending message to slack channel %v, reason=%v", slackChannel, err)
+		}
+		// Slack allows 1 post message per second. reference: https://api.slack.com/apis/rate-limits
+		time.Sleep(time.Millisecond * 1200)
+	}
+	return nil
+}
+
+// SendMessageBreakGlass alerts the review channels that a session was opened
+// without waiting for the approval of the review
+func (s *SlackService) SendMessageBreakGlass(msg *MessageBreakGlass) error {
+	justification := msg.Justification
+	if len(justification) > maxLabelSize {
+		justification = justification[:maxLabelSize] + " ..."
+	}
+	text := fmt.Sprintf("🚨 *%s* used break-glass access on connection *%s*, session <%s/sessions/%s|%s> requires a retrospective review.\n> %s",
+		msg.UserEmail, msg.Connection, s.apiURL, msg.SessionID, msg.SessionID, justification)
+
+	slackChannels := msg.SlackChannels
+	if s.slackChannel != "" && !slices.Contains(slackChannels, s.slackChannel) {
+		slackChannels = append(slackChannels, s.slackChannel)
+	}
+	for _, slackChannel := range slackChannels {
+		_, _, err := s.apiClient.PostMessage(slackChannel, slack.MsgOptionText(text, false))
+		if err != nil {
+			return fmt.Errorf("failed sending message to slack channel %v, reason=%v", slackChannel, err)
+		}
+		// Slack allows 1 post message per second. reference: https://api.slack.com/apis/rate-limits
+		time.Sleep(time.Millisecond * 1200)
+	}
+	return nil
+}
+
 func (s *SlackService) UpdateMessage(msg *MessageReviewResponse, isApproved bool) error {
 	blockID := msg.item.ActionCallback.BlockActions[0].BlockID
 	blocks := msg.item.Message.Blocks.BlockSet
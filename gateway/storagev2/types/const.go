@@ -40,6 +40,7 @@ const (
 	ReviewStatusRevoked    ReviewStatus = "REVOKED"
 	ReviewStatusProcessing ReviewStatus = "PROCESSING"
 	ReviewStatusExecuted   ReviewStatus = "EXECUTED"
+	ReviewStatusExpired    ReviewStatus = "EXPIRED"
 	ReviewStatusUnknown    ReviewStatus = "UNKNOWN"
 )
 
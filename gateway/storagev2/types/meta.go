@@ -30,3 +30,6 @@ func (p *PluginConnection) SetName() {
 		p.Name = p.Connection.Name
 	}
 }
+
+// IsBreakGlass reports if the session of the review was opened without waiting for the approval
+func (r *Review) IsBreakGlass() bool { return r.BreakGlassJustification != "" }
@@ -63,6 +63,7 @@ type Login struct {
 	Redirect string `edn:"login/redirect"`
 	Outcome  string `edn:"login/outcome"`
 	SlackID  string `edn:"login/slack-id"`
+	Provider string `edn:"login/provider"`
 }
 
 type Client struct {
@@ -93,19 +94,26 @@ type Connection struct {
 }
 
 type ConnectionInfo struct {
-	ID                 string
-	Name               string
-	Type               string
-	SubType            string
-	CmdEntrypoint      []string
-	Secrets            map[string]any
-	AgentID            string
-	AgentName          string
-	AgentMode          string
-	AccessModeRunbooks string
-	AccessModeExec     string
-	AccessModeConnect  string
-	AccessSchema       string
+	ID                   string
+	Name                 string
+	Type                 string
+	SubType              string
+	CmdEntrypoint        []string
+	Secrets              map[string]any
+	AgentID              string
+	AgentName            string
+	AgentMode            string
+	AccessModeRunbooks   string
+	AccessModeExec       string
+	AccessModeConnect    string
+	AccessSchema         string
+	ReviewTTL            time.Duration
+	RequireJustification bool
+	TicketIDPattern      string
+	RequireRecentAuth    time.Duration
+	DenyRules            []string
+	BreakGlassGroups     []string
+	Tags                 []string
 }
 
 type ReviewOwner struct {
@@ -128,6 +136,30 @@ type ReviewGroup struct {
 	ReviewDate *string      `json:"review_date" edn:"review-group/review_date"`
 }
 
+// ReviewWorkflow defines the stages that must approve a review in order
+type ReviewWorkflow struct {
+	Stages []ReviewStage `json:"stages"`
+	// allow the owner of the review to approve it
+	AllowSelfApproval bool `json:"allow_self_approval"`
+	// prevent the same user approving more than one stage
+	SeparationOfDuties bool `json:"separation_of_duties"`
+}
+
+type ReviewStage struct {
+	Name         string           `json:"name"`
+	Groups       []string         `json:"groups"`
+	MinApprovals int              `json:"min_approvals"`
+	Status       ReviewStatus     `json:"status,omitempty"`
+	Approvals    []ReviewApproval `json:"approvals,omitempty"`
+}
+
+type ReviewApproval struct {
+	Group      string       `json:"group"`
+	Status     ReviewStatus `json:"status"`
+	ReviewedBy ReviewOwner  `json:"reviewed_by"`
+	ReviewDate string       `json:"review_date"`
+}
+
 type Review struct {
 	Id               string            `edn:"xt/id"`
 	OrgId            string            `edn:"review/org"`
@@ -146,23 +178,37 @@ type Review struct {
 	Connection       ReviewConnection  `edn:"review/review-connection"`
 	ReviewGroupsIds  []string          `edn:"review/review-groups"`
 	ReviewGroupsData []ReviewGroup     `edn:"review/review-groups-data"`
+	Workflow         *ReviewWorkflow   `edn:"review/workflow"`
+	ExpiresAt        *time.Time        `edn:"review/expires-at"`
+	ScheduledAt      *time.Time        `edn:"review/scheduled-at"`
+	// the reason to bypass the review, it's set when the review is performed retrospectively
+	BreakGlassJustification string `edn:"review/break-glass-justification"`
+	// the reason and the ticket reference provided by the user when opening the session
+	Justification string `edn:"review/justification"`
+	TicketID      string `edn:"review/ticket-id"`
 }
 
 type ReviewJSON struct {
-	Id               string            `json:"id"`
-	OrgId            string            `json:"org"`
-	CreatedAt        time.Time         `json:"created_at"`
-	Type             string            `json:"type"`
-	Session          string            `json:"session"`
-	Input            string            `json:"input"`
-	InputEnvVars     map[string]string `json:"input_envvars"`
-	InputClientArgs  []string          `json:"input_clientargs"`
-	AccessDuration   time.Duration     `json:"access_duration"`
-	Status           ReviewStatus      `json:"status"`
-	RevokeAt         *time.Time        `json:"revoke_at"`
-	ReviewOwner      ReviewOwner       `json:"review_owner"`
-	Connection       ReviewConnection  `json:"review_connection"`
-	ReviewGroupsData []ReviewGroup     `json:"review_groups_data"`
+	Id                      string            `json:"id"`
+	OrgId                   string            `json:"org"`
+	CreatedAt               time.Time         `json:"created_at"`
+	Type                    string            `json:"type"`
+	Session                 string            `json:"session"`
+	Input                   string            `json:"input"`
+	InputEnvVars            map[string]string `json:"input_envvars"`
+	InputClientArgs         []string          `json:"input_clientargs"`
+	AccessDuration          time.Duration     `json:"access_duration"`
+	Status                  ReviewStatus      `json:"status"`
+	RevokeAt                *time.Time        `json:"revoke_at"`
+	ReviewOwner             ReviewOwner       `json:"review_owner"`
+	Connection              ReviewConnection  `json:"review_connection"`
+	ReviewGroupsData        []ReviewGroup     `json:"review_groups_data"`
+	Workflow                *ReviewWorkflow   `json:"workflow"`
+	ExpiresAt               *time.Time        `json:"expires_at"`
+	ScheduledAt             *time.Time        `json:"scheduled_at"`
+	BreakGlassJustification string            `json:"break_glass_justification"`
+	Justification           string            `json:"justification"`
+	TicketID                string            `json:"ticket_id"`
 }
 
 type SessionEventStream []any
@@ -3,6 +3,7 @@ package transport
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/hoophq/hoop/common/apiutils"
@@ -146,6 +147,11 @@ func (s *Server) listenClientMessages(stream *streamclient.ProxyStream) error {
 			pkt.Spec = make(map[string][]byte)
 		}
 		pkt.Spec[pb.SpecGatewaySessionID] = []byte(pctx.SID)
+		if pb.PacketType(pkt.Type) == pbagent.SessionOpen {
+			if err := setSessionJustification(stream, &pctx, pkt.Spec); err != nil {
+				return err
+			}
+		}
 		shouldProcessClientPacket := true
 		connectResponse, err := stream.PluginExecOnReceive(pctx, pkt)
 		switch v := err.(type) {
@@ -155,6 +161,10 @@ func (s *Server) listenClientMessages(stream *streamclient.ProxyStream) error {
 				sentry.CaptureException(fmt.Errorf(v.FullErr()))
 			}
 			return status.Errorf(codes.Internal, err.Error())
+		case *plugintypes.InvalidArgErr:
+			return status.Errorf(codes.InvalidArgument, err.Error())
+		case *plugintypes.PermissionDeniedErr:
+			return status.Errorf(codes.PermissionDenied, err.Error())
 		case nil: // noop
 		default:
 			return status.Errorf(codes.Internal, err.Error())
@@ -200,16 +210,17 @@ func (s *Server) processClientPacket(stream *streamclient.ProxyStream, pkt *pb.P
 		}
 		clientArgs := clientArgsDecode(pkt.Spec)
 		connParams, err := pb.GobEncode(&pb.AgentConnectionParams{
-			ConnectionName: pctx.ConnectionName,
-			ConnectionType: pb.ToConnectionType(pctx.ConnectionType, pctx.ConnectionSubType).String(),
-			UserID:         pctx.UserID,
-			UserEmail:      pctx.UserEmail,
-			EnvVars:        pctx.ConnectionSecret,
-			CmdList:        pctx.ConnectionCommand,
-			ClientArgs:     clientArgs,
-			ClientVerb:     pctx.ClientVerb,
-			ClientOrigin:   pctx.ClientOrigin,
-			DLPInfoTypes:   stream.GetRedactInfoTypes(),
+			ConnectionName:            pctx.ConnectionName,
+			ConnectionType:            pb.ToConnectionType(pctx.ConnectionType, pctx.ConnectionSubType).String(),
+			UserID:                    pctx.UserID,
+			UserEmail:                 pctx.UserEmail,
+			EnvVars:                   pctx.ConnectionSecret,
+			CmdList:                   pctx.ConnectionCommand,
+			ClientArgs:                clientArgs,
+			ClientVerb:                pctx.ClientVerb,
+			ClientOrigin:              pctx.ClientOrigin,
+			DLPInfoTypes:              stream.GetRedactInfoTypes(),
+			DatabaseCredentialsGrants: stream.GetDatabaseCredentialsGrants(),
 		})
 		if err != nil {
 			return fmt.Errorf("failed encoding connection params err=%v", err)
@@ -250,10 +261,23 @@ func (s *Server) ReviewStatusChange(rev *types.Review) {
 	if proxyStream != nil {
 		payload := []byte(rev.Input)
 		packetType := pbclient.SessionOpenApproveOK
-		if rev.Status == types.ReviewStatusRejected {
+		switch rev.Status {
+		case types.ReviewStatusApproved:
+			// the gateway will execute it at the scheduled time
+			if rev.ScheduledAt != nil {
+				packetType = pbclient.SessionClose
+				payload = []byte(fmt.Sprintf("the review was approved and it's scheduled to run at %s",
+					rev.ScheduledAt.Format(time.RFC3339)))
+				proxyStream.Close(fmt.Errorf("review scheduled to run at %s", rev.ScheduledAt.Format(time.RFC3339)))
+			}
+		case types.ReviewStatusRejected:
 			packetType = pbclient.SessionClose
 			payload = []byte(`access to connection has been denied`)
 			proxyStream.Close(fmt.Errorf("access to connection has been denied"))
+		case types.ReviewStatusExpired:
+			packetType = pbclient.SessionClose
+			payload = []byte(`the review of this session has expired`)
+			proxyStream.Close(fmt.Errorf("the review of this session has expired"))
 		}
 		// TODO: return erroo to caller
 		_ = proxyStream.Send(&pb.Packet{
@@ -264,4 +288,11 @@ func (s *Server) ReviewStatusChange(rev *types.Review) {
 	}
 	log.With("sid", rev.Session, "connection", rev.Connection.Name, "has-stream", proxyStream != nil).
 		Infof("review status change")
+	if rev.Status == types.ReviewStatusExpired {
+		for _, p := range plugintypes.RegisteredPlugins {
+			if h, ok := p.(plugintypes.ReviewExpiredHandler); ok {
+				h.OnReviewExpired(rev)
+			}
+		}
+	}
 }
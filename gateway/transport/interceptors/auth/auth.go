@@ -3,6 +3,7 @@ package authinterceptor
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/hoophq/hoop/common/dsnkeys"
@@ -14,6 +15,8 @@ import (
 	pgagents "github.com/hoophq/hoop/gateway/pgrest/agents"
 	pguserauth "github.com/hoophq/hoop/gateway/pgrest/userauth"
 	"github.com/hoophq/hoop/gateway/security/idp"
+	"github.com/hoophq/hoop/gateway/security/localauth"
+	"github.com/hoophq/hoop/gateway/security/usertoken"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -107,11 +110,15 @@ func (i *interceptor) StreamServerInterceptor(srv any, ss grpc.ServerStream, inf
 		}
 	// client proxy manager authentication (access token)
 	case clientOrigin[0] == pb.ConnectionOriginClientProxyManager:
-		sub, err := i.idp.VerifyAccessToken(bearerToken)
+		sub, authTime, tokenScopes, err := i.verifyAccessToken(bearerToken)
 		if err != nil {
 			log.Debugf("failed verifying access token, reason=%v", err)
 			return status.Errorf(codes.Unauthenticated, "invalid authentication")
 		}
+		// the proxy manager connects to any connection of the user
+		if tokenScopes != nil && !usertoken.HasScope(tokenScopes, usertoken.ScopeExecAll) {
+			return status.Errorf(codes.PermissionDenied, "the scopes of the token don't allow connecting to any connection")
+		}
 		userCtx, err := pguserauth.New().FetchUserContext(sub)
 		if userCtx.IsEmpty() {
 			if err != nil {
@@ -122,10 +129,11 @@ func (i *interceptor) StreamServerInterceptor(srv any, ss grpc.ServerStream, inf
 		ctxVal = &GatewayContext{
 			UserContext: *userCtx.ToAPIContext(),
 			BearerToken: bearerToken,
+			AuthTime:    authTime,
 		}
 	// client proxy authentication (access token)
 	default:
-		sub, err := i.idp.VerifyAccessToken(bearerToken)
+		sub, authTime, tokenScopes, err := i.verifyAccessToken(bearerToken)
 		if err != nil {
 			log.Debugf("failed verifying access token, reason=%v", err)
 			return status.Errorf(codes.Unauthenticated, "invalid authentication")
@@ -140,6 +148,7 @@ func (i *interceptor) StreamServerInterceptor(srv any, ss grpc.ServerStream, inf
 		gwctx := &GatewayContext{
 			UserContext: *userCtx.ToAPIContext(),
 			BearerToken: bearerToken,
+			AuthTime:    authTime,
 		}
 		gwctx.UserContext.ApiURL = i.idp.ApiURL
 		connectionName := commongrpc.MetaGet(md, "connection-name")
@@ -150,6 +159,9 @@ func (i *interceptor) StreamServerInterceptor(srv any, ss grpc.ServerStream, inf
 		if conn == nil {
 			return status.Errorf(codes.NotFound, "connection not found")
 		}
+		if tokenScopes != nil && !usertoken.AllowExec(tokenScopes, conn.Name) {
+			return status.Errorf(codes.PermissionDenied, "the scopes of the token don't allow accessing the connection %v", conn.Name)
+		}
 		gwctx.Connection = *conn
 		ctxVal = gwctx
 	}
@@ -157,6 +169,26 @@ func (i *interceptor) StreamServerInterceptor(srv any, ss grpc.ServerStream, inf
 	return handler(srv, &serverStreamWrapper{ss, nil, ctxVal})
 }
 
+// verifyAccessToken validates tokens issued by the identity provider, personal access
+// tokens or tokens issued by the gateway itself when it opens sessions on behalf of users.
+// The scopes are only returned when it's a personal access token.
+func (i *interceptor) verifyAccessToken(accessToken string) (string, time.Time, []string, error) {
+	switch {
+	case localauth.IsLocalToken(accessToken):
+		// sessions opened by the gateway on behalf of users are not challenged
+		// to authenticate again, the token is issued right before opening them
+		sub, err := localauth.VerifyToken(accessToken)
+		return sub, time.Now().UTC(), nil, err
+	case usertoken.IsToken(accessToken):
+		// personal access tokens don't have an authentication time,
+		// connections requiring a recent authentication refuse them
+		sub, scopes, err := usertoken.Verify(accessToken)
+		return sub, time.Time{}, scopes, err
+	}
+	sub, authTime, err := i.idp.VerifyAccessTokenWithAuthTime(accessToken)
+	return sub, authTime, nil, err
+}
+
 func (i *interceptor) getConnection(name string, userCtx *pguserauth.Context) (*types.ConnectionInfo, error) {
 	conn, err := apiconnections.FetchByName(userCtx, name)
 	if err != nil {
@@ -168,19 +200,26 @@ func (i *interceptor) getConnection(name string, userCtx *pguserauth.Context) (*
 		return nil, nil
 	}
 	return &types.ConnectionInfo{
-		ID:                 conn.ID,
-		Name:               conn.Name,
-		Type:               string(conn.Type),
-		SubType:            conn.SubType,
-		CmdEntrypoint:      conn.Command,
-		Secrets:            conn.AsSecrets(),
-		AgentID:            conn.AgentID,
-		AgentMode:          conn.Agent.Mode,
-		AgentName:          conn.Agent.Name,
-		AccessModeRunbooks: conn.AccessModeRunbooks,
-		AccessModeExec:     conn.AccessModeExec,
-		AccessModeConnect:  conn.AccessModeConnect,
-		AccessSchema:       conn.AccessSchema,
+		ID:                   conn.ID,
+		Name:                 conn.Name,
+		Type:                 string(conn.Type),
+		SubType:              conn.SubType,
+		CmdEntrypoint:        conn.Command,
+		Secrets:              conn.AsSecrets(),
+		AgentID:              conn.AgentID,
+		AgentMode:            conn.Agent.Mode,
+		AgentName:            conn.Agent.Name,
+		AccessModeRunbooks:   conn.AccessModeRunbooks,
+		AccessModeExec:       conn.AccessModeExec,
+		AccessModeConnect:    conn.AccessModeConnect,
+		AccessSchema:         conn.AccessSchema,
+		ReviewTTL:            time.Duration(conn.ReviewTTLSec) * time.Second,
+		RequireJustification: conn.RequireJustification,
+		TicketIDPattern:      conn.TicketIDPattern,
+		RequireRecentAuth:    time.Duration(conn.RequireRecentAuth) * time.Minute,
+		DenyRules:            conn.DenyRules,
+		BreakGlassGroups:     conn.BreakGlassGroups,
+		Tags:                 conn.Tags,
 	}, nil
 }
 
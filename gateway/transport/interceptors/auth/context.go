@@ -3,6 +3,7 @@ package authinterceptor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/hoophq/hoop/gateway/pgrest"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
@@ -18,6 +19,8 @@ type GatewayContext struct {
 
 	BearerToken string
 	IsAdminExec bool
+	// the time the user has authenticated in the identity provider
+	AuthTime time.Time
 }
 
 func (c *GatewayContext) ValidateConnectionAttrs() error {
@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+	pgsession "github.com/hoophq/hoop/gateway/pgrest/session"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+	"github.com/hoophq/hoop/gateway/transport/streamclient"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxJustificationSize = 1000
+	maxTicketIDSize      = 255
+)
+
+// setSessionJustification validates the justification and the ticket id provided when opening a session.
+// The attributes sent in the packet spec are persisted in the metadata of the session and propagated
+// to the plugin context, otherwise the ones already present in the session metadata are used (api clients).
+func setSessionJustification(stream *streamclient.ProxyStream, pctx *plugintypes.Context, spec map[string][]byte) error {
+	justification := strings.TrimSpace(string(spec[pb.SpecSessionJustification]))
+	ticketID := strings.TrimSpace(string(spec[pb.SpecSessionTicketID]))
+	if justification == "" && ticketID == "" {
+		justification, ticketID = pctx.SessionJustification()
+		return validateSessionJustification(*pctx, justification, ticketID)
+	}
+	if err := validateSessionJustification(*pctx, justification, ticketID); err != nil {
+		return err
+	}
+	metadata := map[string]any{}
+	for key, val := range pctx.Metadata {
+		metadata[key] = val
+	}
+	if justification != "" {
+		metadata[plugintypes.MetadataJustificationKey] = justification
+	}
+	if ticketID != "" {
+		metadata[plugintypes.MetadataTicketIDKey] = ticketID
+	}
+	if err := pgsession.New().UpdateMetadata(pctx, pctx.SID, metadata); err != nil {
+		log.With("sid", pctx.SID).Errorf("failed persisting session justification, err=%v", err)
+		sentry.CaptureException(err)
+		return status.Error(codes.Internal, "internal error, failed persisting session justification")
+	}
+	pctx.Metadata = metadata
+	stream.SetPluginContext(func(c *plugintypes.Context) { c.Metadata = metadata })
+	return nil
+}
+
+func validateSessionJustification(pctx plugintypes.Context, justification, ticketID string) error {
+	if pctx.ConnectionRequireJustification && (justification == "" || ticketID == "") {
+		return status.Errorf(codes.InvalidArgument,
+			"the connection %v requires a justification and a ticket id to open sessions", pctx.ConnectionName)
+	}
+	if len(justification) > maxJustificationSize {
+		return status.Errorf(codes.InvalidArgument,
+			"the justification must not contain more than %v characters", maxJustificationSize)
+	}
+	if len(ticketID) > maxTicketIDSize {
+		return status.Errorf(codes.InvalidArgument,
+			"the ticket id must not contain more than %v characters", maxTicketIDSize)
+	}
+	if ticketID != "" && pctx.ConnectionTicketIDPattern != "" {
+		// the pattern must match the whole ticket id
+		re, err := regexp.Compile(fmt.Sprintf(`^(?:%s)$`, pctx.ConnectionTicketIDPattern))
+		if err != nil {
+			log.With("sid", pctx.SID, "connection", pctx.ConnectionName).
+				Warnf("failed compiling ticket id pattern, err=%v", err)
+			return status.Errorf(codes.Internal, "internal error, the ticket id pattern of the connection is invalid")
+		}
+		if !re.MatchString(ticketID) {
+			return status.Errorf(codes.InvalidArgument,
+				"the ticket id %q doesn't match the pattern %v", ticketID, pctx.ConnectionTicketIDPattern)
+		}
+	}
+	return nil
+}
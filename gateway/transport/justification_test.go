@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"strings"
+	"testing"
+
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidateSessionJustification(t *testing.T) {
+	for _, tt := range []struct {
+		msg           string
+		pctx          plugintypes.Context
+		justification string
+		ticketID      string
+		wantCode      codes.Code
+	}{
+		{
+			msg:  "it must allow empty attributes when the connection doesn't require them",
+			pctx: plugintypes.Context{ConnectionName: "pg"},
+		},
+		{
+			msg:           "it must allow attributes matching the ticket pattern",
+			pctx:          plugintypes.Context{ConnectionName: "pg", ConnectionRequireJustification: true, ConnectionTicketIDPattern: `JIRA-\d+`},
+			justification: "fix the payments of the incident",
+			ticketID:      "JIRA-123",
+		},
+		{
+			msg:           "it must reject sessions without a ticket id when the connection requires it",
+			pctx:          plugintypes.Context{ConnectionName: "pg", ConnectionRequireJustification: true},
+			justification: "fix the payments of the incident",
+			wantCode:      codes.InvalidArgument,
+		},
+		{
+			msg:      "it must reject sessions without a justification when the connection requires it",
+			pctx:     plugintypes.Context{ConnectionName: "pg", ConnectionRequireJustification: true},
+			ticketID: "JIRA-123",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			msg:      "it must match the whole ticket id with the pattern",
+			pctx:     plugintypes.Context{ConnectionName: "pg", ConnectionTicketIDPattern: `JIRA-\d+`},
+			ticketID: "JIRA-123; other",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			msg:           "it must reject justifications greater than the max size",
+			pctx:          plugintypes.Context{ConnectionName: "pg"},
+			justification: strings.Repeat("a", maxJustificationSize+1),
+			wantCode:      codes.InvalidArgument,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := validateSessionJustification(tt.pctx, tt.justification, tt.ticketID)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("expected code=%v, got=%v, err=%v", tt.wantCode, got, err)
+			}
+		})
+	}
+}
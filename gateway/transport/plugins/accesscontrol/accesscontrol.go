@@ -1,25 +1,233 @@
 package accesscontrol
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hoophq/hoop/common/log"
+	"github.com/hoophq/hoop/common/mongotypes"
+	"github.com/hoophq/hoop/common/mssqltypes"
+	"github.com/hoophq/hoop/common/mysqltypes"
+	"github.com/hoophq/hoop/common/pgtypes"
 	pb "github.com/hoophq/hoop/common/proto"
+	pbagent "github.com/hoophq/hoop/common/proto/agent"
+	"github.com/hoophq/hoop/common/redistypes"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 )
 
-const Name string = "access_control"
+const (
+	Name string = "access_control"
+
+	// PluginConfigEnvVarsParam is the key of the plugin context params
+	// containing the top level configuration of the plugin
+	PluginConfigEnvVarsParam = "access_control_config"
+	// DenyStatementsConfigKey is the plugin configuration key containing
+	// rules (one per line) that applies to all connections of the organization
+	DenyStatementsConfigKey = "DENY_STATEMENTS"
+	// DenyRulePrefix identified rules in the plugin connection configuration (deprecated),
+	// the rules of a connection are stored in its deny_rules attribute
+	DenyRulePrefix = "deny:"
+)
 
 type plugin struct {
 	name string
+	// compiled rules by its expression
+	rules sync.Map
 }
 
 func New() *plugin { return &plugin{name: Name} }
 
 func (r *plugin) Name() string                          { return r.name }
 func (r *plugin) OnStartup(_ plugintypes.Context) error { return nil }
-func (p *plugin) OnUpdate(_, _ *types.Plugin) error     { return nil }
+func (r *plugin) OnUpdate(_, newState *types.Plugin) error {
+	if newState == nil {
+		return nil
+	}
+	if _, err := ParseConfigRules(newState.Config); err != nil {
+		return err
+	}
+	for _, conn := range newState.Connections {
+		if err := ValidateGroups(conn.Config); err != nil {
+			return fmt.Errorf("connection %v: %v", conn.Name, err)
+		}
+	}
+	return nil
+}
 func (r *plugin) OnConnect(_ plugintypes.Context) error { return nil }
-func (r *plugin) OnReceive(_ plugintypes.Context, _ *pb.Packet) (*plugintypes.ConnectResponse, error) {
+func (r *plugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plugintypes.ConnectResponse, error) {
+	exprList := denyRules(pctx)
+	if len(exprList) == 0 {
+		return nil, nil
+	}
+
+	statements, err := decodeStatements(pctx, pkt)
+	if err != nil {
+		log.With("sid", pctx.SID, "connection", pctx.ConnectionName, "user", pctx.UserEmail).
+			Infof("packet rejected by access control, reason=%v", err)
+		return nil, plugintypes.InvalidArgument("%v, connection=%v", err, pctx.ConnectionName)
+	}
+	for _, statement := range statements {
+		for _, expr := range exprList {
+			re, err := r.compile(expr)
+			if err != nil {
+				log.With("sid", pctx.SID).Warnf("failed compiling access control rule %q, err=%v", expr, err)
+				continue
+			}
+			if re.Match(statement) {
+				log.With("sid", pctx.SID, "connection", pctx.ConnectionName, "user", pctx.UserEmail).
+					Infof("statement rejected by access control rule %q", expr)
+				return nil, plugintypes.InvalidArgument(
+					"statement rejected by access control policy, rule=%q, connection=%v", expr, pctx.ConnectionName)
+			}
+		}
+	}
 	return nil, nil
 }
 func (r *plugin) OnDisconnect(_ plugintypes.Context, _ error) error { return nil }
 func (r *plugin) OnShutdown()                                       {}
+
+// HasDenyRules reports if there are rules of the connection or of the organization
+// to be evaluated, they're enforced even if the connection isn't attached to the plugin.
+func HasDenyRules(pctx plugintypes.Context) bool { return len(denyRules(pctx)) > 0 }
+
+// denyRules returns the rules of the connection along with the organization wide rules
+func denyRules(pctx plugintypes.Context) []string {
+	exprList := append([]string{}, pctx.ConnectionDenyRules...)
+	if envVars, ok := pctx.ParamsData[PluginConfigEnvVarsParam].(map[string]string); ok {
+		exprList = append(exprList, parseDenyStatements(envVars)...)
+	}
+	return exprList
+}
+
+func (r *plugin) compile(expr string) (*regexp.Regexp, error) {
+	if obj, ok := r.rules.Load(expr); ok {
+		return obj.(*regexp.Regexp), nil
+	}
+	re, err := compileRule(expr)
+	if err != nil {
+		return nil, err
+	}
+	r.rules.Store(expr, re)
+	return re, nil
+}
+
+// ParseRules compiles the deny rules of a connection
+func ParseRules(exprList []string) ([]*regexp.Regexp, error) {
+	var rules []*regexp.Regexp
+	for _, expr := range exprList {
+		re, err := compileRule(expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, re)
+	}
+	return rules, nil
+}
+
+// ValidateGroups validates the groups of a plugin connection configuration,
+// rules are refused to not be mistaken by groups
+func ValidateGroups(config []string) error {
+	for _, conf := range config {
+		if strings.HasPrefix(conf, DenyRulePrefix) {
+			return fmt.Errorf("%q is not a valid group, deny rules are configured in the deny_rules attribute of the connection", conf)
+		}
+	}
+	return nil
+}
+
+// ParseConfigRules compiles the organization wide rules from the plugin configuration
+func ParseConfigRules(pconf *types.PluginConfig) ([]*regexp.Regexp, error) {
+	if pconf == nil {
+		return nil, nil
+	}
+	var rules []*regexp.Regexp
+	for _, expr := range parseDenyStatements(pconf.EnvVars) {
+		re, err := compileRule(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", DenyStatementsConfigKey, err)
+		}
+		rules = append(rules, re)
+	}
+	return rules, nil
+}
+
+// compileRule compiles a case insensitive expression
+func compileRule(expr string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("access control rule is empty")
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access control rule %q: %v", expr, err)
+	}
+	return re, nil
+}
+
+func parseDenyStatements(envVars map[string]string) (exprList []string) {
+	data, _ := base64.StdEncoding.DecodeString(envVars[DenyStatementsConfigKey])
+	for _, expr := range strings.Split(string(data), "\n") {
+		if expr = strings.TrimSpace(expr); expr != "" {
+			exprList = append(exprList, expr)
+		}
+	}
+	return
+}
+
+// decodeStatements returns the statements sent by the client to the target database,
+// including the statements prepared by the extended protocol of Postgres and by MySQL.
+// It returns an error when the packet could contain a statement that isn't possible to inspect,
+// e.g.: MSSQL remote procedure calls (sp_executesql, sp_prepare).
+func decodeStatements(pctx plugintypes.Context, pkt *pb.Packet) ([][]byte, error) {
+	switch pb.PacketType(pkt.Type) {
+	case pbagent.PGConnectionWrite:
+		// the packet is refused when it isn't possible to decode all of its messages,
+		// the remaining of the packet could contain a statement that isn't inspected
+		queries, err := pgtypes.QueriesContent(pkt.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode postgres packet: %v", err)
+		}
+		return queries, nil
+	case pbagent.MySQLConnectionWrite:
+		if query := mysqltypes.DecodeCommandQuery(pkt.Payload); query != nil {
+			return [][]byte{query}, nil
+		}
+		if stmt := mysqltypes.DecodeStmtPrepare(pkt.Payload); stmt != nil {
+			return [][]byte{stmt}, nil
+		}
+	case pbagent.MSSQLConnectionWrite:
+		if len(pkt.Payload) == 0 {
+			return nil, nil
+		}
+		switch mssqltypes.PacketType(pkt.Payload[0]) {
+		case mssqltypes.PacketSQLBatchType:
+			if query, err := mssqltypes.DecodeSQLBatchToRawQuery(pkt.Payload); err == nil {
+				return [][]byte{[]byte(query)}, nil
+			}
+		case mssqltypes.PacketRPCRequestType:
+			return nil, fmt.Errorf("remote procedure calls (prepared statements) are not supported by access control rules")
+		}
+	case pbagent.MongoDBConnectionWrite:
+		mpkt, err := mongotypes.Decode(bytes.NewReader(pkt.Payload))
+		if err != nil || mpkt.OpCode != mongotypes.OpMsgType {
+			return nil, nil
+		}
+		data, _ := mongotypes.DecodeOpMsgToJSON(mpkt)
+		return [][]byte{data}, nil
+	case pbagent.RedisConnectionWrite:
+		if rpkt, err := redistypes.DecodeCommand(pkt.Payload); err == nil {
+			return [][]byte{[]byte(rpkt.String())}, nil
+		}
+	case pbagent.ExecWriteStdin:
+		switch pb.ToConnectionType(pctx.ConnectionType, pctx.ConnectionSubType) {
+		case pb.ConnectionTypePostgres, pb.ConnectionTypeMySQL, pb.ConnectionTypeMSSQL,
+			pb.ConnectionTypeMongoDB, pb.ConnectionTypeRedis:
+			return [][]byte{pkt.Payload}, nil
+		}
+	}
+	return nil, nil
+}
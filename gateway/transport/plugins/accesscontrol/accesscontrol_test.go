@@ -0,0 +1,209 @@
+package accesscontrol
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	pb "github.com/hoophq/hoop/common/proto"
+	pbagent "github.com/hoophq/hoop/common/proto/agent"
+	"github.com/hoophq/hoop/common/redistypes"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+)
+
+func newPgSimpleQuery(query string) []byte {
+	frame := append([]byte(query), 0x00)
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(frame)+4))
+	return append(append([]byte{'Q'}, header...), frame...)
+}
+
+// newPgExtendedQuery encodes the parse, bind and sync messages of the extended query protocol
+func newPgExtendedQuery(query string) []byte {
+	encode := func(typ byte, frame []byte) []byte {
+		header := make([]byte, 4)
+		binary.BigEndian.PutUint32(header, uint32(len(frame)+4))
+		return append(append([]byte{typ}, header...), frame...)
+	}
+	// unnamed statement, query and zero parameter types
+	parse := append(append([]byte{0x00}, append([]byte(query), 0x00)...), 0x00, 0x00)
+	// unnamed portal and statement, zero formats, parameters and result formats
+	bind := []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	pkt := encode('P', parse)
+	pkt = append(pkt, encode('B', bind)...)
+	return append(pkt, encode('S', nil)...)
+}
+
+func newMySQLStmtPrepare(query string) []byte {
+	return append([]byte{0x00, 0x00, 0x00, 0x00, 0x16}, []byte(query)...)
+}
+
+func newMySQLQuery(query string) []byte {
+	// header (3 bytes length + sequence) is not validated by the decoder
+	return append([]byte{0x00, 0x00, 0x00, 0x00, 0x03}, []byte(query)...)
+}
+
+func TestOnReceive(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		rules   []string
+		config  []string
+		envVars map[string]string
+		pkt     *pb.Packet
+		conn    pb.ConnectionType
+		wantErr bool
+	}{
+		{
+			msg:     "it should reject postgres statements matching a rule",
+			rules:   []string{"drop\\s+table"},
+			config:  []string{"admin"},
+			pkt:     &pb.Packet{Type: pbagent.PGConnectionWrite, Payload: newPgSimpleQuery("DROP TABLE customers")},
+			wantErr: true,
+		},
+		{
+			msg:   "it should allow postgres statements not matching any rule",
+			rules: []string{"drop\\s+table"},
+			pkt:   &pb.Packet{Type: pbagent.PGConnectionWrite, Payload: newPgSimpleQuery("SELECT 1")},
+		},
+		{
+			msg:     "it should reject postgres prepared statements matching a rule",
+			rules:   []string{"drop\\s+table"},
+			pkt:     &pb.Packet{Type: pbagent.PGConnectionWrite, Payload: newPgExtendedQuery("DROP TABLE customers")},
+			wantErr: true,
+		},
+		{
+			msg:   "it should allow postgres prepared statements not matching any rule",
+			rules: []string{"drop\\s+table"},
+			pkt:   &pb.Packet{Type: pbagent.PGConnectionWrite, Payload: newPgExtendedQuery("SELECT $1")},
+		},
+		{
+			msg:   "it should reject postgres packets that are not possible to decode entirely",
+			rules: []string{"drop\\s+table"},
+			pkt: &pb.Packet{Type: pbagent.PGConnectionWrite,
+				Payload: append(newPgSimpleQuery("SELECT 1"), newPgSimpleQuery("DROP TABLE customers")[:8]...)},
+			wantErr: true,
+		},
+		{
+			msg:     "it should reject mysql statements matching a rule",
+			rules:   []string{"^truncate"},
+			pkt:     &pb.Packet{Type: pbagent.MySQLConnectionWrite, Payload: newMySQLQuery("truncate customers")},
+			wantErr: true,
+		},
+		{
+			msg:     "it should reject mysql prepared statements matching a rule",
+			rules:   []string{"^truncate"},
+			pkt:     &pb.Packet{Type: pbagent.MySQLConnectionWrite, Payload: newMySQLStmtPrepare("truncate customers")},
+			wantErr: true,
+		},
+		{
+			msg:     "it should reject mssql remote procedure calls when there are rules",
+			rules:   []string{"drop\\s+table"},
+			pkt:     &pb.Packet{Type: pbagent.MSSQLConnectionWrite, Payload: []byte{0x03, 0x01, 0x00, 0x08, 0x00, 0x00, 0x01, 0x00}},
+			wantErr: true,
+		},
+		{
+			msg:     "it should reject redis commands matching organization rules",
+			envVars: map[string]string{DenyStatementsConfigKey: base64.StdEncoding.EncodeToString([]byte("^FLUSHALL\n^FLUSHDB"))},
+			pkt:     &pb.Packet{Type: pbagent.RedisConnectionWrite, Payload: redistypes.NewCommand("flushdb")},
+			wantErr: true,
+		},
+		{
+			msg:     "it should reject exec inputs of database connections",
+			rules:   []string{"dropDatabase"},
+			conn:    pb.ConnectionTypeMongoDB,
+			pkt:     &pb.Packet{Type: pbagent.ExecWriteStdin, Payload: []byte(`db.dropDatabase()`)},
+			wantErr: true,
+		},
+		{
+			msg:   "it should ignore exec inputs of non database connections",
+			rules: []string{"rm -rf"},
+			conn:  pb.ConnectionTypeCommandLine,
+			pkt:   &pb.Packet{Type: pbagent.ExecWriteStdin, Payload: []byte(`rm -rf /`)},
+		},
+		{
+			msg:    "it should noop when there are only groups configured",
+			config: []string{"admin", "sre"},
+			pkt:    &pb.Packet{Type: pbagent.PGConnectionWrite, Payload: newPgSimpleQuery("DROP TABLE customers")},
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			connType, subType := "database", tt.conn.String()
+			if tt.conn == pb.ConnectionTypeCommandLine {
+				connType, subType = "application", ""
+			}
+			pctx := plugintypes.Context{
+				ConnectionName:         "pgdemo",
+				ConnectionType:         connType,
+				ConnectionSubType:      subType,
+				PluginConnectionConfig: tt.config,
+				ConnectionDenyRules:    tt.rules,
+				ParamsData:             map[string]any{},
+			}
+			if tt.envVars != nil {
+				pctx.ParamsData[PluginConfigEnvVarsParam] = tt.envVars
+			}
+			_, err := New().OnReceive(pctx, tt.pkt)
+			if tt.wantErr {
+				if _, ok := err.(*plugintypes.InvalidArgErr); !ok {
+					t.Fatalf("expected invalid argument error, got=%T (%v)", err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error, got=%v", err)
+			}
+		})
+	}
+}
+
+func TestOnUpdate(t *testing.T) {
+	err := New().OnUpdate(nil, &types.Plugin{
+		Config:      &types.PluginConfig{EnvVars: map[string]string{DenyStatementsConfigKey: base64.StdEncoding.EncodeToString([]byte("drop ("))}},
+		Connections: []*types.PluginConnection{{Name: "pgdemo", Config: []string{"admin"}}},
+	})
+	if err == nil {
+		t.Fatal("expected error with invalid rule")
+	}
+	err = New().OnUpdate(nil, &types.Plugin{
+		Connections: []*types.PluginConnection{{Name: "pgdemo", Config: []string{"admin", "deny:^truncate"}}},
+	})
+	if err == nil {
+		t.Fatal("expected error with rules in the configuration of connections")
+	}
+	err = New().OnUpdate(nil, &types.Plugin{
+		Config:      &types.PluginConfig{EnvVars: map[string]string{DenyStatementsConfigKey: base64.StdEncoding.EncodeToString([]byte("drop table"))}},
+		Connections: []*types.PluginConnection{{Name: "pgdemo", Config: []string{"admin", "sre"}}},
+	})
+	if err != nil {
+		t.Fatalf("did not expect error, got=%v", err)
+	}
+}
+
+func TestHasDenyRules(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		rules   []string
+		envVars map[string]string
+		want    bool
+	}{
+		{msg: "it must be false when there are no rules"},
+		{msg: "it must be true when the connection has rules", rules: []string{"drop\\s+table"}, want: true},
+		{
+			msg:     "it must be true when the organization has rules",
+			envVars: map[string]string{DenyStatementsConfigKey: base64.StdEncoding.EncodeToString([]byte("^FLUSHALL"))},
+			want:    true,
+		},
+		{msg: "it must be false when the organization rules are empty", envVars: map[string]string{DenyStatementsConfigKey: ""}},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			pctx := plugintypes.Context{ConnectionDenyRules: tt.rules, ParamsData: map[string]any{}}
+			if tt.envVars != nil {
+				pctx.ParamsData[PluginConfigEnvVarsParam] = tt.envVars
+			}
+			if got := HasDenyRules(pctx); got != tt.want {
+				t.Errorf("expected=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package accessrules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgaccessrules "github.com/hoophq/hoop/gateway/pgrest/accessrules"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+type plugin struct {
+	fetchRules func(ctx pgrest.OrgContext) ([]pgrest.AccessRule, error)
+}
+
+func New() *plugin { return &plugin{fetchRules: pgaccessrules.New().FetchAll} }
+
+func (p *plugin) Name() string                                      { return plugintypes.PluginAccessRulesName }
+func (p *plugin) OnStartup(_ plugintypes.Context) error             { return nil }
+func (p *plugin) OnUpdate(_, _ *types.Plugin) error                 { return nil }
+func (p *plugin) OnDisconnect(_ plugintypes.Context, _ error) error { return nil }
+func (p *plugin) OnShutdown()                                       {}
+func (p *plugin) OnReceive(_ plugintypes.Context, _ *pb.Packet) (*plugintypes.ConnectResponse, error) {
+	return nil, nil
+}
+
+// OnConnect evaluates the access rules of the organization against the attributes of the context
+func (p *plugin) OnConnect(pctx plugintypes.Context) error {
+	rules, err := p.fetchRules(pctx)
+	if err != nil {
+		log.With("sid", pctx.SID).Errorf("failed fetching access rules, err=%v", err)
+		return fmt.Errorf("internal error, failed fetching access rules")
+	}
+	return Evaluate(pctx, rules, time.Now().UTC())
+}
+
+// Evaluate returns an error when the access to the connection is not granted by the rules.
+// A matching deny rule refuses the access; when there are allow rules applied to the
+// connection, at least one of them must match. Rules failing to evaluate refuse the access.
+func Evaluate(pctx plugintypes.Context, rules []pgrest.AccessRule, now time.Time) error {
+	var allowRules []string
+	allowed := false
+	for _, rule := range rules {
+		if len(rule.Connections) > 0 && !slices.Contains(rule.Connections, pctx.ConnectionName) {
+			continue
+		}
+		match, err := evalRule(pctx, rule, now)
+		if err != nil {
+			log.With("sid", pctx.SID, "connection", pctx.ConnectionName).
+				Warnf("failed evaluating access rule %v, err=%v", rule.Name, err)
+			return fmt.Errorf("access denied, failed evaluating access rule %v", rule.Name)
+		}
+		switch rule.Effect {
+		case EffectDeny:
+			if match {
+				return fmt.Errorf("access denied by the access rule %v", rule.Name)
+			}
+		case EffectAllow:
+			allowRules = append(allowRules, rule.Name)
+			allowed = allowed || match
+		}
+	}
+	if len(allowRules) > 0 && !allowed {
+		return fmt.Errorf("access denied, the access rules %v do not allow access to the connection %v",
+			strings.Join(allowRules, ", "), pctx.ConnectionName)
+	}
+	return nil
+}
+
+func evalRule(pctx plugintypes.Context, rule pgrest.AccessRule, now time.Time) (bool, error) {
+	expr, err := Compile(rule.Expression)
+	if err != nil {
+		return false, err
+	}
+	if rule.Timezone != "" {
+		loc, err := time.LoadLocation(rule.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("invalid timezone: %v", err)
+		}
+		now = now.In(loc)
+	}
+	return expr.Eval(NewAttributes(pctx, now))
+}
+
+// NewAttributes returns the attributes available to the expressions
+func NewAttributes(pctx plugintypes.Context, now time.Time) map[string]any {
+	var emailDomain string
+	if _, domain, found := strings.Cut(pctx.UserEmail, "@"); found {
+		emailDomain = domain
+	}
+	return map[string]any{
+		"user.id":            pctx.UserID,
+		"user.email":         pctx.UserEmail,
+		"user.email_domain":  emailDomain,
+		"user.groups":        nonNilSlice(pctx.UserGroups),
+		"connection.name":    pctx.ConnectionName,
+		"connection.type":    pctx.ConnectionType,
+		"connection.subtype": pctx.ConnectionSubType,
+		"connection.tags":    nonNilSlice(pctx.ConnectionTags),
+		"agent.name":         pctx.AgentName,
+		"client.verb":        pctx.ClientVerb,
+		"client.origin":      pctx.ClientOrigin,
+		"client.source_ip":   pctx.ClientSourceIP,
+		"now.weekday":        int64(now.Weekday()),
+		"now.hour":           int64(now.Hour()),
+		"now.minute":         int64(now.Minute()),
+		"now.time":           now.Format("15:04"),
+	}
+}
+
+func nonNilSlice(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
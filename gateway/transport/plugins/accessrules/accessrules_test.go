@@ -0,0 +1,118 @@
+package accessrules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/pgrest"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	contractorsRule := pgrest.AccessRule{
+		Name:       "contractors-staging-only",
+		Effect:     EffectDeny,
+		Expression: `user.groups.contains("contractors") && !(client.verb == "exec" && connection.tags.contains("staging") && now.weekday >= 1 && now.weekday <= 5)`,
+	}
+	// 2024-07-24 is a wednesday
+	wednesday := time.Date(2024, 7, 24, 10, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 7, 27, 10, 0, 0, 0, time.UTC)
+	newContext := func(groups, tags []string, verb string) plugintypes.Context {
+		return plugintypes.Context{
+			UserEmail:      "john@domain.tld",
+			UserGroups:     groups,
+			ConnectionName: "pg",
+			ConnectionTags: tags,
+			ClientVerb:     verb,
+		}
+	}
+	for _, tt := range []struct {
+		msg     string
+		pctx    plugintypes.Context
+		rules   []pgrest.AccessRule
+		now     time.Time
+		wantErr bool
+	}{
+		{
+			msg:  "it must allow access without rules",
+			pctx: newContext([]string{"contractors"}, nil, "connect"),
+			now:  wednesday,
+		},
+		{
+			msg:   "it must allow contractors to exec on staging connections during weekdays",
+			pctx:  newContext([]string{"contractors"}, []string{"staging"}, "exec"),
+			rules: []pgrest.AccessRule{contractorsRule},
+			now:   wednesday,
+		},
+		{
+			msg:     "it must deny contractors to exec on staging connections during weekends",
+			pctx:    newContext([]string{"contractors"}, []string{"staging"}, "exec"),
+			rules:   []pgrest.AccessRule{contractorsRule},
+			now:     saturday,
+			wantErr: true,
+		},
+		{
+			msg:     "it must deny contractors to connect on staging connections",
+			pctx:    newContext([]string{"contractors"}, []string{"staging"}, "connect"),
+			rules:   []pgrest.AccessRule{contractorsRule},
+			now:     wednesday,
+			wantErr: true,
+		},
+		{
+			msg:   "it must allow users that are not contractors",
+			pctx:  newContext([]string{"sre"}, []string{"production"}, "connect"),
+			rules: []pgrest.AccessRule{contractorsRule},
+			now:   saturday,
+		},
+		{
+			msg:  "it must ignore rules of other connections",
+			pctx: newContext([]string{"contractors"}, nil, "connect"),
+			rules: []pgrest.AccessRule{{
+				Name: "deny-all", Effect: EffectDeny, Expression: "true", Connections: []string{"mysql"}}},
+			now: wednesday,
+		},
+		{
+			msg:  "it must allow access when any allow rule matches",
+			pctx: newContext([]string{"sre"}, nil, "connect"),
+			rules: []pgrest.AccessRule{
+				{Name: "domain-only", Effect: EffectAllow, Expression: `user.email_domain == "other.tld"`},
+				{Name: "sre-only", Effect: EffectAllow, Expression: `user.groups.contains("sre")`},
+			},
+			now: wednesday,
+		},
+		{
+			msg:  "it must deny access when no allow rule matches",
+			pctx: newContext([]string{"sre"}, nil, "connect"),
+			rules: []pgrest.AccessRule{
+				{Name: "domain-only", Effect: EffectAllow, Expression: `user.email_domain == "other.tld"`},
+			},
+			now:     wednesday,
+			wantErr: true,
+		},
+		{
+			msg:  "it must evaluate the time attributes in the timezone of the rule",
+			pctx: newContext(nil, nil, "connect"),
+			rules: []pgrest.AccessRule{
+				// 10:00 UTC is 07:00 in Sao Paulo
+				{Name: "business-hours", Effect: EffectAllow, Expression: `now.hour >= 9`, Timezone: "America/Sao_Paulo"},
+			},
+			now:     wednesday,
+			wantErr: true,
+		},
+		{
+			msg:  "it must deny access when a rule fails to evaluate",
+			pctx: newContext(nil, nil, "connect"),
+			rules: []pgrest.AccessRule{
+				{Name: "invalid", Effect: EffectDeny, Expression: `now.hour == "10"`},
+			},
+			now:     wednesday,
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := Evaluate(tt.pctx, tt.rules, tt.now)
+			assert.Equal(t, tt.wantErr, err != nil, "err=%v", err)
+		})
+	}
+}
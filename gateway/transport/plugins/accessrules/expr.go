@@ -0,0 +1,347 @@
+package accessrules
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const maxExpressionSize = 4096
+
+// Attributes available to the expressions of access rules
+var attributes = map[string]struct{}{
+	"user.id":            {},
+	"user.email":         {},
+	"user.email_domain":  {},
+	"user.groups":        {},
+	"connection.name":    {},
+	"connection.type":    {},
+	"connection.subtype": {},
+	"connection.tags":    {},
+	"agent.name":         {},
+	"client.verb":        {},
+	"client.origin":      {},
+	"client.source_ip":   {},
+	"now.weekday":        {},
+	"now.hour":           {},
+	"now.minute":         {},
+	"now.time":           {},
+}
+
+// methods available to the expressions and the number of arguments of each one
+var methods = map[string]int{
+	"contains":   1,
+	"startsWith": 1,
+	"endsWith":   1,
+	"matches":    1,
+	"inCIDR":     1,
+}
+
+// Expression is a compiled boolean expression of an access rule.
+// The syntax is a subset of the Common Expression Language (CEL):
+//
+//   - literals: strings ("value" or `value`), integers, true and false
+//   - operators: &&, ||, !, ==, !=, <, <=, >, >= and parentheses
+//   - attributes: user.email, user.groups, connection.tags, client.verb, now.weekday, etc
+//   - methods: contains, startsWith, endsWith, matches (regular expression) and inCIDR
+//
+// Example: user.groups.contains("contractors") && !connection.tags.contains("staging")
+type Expression struct {
+	source string
+	node   ast.Expr
+}
+
+// Compile parses and validates the syntax of an expression
+func Compile(source string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > maxExpressionSize {
+		return nil, fmt.Errorf("expression must not contain more than %v characters", maxExpressionSize)
+	}
+	node, err := parser.ParseExpr(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing expression: %v", err)
+	}
+	if err := validate(node); err != nil {
+		return nil, err
+	}
+	return &Expression{source: source, node: node}, nil
+}
+
+func (e *Expression) String() string { return e.source }
+
+// Eval evaluates the expression against the attributes, it returns an error
+// if the expression doesn't evaluate to a boolean value
+func (e *Expression) Eval(attrs map[string]any) (bool, error) {
+	v, err := eval(e.node, attrs)
+	if err != nil {
+		return false, err
+	}
+	result, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to a boolean, got %T", v)
+	}
+	return result, nil
+}
+
+func validate(node ast.Expr) error {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.STRING && n.Kind != token.INT {
+			return fmt.Errorf("unsupported literal %v", n.Value)
+		}
+	case *ast.Ident:
+		if n.Name != "true" && n.Name != "false" {
+			return fmt.Errorf("unknown attribute %q", n.Name)
+		}
+	case *ast.SelectorExpr:
+		name, ok := attributeName(n)
+		if !ok {
+			return fmt.Errorf("unknown attribute %q", exprString(n))
+		}
+		if _, ok := attributes[name]; !ok {
+			return fmt.Errorf("unknown attribute %q", name)
+		}
+	case *ast.ParenExpr:
+		return validate(n.X)
+	case *ast.UnaryExpr:
+		if n.Op != token.NOT && n.Op != token.SUB {
+			return fmt.Errorf("unsupported operator %v", n.Op)
+		}
+		return validate(n.X)
+	case *ast.BinaryExpr:
+		switch n.Op {
+		case token.LAND, token.LOR, token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		default:
+			return fmt.Errorf("unsupported operator %v", n.Op)
+		}
+		if err := validate(n.X); err != nil {
+			return err
+		}
+		return validate(n.Y)
+	case *ast.CallExpr:
+		fn, ok := n.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return fmt.Errorf("unsupported function %v", exprString(n.Fun))
+		}
+		nargs, ok := methods[fn.Sel.Name]
+		if !ok {
+			return fmt.Errorf("unknown method %q", fn.Sel.Name)
+		}
+		if len(n.Args) != nargs || n.Ellipsis.IsValid() {
+			return fmt.Errorf("method %v expects %v argument(s)", fn.Sel.Name, nargs)
+		}
+		if fn.Sel.Name == "matches" {
+			if lit, ok := n.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				pattern, _ := strconv.Unquote(lit.Value)
+				if _, err := regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("invalid regular expression %v: %v", lit.Value, err)
+				}
+			}
+		}
+		if err := validate(fn.X); err != nil {
+			return err
+		}
+		for _, arg := range n.Args {
+			if err := validate(arg); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported expression %v", exprString(node))
+	}
+	return nil
+}
+
+func eval(node ast.Expr, attrs map[string]any) (any, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind == token.INT {
+			return strconv.ParseInt(n.Value, 0, 64)
+		}
+		return strconv.Unquote(n.Value)
+	case *ast.Ident:
+		return n.Name == "true", nil
+	case *ast.SelectorExpr:
+		name, _ := attributeName(n)
+		v, ok := attrs[name]
+		if !ok {
+			return nil, fmt.Errorf("attribute %q is not available", name)
+		}
+		return v, nil
+	case *ast.ParenExpr:
+		return eval(n.X, attrs)
+	case *ast.UnaryExpr:
+		v, err := eval(n.X, attrs)
+		if err != nil {
+			return nil, err
+		}
+		switch val := v.(type) {
+		case bool:
+			if n.Op == token.NOT {
+				return !val, nil
+			}
+		case int64:
+			if n.Op == token.SUB {
+				return -val, nil
+			}
+		}
+		return nil, fmt.Errorf("operator %v is not supported for %T", n.Op, v)
+	case *ast.BinaryExpr:
+		return evalBinary(n, attrs)
+	case *ast.CallExpr:
+		return evalMethod(n, attrs)
+	}
+	return nil, fmt.Errorf("unsupported expression %v", exprString(node))
+}
+
+func evalBinary(n *ast.BinaryExpr, attrs map[string]any) (any, error) {
+	x, err := eval(n.X, attrs)
+	if err != nil {
+		return nil, err
+	}
+	// short circuit logical operators
+	if n.Op == token.LAND || n.Op == token.LOR {
+		left, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator %v expects boolean operands, got %T", n.Op, x)
+		}
+		if (n.Op == token.LAND && !left) || (n.Op == token.LOR && left) {
+			return left, nil
+		}
+		y, err := eval(n.Y, attrs)
+		if err != nil {
+			return nil, err
+		}
+		right, ok := y.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator %v expects boolean operands, got %T", n.Op, y)
+		}
+		return right, nil
+	}
+	y, err := eval(n.Y, attrs)
+	if err != nil {
+		return nil, err
+	}
+	var result int
+	switch left := x.(type) {
+	case string:
+		right, ok := y.(string)
+		if !ok {
+			return nil, fmt.Errorf("mismatch types %T %v %T", x, n.Op, y)
+		}
+		result = cmp.Compare(left, right)
+	case int64:
+		right, ok := y.(int64)
+		if !ok {
+			return nil, fmt.Errorf("mismatch types %T %v %T", x, n.Op, y)
+		}
+		result = cmp.Compare(left, right)
+	case bool:
+		right, ok := y.(bool)
+		if !ok {
+			return nil, fmt.Errorf("mismatch types %T %v %T", x, n.Op, y)
+		}
+		if n.Op != token.EQL && n.Op != token.NEQ {
+			return nil, fmt.Errorf("operator %v is not supported for booleans", n.Op)
+		}
+		if left != right {
+			result = 1
+		}
+	default:
+		return nil, fmt.Errorf("operator %v is not supported for %T", n.Op, x)
+	}
+	switch n.Op {
+	case token.EQL:
+		return result == 0, nil
+	case token.NEQ:
+		return result != 0, nil
+	case token.LSS:
+		return result < 0, nil
+	case token.LEQ:
+		return result <= 0, nil
+	case token.GTR:
+		return result > 0, nil
+	case token.GEQ:
+		return result >= 0, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %v", n.Op)
+}
+
+func evalMethod(n *ast.CallExpr, attrs map[string]any) (any, error) {
+	fn := n.Fun.(*ast.SelectorExpr)
+	receiver, err := eval(fn.X, attrs)
+	if err != nil {
+		return nil, err
+	}
+	v, err := eval(n.Args[0], attrs)
+	if err != nil {
+		return nil, err
+	}
+	arg, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("method %v expects a string argument, got %T", fn.Sel.Name, v)
+	}
+	if list, ok := receiver.([]string); ok {
+		if fn.Sel.Name != "contains" {
+			return nil, fmt.Errorf("method %v is not supported for lists", fn.Sel.Name)
+		}
+		return slices.Contains(list, arg), nil
+	}
+	str, ok := receiver.(string)
+	if !ok {
+		return nil, fmt.Errorf("method %v is not supported for %T", fn.Sel.Name, receiver)
+	}
+	switch fn.Sel.Name {
+	case "contains":
+		return strings.Contains(str, arg), nil
+	case "startsWith":
+		return strings.HasPrefix(str, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(str, arg), nil
+	case "matches":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", arg, err)
+		}
+		return re.MatchString(str), nil
+	case "inCIDR":
+		_, ipNet, err := net.ParseCIDR(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %v", arg, err)
+		}
+		ip := net.ParseIP(str)
+		return ip != nil && ipNet.Contains(ip), nil
+	}
+	return nil, fmt.Errorf("unknown method %q", fn.Sel.Name)
+}
+
+// attributeName returns the dotted name of a selector expression, e.g.: user.email
+func attributeName(n *ast.SelectorExpr) (string, bool) {
+	switch x := n.X.(type) {
+	case *ast.Ident:
+		return x.Name + "." + n.Sel.Name, true
+	case *ast.SelectorExpr:
+		prefix, ok := attributeName(x)
+		return prefix + "." + n.Sel.Name, ok
+	}
+	return "", false
+}
+
+func exprString(node ast.Expr) string {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return n.Name
+	case *ast.SelectorExpr:
+		return exprString(n.X) + "." + n.Sel.Name
+	}
+	return fmt.Sprintf("%T", node)
+}
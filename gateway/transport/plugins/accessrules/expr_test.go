@@ -0,0 +1,75 @@
+package accessrules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		expr    string
+		wantErr bool
+	}{
+		{msg: "it must compile logical expressions", expr: `user.email_domain == "domain.tld" && (client.verb == "exec" || now.hour < 18)`},
+		{msg: "it must compile method calls", expr: `user.groups.contains("sre") && client.source_ip.inCIDR("10.0.0.0/8")`},
+		{msg: "it must compile negations and raw strings", expr: "!user.email.matches(`^.+@contractor\\.io$`)"},
+		{msg: "it must fail with empty expressions", expr: " ", wantErr: true},
+		{msg: "it must fail with syntax errors", expr: `user.email ==`, wantErr: true},
+		{msg: "it must fail with unknown attributes", expr: `user.password == "secret"`, wantErr: true},
+		{msg: "it must fail with unknown identifiers", expr: `admin`, wantErr: true},
+		{msg: "it must fail with unknown methods", expr: `user.email.lower() == "a"`, wantErr: true},
+		{msg: "it must fail with the wrong number of arguments", expr: `user.groups.contains("a", "b")`, wantErr: true},
+		{msg: "it must fail with unsupported operators", expr: `now.hour + 1 > 2`, wantErr: true},
+		{msg: "it must fail with unsupported expressions", expr: `user.groups[0] == "admin"`, wantErr: true},
+		{msg: "it must fail with invalid regular expressions", expr: `user.email.matches("(")`, wantErr: true},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			assert.Equal(t, tt.wantErr, err != nil, "err=%v", err)
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	attrs := map[string]any{
+		"user.email":        "john@contractor.io",
+		"user.email_domain": "contractor.io",
+		"user.groups":       []string{"contractors", "engineering"},
+		"connection.tags":   []string{"staging"},
+		"client.verb":       "exec",
+		"client.source_ip":  "10.10.1.20",
+		"now.weekday":       int64(6),
+		"now.time":          "10:30",
+	}
+	for _, tt := range []struct {
+		msg     string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{msg: "it must match list membership", expr: `user.groups.contains("contractors")`, want: true},
+		{msg: "it must match string methods", expr: `user.email.endsWith("@contractor.io") && user.email.startsWith("john")`, want: true},
+		{msg: "it must match regular expressions", expr: `user.email.matches("^[a-z]+@")`, want: true},
+		{msg: "it must match cidr ranges", expr: `client.source_ip.inCIDR("10.10.0.0/16")`, want: true},
+		{msg: "it must not match ip addresses outside of cidr ranges", expr: `client.source_ip.inCIDR("192.168.0.0/16")`},
+		{msg: "it must compare integers", expr: `now.weekday >= 1 && now.weekday <= 5`},
+		{msg: "it must compare strings", expr: `now.time >= "09:00" && now.time < "18:00"`, want: true},
+		{msg: "it must evaluate negations", expr: `!(client.verb == "connect")`, want: true},
+		{msg: "it must short circuit logical operators", expr: `client.verb == "exec" || user.id == "unknown"`, want: true},
+		{msg: "it must fail with missing attributes", expr: `user.id == "unknown"`, wantErr: true},
+		{msg: "it must fail with mismatch types", expr: `now.weekday == "6"`, wantErr: true},
+		{msg: "it must fail with non boolean results", expr: `client.verb`, wantErr: true},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			expr, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("failed compiling expression: %v", err)
+			}
+			got, err := expr.Eval(attrs)
+			assert.Equal(t, tt.wantErr, err != nil, "err=%v", err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
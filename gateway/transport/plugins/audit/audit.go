@@ -10,6 +10,7 @@ import (
 	"github.com/hoophq/hoop/common/log"
 	"github.com/hoophq/hoop/common/memory"
 	mssqltypes "github.com/hoophq/hoop/common/mssqltypes"
+	"github.com/hoophq/hoop/common/mysqltypes"
 	pgtypes "github.com/hoophq/hoop/common/pgtypes"
 	pb "github.com/hoophq/hoop/common/proto"
 	pbagent "github.com/hoophq/hoop/common/proto/agent"
@@ -17,6 +18,7 @@ import (
 	"github.com/hoophq/hoop/common/proto/spectypes"
 	pgsession "github.com/hoophq/hoop/gateway/pgrest/session"
 	eventlogv1 "github.com/hoophq/hoop/gateway/session/eventlog/v1"
+	"github.com/hoophq/hoop/gateway/session/livestream"
 	"github.com/hoophq/hoop/gateway/storagev2"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
@@ -112,7 +114,7 @@ func (p *auditPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plug
 		}
 		return nil, p.writeOnReceive(pctx.SID, eventlogv1.InputType, queryBytes, eventMetadata)
 	case pbagent.MySQLConnectionWrite:
-		if queryBytes := decodeMySQLCommandQuery(pkt.Payload); queryBytes != nil {
+		if queryBytes := mysqltypes.DecodeCommandQuery(pkt.Payload); queryBytes != nil {
 			return nil, p.writeOnReceive(pctx.SID, eventlogv1.InputType, queryBytes, eventMetadata)
 		}
 	case pbagent.MSSQLConnectionWrite:
@@ -138,6 +140,14 @@ func (p *auditPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plug
 		if decJSONPayload != nil {
 			return nil, p.writeOnReceive(pctx.SID, eventlogv1.InputType, decJSONPayload, eventMetadata)
 		}
+	case pbagent.RedisConnectionWrite:
+		cmd, err := decodeClientRedisCommand(pkt.Payload)
+		if err != nil {
+			return nil, err
+		}
+		if cmd != nil {
+			return nil, p.writeOnReceive(pctx.SID, eventlogv1.InputType, cmd, eventMetadata)
+		}
 	case pbclient.WriteStdout,
 		pbclient.WriteStderr:
 		err := p.writeOnReceive(pctx.SID, eventlogv1.OutputType, pkt.Payload, eventMetadata)
@@ -180,6 +190,7 @@ func (p *auditPlugin) OnDisconnect(pctx plugintypes.Context, errMsg error) error
 
 func (p *auditPlugin) closeSession(pctx plugintypes.Context, errMsg error) {
 	log.With("sid", pctx.SID).Infof("closing session, reason=%v", errMsg)
+	livestream.End(pctx.SID)
 	go func() {
 		if err := p.writeOnClose(pctx, errMsg); err != nil {
 			log.Warnf("session=%v - failed closing session: %v", pctx.SID, err)
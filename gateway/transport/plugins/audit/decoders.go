@@ -5,32 +5,9 @@ import (
 	"fmt"
 
 	"github.com/hoophq/hoop/common/mongotypes"
+	"github.com/hoophq/hoop/common/redistypes"
 )
 
-// decodeMySQLCommandQuery try to decode a packet to see if it's a COMM_QUERY type
-// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_query.html
-func decodeMySQLCommandQuery(payload []byte) []byte {
-	if len(payload) < 5 {
-		return nil
-	}
-	// type packet
-	pos := 4
-
-	if payload[pos] != 0x03 {
-		return nil
-	}
-
-	if payload[pos+1] == 0x00 {
-		// param count + param set count
-		pos += 2
-	}
-	if len(payload) < pos {
-		return nil
-	}
-	// TODO: must check when parameters is set
-	return payload[pos:]
-}
-
 func decodeClientMongoOpMsgPacket(payload []byte) ([]byte, error) {
 	pkt, err := mongotypes.Decode(bytes.NewReader(payload))
 	if err != nil {
@@ -44,3 +21,19 @@ func decodeClientMongoOpMsgPacket(payload []byte) ([]byte, error) {
 	}
 	return nil, nil
 }
+
+// decodeClientRedisCommand decodes a RESP command into a human readable format.
+// Credentials sent in authentication commands are redacted.
+func decodeClientRedisCommand(payload []byte) ([]byte, error) {
+	if len(payload) == 0 {
+		return nil, nil
+	}
+	pkt, err := redistypes.DecodeCommand(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding redis command: %v", err)
+	}
+	if cmd := pkt.String(); cmd != "" {
+		return []byte(cmd), nil
+	}
+	return nil, nil
+}
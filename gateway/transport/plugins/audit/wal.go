@@ -13,6 +13,7 @@ import (
 	"github.com/hoophq/hoop/common/proto/spectypes"
 	pgsession "github.com/hoophq/hoop/gateway/pgrest/session"
 	eventlogv1 "github.com/hoophq/hoop/gateway/session/eventlog/v1"
+	"github.com/hoophq/hoop/gateway/session/livestream"
 	sessionwal "github.com/hoophq/hoop/gateway/session/wal"
 	"github.com/hoophq/hoop/gateway/storagev2"
 	sessionstorage "github.com/hoophq/hoop/gateway/storagev2/session"
@@ -67,9 +68,12 @@ func (p *auditPlugin) writeOnReceive(sessionID string, eventType eventlogv1.Even
 	if !ok {
 		return fmt.Errorf("failed obtaining write ahead log for session %v", sessionID)
 	}
+	eventTime := time.Now().UTC()
+	// fan out to anyone watching the session in real time
+	livestream.Publish(sessionID, eventTime, byte(eventType), event)
 	walogm.mu.Lock()
 	defer walogm.mu.Unlock()
-	return walogm.log.Write(eventlogv1.New(time.Now().UTC(), eventType, event, metadata))
+	return walogm.log.Write(eventlogv1.New(eventTime, eventType, event, metadata))
 }
 
 func (p *auditPlugin) dropWalLog(sid string) {
@@ -0,0 +1,76 @@
+package dbcredentials
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	pb "github.com/hoophq/hoop/common/proto"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The grants applied to the temporary user when the plugin connection
+// doesn't have any configuration. The template accepts the attributes:
+// .User and .Database
+var defaultGrants = map[pb.ConnectionType][]string{
+	pb.ConnectionTypePostgres: {`GRANT SELECT ON ALL TABLES IN SCHEMA public TO "{{ .User }}"`},
+	pb.ConnectionTypeMySQL:    {"GRANT SELECT ON {{ if .Database }}`{{ .Database }}`{{ else }}*{{ end }}.* TO '{{ .User }}'@'%'"},
+}
+
+type plugin struct{}
+
+func New() *plugin                                      { return &plugin{} }
+func (p *plugin) Name() string                          { return plugintypes.PluginDatabaseCredentialsManagerName }
+func (p *plugin) OnStartup(_ plugintypes.Context) error { return nil }
+func (p *plugin) OnUpdate(_, newState *types.Plugin) error {
+	if newState == nil {
+		return nil
+	}
+	for _, conn := range newState.Connections {
+		if err := ValidateGrants(conn.Config); err != nil {
+			return fmt.Errorf("connection %v: %v", conn.Name, err)
+		}
+	}
+	return nil
+}
+func (p *plugin) OnConnect(ctx plugintypes.Context) error {
+	switch pb.ToConnectionType(ctx.ConnectionType, ctx.ConnectionSubType) {
+	case pb.ConnectionTypePostgres, pb.ConnectionTypeMySQL:
+		return nil
+	}
+	return status.Errorf(codes.FailedPrecondition,
+		"the %v plugin is only supported by postgres and mysql connections", plugintypes.PluginDatabaseCredentialsManagerName)
+}
+func (p *plugin) OnReceive(_ plugintypes.Context, _ *pb.Packet) (*plugintypes.ConnectResponse, error) {
+	return nil, nil
+}
+func (p *plugin) OnDisconnect(_ plugintypes.Context, _ error) error { return nil }
+func (p *plugin) OnShutdown()                                       {}
+
+// Grants returns the grant statements of a plugin connection configuration
+// or the default ones based on the type of the connection
+func Grants(connType pb.ConnectionType, config []string) []string {
+	var grants []string
+	for _, stmt := range config {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			grants = append(grants, stmt)
+		}
+	}
+	if len(grants) == 0 {
+		return defaultGrants[connType]
+	}
+	return grants
+}
+
+// ValidateGrants validates if the statements are valid templates
+func ValidateGrants(config []string) error {
+	for _, stmt := range config {
+		if _, err := template.New("").Option("missingkey=error").Parse(stmt); err != nil {
+			return fmt.Errorf("failed parsing grant template %q: %v", stmt, err)
+		}
+	}
+	return nil
+}
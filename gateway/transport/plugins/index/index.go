@@ -13,6 +13,7 @@ import (
 	pb "github.com/hoophq/hoop/common/proto"
 	pbagent "github.com/hoophq/hoop/common/proto/agent"
 	pbclient "github.com/hoophq/hoop/common/proto/client"
+	"github.com/hoophq/hoop/common/redistypes"
 	"github.com/hoophq/hoop/gateway/indexer"
 	eventlogv0 "github.com/hoophq/hoop/gateway/session/eventlog/v0"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
@@ -81,6 +82,8 @@ func (p *indexPlugin) OnConnect(pctx plugintypes.Context) error {
 
 func (p *indexPlugin) OnReceive(c plugintypes.Context, pkt *pb.Packet) (*plugintypes.ConnectResponse, error) {
 	switch pb.PacketType(pkt.GetType()) {
+	case pbagent.SessionOpen:
+		p.setTicketID(c)
 	case pbagent.PGConnectionWrite:
 		isSimpleQuery, queryBytes, err := pgtypes.SimpleQueryContent(pkt.Payload)
 		if !isSimpleQuery {
@@ -105,6 +108,17 @@ func (p *indexPlugin) OnReceive(c plugintypes.Context, pkt *pb.Packet) (*plugint
 				return nil, p.writeOnReceive(c.SID, eventlogv0.InputType, []byte(query))
 			}
 		}
+	case pbagent.RedisConnectionWrite:
+		if len(pkt.Payload) == 0 {
+			break
+		}
+		redisPkt, err := redistypes.DecodeCommand(pkt.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("session=%v - failed decoding redis command, err=%v", c.SID, err)
+		}
+		if cmd := redisPkt.String(); cmd != "" {
+			return nil, p.writeOnReceive(c.SID, eventlogv0.InputType, []byte(cmd+"\n"))
+		}
 	case pbclient.WriteStdout:
 		return nil, p.writeOnReceive(c.SID, eventlogv0.OutputType, pkt.Payload)
 	case pbclient.WriteStderr:
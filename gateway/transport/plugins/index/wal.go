@@ -24,6 +24,8 @@ type walLogRWMutex struct {
 	stdoutSize      int64
 	stdinTruncated  bool
 	stdoutTruncated bool
+	// it's only known when the session is opened
+	ticketID string
 }
 
 func (p *indexPlugin) writeOnConnect(c plugintypes.Context) error {
@@ -52,6 +54,16 @@ func (p *indexPlugin) writeOnConnect(c plugintypes.Context) error {
 	return nil
 }
 
+func (p *indexPlugin) setTicketID(c plugintypes.Context) {
+	walogm, ok := p.walSessionStore.Get(c.SID).(*walLogRWMutex)
+	if !ok {
+		return
+	}
+	walogm.mu.Lock()
+	defer walogm.mu.Unlock()
+	_, walogm.ticketID = c.SessionJustification()
+}
+
 func (p *indexPlugin) writeOnReceive(sid string, eventType eventlogv0.EventType, event []byte) error {
 	walLogObj := p.walSessionStore.Get(sid)
 	walogm, ok := walLogObj.(*walLogRWMutex)
@@ -145,6 +157,7 @@ func (p *indexPlugin) indexOnClose(c plugintypes.Context, isError bool) {
 		StartDate:         wh.StartDate.Format(time.RFC3339),
 		EndDate:           endDate.Format(time.RFC3339),
 		Duration:          durationInSecs,
+		TicketID:          walogm.ticketID,
 	}
 	indexCh := p.indexers.Get(c.OrgID).(chan *indexer.Session)
 	if indexCh == nil {
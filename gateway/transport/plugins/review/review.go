@@ -3,6 +3,8 @@ package review
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,15 +13,28 @@ import (
 	pb "github.com/hoophq/hoop/common/proto"
 	pbagent "github.com/hoophq/hoop/common/proto/agent"
 	pbclient "github.com/hoophq/hoop/common/proto/client"
+	"github.com/hoophq/hoop/gateway/pgrest"
+	pgaudit "github.com/hoophq/hoop/gateway/pgrest/audit"
+	pgautoapproval "github.com/hoophq/hoop/gateway/pgrest/autoapproval"
 	pgreview "github.com/hoophq/hoop/gateway/pgrest/review"
+	pgsession "github.com/hoophq/hoop/gateway/pgrest/session"
 	"github.com/hoophq/hoop/gateway/review"
 	"github.com/hoophq/hoop/gateway/storagev2/types"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 )
 
+const (
+	auditEventAutoApproved = "review-auto-approved"
+	auditEventBreakGlass   = "session-break-glass"
+
+	maxBreakGlassJustificationSize = 1000
+)
+
 type reviewPlugin struct {
 	apiURL    string
 	reviewSvc *review.Service
+	// the cancel functions of the time based sessions, released when the session disconnects
+	cancelFns sync.Map
 }
 
 func New(reviewSvc *review.Service, apiURL string) *reviewPlugin {
@@ -48,9 +63,22 @@ func (p *reviewPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plu
 		return nil, plugintypes.InternalErr("failed fetching review", err)
 	}
 
+	// the session was already released, the client sends it again when the agent is offline
+	if otrev != nil && otrev.IsBreakGlass() {
+		return p.reuseBreakGlass(pctx, otrev)
+	}
+
 	if otrev != nil && otrev.Type == review.ReviewTypeOneTime {
 		log.With("id", otrev.Id, "sid", pctx.SID, "user", otrev.ReviewOwner.Email, "org", pctx.OrgID,
 			"status", otrev.Status).Info("one time review")
+		if otrev.Status == types.ReviewStatusExpired {
+			return nil, plugintypes.InvalidArgument("the review of this session has expired, create a new one")
+		}
+		// the gateway changes the status to processing when running scheduled reviews
+		if otrev.Status == types.ReviewStatusApproved && otrev.ScheduledAt != nil {
+			return nil, plugintypes.InvalidArgument("the review of this session is scheduled to run at %s",
+				otrev.ScheduledAt.Format(time.RFC3339))
+		}
 		if !(otrev.Status == types.ReviewStatusApproved || otrev.Status == types.ReviewStatusProcessing) {
 			reviewURL := fmt.Sprintf("%s/plugins/reviews/%s", p.apiURL, otrev.Id)
 			p.setSpecReview(pkt)
@@ -78,8 +106,7 @@ func (p *reviewPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plu
 		log.With("sid", pctx.SID, "id", jitr.Id, "user", jitr.CreatedBy, "org", pctx.OrgID,
 			"revoke-at", jitr.RevokeAt.Format(time.RFC3339),
 			"duration", fmt.Sprintf("%vm", jitr.AccessDuration.Minutes())).Infof("jit access granted")
-		newCtx, _ := context.WithTimeout(pctx.Context, jitr.AccessDuration)
-		return &plugintypes.ConnectResponse{Context: newCtx, ClientPacket: nil}, nil
+		return p.withDeadline(pctx, time.Now().UTC().Add(jitr.AccessDuration)), nil
 	}
 	log.With("sid", pctx.SID, "orgid", pctx.GetOrgID()).Infof("jit review not found for connection id %v", pctx.ConnectionID)
 
@@ -97,15 +124,24 @@ func (p *reviewPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plu
 		}
 	}
 
-	if len(pctx.PluginConnectionConfig) == 0 {
+	// the workflow takes precedence over the approval groups of the connection
+	var workflow *types.ReviewWorkflow
+	groups := pctx.PluginConnectionConfig
+	connWorkflow, err := pgreview.New().FetchWorkflow(pctx, pctx.ConnectionID)
+	if err != nil {
+		return nil, plugintypes.InternalErr("failed fetching review workflow", err)
+	}
+	if connWorkflow != nil {
+		workflow = connWorkflow.ReviewWorkflow()
+		groups = review.WorkflowGroups(workflow)
+	}
+	if len(groups) == 0 {
 		err = fmt.Errorf("missing approval groups for connection")
 		return nil, plugintypes.InternalErr(err.Error(), err)
 	}
 
 	reviewGroups := make([]types.ReviewGroup, 0)
-	groups := make([]string, 0)
-	for _, s := range pctx.PluginConnectionConfig {
-		groups = append(groups, s)
+	for _, s := range groups {
 		reviewGroups = append(reviewGroups, types.ReviewGroup{
 			Group:  s,
 			Status: types.ReviewStatusPending,
@@ -143,6 +179,12 @@ func (p *reviewPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plu
 		Status:           types.ReviewStatusPending,
 		ReviewGroupsIds:  groups,
 		ReviewGroupsData: reviewGroups,
+		Workflow:         workflow,
+	}
+	newRev.Justification, newRev.TicketID = pctx.SessionJustification()
+	if pctx.ConnectionReviewTTL > 0 {
+		expiresAt := newRev.CreatedAt.Add(pctx.ConnectionReviewTTL)
+		newRev.ExpiresAt = &expiresAt
 	}
 
 	if !isJitReview {
@@ -157,9 +199,21 @@ func (p *reviewPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plu
 		newRev.InputEnvVars = inputEnvVars
 	}
 
+	// auto approved sessions are executed as non reviewed ones
+	rule, err := p.matchAutoApprovalRule(pctx, newRev)
+	if err != nil {
+		return nil, plugintypes.InternalErr("failed evaluating auto approval rules", err)
+	}
+	if rule != nil {
+		return p.autoApprove(pctx, newRev, rule)
+	}
+	if justification := string(pkt.Spec[pb.SpecBreakGlassJustification]); justification != "" {
+		return p.breakGlass(pctx, newRev, justification)
+	}
+
 	p.setSpecReview(pkt)
 	log.With("sid", pctx.SID, "id", newRev.Id, "user", pctx.UserID, "org", pctx.OrgID,
-		"type", reviewType, "duration", fmt.Sprintf("%vm", accessDuration.Minutes())).
+		"type", reviewType, "duration", fmt.Sprintf("%vm", accessDuration.Minutes()), "workflow", workflow != nil).
 		Infof("creating review")
 	if err := p.reviewSvc.Persist(pctx, newRev); err != nil {
 		return nil, plugintypes.InternalErr("failed saving review", err)
@@ -171,8 +225,164 @@ func (p *reviewPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plu
 	}}, nil
 }
 
-func (p *reviewPlugin) OnDisconnect(_ plugintypes.Context, errMsg error) error { return nil }
-func (p *reviewPlugin) OnShutdown()                                            {}
+func (p *reviewPlugin) matchAutoApprovalRule(pctx plugintypes.Context, rev *types.Review) (*pgrest.ReviewAutoApprovalRule, error) {
+	rules, err := pgautoapproval.New().FetchAll(pctx)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	return review.MatchAutoApprovalRule(rules, review.AutoApprovalRequest{
+		ConnectionName: pctx.ConnectionName,
+		ConnectionType: pb.ToConnectionType(pctx.ConnectionType, pctx.ConnectionSubType),
+		ReviewType:     rev.Type,
+		Input:          rev.Input,
+		UserGroups:     pctx.UserGroups,
+	}, time.Now().UTC()), nil
+}
+
+// autoApprove persists the review approved by the rule and releases the session
+func (p *reviewPlugin) autoApprove(pctx plugintypes.Context, rev *types.Review, rule *pgrest.ReviewAutoApprovalRule) (*plugintypes.ConnectResponse, error) {
+	review.AutoApprove(rev, rule, time.Now().UTC())
+	// one time reviews are executed in this session
+	if rev.Type == review.ReviewTypeOneTime {
+		rev.Status = types.ReviewStatusProcessing
+	}
+	log.With("sid", pctx.SID, "id", rev.Id, "user", pctx.UserID, "org", pctx.OrgID,
+		"type", rev.Type, "rule", rule.Name).Infof("creating auto approved review")
+	if err := p.reviewSvc.Persist(pctx, rev); err != nil {
+		return nil, plugintypes.InternalErr("failed saving auto approved review", err)
+	}
+	auditCtx := pgrest.NewAuditContext(pctx.OrgID, auditEventAutoApproved, pctx.UserEmail).
+		WithMetadata(map[string]any{
+			"review_id":  rev.Id,
+			"session_id": rev.Session,
+			"connection": rev.Connection.Name,
+			"type":       rev.Type,
+			"rule_id":    rule.ID,
+			"rule_name":  rule.Name,
+		})
+	if err := pgaudit.New().Create(auditCtx); err != nil {
+		log.With("sid", pctx.SID, "id", rev.Id).Warnf("failed creating audit event for auto approved review, err=%v", err)
+	}
+	if rev.Type == review.ReviewTypeJit {
+		return p.withDeadline(pctx, *rev.RevokeAt), nil
+	}
+	return nil, nil
+}
+
+// breakGlass releases the session without waiting for the approval and creates the review
+// to be performed retrospectively. The integrations are alerted about the access.
+func (p *reviewPlugin) breakGlass(pctx plugintypes.Context, rev *types.Review, justification string) (*plugintypes.ConnectResponse, error) {
+	if err := allowBreakGlass(pctx); err != nil {
+		log.With("sid", pctx.SID, "user", pctx.UserID, "org", pctx.OrgID, "connection", pctx.ConnectionName).
+			Infof("break-glass access denied, reason=%v", err)
+		return nil, err
+	}
+	if len(justification) > maxBreakGlassJustificationSize {
+		return nil, plugintypes.InvalidArgument("break-glass justification must not be greater than %v characters",
+			maxBreakGlassJustificationSize)
+	}
+	rev.BreakGlassJustification = justification
+	// the review is performed after the access, it must stay pending until someone reviews it
+	rev.ExpiresAt = nil
+	if rev.Type == review.ReviewTypeJit {
+		revokeAt := time.Now().UTC().Add(rev.AccessDuration)
+		rev.RevokeAt = &revokeAt
+	}
+	log.With("sid", pctx.SID, "id", rev.Id, "user", pctx.UserID, "org", pctx.OrgID,
+		"type", rev.Type, "connection", pctx.ConnectionName).Warnf("break-glass access, creating retrospective review")
+	if err := p.reviewSvc.Persist(pctx, rev); err != nil {
+		return nil, plugintypes.InternalErr("failed saving break-glass review", err)
+	}
+	if err := pgsession.New().UpdateBreakGlass(pctx, pctx.SID); err != nil {
+		return nil, plugintypes.InternalErr("failed updating break-glass session", err)
+	}
+	auditCtx := pgrest.NewAuditContext(pctx.OrgID, auditEventBreakGlass, pctx.UserEmail).
+		WithMetadata(map[string]any{
+			"review_id":     rev.Id,
+			"session_id":    rev.Session,
+			"connection":    rev.Connection.Name,
+			"type":          rev.Type,
+			"justification": justification,
+		})
+	if err := pgaudit.New().Create(auditCtx); err != nil {
+		log.With("sid", pctx.SID, "id", rev.Id).Warnf("failed creating audit event for break-glass access, err=%v", err)
+	}
+	// alerting must not delay the access
+	go func() {
+		for _, pl := range plugintypes.RegisteredPlugins {
+			if h, ok := pl.(plugintypes.BreakGlassHandler); ok {
+				h.OnBreakGlass(rev)
+			}
+		}
+	}()
+	if rev.Type == review.ReviewTypeJit {
+		return p.withDeadline(pctx, *rev.RevokeAt), nil
+	}
+	return nil, nil
+}
+
+// reuseBreakGlass releases a session that was already released with break-glass access.
+// It's only allowed for the owner of the review in the same connection while the user
+// is still allowed to use break-glass access.
+func (p *reviewPlugin) reuseBreakGlass(pctx plugintypes.Context, rev *types.Review) (*plugintypes.ConnectResponse, error) {
+	if rev.ReviewOwner.Email != pctx.UserEmail || rev.Connection.Id != pctx.ConnectionID {
+		log.With("id", rev.Id, "sid", pctx.SID, "user", pctx.UserEmail, "owner", rev.ReviewOwner.Email, "org", pctx.OrgID).
+			Warnf("break-glass session used by another user or connection")
+		return nil, plugintypes.PermissionDenied("the break-glass access of this session belongs to another user or connection")
+	}
+	if err := allowBreakGlass(pctx); err != nil {
+		return nil, err
+	}
+	if rev.Status == types.ReviewStatusRejected {
+		return nil, plugintypes.PermissionDenied("the break-glass access of this session was rejected")
+	}
+	log.With("id", rev.Id, "sid", pctx.SID, "org", pctx.OrgID).Infof("break-glass session, skipping review")
+	if rev.Type != review.ReviewTypeJit {
+		return nil, nil
+	}
+	// reviews created before tracking the revoke time expire after the access duration
+	revokeAt := rev.CreatedAt.Add(rev.AccessDuration)
+	if rev.RevokeAt != nil {
+		revokeAt = *rev.RevokeAt
+	}
+	if !time.Now().UTC().Before(revokeAt) {
+		return nil, plugintypes.InvalidArgument("the break-glass access of this session has expired")
+	}
+	return p.withDeadline(pctx, revokeAt), nil
+}
+
+// withDeadline returns a response with a context that ends the session at the deadline
+// of the time based access. The context is released when the session disconnects.
+func (p *reviewPlugin) withDeadline(pctx plugintypes.Context, deadline time.Time) *plugintypes.ConnectResponse {
+	ctx, cancelFn := context.WithDeadline(pctx.Context, deadline)
+	// a session opened again replaces the previous stream
+	if prev, loaded := p.cancelFns.Swap(pctx.SID, cancelFn); loaded {
+		prev.(context.CancelFunc)()
+	}
+	return &plugintypes.ConnectResponse{Context: ctx, ClientPacket: nil}
+}
+
+// allowBreakGlass validates if the user is a member of the groups allowed
+// to bypass the review of the connection, it's disabled when there're no groups.
+func allowBreakGlass(pctx plugintypes.Context) error {
+	if len(pctx.ConnectionBreakGlassGroups) == 0 {
+		return plugintypes.PermissionDenied("break-glass access is not enabled for the connection %v", pctx.ConnectionName)
+	}
+	for _, group := range pctx.UserGroups {
+		if slices.Contains(pctx.ConnectionBreakGlassGroups, group) {
+			return nil
+		}
+	}
+	return plugintypes.PermissionDenied("user is not allowed to use break-glass access in the connection %v", pctx.ConnectionName)
+}
+
+func (p *reviewPlugin) OnDisconnect(pctx plugintypes.Context, _ error) error {
+	if cancelFn, loaded := p.cancelFns.LoadAndDelete(pctx.SID); loaded {
+		cancelFn.(context.CancelFunc)()
+	}
+	return nil
+}
+func (p *reviewPlugin) OnShutdown() {}
 
 // indicate to other plugins that this packet has the review enabled
 // it will allow applying special logic for these cases
@@ -1,7 +1,6 @@
 package review
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -27,8 +26,7 @@ func (r *reviewPlugin) onReceiveOSS(pctx plugintypes.Context, pkt *pb.Packet) (*
 		log.With("session", pctx.SID, "id", jitr.Id, "user", jitr.CreatedBy, "org", pctx.OrgID,
 			"revoke-at", jitr.RevokeAt.Format(time.RFC3339),
 			"duration", fmt.Sprintf("%vm", jitr.AccessDuration.Minutes())).Infof("jit access granted")
-		newCtx, _ := context.WithTimeout(pctx.Context, jitr.AccessDuration)
-		return &plugintypes.ConnectResponse{Context: newCtx, ClientPacket: nil}, nil
+		return r.withDeadline(pctx, time.Now().UTC().Add(jitr.AccessDuration)), nil
 	}
 	// reviewType := review.ReviewTypeOneTime
 	accessDuration := time.Duration(time.Minute * 30)
@@ -0,0 +1,176 @@
+package review
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+)
+
+func TestAllowBreakGlass(t *testing.T) {
+	for _, tt := range []struct {
+		msg           string
+		allowedGroups []string
+		userGroups    []string
+		allow         bool
+	}{
+		{msg: "it must deny when break-glass is not enabled for the connection", userGroups: []string{"sre"}},
+		{msg: "it must deny users that aren't members of the allowed groups", allowedGroups: []string{"sre"}, userGroups: []string{"developers"}},
+		{msg: "it must deny users without groups", allowedGroups: []string{"sre"}},
+		{msg: "it must allow members of the allowed groups", allowedGroups: []string{"sre", "dba"}, userGroups: []string{"developers", "dba"}, allow: true},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := allowBreakGlass(plugintypes.Context{
+				ConnectionName:             "pgprod",
+				ConnectionBreakGlassGroups: tt.allowedGroups,
+				UserGroups:                 tt.userGroups,
+			})
+			if tt.allow {
+				if err != nil {
+					t.Fatalf("did not expect error, got=%v", err)
+				}
+				return
+			}
+			if _, ok := err.(*plugintypes.PermissionDeniedErr); !ok {
+				t.Fatalf("expected permission denied error, got=%T (%v)", err, err)
+			}
+		})
+	}
+}
+
+func TestBreakGlassDenied(t *testing.T) {
+	rev := &types.Review{Id: "review-id", Type: "onetime"}
+	pctx := plugintypes.Context{
+		SID:                        "sid",
+		ConnectionName:             "pgprod",
+		ConnectionBreakGlassGroups: []string{"sre"},
+		UserGroups:                 []string{"developers"},
+	}
+	// the review must not be persisted when the user is not allowed
+	resp, err := New(nil, "").breakGlass(pctx, rev, "production incident")
+	if _, ok := err.(*plugintypes.PermissionDeniedErr); !ok {
+		t.Fatalf("expected permission denied error, got=%T (%v)", err, err)
+	}
+	if resp != nil {
+		t.Errorf("expected nil response, got=%v", resp)
+	}
+	if rev.BreakGlassJustification != "" {
+		t.Errorf("expected review to not be changed, got justification=%q", rev.BreakGlassJustification)
+	}
+}
+
+func TestReuseBreakGlass(t *testing.T) {
+	revokeAt := time.Now().UTC().Add(time.Hour)
+	expiredAt := time.Now().UTC().Add(-time.Minute)
+	newReview := func(reviewType string, status types.ReviewStatus, revokeAt *time.Time) *types.Review {
+		return &types.Review{
+			Id:                      "review-id",
+			Type:                    reviewType,
+			Status:                  status,
+			CreatedAt:               time.Now().UTC().Add(-time.Hour * 2),
+			AccessDuration:          time.Hour,
+			RevokeAt:                revokeAt,
+			ReviewOwner:             types.ReviewOwner{Email: "owner@domain.tld"},
+			Connection:              types.ReviewConnection{Id: "conn-id", Name: "pgprod"},
+			BreakGlassJustification: "production incident",
+		}
+	}
+	newContext := func(email, connectionID string, userGroups ...string) plugintypes.Context {
+		return plugintypes.Context{
+			Context:                    context.Background(),
+			SID:                        "sid",
+			UserEmail:                  email,
+			ConnectionID:               connectionID,
+			ConnectionName:             "pgprod",
+			ConnectionBreakGlassGroups: []string{"sre"},
+			UserGroups:                 userGroups,
+		}
+	}
+	for _, tt := range []struct {
+		msg          string
+		pctx         plugintypes.Context
+		rev          *types.Review
+		wantErr      bool
+		wantDeadline *time.Time
+	}{
+		{
+			msg:     "it must deny a different user reusing the session",
+			pctx:    newContext("attacker@domain.tld", "conn-id", "sre"),
+			rev:     newReview("onetime", types.ReviewStatusPending, nil),
+			wantErr: true,
+		},
+		{
+			msg:     "it must deny reusing the session in another connection",
+			pctx:    newContext("owner@domain.tld", "other-conn-id", "sre"),
+			rev:     newReview("onetime", types.ReviewStatusPending, nil),
+			wantErr: true,
+		},
+		{
+			msg:     "it must deny the owner when break-glass is no longer allowed",
+			pctx:    newContext("owner@domain.tld", "conn-id", "developers"),
+			rev:     newReview("onetime", types.ReviewStatusPending, nil),
+			wantErr: true,
+		},
+		{
+			msg:     "it must deny when the review was rejected",
+			pctx:    newContext("owner@domain.tld", "conn-id", "sre"),
+			rev:     newReview("onetime", types.ReviewStatusRejected, nil),
+			wantErr: true,
+		},
+		{
+			msg:     "it must deny when the time based access has expired",
+			pctx:    newContext("owner@domain.tld", "conn-id", "sre"),
+			rev:     newReview("jit", types.ReviewStatusPending, &expiredAt),
+			wantErr: true,
+		},
+		{
+			msg:     "it must deny time based access without revoke time after the access duration",
+			pctx:    newContext("owner@domain.tld", "conn-id", "sre"),
+			rev:     newReview("jit", types.ReviewStatusPending, nil),
+			wantErr: true,
+		},
+		{
+			msg:  "it must release the one time session to the owner",
+			pctx: newContext("owner@domain.tld", "conn-id", "sre"),
+			rev:  newReview("onetime", types.ReviewStatusPending, nil),
+		},
+		{
+			msg:          "it must release the time based session until the revoke time",
+			pctx:         newContext("owner@domain.tld", "conn-id", "sre"),
+			rev:          newReview("jit", types.ReviewStatusPending, &revokeAt),
+			wantDeadline: &revokeAt,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			p := New(nil, "")
+			resp, err := p.reuseBreakGlass(tt.pctx, tt.rev)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got response=%v", resp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error, got=%v", err)
+			}
+			if tt.wantDeadline == nil {
+				if resp != nil {
+					t.Errorf("expected nil response, got=%v", resp)
+				}
+				return
+			}
+			deadline, ok := resp.Context.Deadline()
+			if !ok || !deadline.Equal(*tt.wantDeadline) {
+				t.Errorf("expected deadline=%v, got=%v", tt.wantDeadline, deadline)
+			}
+			if err := p.OnDisconnect(tt.pctx, nil); err != nil {
+				t.Fatalf("did not expect error on disconnect, got=%v", err)
+			}
+			if resp.Context.Err() != context.Canceled {
+				t.Errorf("expected context to be canceled on disconnect, got=%v", resp.Context.Err())
+			}
+		})
+	}
+}
@@ -48,8 +48,11 @@ func (p *slackPlugin) processEventResponse(ev *event) {
 	}
 	log.With("session", sid).Infof("found a valid approver user=%s, slackid=%s",
 		slackApprover.Email, ev.msg.SlackID)
-	userContext := storagev2.NewContext(slackApprover.Id, ev.orgID)
+	userContext := storagev2.NewContext(slackApprover.Id, ev.orgID).
+		WithSlackID(ev.msg.SlackID)
 	userContext.UserGroups = slackApprover.Groups
+	userContext.UserName = slackApprover.Name
+	userContext.UserEmail = slackApprover.Email
 
 	// perform the review in the system
 	log.With("session", sid).Infof("performing review, kind=%v, id=%v, status=%s, group=%v",
@@ -83,6 +86,12 @@ func (p *slackPlugin) performExecReview(ev *event, ctx *storagev2.Context, statu
 		}
 		err = ev.ss.UpdateMessageStatus(ev.msg, fmt.Sprintf("• _review has already been `%s`_", status))
 	case nil:
+		// keep the buttons available for the remaining approvals of the workflow
+		if rev.Workflow != nil && rev.Status == types.ReviewStatusPending {
+			err = ev.ss.PostEphemeralMessage(ev.msg, fmt.Sprintf("Your review has been registered, waiting for the next approvals:\n%s",
+				strings.Join(parseStages(rev.Workflow), "\n")))
+			break
+		}
 		isApproved := rev.Status == types.ReviewStatusApproved
 		err = ev.ss.UpdateMessage(ev.msg, isApproved)
 
@@ -114,6 +123,12 @@ func (p *slackPlugin) performJitReview(ev *event, ctx *storagev2.Context, status
 		}
 		err = ev.ss.UpdateMessageStatus(ev.msg, fmt.Sprintf("• _jit has already been `%s`_", status))
 	case nil:
+		// keep the buttons available for the remaining approvals of the workflow
+		if j.Workflow != nil && j.Status == types.ReviewStatusPending {
+			err = ev.ss.PostEphemeralMessage(ev.msg, fmt.Sprintf("Your review has been registered, waiting for the next approvals:\n%s",
+				strings.Join(parseStages(j.Workflow), "\n")))
+			break
+		}
 		isApproved := j.Status == types.ReviewStatusApproved
 		err = ev.ss.UpdateMessage(ev.msg, isApproved)
 
@@ -3,6 +3,7 @@ package slack
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/hoophq/hoop/common/log"
@@ -222,10 +223,13 @@ func (p *slackPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plug
 		sreq.ID = rev.Id
 		sreq.WebappURL = fmt.Sprintf("%s/sessions/%s", p.idpProvider.ApiURL, pctx.SID)
 		sreq.ApprovalGroups = parseGroups(rev.ReviewGroupsData)
+		sreq.ApprovalStages = parseStages(rev.Workflow)
 		if rev.AccessDuration > 0 {
 			sreq.SessionTime = &rev.AccessDuration
 		}
 		sreq.Script = rev.Input
+		sreq.Justification = rev.Justification
+		sreq.TicketID = rev.TicketID
 	}
 
 	if sreq.WebappURL == "" || len(sreq.ApprovalGroups) == 0 || len(sreq.ApprovalGroups) >= slackMaxButtons {
@@ -240,6 +244,68 @@ func (p *slackPlugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plug
 	return nil, nil
 }
 
+// OnReviewExpired notifies the channels of the connection that the review has expired
+func (p *slackPlugin) OnReviewExpired(rev *types.Review) {
+	slackSvc := getSlackServiceInstance(rev.OrgId)
+	if slackSvc == nil {
+		return
+	}
+	pl, err := pgplugins.New().FetchOne(pgrest.NewOrgContext(rev.OrgId), plugintypes.PluginSlackName)
+	if err != nil {
+		log.With("session", rev.Session).Warnf("failed fetching slack plugin, reason=%v", err)
+		return
+	}
+	msg := &slack.MessageReviewExpired{
+		ID:           rev.Id,
+		SessionID:    rev.Session,
+		Connection:   rev.Connection.Name,
+		OwnerSlackID: rev.ReviewOwner.SlackID,
+	}
+	if pl != nil {
+		for _, conn := range pl.Connections {
+			if conn.ConnectionID == rev.Connection.Id {
+				msg.SlackChannels = conn.Config
+				break
+			}
+		}
+	}
+	log.With("session", rev.Session).Infof("sending slack review expired message, conn=%v", msg.Connection)
+	if err := slackSvc.SendMessageReviewExpired(msg); err != nil {
+		log.With("session", rev.Session).Errorf("failed sending slack review expired message, reason=%v", err)
+	}
+}
+
+// OnBreakGlass alerts the channels of the connection that the review was bypassed
+func (p *slackPlugin) OnBreakGlass(rev *types.Review) {
+	slackSvc := getSlackServiceInstance(rev.OrgId)
+	if slackSvc == nil {
+		return
+	}
+	pl, err := pgplugins.New().FetchOne(pgrest.NewOrgContext(rev.OrgId), plugintypes.PluginSlackName)
+	if err != nil {
+		log.With("session", rev.Session).Warnf("failed fetching slack plugin, reason=%v", err)
+		return
+	}
+	msg := &slack.MessageBreakGlass{
+		SessionID:     rev.Session,
+		Connection:    rev.Connection.Name,
+		UserEmail:     rev.ReviewOwner.Email,
+		Justification: rev.BreakGlassJustification,
+	}
+	if pl != nil {
+		for _, conn := range pl.Connections {
+			if conn.ConnectionID == rev.Connection.Id {
+				msg.SlackChannels = conn.Config
+				break
+			}
+		}
+	}
+	log.With("session", rev.Session).Infof("sending slack break-glass message, conn=%v", msg.Connection)
+	if err := slackSvc.SendMessageBreakGlass(msg); err != nil {
+		log.With("session", rev.Session).Errorf("failed sending slack break-glass message, reason=%v", err)
+	}
+}
+
 func (p *slackPlugin) OnDisconnect(_ plugintypes.Context, _ error) error { return nil }
 func (p *slackPlugin) OnShutdown()                                       {}
 
@@ -274,3 +340,24 @@ func parseGroups(reviewGroups []types.ReviewGroup) []string {
 	}
 	return groups
 }
+
+// parseStages describes each stage of the workflow in the following format:
+// 1. *stage-name*: 2 approval(s) from group1, group2 (1/2)
+func parseStages(wf *types.ReviewWorkflow) []string {
+	if wf == nil {
+		return nil
+	}
+	var stages []string
+	for i, stage := range wf.Stages {
+		minApprovals := max(stage.MinApprovals, 1)
+		var approved int
+		for _, a := range stage.Approvals {
+			if a.Status == types.ReviewStatusApproved {
+				approved++
+			}
+		}
+		stages = append(stages, fmt.Sprintf("%v. *%s*: %v approval(s) from %s (%v/%v)",
+			i+1, stage.Name, minApprovals, strings.Join(stage.Groups, ", "), approved, minApprovals))
+	}
+	return stages
+}
@@ -0,0 +1,164 @@
+package ticketvalidation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+	pbagent "github.com/hoophq/hoop/common/proto/agent"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+)
+
+const (
+	// PluginConfigEnvVarsParam is the key of the plugin context params
+	// containing the top level configuration of the plugin
+	PluginConfigEnvVarsParam = "ticket_validation_config"
+	// ValidationURLConfigKey is the plugin configuration key containing the
+	// http(s) endpoint that validates the ticket ids of the organization
+	ValidationURLConfigKey = "VALIDATION_URL"
+	// AuthorizationConfigKey is the plugin configuration key containing
+	// the value of the Authorization header sent to the endpoint (optional)
+	AuthorizationConfigKey = "AUTHORIZATION_HEADER"
+
+	requestTimeout  = 10 * time.Second
+	maxResponseSize = 64 * 1024
+)
+
+// Request is the payload sent to the validation endpoint
+type Request struct {
+	TicketID       string   `json:"ticket_id"`
+	Justification  string   `json:"justification"`
+	SessionID      string   `json:"session_id"`
+	UserID         string   `json:"user_id"`
+	UserEmail      string   `json:"user_email"`
+	UserGroups     []string `json:"user_groups"`
+	Connection     string   `json:"connection"`
+	ConnectionType string   `json:"connection_type"`
+	Verb           string   `json:"verb"`
+}
+
+// Response is the payload that the validation endpoint must return with a 2xx status code.
+// The message is presented to the user when the ticket is not valid.
+type Response struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message"`
+}
+
+type plugin struct {
+	client *http.Client
+}
+
+func New() *plugin { return &plugin{client: &http.Client{Timeout: requestTimeout}} }
+
+func (p *plugin) Name() string                          { return plugintypes.PluginTicketValidationName }
+func (p *plugin) OnStartup(_ plugintypes.Context) error { return nil }
+func (p *plugin) OnUpdate(_, newState *types.Plugin) error {
+	if newState == nil || newState.Config == nil {
+		return nil
+	}
+	_, err := parseValidationURL(newState.Config.EnvVars)
+	return err
+}
+func (p *plugin) OnConnect(_ plugintypes.Context) error { return nil }
+
+// OnReceive validates the ticket id of the session against the configured endpoint
+// before the session is opened or a review is created
+func (p *plugin) OnReceive(pctx plugintypes.Context, pkt *pb.Packet) (*plugintypes.ConnectResponse, error) {
+	if pb.PacketType(pkt.Type) != pbagent.SessionOpen {
+		return nil, nil
+	}
+	envVars, _ := pctx.ParamsData[PluginConfigEnvVarsParam].(map[string]string)
+	validationURL, err := parseValidationURL(envVars)
+	if err != nil {
+		return nil, plugintypes.InternalErr("ticket validation plugin is not configured properly", err)
+	}
+	justification, ticketID := pctx.SessionJustification()
+	if ticketID == "" {
+		return nil, plugintypes.InvalidArgument("the connection %v requires a ticket id to open sessions", pctx.ConnectionName)
+	}
+	resp, err := p.validate(validationURL, decodeConfig(envVars, AuthorizationConfigKey), &Request{
+		TicketID:       ticketID,
+		Justification:  justification,
+		SessionID:      pctx.SID,
+		UserID:         pctx.UserID,
+		UserEmail:      pctx.UserEmail,
+		UserGroups:     pctx.UserGroups,
+		Connection:     pctx.ConnectionName,
+		ConnectionType: pb.ToConnectionType(pctx.ConnectionType, pctx.ConnectionSubType).String(),
+		Verb:           pctx.ClientVerb,
+	})
+	if err != nil {
+		return nil, plugintypes.InternalErr("failed validating ticket id", err)
+	}
+	log.With("sid", pctx.SID, "connection", pctx.ConnectionName, "user", pctx.UserEmail).
+		Infof("ticket %v validated, valid=%v", ticketID, resp.Valid)
+	if !resp.Valid {
+		if resp.Message == "" {
+			return nil, plugintypes.InvalidArgument("the ticket %v is not valid to access the connection %v", ticketID, pctx.ConnectionName)
+		}
+		return nil, plugintypes.InvalidArgument("the ticket %v is not valid to access the connection %v: %v", ticketID, pctx.ConnectionName, resp.Message)
+	}
+	return nil, nil
+}
+func (p *plugin) OnDisconnect(_ plugintypes.Context, _ error) error { return nil }
+func (p *plugin) OnShutdown()                                       {}
+
+func (p *plugin) validate(validationURL, authorization string, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding request: %v", err)
+	}
+	ctx, cancelFn := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancelFn()
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", validationURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		httpReq.Header.Set("Authorization", authorization)
+	}
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed reading response: %v", err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, fmt.Errorf("endpoint returned status=%v, body=%v", httpResp.StatusCode, string(data))
+	}
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed decoding response: %v", err)
+	}
+	return &resp, nil
+}
+
+func parseValidationURL(envVars map[string]string) (string, error) {
+	validationURL := decodeConfig(envVars, ValidationURLConfigKey)
+	if validationURL == "" {
+		return "", fmt.Errorf("missing %v configuration", ValidationURLConfigKey)
+	}
+	u, err := url.Parse(validationURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%v must be a valid http(s) url", ValidationURLConfigKey)
+	}
+	return validationURL, nil
+}
+
+func decodeConfig(envVars map[string]string, key string) string {
+	data, _ := base64.StdEncoding.DecodeString(envVars[key])
+	return string(data)
+}
@@ -0,0 +1,138 @@
+package ticketvalidation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/hoophq/hoop/common/proto"
+	pbagent "github.com/hoophq/hoop/common/proto/agent"
+	"github.com/hoophq/hoop/gateway/storagev2/types"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeConfig(validationURL string) map[string]string {
+	return map[string]string{
+		ValidationURLConfigKey: base64.StdEncoding.EncodeToString([]byte(validationURL)),
+		AuthorizationConfigKey: base64.StdEncoding.EncodeToString([]byte("Bearer secret")),
+	}
+}
+
+func newContext(envVars map[string]string, ticketID string) plugintypes.Context {
+	return plugintypes.Context{
+		SID:            "sid-1",
+		UserEmail:      "john@domain.tld",
+		ConnectionName: "pg",
+		ConnectionType: "database",
+		Metadata: map[string]any{
+			plugintypes.MetadataJustificationKey: "fix the payments of the incident",
+			plugintypes.MetadataTicketIDKey:      ticketID,
+		},
+		ParamsData: map[string]any{PluginConfigEnvVarsParam: envVars},
+	}
+}
+
+func TestOnReceive(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch req.TicketID {
+		case "JIRA-1":
+			_ = json.NewEncoder(w).Encode(Response{Valid: true})
+		case "JIRA-2":
+			_ = json.NewEncoder(w).Encode(Response{Valid: false, Message: "the issue is closed"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		msg     string
+		pctx    plugintypes.Context
+		pktType string
+		wantErr any
+	}{
+		{
+			msg:     "it must allow sessions with valid tickets",
+			pctx:    newContext(encodeConfig(srv.URL), "JIRA-1"),
+			pktType: pbagent.SessionOpen,
+		},
+		{
+			msg:     "it must reject sessions with invalid tickets",
+			pctx:    newContext(encodeConfig(srv.URL), "JIRA-2"),
+			pktType: pbagent.SessionOpen,
+			wantErr: &plugintypes.InvalidArgErr{},
+		},
+		{
+			msg:     "it must reject sessions without a ticket id",
+			pctx:    newContext(encodeConfig(srv.URL), ""),
+			pktType: pbagent.SessionOpen,
+			wantErr: &plugintypes.InvalidArgErr{},
+		},
+		{
+			msg:     "it must return an internal error when the endpoint fails",
+			pctx:    newContext(encodeConfig(srv.URL), "JIRA-3"),
+			pktType: pbagent.SessionOpen,
+			wantErr: &plugintypes.InternalError{},
+		},
+		{
+			msg:     "it must return an internal error when the plugin is not configured",
+			pctx:    newContext(nil, "JIRA-1"),
+			pktType: pbagent.SessionOpen,
+			wantErr: &plugintypes.InternalError{},
+		},
+		{
+			msg:     "it must ignore packets other than session open",
+			pctx:    newContext(encodeConfig(srv.URL), "JIRA-2"),
+			pktType: pbagent.ExecWriteStdin,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			_, err := New().OnReceive(tt.pctx, &pb.Packet{Type: tt.pktType})
+			if tt.wantErr == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.IsType(t, tt.wantErr, err, "err=%v", err)
+		})
+	}
+}
+
+func TestOnUpdate(t *testing.T) {
+	for _, tt := range []struct {
+		msg     string
+		config  *types.PluginConfig
+		wantErr bool
+	}{
+		{
+			msg:    "it must accept http(s) urls",
+			config: &types.PluginConfig{EnvVars: encodeConfig("https://tickets.domain.tld/validate")},
+		},
+		{
+			msg:     "it must reject configuration without the validation url",
+			config:  &types.PluginConfig{EnvVars: map[string]string{}},
+			wantErr: true,
+		},
+		{
+			msg:     "it must reject urls with other schemes",
+			config:  &types.PluginConfig{EnvVars: encodeConfig("ftp://tickets.domain.tld")},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := New().OnUpdate(nil, &types.Plugin{Config: tt.config})
+			assert.Equal(t, tt.wantErr, err != nil, "err=%v", err)
+		})
+	}
+}
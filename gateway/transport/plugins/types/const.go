@@ -16,6 +16,12 @@ const (
 	PluginDLPName                        = "dlp"
 	PluginDatabaseCredentialsManagerName = "database-credentials-manager"
 	PluginWebhookName                    = "webhooks"
+	PluginTicketValidationName           = "ticket_validation"
+	PluginAccessRulesName                = "access_rules"
+
+	// session metadata attributes provided by users when opening sessions
+	MetadataJustificationKey = "justification"
+	MetadataTicketIDKey      = "ticket_id"
 )
 
 var (
@@ -19,6 +19,10 @@ type InvalidArgErr struct {
 	baseErr
 }
 
+type PermissionDeniedErr struct {
+	baseErr
+}
+
 func (e *baseErr) Error() string { return e.message }
 
 func (e *InternalError) FullErr() string {
@@ -38,3 +42,8 @@ func InvalidArgument(format string, a ...any) *InvalidArgErr {
 	_, file, _, _ := runtime.Caller(1)
 	return &InvalidArgErr{baseErr: baseErr{message: fmt.Sprintf(format, a...), caller: file}}
 }
+
+func PermissionDenied(format string, a ...any) *PermissionDeniedErr {
+	_, file, _, _ := runtime.Caller(1)
+	return &PermissionDeniedErr{baseErr: baseErr{message: fmt.Sprintf(format, a...), caller: file}}
+}
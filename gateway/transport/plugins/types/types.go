@@ -26,6 +26,9 @@ type Context struct {
 	UserEmail      string
 	UserSlackID    string
 	UserGroups     []string
+	// the time the user has authenticated in the identity provider,
+	// it's zero when the access token doesn't inform it
+	UserAuthTime time.Time
 
 	// Connection attributes
 	ConnectionID      string
@@ -34,6 +37,19 @@ type Context struct {
 	ConnectionSubType string
 	ConnectionCommand []string
 	ConnectionSecret  map[string]any
+	// the amount of time a review could stay pending, zero means it never expires
+	ConnectionReviewTTL time.Duration
+	// require a justification and a ticket id when opening sessions
+	ConnectionRequireJustification bool
+	// the regular expression that the ticket id must match
+	ConnectionTicketIDPattern string
+	// require the user to have authenticated within this duration to open sessions
+	ConnectionRequireRecentAuth time.Duration
+	// statements matching these rules are denied by the access control plugin
+	ConnectionDenyRules []string
+	// the groups allowed to bypass the review of the connection (break-glass)
+	ConnectionBreakGlassGroups []string
+	ConnectionTags             []string
 
 	// Agent attributes
 	AgentID   string
@@ -46,6 +62,8 @@ type Context struct {
 	// Gateway client attributes
 	ClientVerb   string
 	ClientOrigin string
+	// the ip address of the peer connected to the gateway
+	ClientSourceIP string
 
 	Script   string
 	Labels   map[string]string
@@ -63,6 +81,24 @@ type Plugin interface {
 	OnDisconnect(pctx Context, errMsg error) error
 }
 
+// ReviewExpiredHandler is implemented by plugins that notify
+// integrations when a pending review expires
+type ReviewExpiredHandler interface {
+	OnReviewExpired(rev *types.Review)
+}
+
+// ReviewScheduledExecHandler is implemented by plugins that notify
+// integrations when the gateway executes a scheduled review
+type ReviewScheduledExecHandler interface {
+	OnReviewScheduledExec(rev *types.Review, exitCode int, outputStatus string)
+}
+
+// BreakGlassHandler is implemented by plugins that alert integrations
+// when a session is opened bypassing the review (break-glass)
+type BreakGlassHandler interface {
+	OnBreakGlass(rev *types.Review)
+}
+
 type ConnectResponse struct {
 	// The new context to propagate to the client transport layer
 	Context context.Context
@@ -89,6 +125,13 @@ func (c *Context) Validate() error {
 	return nil
 }
 
+// SessionJustification returns the justification and the ticket id provided by the user when opening the session
+func (c Context) SessionJustification() (justification, ticketID string) {
+	justification, _ = c.Metadata[MetadataJustificationKey].(string)
+	ticketID, _ = c.Metadata[MetadataTicketIDKey].(string)
+	return
+}
+
 func (m GenericMap) Get(key string) any { return m[key] }
 func (m GenericMap) GetString(key string) string {
 	val, ok := m[key]
@@ -3,6 +3,9 @@ package webhooks
 const (
 	eventSessionOpenType         = "session.open"
 	eventSessionCloseType        = "session.close"
+	eventReviewExpiredType       = "review.expired"
+	eventReviewScheduledExecType = "review.scheduled.exec"
+	eventSessionBreakGlassType   = "session.breakglass"
 	eventMSTeamsReviewCreateType = "microsoftteams.review.create"
 	maxInputSize                 = 10 * 1000 // 10KB
 )
@@ -202,6 +202,7 @@ func (p *plugin) processSessionOpenEvent(ctx plugintypes.Context, pkt *pb.Packet
 		inputEventEnvs = append(inputEventEnvs, key)
 	}
 	fullCommand := append(ctx.ConnectionCommand, clientArgs...)
+	justification, ticketID := ctx.SessionJustification()
 	eventID := uuid.NewString()
 	out, err := p.client.Message.Create(ctxtimeout, appID, &svix.MessageIn{
 		EventType: eventSessionOpenType,
@@ -223,6 +224,8 @@ func (p *plugin) processSessionOpenEvent(ctx plugintypes.Context, pkt *pb.Packet
 			"has_input_args":     len(clientArgs) > 0,
 			"command":            fullCommand,
 			"verb":               ctx.ClientVerb,
+			"justification":      justification,
+			"ticket_id":          ticketID,
 		},
 	})
 	if err != nil {
@@ -272,6 +275,117 @@ func (p *plugin) processSessionCloseEvent(ctx plugintypes.Context, pkt *pb.Packe
 	}
 }
 
+// OnReviewExpired sends an event when a pending review expires
+func (p *plugin) OnReviewExpired(rev *types.Review) {
+	if !p.hasLoadedApp(rev.OrgId) {
+		return
+	}
+	appID := rev.OrgId
+	eventID := uuid.NewString()
+	ctxtimeout, cancelFn := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancelFn()
+	out, err := p.client.Message.Create(ctxtimeout, appID, &svix.MessageIn{
+		EventType: eventReviewExpiredType,
+		EventId:   *svix.NullableString(func() *string { v := eventID; return &v }()),
+		// TODO: use openapi schema
+		Payload: map[string]any{
+			"event_type":      eventReviewExpiredType,
+			"id":              rev.Id,
+			"session_id":      rev.Session,
+			"type":            rev.Type,
+			"connection":      rev.Connection.Name,
+			"user_id":         rev.ReviewOwner.Id,
+			"user_email":      rev.ReviewOwner.Email,
+			"approval_groups": parseGroups(rev.ReviewGroupsData),
+			"created_at":      rev.CreatedAt,
+			"expires_at":      rev.ExpiresAt,
+		},
+	})
+	if err != nil {
+		log.With("appid", appID).Warnf("failed sending webhook event to remote source, event=%s, err=%v",
+			eventReviewExpiredType, err)
+		return
+	}
+	if out != nil {
+		log.With("appid", appID).Infof("sent webhook with success, id=%s, event=%s, eventid=%s",
+			out.Id, out.EventType, eventID)
+	}
+}
+
+// OnReviewScheduledExec sends an event when the gateway executes a scheduled review
+func (p *plugin) OnReviewScheduledExec(rev *types.Review, exitCode int, outputStatus string) {
+	if !p.hasLoadedApp(rev.OrgId) {
+		return
+	}
+	appID := rev.OrgId
+	eventID := uuid.NewString()
+	ctxtimeout, cancelFn := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancelFn()
+	out, err := p.client.Message.Create(ctxtimeout, appID, &svix.MessageIn{
+		EventType: eventReviewScheduledExecType,
+		EventId:   *svix.NullableString(func() *string { v := eventID; return &v }()),
+		// TODO: use openapi schema
+		Payload: map[string]any{
+			"event_type":    eventReviewScheduledExecType,
+			"id":            rev.Id,
+			"session_id":    rev.Session,
+			"status":        rev.Status,
+			"connection":    rev.Connection.Name,
+			"user_id":       rev.ReviewOwner.Id,
+			"user_email":    rev.ReviewOwner.Email,
+			"scheduled_at":  rev.ScheduledAt,
+			"exit_code":     exitCode,
+			"output_status": outputStatus,
+		},
+	})
+	if err != nil {
+		log.With("appid", appID).Warnf("failed sending webhook event to remote source, event=%s, err=%v",
+			eventReviewScheduledExecType, err)
+		return
+	}
+	if out != nil {
+		log.With("appid", appID).Infof("sent webhook with success, id=%s, event=%s, eventid=%s",
+			out.Id, out.EventType, eventID)
+	}
+}
+
+// OnBreakGlass sends an event when a session is opened bypassing the review
+func (p *plugin) OnBreakGlass(rev *types.Review) {
+	if !p.hasLoadedApp(rev.OrgId) {
+		return
+	}
+	appID := rev.OrgId
+	eventID := uuid.NewString()
+	ctxtimeout, cancelFn := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancelFn()
+	out, err := p.client.Message.Create(ctxtimeout, appID, &svix.MessageIn{
+		EventType: eventSessionBreakGlassType,
+		EventId:   *svix.NullableString(func() *string { v := eventID; return &v }()),
+		// TODO: use openapi schema
+		Payload: map[string]any{
+			"event_type":      eventSessionBreakGlassType,
+			"id":              rev.Session,
+			"review_id":       rev.Id,
+			"type":            rev.Type,
+			"connection":      rev.Connection.Name,
+			"user_id":         rev.ReviewOwner.Id,
+			"user_email":      rev.ReviewOwner.Email,
+			"justification":   rev.BreakGlassJustification,
+			"approval_groups": parseGroups(rev.ReviewGroupsData),
+			"created_at":      rev.CreatedAt,
+		},
+	})
+	if err != nil {
+		log.With("appid", appID).Warnf("failed sending webhook event to remote source, event=%s, err=%v",
+			eventSessionBreakGlassType, err)
+		return
+	}
+	if out != nil {
+		log.With("appid", appID).Infof("sent webhook with success, id=%s, event=%s, eventid=%s",
+			out.Id, out.EventType, eventID)
+	}
+}
+
 func (p *plugin) OnDisconnect(_ plugintypes.Context, _ error) error { return nil }
 func (p *plugin) OnShutdown()                                       {}
 
@@ -181,6 +181,12 @@ func (s *Server) proccessConnectOKAck(stream *streamclient.ProxyStream) error {
 			pluginCtx.ConnectionSubType = conn.SubType
 			pluginCtx.ConnectionCommand = conn.Command
 			pluginCtx.ConnectionSecret = conn.AsSecrets()
+			pluginCtx.ConnectionRequireJustification = conn.RequireJustification
+			pluginCtx.ConnectionTicketIDPattern = conn.TicketIDPattern
+			pluginCtx.ConnectionRequireRecentAuth = time.Duration(conn.RequireRecentAuth) * time.Minute
+			pluginCtx.ConnectionDenyRules = conn.DenyRules
+			pluginCtx.ConnectionBreakGlassGroups = conn.BreakGlassGroups
+			pluginCtx.ConnectionTags = conn.Tags
 
 			pluginCtx.AgentID = conn.AgentID
 			pluginCtx.AgentMode = conn.Agent.Mode
@@ -219,6 +225,12 @@ func (s *Server) proccessConnectOKAck(stream *streamclient.ProxyStream) error {
 				pb.SpecClientRequestPort: []byte(req.RequestPort),
 			},
 		}
+		// the proxy manager doesn't send a justification, it opens
+		// sessions only when the connection doesn't require it
+		if err := setSessionJustification(stream, &pctx, onOpenSessionPkt.Spec); err != nil {
+			disp.sendResponse(nil, err)
+			return err
+		}
 		connectResponse, err := stream.PluginExecOnReceive(pctx, onOpenSessionPkt)
 		if err != nil {
 			disp.sendResponse(nil, err)
@@ -145,13 +145,21 @@ func (s *Server) Connect(stream pb.Transport_ConnectServer) (err error) {
 		UserEmail:      gwctx.UserContext.UserEmail,
 		UserSlackID:    gwctx.UserContext.SlackID,
 		UserGroups:     gwctx.UserContext.UserGroups,
-
-		ConnectionID:      gwctx.Connection.ID,
-		ConnectionName:    gwctx.Connection.Name,
-		ConnectionType:    gwctx.Connection.Type,
-		ConnectionSubType: gwctx.Connection.SubType,
-		ConnectionCommand: gwctx.Connection.CmdEntrypoint,
-		ConnectionSecret:  gwctx.Connection.Secrets,
+		UserAuthTime:   gwctx.AuthTime,
+
+		ConnectionID:                   gwctx.Connection.ID,
+		ConnectionName:                 gwctx.Connection.Name,
+		ConnectionType:                 gwctx.Connection.Type,
+		ConnectionSubType:              gwctx.Connection.SubType,
+		ConnectionCommand:              gwctx.Connection.CmdEntrypoint,
+		ConnectionSecret:               gwctx.Connection.Secrets,
+		ConnectionReviewTTL:            gwctx.Connection.ReviewTTL,
+		ConnectionRequireJustification: gwctx.Connection.RequireJustification,
+		ConnectionTicketIDPattern:      gwctx.Connection.TicketIDPattern,
+		ConnectionRequireRecentAuth:    gwctx.Connection.RequireRecentAuth,
+		ConnectionDenyRules:            gwctx.Connection.DenyRules,
+		ConnectionBreakGlassGroups:     gwctx.Connection.BreakGlassGroups,
+		ConnectionTags:                 gwctx.Connection.Tags,
 
 		AgentID:   gwctx.Connection.AgentID,
 		AgentName: gwctx.Connection.AgentName,
@@ -1,10 +1,15 @@
 package streamclient
 
 import (
+	"time"
+
 	"github.com/hoophq/hoop/common/log"
 	pb "github.com/hoophq/hoop/common/proto"
 	pgplugins "github.com/hoophq/hoop/gateway/pgrest/plugins"
+	pluginsaccesscontrol "github.com/hoophq/hoop/gateway/transport/plugins/accesscontrol"
+	pluginsdbcredentials "github.com/hoophq/hoop/gateway/transport/plugins/dbcredentials"
 	pluginsslack "github.com/hoophq/hoop/gateway/transport/plugins/slack"
+	pluginsticketvalidation "github.com/hoophq/hoop/gateway/transport/plugins/ticketvalidation"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -16,9 +21,22 @@ type runtimePlugin struct {
 }
 
 func loadRuntimePlugins(ctx plugintypes.Context) ([]runtimePlugin, error) {
+	// the step-up authentication is validated before the plugins accept the connection
+	if err := validateRecentAuth(ctx, time.Now().UTC()); err != nil {
+		return nil, err
+	}
 	pluginsConfig := make([]runtimePlugin, 0)
 	var nonRegisteredPlugins []string
 	for _, p := range plugintypes.RegisteredPlugins {
+		// the access rules of the organization are evaluated for every connection,
+		// a rule is scoped to connections by its own attributes
+		if p.Name() == plugintypes.PluginAccessRulesName {
+			if err := p.OnConnect(ctx); err != nil {
+				log.Warnf("plugin %q refused to accept connection %q, err=%v", p.Name(), ctx.SID, err)
+				return pluginsConfig, status.Errorf(codes.FailedPrecondition, err.Error())
+			}
+			continue
+		}
 		p1, err := pgplugins.New().FetchOne(ctx, p.Name())
 		if err != nil {
 			log.Errorf("failed retrieving plugin %q, err=%v", p.Name(), err)
@@ -35,8 +53,22 @@ func loadRuntimePlugins(ctx plugintypes.Context) ([]runtimePlugin, error) {
 			}
 		}
 
+		if p.Name() == plugintypes.PluginAccessControlName {
+			if p1.Config != nil {
+				ctx.ParamsData[pluginsaccesscontrol.PluginConfigEnvVarsParam] = p1.Config.EnvVars
+			}
+		}
+
+		if p.Name() == plugintypes.PluginTicketValidationName {
+			if p1.Config != nil {
+				ctx.ParamsData[pluginsticketvalidation.PluginConfigEnvVarsParam] = p1.Config.EnvVars
+			}
+		}
+
+		attached := false
 		for _, c := range p1.Connections {
 			if c.Name == ctx.ConnectionName {
+				attached = true
 				config := removePluginConfigDuplicates(c.Config)
 				ep := runtimePlugin{
 					Plugin: p,
@@ -52,6 +84,11 @@ func loadRuntimePlugins(ctx plugintypes.Context) ([]runtimePlugin, error) {
 				break
 			}
 		}
+		// the deny rules of the connection and of the organization are evaluated even if it's not
+		// attached to the plugin, the groups allowed to access it are validated when fetching the connection
+		if !attached && p.Name() == plugintypes.PluginAccessControlName && pluginsaccesscontrol.HasDenyRules(ctx) {
+			pluginsConfig = append(pluginsConfig, runtimePlugin{Plugin: p})
+		}
 	}
 	if len(nonRegisteredPlugins) > 0 {
 		log.With("sid", ctx.SID).Infof("non registered plugins %v", nonRegisteredPlugins)
@@ -94,6 +131,18 @@ func (s *ProxyStream) GetRedactInfoTypes() []string {
 	return infoTypes
 }
 
+// GetDatabaseCredentialsGrants return the grant statements of the database credentials manager plugin.
+// It returns nil if the plugin is not enabled for the connection
+func (s *ProxyStream) GetDatabaseCredentialsGrants() []string {
+	for _, p := range s.runtimePlugins {
+		if p.Plugin.Name() == plugintypes.PluginDatabaseCredentialsManagerName {
+			connType := pb.ToConnectionType(s.pluginCtx.ConnectionType, s.pluginCtx.ConnectionSubType)
+			return pluginsdbcredentials.Grants(connType, p.config)
+		}
+	}
+	return nil
+}
+
 func removePluginConfigDuplicates(strSlice []string) []string {
 	allKeys := make(map[string]bool)
 	list := make([]string, 0)
@@ -3,6 +3,9 @@ package streamclient
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,11 +13,14 @@ import (
 	"github.com/hoophq/hoop/common/memory"
 	pb "github.com/hoophq/hoop/common/proto"
 	pbagent "github.com/hoophq/hoop/common/proto/agent"
+	pbclient "github.com/hoophq/hoop/common/proto/client"
+	"github.com/hoophq/hoop/gateway/appconfig"
 	sessionstorage "github.com/hoophq/hoop/gateway/storagev2/session"
 	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
 	streamtypes "github.com/hoophq/hoop/gateway/transport/streamclient/types"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -58,6 +64,11 @@ type ProxyStream struct {
 	runtimePlugins []runtimePlugin
 	pluginCtx      *plugintypes.Context
 	stateTime      time.Time
+
+	// bytes of payload transferred from client to agent (sent)
+	// and from agent to client (received)
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
 }
 
 func GetProxyStream(sid string) *ProxyStream {
@@ -66,6 +77,17 @@ func GetProxyStream(sid string) *ProxyStream {
 	return stream
 }
 
+// ListProxyStreams returns the active proxy streams of an organization
+func ListProxyStreams(orgID string) []*ProxyStream {
+	var items []*ProxyStream
+	for _, obj := range proxyStore.List() {
+		if s, _ := obj.(*ProxyStream); s != nil && s.pluginCtx.OrgID == orgID {
+			items = append(items, s)
+		}
+	}
+	return items
+}
+
 func NewProxy(pluginCtx *plugintypes.Context, s pb.Transport_ConnectServer) *ProxyStream {
 	streamCtx := s.Context()
 	ctx, cancelFn := context.WithCancelCause(streamCtx)
@@ -93,9 +115,75 @@ func NewProxy(pluginCtx *plugintypes.Context, s pb.Transport_ConnectServer) *Pro
 	stream.pluginCtx.SID = sessionID
 	stream.pluginCtx.ClientOrigin = stream.GetMeta("origin")
 	stream.pluginCtx.ClientVerb = stream.GetMeta("verb")
+	if p, ok := peer.FromContext(streamCtx); ok && p.Addr != nil {
+		stream.pluginCtx.ClientSourceIP = clientSourceIP(p.Addr, md, appconfig.Get().TrustedProxies())
+	}
 	return stream
 }
 
+// clientSourceIP returns the address of the client. The address informed by the api of the
+// gateway (clientexec) is trusted when the connection is local and the X-Forwarded-For
+// header is trusted when the connection comes from one of the trusted proxies.
+func clientSourceIP(peerAddr net.Addr, md metadata.MD, trustedProxies []string) string {
+	peerIP, _, _ := net.SplitHostPort(peerAddr.String())
+	ip := net.ParseIP(peerIP)
+	if ip == nil {
+		return peerIP
+	}
+	if ip.IsLoopback() {
+		if v := md.Get("client-ip"); len(v) > 0 && net.ParseIP(v[0]) != nil {
+			return v[0]
+		}
+		return peerIP
+	}
+	trustedNets := parseNetworks(trustedProxies)
+	if !containsIP(trustedNets, ip) {
+		return peerIP
+	}
+	var forwardedAddrs []string
+	for _, val := range md.Get("x-forwarded-for") {
+		for _, addr := range strings.Split(val, ",") {
+			forwardedAddrs = append(forwardedAddrs, strings.TrimSpace(addr))
+		}
+	}
+	// each proxy appends the address of its peer, the first untrusted address from the right is the client
+	for i := len(forwardedAddrs) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(forwardedAddrs[i])
+		if forwardedIP == nil {
+			break
+		}
+		if !containsIP(trustedNets, forwardedIP) {
+			return forwardedAddrs[i]
+		}
+	}
+	return peerIP
+}
+
+func parseNetworks(addrs []string) (networks []*net.IPNet) {
+	for _, addr := range addrs {
+		if !strings.Contains(addr, "/") {
+			if ip := net.ParseIP(addr); ip != nil && ip.To4() != nil {
+				addr += "/32"
+			} else {
+				addr += "/128"
+			}
+		}
+		if _, n, err := net.ParseCIDR(addr); err == nil {
+			networks = append(networks, n)
+		}
+	}
+	return
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // Override context from transport stream
 func (s *ProxyStream) Context() context.Context { return s.context }
 func (s *ProxyStream) ContextCauseError() error { return context.Cause(s.context) }
@@ -110,6 +198,9 @@ func (s *ProxyStream) GetMeta(key string) string {
 // SetPluginContext allows overriding the plugin context configuration
 func (s *ProxyStream) SetPluginContext(fn func(pctx *plugintypes.Context)) { fn(s.pluginCtx) }
 func (s *ProxyStream) PluginContext() plugintypes.Context                  { return *s.pluginCtx }
+func (s *ProxyStream) StartedAt() time.Time                                { return s.stateTime }
+func (s *ProxyStream) BytesSent() int64                                    { return s.bytesSent.Load() }
+func (s *ProxyStream) BytesReceived() int64                                { return s.bytesReceived.Load() }
 
 func (s *ProxyStream) String() string {
 	return fmt.Sprintf("user=%v,hostname=%v,origin=%v,verb=%v,platform=%v,version=%v,license=%v",
@@ -174,8 +265,29 @@ func (s *ProxyStream) Close(errMsg error) error {
 	return nil
 }
 
+// Kill terminates the session on behalf of the reason, the client receives
+// a SessionClose packet and the agent is notified to close the session.
+func (s *ProxyStream) Kill(reason error) error {
+	if !proxyStore.Has(s.pluginCtx.SID) {
+		return nil
+	}
+	_ = s.Send(&pb.Packet{
+		Type:    pbclient.SessionClose,
+		Spec:    map[string][]byte{pb.SpecGatewaySessionID: []byte(s.pluginCtx.SID)},
+		Payload: []byte(reason.Error()),
+	})
+	return s.Close(reason)
+}
+
+// Send sends a packet to the client keeping track of the bytes transferred
+func (s *ProxyStream) Send(pkt *pb.Packet) error {
+	s.bytesReceived.Add(int64(len(pkt.Payload)))
+	return s.Transport_ConnectServer.Send(pkt)
+}
+
 func (s *ProxyStream) SendToAgent(pkt *pb.Packet) error {
 	if agentStream := GetAgentStream(s.StreamAgentID()); agentStream != nil {
+		s.bytesSent.Add(int64(len(pkt.Payload)))
 		return agentStream.Send(pkt)
 	}
 	return pb.ErrAgentOffline
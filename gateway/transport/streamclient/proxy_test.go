@@ -0,0 +1,83 @@
+package streamclient
+
+import (
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestClientSourceIP(t *testing.T) {
+	trustedProxies := []string{"10.0.0.0/8", "192.168.1.10"}
+	for _, tt := range []struct {
+		msg      string
+		peerAddr string
+		md       metadata.MD
+		want     string
+	}{
+		{
+			msg:      "it must use the address of the peer",
+			peerAddr: "203.0.113.10:51000",
+			md:       metadata.MD{},
+			want:     "203.0.113.10",
+		},
+		{
+			msg:      "it must use the address informed by the api in local connections",
+			peerAddr: "127.0.0.1:51000",
+			md:       metadata.Pairs("client-ip", "203.0.113.10"),
+			want:     "203.0.113.10",
+		},
+		{
+			msg:      "it must use the loopback address in local connections without the client address",
+			peerAddr: "127.0.0.1:51000",
+			md:       metadata.MD{},
+			want:     "127.0.0.1",
+		},
+		{
+			msg:      "it must ignore the client address of remote connections",
+			peerAddr: "203.0.113.10:51000",
+			md:       metadata.Pairs("client-ip", "198.51.100.7"),
+			want:     "203.0.113.10",
+		},
+		{
+			msg:      "it must ignore invalid client addresses",
+			peerAddr: "127.0.0.1:51000",
+			md:       metadata.Pairs("client-ip", "not-an-ip"),
+			want:     "127.0.0.1",
+		},
+		{
+			msg:      "it must use the forwarded address of trusted proxies",
+			peerAddr: "10.0.0.5:51000",
+			md:       metadata.Pairs("x-forwarded-for", "203.0.113.10"),
+			want:     "203.0.113.10",
+		},
+		{
+			msg:      "it must skip the chain of trusted proxies",
+			peerAddr: "10.0.0.5:51000",
+			md:       metadata.Pairs("x-forwarded-for", "198.51.100.7, 203.0.113.10, 192.168.1.10, 10.1.2.3"),
+			want:     "203.0.113.10",
+		},
+		{
+			msg:      "it must ignore the forwarded address of untrusted peers",
+			peerAddr: "203.0.113.10:51000",
+			md:       metadata.Pairs("x-forwarded-for", "198.51.100.7"),
+			want:     "203.0.113.10",
+		},
+		{
+			msg:      "it must use the address of the proxy when the forwarded addresses are invalid",
+			peerAddr: "10.0.0.5:51000",
+			md:       metadata.Pairs("x-forwarded-for", "unknown"),
+			want:     "10.0.0.5",
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peerAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := clientSourceIP(addr, tt.md, trustedProxies); got != tt.want {
+				t.Errorf("expected source ip %v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package streamclient
+
+import (
+	"time"
+
+	"github.com/hoophq/hoop/common/log"
+	pb "github.com/hoophq/hoop/common/proto"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+)
+
+// validateRecentAuth refuses connections that require a recent authentication (step-up)
+// when the user has authenticated in the identity provider before the required window.
+// Tokens that don't inform the time of the authentication are always refused.
+func validateRecentAuth(pctx plugintypes.Context, now time.Time) error {
+	maxAge := pctx.ConnectionRequireRecentAuth
+	if maxAge <= 0 {
+		return nil
+	}
+	if !pctx.UserAuthTime.IsZero() && now.Sub(pctx.UserAuthTime) <= maxAge {
+		return nil
+	}
+	log.With("sid", pctx.SID, "connection", pctx.ConnectionName).
+		Infof("step-up authentication required, user=%v, auth-time=%v, max-age=%v",
+			pctx.UserEmail, pctx.UserAuthTime.Format(time.RFC3339), maxAge)
+	return pb.NewStepUpAuthError(pctx.ConnectionName, int(maxAge.Minutes()))
+}
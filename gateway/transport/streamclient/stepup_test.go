@@ -0,0 +1,48 @@
+package streamclient
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/hoophq/hoop/common/proto"
+	plugintypes "github.com/hoophq/hoop/gateway/transport/plugins/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRecentAuth(t *testing.T) {
+	now := time.Now().UTC()
+	for _, tt := range []struct {
+		msg        string
+		pctx       plugintypes.Context
+		wantStepUp bool
+	}{
+		{
+			msg:  "it must allow connections that don't require a recent authentication",
+			pctx: plugintypes.Context{ConnectionName: "pg"},
+		},
+		{
+			msg:  "it must allow users that have authenticated within the window",
+			pctx: plugintypes.Context{ConnectionName: "pg", ConnectionRequireRecentAuth: 15 * time.Minute, UserAuthTime: now.Add(-10 * time.Minute)},
+		},
+		{
+			msg:        "it must refuse users that have authenticated before the window",
+			pctx:       plugintypes.Context{ConnectionName: "pg", ConnectionRequireRecentAuth: 15 * time.Minute, UserAuthTime: now.Add(-16 * time.Minute)},
+			wantStepUp: true,
+		},
+		{
+			msg:        "it must refuse tokens without the time of the authentication",
+			pctx:       plugintypes.Context{ConnectionName: "pg", ConnectionRequireRecentAuth: 15 * time.Minute},
+			wantStepUp: true,
+		},
+	} {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := validateRecentAuth(tt.pctx, now)
+			if !tt.wantStepUp {
+				assert.Nil(t, err)
+				return
+			}
+			assert.True(t, pb.IsStepUpAuthError(err), "expected a step-up error, got %v", err)
+			assert.Contains(t, err.Error(), "15 minute(s)")
+		})
+	}
+}
@@ -0,0 +1,10 @@
+BEGIN;
+
+SET search_path TO private;
+
+ALTER TABLE blobs
+DROP COLUMN blob_storage,
+DROP COLUMN blob_key,
+DROP COLUMN blob_size;
+
+COMMIT;
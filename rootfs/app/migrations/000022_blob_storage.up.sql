@@ -0,0 +1,11 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the content of a blob could be stored in an external storage (filesystem, s3),
+-- in this case the blob_stream column is empty and the content is obtained by the key
+ALTER TABLE blobs ADD COLUMN blob_storage VARCHAR(32) NOT NULL DEFAULT 'postgres';
+ALTER TABLE blobs ADD COLUMN blob_key TEXT;
+ALTER TABLE blobs ADD COLUMN blob_size BIGINT;
+
+COMMIT;
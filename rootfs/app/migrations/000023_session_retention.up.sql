@@ -0,0 +1,23 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- retention policies are applied to sessions matching all the non empty attributes:
+-- connection type, verb and connection tag. When more than one policy matches a session,
+-- the longest retention is used.
+CREATE TABLE retention_policies(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+
+    name VARCHAR(128) NOT NULL,
+    connection_type VARCHAR(64) NULL,
+    verb VARCHAR(64) NULL,
+    connection_tag VARCHAR(128) NULL,
+    retention_days INT NOT NULL CHECK (retention_days > 0),
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(org_id, name)
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+SET search_path TO private;
+
+DROP TABLE IF EXISTS review_workflows;
+ALTER TABLE reviews DROP COLUMN IF EXISTS workflow;
+
+COMMIT;
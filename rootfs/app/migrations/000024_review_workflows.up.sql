@@ -0,0 +1,24 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the approval workflow of a connection. The stages are approved in order and
+-- each stage requires a minimum number of approvals from any of its groups.
+CREATE TABLE review_workflows(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+    connection_id UUID NOT NULL REFERENCES connections (id) ON DELETE CASCADE,
+
+    stages JSONB NOT NULL,
+    allow_self_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    separation_of_duties BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(org_id, connection_id)
+);
+
+-- a snapshot of the workflow when the review was created with the state of each stage
+ALTER TABLE reviews ADD COLUMN workflow JSONB NULL;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- it's not possible to remove a value from an enum type,
+-- the expired reviews are moved to the rejected state instead
+UPDATE reviews SET status = 'REJECTED' WHERE status = 'EXPIRED';
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE connections DROP COLUMN IF EXISTS review_ttl_sec;
+
+COMMIT;
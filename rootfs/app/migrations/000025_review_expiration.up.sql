@@ -0,0 +1,11 @@
+BEGIN;
+
+SET search_path TO private;
+
+ALTER TYPE enum_reviews_status ADD VALUE IF NOT EXISTS 'EXPIRED';
+
+-- the amount of time (in seconds) a review could stay pending before expiring
+ALTER TABLE connections ADD COLUMN review_ttl_sec INT NULL;
+ALTER TABLE reviews ADD COLUMN expires_at TIMESTAMP NULL;
+
+COMMIT;
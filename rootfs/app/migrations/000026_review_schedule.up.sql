@@ -0,0 +1,8 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the time to execute an approved one time review
+ALTER TABLE reviews ADD COLUMN scheduled_at TIMESTAMP NULL;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- auto approval rules approve reviews matching all the non empty attributes of a rule.
+-- The business hours attribute is a json object containing the timezone, the weekdays
+-- (0 is sunday) and the start and end hours in the format HH:MM
+CREATE TABLE review_auto_approval_rules(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+
+    name VARCHAR(128) NOT NULL,
+    connections VARCHAR(128)[] NOT NULL DEFAULT '{}',
+    read_only BOOLEAN NOT NULL DEFAULT FALSE,
+    query_pattern TEXT NULL,
+    groups VARCHAR(100)[] NOT NULL DEFAULT '{}',
+    business_hours JSONB NULL,
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(org_id, name)
+);
+
+COMMIT;
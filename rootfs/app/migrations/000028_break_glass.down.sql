@@ -0,0 +1,8 @@
+BEGIN;
+
+SET search_path TO private;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS break_glass;
+ALTER TABLE reviews DROP COLUMN IF EXISTS break_glass_justification;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- sessions opened bypassing the review in emergencies,
+-- the review is created to be performed retrospectively
+ALTER TABLE sessions ADD COLUMN break_glass BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE reviews ADD COLUMN break_glass_justification TEXT NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+SET search_path TO private;
+
+ALTER TABLE connections DROP COLUMN IF EXISTS require_justification;
+ALTER TABLE connections DROP COLUMN IF EXISTS ticket_id_pattern;
+ALTER TABLE reviews DROP COLUMN IF EXISTS justification;
+ALTER TABLE reviews DROP COLUMN IF EXISTS ticket_id;
+
+COMMIT;
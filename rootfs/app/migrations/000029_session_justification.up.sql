@@ -0,0 +1,12 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- connections that require users to provide a reason
+-- and a ticket reference when opening sessions
+ALTER TABLE connections ADD COLUMN require_justification BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE connections ADD COLUMN ticket_id_pattern TEXT NULL;
+ALTER TABLE reviews ADD COLUMN justification TEXT NULL;
+ALTER TABLE reviews ADD COLUMN ticket_id VARCHAR(255) NULL;
+
+COMMIT;
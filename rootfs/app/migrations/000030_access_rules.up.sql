@@ -0,0 +1,24 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- access rules are boolean expressions evaluated over the attributes of a user
+-- when connecting to a connection. A matching deny rule refuses the access, when there
+-- are allow rules applied to a connection, at least one of them must match
+CREATE TABLE access_rules(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+
+    name VARCHAR(128) NOT NULL,
+    description TEXT NULL,
+    effect VARCHAR(10) NOT NULL CHECK (effect IN ('allow', 'deny')),
+    expression TEXT NOT NULL,
+    connections VARCHAR(128)[] NOT NULL DEFAULT '{}',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(org_id, name)
+);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path TO private;
+
+DROP TABLE IF EXISTS user_group_grants;
+DELETE FROM user_groups WHERE expires_at IS NOT NULL;
+ALTER TABLE user_groups DROP COLUMN IF EXISTS expires_at;
+
+COMMIT;
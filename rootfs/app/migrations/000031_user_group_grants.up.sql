@@ -0,0 +1,28 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- memberships with an expiration are granted for a period of time,
+-- the groups of a user only contain the memberships that aren't expired
+ALTER TABLE user_groups ADD COLUMN expires_at TIMESTAMP NULL;
+
+-- the history of the time-bound memberships granted to users.
+-- The status of a grant is one of: active, expired, revoked or replaced (by a new grant)
+CREATE TABLE user_group_grants(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+    user_id UUID NOT NULL,
+    user_email VARCHAR(255) NOT NULL,
+
+    group_name VARCHAR(100) NOT NULL,
+    reason TEXT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    granted_by VARCHAR(255) NOT NULL,
+    revoked_by VARCHAR(255) NULL,
+
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+COMMIT;
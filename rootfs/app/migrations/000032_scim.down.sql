@@ -0,0 +1,8 @@
+BEGIN;
+
+SET search_path TO private;
+
+DROP TABLE IF EXISTS scim_groups;
+DROP TABLE IF EXISTS scim_tokens;
+
+COMMIT;
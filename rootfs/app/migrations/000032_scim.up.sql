@@ -0,0 +1,26 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the bearer token used by identity providers to provision users and groups (SCIM),
+-- only the sha256 hash of the token is stored
+CREATE TABLE scim_tokens(
+    org_id UUID PRIMARY KEY REFERENCES orgs (id),
+    token_hash VARCHAR(128) NOT NULL UNIQUE,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- groups provisioned by identity providers, the members of a group
+-- are the users containing the group name in their groups
+CREATE TABLE scim_groups(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+    display_name VARCHAR(100) NOT NULL,
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(org_id, display_name)
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+SET search_path TO private;
+
+ALTER TABLE login DROP COLUMN IF EXISTS provider;
+
+COMMIT;
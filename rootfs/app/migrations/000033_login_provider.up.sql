@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the name of the identity provider chosen to perform the login,
+-- empty when it's the default provider of the gateway
+ALTER TABLE login ADD COLUMN provider VARCHAR(64) NULL;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the credentials of users authenticated by the gateway (AUTH_METHOD=local).
+-- The time-based one time password (totp) is enabled after the user enrolls it,
+-- recovery codes and passwords are stored as hashes
+CREATE TABLE user_credentials(
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+    password_hash VARCHAR(255) NOT NULL,
+    totp_secret VARCHAR(64) NOT NULL DEFAULT '',
+    totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    recovery_codes TEXT[] NOT NULL DEFAULT '{}',
+
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP NULL,
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+COMMIT;
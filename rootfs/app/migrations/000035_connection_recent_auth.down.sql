@@ -0,0 +1,7 @@
+BEGIN;
+
+SET search_path TO private;
+
+ALTER TABLE connections DROP COLUMN IF EXISTS require_recent_auth;
+
+COMMIT;
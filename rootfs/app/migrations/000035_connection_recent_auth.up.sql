@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- connections that require users to have authenticated in
+-- the identity provider within the last minutes (step-up)
+ALTER TABLE connections ADD COLUMN require_recent_auth INT NOT NULL DEFAULT 0;
+
+COMMIT;
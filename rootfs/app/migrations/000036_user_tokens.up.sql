@@ -0,0 +1,23 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- personal access tokens owned by users to automate the access to the gateway,
+-- the scopes limit what the token is allowed to do and only the sha256 hash of the token is stored
+CREATE TABLE user_tokens(
+    id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES orgs (id),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    token_hash VARCHAR(128) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(org_id, user_id, name)
+);
+
+COMMIT;
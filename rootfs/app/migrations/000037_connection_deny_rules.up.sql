@@ -0,0 +1,25 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the rules (regular expressions) of statements denied by the access control plugin,
+-- they were stored with the prefix deny: along with the groups of the plugin connection
+ALTER TABLE connections ADD COLUMN deny_rules TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE connections c SET deny_rules = r.rules
+FROM (
+    SELECT pc.connection_id, array_agg(substr(v, length('deny:') + 1)) AS rules
+    FROM plugin_connections pc
+    INNER JOIN plugins p ON p.id = pc.plugin_id AND p.name = 'access_control'
+    CROSS JOIN LATERAL unnest(pc.config) AS v
+    WHERE v LIKE 'deny:%'
+    GROUP BY pc.connection_id
+) r
+WHERE r.connection_id = c.id;
+
+UPDATE plugin_connections pc SET config = ARRAY(SELECT v FROM unnest(pc.config) AS v WHERE v NOT LIKE 'deny:%')
+FROM plugins p
+WHERE p.id = pc.plugin_id AND p.name = 'access_control'
+AND EXISTS (SELECT 1 FROM unnest(pc.config) AS v WHERE v LIKE 'deny:%');
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path TO private;
+
+-- the groups allowed to bypass the review of a connection (break-glass),
+-- it's disabled by default
+ALTER TABLE connections ADD COLUMN break_glass_groups TEXT[] NOT NULL DEFAULT '{}';
+
+COMMIT;